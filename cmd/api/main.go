@@ -1,13 +1,20 @@
 package main
 
 import (
+	"beldur/internal/account"
 	"beldur/internal/app"
 	"beldur/pkg/auth/jwt"
+	"beldur/pkg/auth/oidc"
+	"beldur/pkg/blob"
 	"beldur/pkg/db/postgres"
 	"beldur/pkg/db/tx"
 	"beldur/pkg/logger"
+	"beldur/pkg/mail"
+	"beldur/pkg/middleware"
 	"context"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -28,19 +35,123 @@ func main() {
 		JwtService: jwtService,
 		Transactor: transactor,
 		QProvider:  querier,
+		BlobStore:  buildBlobStore(),
+		Mailer:     buildMailer(),
+
+		PasswordResetURL: os.Getenv("PASSWORD_RESET_URL"),
+		EmailVerifyURL:   os.Getenv("EMAIL_VERIFY_URL"),
+
+		IdentityProviders: buildIdentityProviders(),
+		SessionCookie:     buildSessionCookie(),
 	}
 
 	fiber := app.NewDev(deps)
+	go fiber.RunJobs(context.Background())
 	if err := fiber.Listen(port); err != nil {
 		panic(err)
 	}
 }
 
+// buildJwtService signs with the key of JWT_SIGNING_KEY_FILE, named JWT_SIGNING_KEY_ID, or else
+// with JWT_SECRET. JWT_VERIFICATION_KEYS lists the previous keys as kid=file, comma separated,
+// PEM private or public keys. With a signing key, JWT_SECRET keeps verifying the HS256 tokens
+// unless JWT_SECRET_FALLBACK is false, to be turned off once they have all expired.
 func buildJwtService() *jwt.Service {
-	secret := []byte(os.Getenv("JWT_SECRET"))
 	expiration, _ := time.ParseDuration(os.Getenv("JWT_EXPIRATION"))
-	issuer := os.Getenv("JWT_ISSUER")
-	return jwt.NewService(secret, expiration, issuer)
+	cfg := jwt.Config{
+		Secret:     []byte(os.Getenv("JWT_SECRET")),
+		Expiration: expiration,
+		Issuer:     os.Getenv("JWT_ISSUER"),
+	}
+
+	if path := os.Getenv("JWT_SIGNING_KEY_FILE"); path != "" {
+		key, err := jwt.ParsePrivateKeyPEM(os.Getenv("JWT_SIGNING_KEY_ID"), readFile(path))
+		if err != nil {
+			panic(err)
+		}
+		cfg.SigningKey = &key
+
+		if fallback, err := strconv.ParseBool(os.Getenv("JWT_SECRET_FALLBACK")); err == nil && !fallback {
+			cfg.Secret = nil
+		}
+	}
+
+	for _, entry := range strings.Split(os.Getenv("JWT_VERIFICATION_KEYS"), ",") {
+		kid, path, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if !found {
+			continue
+		}
+		data := readFile(path)
+		key, err := jwt.ParsePublicKeyPEM(kid, data)
+		if err != nil {
+			key, err = jwt.ParsePrivateKeyPEM(kid, data)
+		}
+		if err != nil {
+			panic(err)
+		}
+		cfg.VerificationKeys = append(cfg.VerificationKeys, key)
+	}
+
+	service, err := jwt.New(cfg)
+	if err != nil {
+		panic(err)
+	}
+	return service
+}
+
+func readFile(path string) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// buildSessionCookie reads the attributes of the session cookie, its max age follows
+// JWT_EXPIRATION
+func buildSessionCookie() middleware.SessionCookie {
+	secure, _ := strconv.ParseBool(os.Getenv("COOKIE_SECURE"))
+	return middleware.SessionCookie{
+		Domain:   os.Getenv("COOKIE_DOMAIN"),
+		Secure:   secure,
+		SameSite: os.Getenv("COOKIE_SAMESITE"),
+	}
+}
+
+// buildIdentityProviders reads the OpenID Connect providers listed in OIDC_PROVIDERS,
+// each configured with OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET and _REDIRECT_URL
+func buildIdentityProviders() map[string]account.IdentityProvider {
+	providers := map[string]account.IdentityProvider{}
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers[strings.ToLower(name)] = oidc.NewProvider(oidc.Config{
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+		}, nil)
+	}
+	return providers
+}
+
+func buildBlobStore() *blob.LocalStore {
+	store, err := blob.NewLocalStore(os.Getenv("UPLOAD_DIR"))
+	if err != nil {
+		panic(err)
+	}
+	return store
+}
+
+func buildMailer() *mail.Outbox {
+	outbox, err := mail.NewOutbox(os.Getenv("MAIL_OUTBOX_DIR"))
+	if err != nil {
+		panic(err)
+	}
+	return outbox
 }
 
 func buildTransactorQuerierProvider() (tx.Transactor, postgres.QuerierProvider) {
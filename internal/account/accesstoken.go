@@ -0,0 +1,193 @@
+package account
+
+import (
+	"beldur/internal/id"
+	"beldur/internal/player"
+	"beldur/pkg/auth"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/logger"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// AccessTokenPrefix tells the personal access tokens apart from the session tokens
+	AccessTokenPrefix = "bldr_pat_"
+	// MaxAccessTokens is the number of personal access tokens an account can hold
+	MaxAccessTokens = 20
+	// AccessTokenNameMaxCharacters is the length limit of the name of a token
+	AccessTokenNameMaxCharacters = 50
+)
+
+// AccessToken is a personal access token, used by the scripts and bots of the user instead of
+// a session. Only the hash of the token is stored, and it can do only what its scopes allow.
+type AccessToken struct {
+	Id         id.AccessTokenId
+	AccountId  id.AccountId
+	Name       string
+	Scopes     []string
+	ExpiresAt  time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// NewAccessToken validates the name and the scopes, which are kept sorted without duplicates
+func NewAccessToken(accountId id.AccountId, name string, scopes []string, expiresAt time.Time) (*AccessToken, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > AccessTokenNameMaxCharacters {
+		return nil, ErrInvalidAccessTokenName
+	}
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !auth.IsScope(scope) {
+			return nil, ErrInvalidScope
+		}
+	}
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+
+	return &AccessToken{
+		AccountId: accountId,
+		Name:      name,
+		Scopes:    slices.Compact(scopes),
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func (t *AccessToken) IsExpired(at time.Time) bool {
+	return !at.Before(t.ExpiresAt)
+}
+
+// AccessTokenManagement is an USE CASE where the owner of an account creates, lists and
+// revokes its personal access tokens
+type AccessTokenManagement struct {
+	tokens AccessTokenStore
+}
+
+func NewAccessTokenManagement(tokens AccessTokenStore) *AccessTokenManagement {
+	return &AccessTokenManagement{tokens: tokens}
+}
+
+// Create gives back the new token, it is shown only once
+func (uc *AccessTokenManagement) Create(ctx context.Context, req CreateAccessTokenRequest, accountId id.AccountId) (CreateAccessTokenResponse, error) {
+	expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+	token, err := NewAccessToken(accountId, req.Name, req.Scopes, expiresAt)
+	if err != nil {
+		return CreateAccessTokenResponse{}, err
+	}
+
+	count, err := uc.tokens.CountAccessTokens(ctx, accountId)
+	if err != nil {
+		logger.Debug("failed to count access tokens", "account", accountId, "err", err)
+		return CreateAccessTokenResponse{}, ErrDatabaseError
+	}
+	if count >= MaxAccessTokens {
+		return CreateAccessTokenResponse{}, ErrTooManyAccessTokens
+	}
+
+	plain, hash := auth.NewSecretToken()
+	saved, err := uc.tokens.SaveAccessToken(ctx, token, hash)
+	if err != nil {
+		logger.Debug("failed to save access token", "account", accountId, "err", err)
+		return CreateAccessTokenResponse{}, ErrDatabaseError
+	}
+
+	return CreateAccessTokenResponse{
+		AccessTokenResponse: toAccessTokenResponse(saved),
+		Token:               AccessTokenPrefix + plain,
+	}, nil
+}
+
+// List gives back the tokens of the account, the expired ones included
+func (uc *AccessTokenManagement) List(ctx context.Context, accountId id.AccountId) ([]AccessTokenResponse, error) {
+	tokens, err := uc.tokens.FindAccessTokens(ctx, accountId)
+	if err != nil {
+		logger.Debug("failed to find access tokens", "account", accountId, "err", err)
+		return nil, ErrDatabaseError
+	}
+
+	resp := make([]AccessTokenResponse, len(tokens))
+	for i := range tokens {
+		resp[i] = toAccessTokenResponse(&tokens[i])
+	}
+	return resp, nil
+}
+
+// Revoke deletes the token, it can't be used anymore
+func (uc *AccessTokenManagement) Revoke(ctx context.Context, tokenId id.AccessTokenId, accountId id.AccountId) error {
+	if err := uc.tokens.DeleteAccessToken(ctx, tokenId, accountId); err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return ErrAccessTokenNotFound
+		}
+		logger.Debug("failed to delete access token", "token", tokenId, "err", err)
+		return ErrDatabaseError
+	}
+	return nil
+}
+
+// AccessTokenVerifier verifies the personal access tokens, and hands the other tokens to the
+// verifier of the sessions
+type AccessTokenVerifier struct {
+	sessions     auth.TokenVerifier
+	tokens       AccessTokenStore
+	playerFinder player.Finder
+}
+
+func NewAccessTokenVerifier(sessions auth.TokenVerifier, tokens AccessTokenStore, playerFinder player.Finder) *AccessTokenVerifier {
+	return &AccessTokenVerifier{
+		sessions:     sessions,
+		tokens:       tokens,
+		playerFinder: playerFinder,
+	}
+}
+
+func (v *AccessTokenVerifier) Verify(ctx context.Context, token string) (auth.Verified, error) {
+	plain, ok := strings.CutPrefix(token, AccessTokenPrefix)
+	if !ok {
+		return v.sessions.Verify(ctx, token)
+	}
+
+	t, err := v.tokens.FindAccessTokenByHash(ctx, auth.HashSecretToken(plain))
+	if err != nil {
+		if !errors.Is(err, postgres.ErrNoRowFound) {
+			logger.Debug("failed to find access token", "err", err)
+		}
+		return auth.Verified{}, ErrInvalidAccessToken
+	}
+	if t.IsExpired(time.Now()) {
+		return auth.Verified{}, ErrInvalidAccessToken
+	}
+
+	p, err := v.playerFinder.FindByAccountId(ctx, t.AccountId)
+	if err != nil || p == nil {
+		logger.Debug("could not find player of access token", "account", t.AccountId, "err", err)
+		return auth.Verified{}, ErrInvalidAccessToken
+	}
+
+	if err := v.tokens.TouchAccessToken(ctx, t.Id); err != nil {
+		logger.Debug("failed to update access token last use", "token", t.Id, "err", err)
+	}
+
+	return auth.Verified{
+		Subject:  t.AccountId,
+		PlayerId: p.Id,
+		Scopes:   t.Scopes,
+	}, nil
+}
+
+func toAccessTokenResponse(t *AccessToken) AccessTokenResponse {
+	return AccessTokenResponse{
+		Id:         int(t.Id),
+		Name:       t.Name,
+		Scopes:     t.Scopes,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
@@ -0,0 +1,184 @@
+package account
+
+import (
+	"beldur/internal/id"
+	"beldur/internal/player"
+	"beldur/pkg/auth"
+	"beldur/pkg/db/postgres"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccessToken(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	token, err := NewAccessToken(1, " discord bot ", []string{auth.ScopeRollsWrite, auth.ScopeCampaignRead, auth.ScopeRollsWrite}, expiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, "discord bot", token.Name)
+	assert.Equal(t, []string{auth.ScopeCampaignRead, auth.ScopeRollsWrite}, token.Scopes)
+
+	_, err = NewAccessToken(1, "bot", []string{"campaign:delete"}, expiresAt)
+	assert.ErrorIs(t, err, ErrInvalidScope)
+
+	_, err = NewAccessToken(1, "bot", nil, expiresAt)
+	assert.ErrorIs(t, err, ErrInvalidScope)
+
+	_, err = NewAccessToken(1, "   ", []string{auth.ScopeCampaignRead}, expiresAt)
+	assert.ErrorIs(t, err, ErrInvalidAccessTokenName)
+}
+
+func TestCreateAccessToken(t *testing.T) {
+	ctx := context.Background()
+	req := CreateAccessTokenRequest{Name: "discord bot", Scopes: []string{auth.ScopeRollsWrite}, ExpiresInDays: 30}
+
+	t.Run("success gives the token once", func(t *testing.T) {
+		tokens := new(MockAccessTokenStore)
+		uc := NewAccessTokenManagement(tokens)
+
+		var storedHash string
+		tokens.On("CountAccessTokens", mock.Anything, id.AccountId(1)).Return(0, nil).Once()
+		tokens.On("SaveAccessToken", mock.Anything, mock.MatchedBy(func(t *AccessToken) bool {
+			return t.AccountId == 1 && t.Name == "discord bot"
+		}), mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) {
+				storedHash = args.String(2)
+				assert.WithinDuration(t, time.Now().AddDate(0, 0, 30), args.Get(1).(*AccessToken).ExpiresAt, time.Minute)
+			}).
+			Return(&AccessToken{Id: 5, Name: "discord bot", Scopes: []string{auth.ScopeRollsWrite}}, nil).Once()
+
+		resp, err := uc.Create(ctx, req, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 5, resp.Id)
+		plain, ok := strings.CutPrefix(resp.Token, AccessTokenPrefix)
+		require.True(t, ok)
+		assert.Equal(t, auth.HashSecretToken(plain), storedHash)
+	})
+
+	t.Run("too many tokens", func(t *testing.T) {
+		tokens := new(MockAccessTokenStore)
+		uc := NewAccessTokenManagement(tokens)
+		tokens.On("CountAccessTokens", mock.Anything, id.AccountId(1)).Return(MaxAccessTokens, nil).Once()
+
+		_, err := uc.Create(ctx, req, 1)
+		assert.ErrorIs(t, err, ErrTooManyAccessTokens)
+		tokens.AssertNotCalled(t, "SaveAccessToken", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRevokeAccessToken(t *testing.T) {
+	tokens := new(MockAccessTokenStore)
+	uc := NewAccessTokenManagement(tokens)
+	tokens.On("DeleteAccessToken", mock.Anything, id.AccessTokenId(5), id.AccountId(1)).Return(nil).Once()
+	tokens.On("DeleteAccessToken", mock.Anything, id.AccessTokenId(5), id.AccountId(2)).Return(postgres.ErrNoRowFound).Once()
+
+	require.NoError(t, uc.Revoke(context.Background(), 5, 1))
+	// the token of another account
+	assert.ErrorIs(t, uc.Revoke(context.Background(), 5, 2), ErrAccessTokenNotFound)
+}
+
+func TestAccessTokenVerifier(t *testing.T) {
+	ctx := context.Background()
+
+	newVerifier := func() (*AccessTokenVerifier, *MockTokenVerifier, *MockAccessTokenStore, *MockPlayerFinder) {
+		sessions, tokens, players := new(MockTokenVerifier), new(MockAccessTokenStore), new(MockPlayerFinder)
+		return NewAccessTokenVerifier(sessions, tokens, players), sessions, tokens, players
+	}
+
+	t.Run("access token gets its scopes", func(t *testing.T) {
+		v, sessions, tokens, players := newVerifier()
+		scopes := []string{auth.ScopeCampaignRead}
+		tokens.On("FindAccessTokenByHash", mock.Anything, auth.HashSecretToken("secret")).
+			Return(&AccessToken{Id: 5, AccountId: 1, Scopes: scopes, ExpiresAt: time.Now().Add(time.Hour)}, nil).Once()
+		players.On("FindByAccountId", mock.Anything, id.AccountId(1)).Return(&player.Player{Id: 7}, nil).Once()
+		tokens.On("TouchAccessToken", mock.Anything, id.AccessTokenId(5)).Return(nil).Once()
+
+		verified, err := v.Verify(ctx, AccessTokenPrefix+"secret")
+		require.NoError(t, err)
+		assert.Equal(t, auth.Verified{Subject: 1, PlayerId: 7, Scopes: scopes}, verified)
+		tokens.AssertExpectations(t)
+		sessions.AssertNotCalled(t, "Verify", mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired access token", func(t *testing.T) {
+		v, _, tokens, _ := newVerifier()
+		tokens.On("FindAccessTokenByHash", mock.Anything, mock.Anything).
+			Return(&AccessToken{Id: 5, AccountId: 1, ExpiresAt: time.Now().Add(-time.Hour)}, nil).Once()
+
+		_, err := v.Verify(ctx, AccessTokenPrefix+"secret")
+		assert.ErrorIs(t, err, ErrInvalidAccessToken)
+	})
+
+	t.Run("revoked access token", func(t *testing.T) {
+		v, _, tokens, _ := newVerifier()
+		tokens.On("FindAccessTokenByHash", mock.Anything, mock.Anything).Return(nil, postgres.ErrNoRowFound).Once()
+
+		_, err := v.Verify(ctx, AccessTokenPrefix+"secret")
+		assert.ErrorIs(t, err, ErrInvalidAccessToken)
+	})
+
+	t.Run("session token", func(t *testing.T) {
+		v, sessions, tokens, _ := newVerifier()
+		sessions.On("Verify", mock.Anything, "jwt-token").Return(auth.Verified{Subject: 1, PlayerId: 7}, nil).Once()
+
+		verified, err := v.Verify(ctx, "jwt-token")
+		require.NoError(t, err)
+		assert.Nil(t, verified.Scopes)
+		tokens.AssertNotCalled(t, "FindAccessTokenByHash", mock.Anything, mock.Anything)
+	})
+}
+
+func TestPrincipal_HasScope(t *testing.T) {
+	session := auth.Principal{AccountID: 1}
+	token := auth.Principal{AccountID: 1, Scopes: []string{auth.ScopeCampaignRead}}
+
+	assert.True(t, session.HasScope(auth.ScopeCharactersWrite))
+	assert.True(t, token.HasScope(auth.ScopeCampaignRead))
+	assert.False(t, token.HasScope(auth.ScopeCampaignWrite))
+	assert.False(t, token.IsSession())
+}
+
+type MockAccessTokenStore struct{ mock.Mock }
+
+func (m *MockAccessTokenStore) SaveAccessToken(ctx context.Context, token *AccessToken, tokenHash string) (*AccessToken, error) {
+	args := m.Called(ctx, token, tokenHash)
+	var saved *AccessToken
+	if v := args.Get(0); v != nil {
+		saved = v.(*AccessToken)
+	}
+	return saved, args.Error(1)
+}
+
+func (m *MockAccessTokenStore) CountAccessTokens(ctx context.Context, accountId id.AccountId) (int, error) {
+	args := m.Called(ctx, accountId)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccessTokenStore) FindAccessTokens(ctx context.Context, accountId id.AccountId) ([]AccessToken, error) {
+	args := m.Called(ctx, accountId)
+	return args.Get(0).([]AccessToken), args.Error(1)
+}
+
+func (m *MockAccessTokenStore) FindAccessTokenByHash(ctx context.Context, tokenHash string) (*AccessToken, error) {
+	args := m.Called(ctx, tokenHash)
+	var token *AccessToken
+	if v := args.Get(0); v != nil {
+		token = v.(*AccessToken)
+	}
+	return token, args.Error(1)
+}
+
+func (m *MockAccessTokenStore) TouchAccessToken(ctx context.Context, tokenId id.AccessTokenId) error {
+	args := m.Called(ctx, tokenId)
+	return args.Error(0)
+}
+
+func (m *MockAccessTokenStore) DeleteAccessToken(ctx context.Context, tokenId id.AccessTokenId, accountId id.AccountId) error {
+	args := m.Called(ctx, tokenId, accountId)
+	return args.Error(0)
+}
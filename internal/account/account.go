@@ -2,13 +2,16 @@ package account
 
 import (
 	"beldur/internal/id"
+	"beldur/internal/player"
 	"fmt"
+	"strings"
 	"time"
 )
 
 const (
 	UsernameMaxCharacters = 20
-	PasswordMaxCharacters = 4
+	// bcrypt ignores the bytes past the 72nd
+	PasswordMaxCharacters = 72
 
 	UsernameMinCharacters = 5
 	PasswordMinCharacters = 6
@@ -24,11 +27,17 @@ func WithEmail(e Email) Option {
 }
 
 type Account struct {
-	Id        id.AccountId
-	Username  string
-	Password  string // hashed password
-	Email     *Email
-	CreatedAt time.Time
+	Id       id.AccountId
+	Username string
+	Password string // hashed password
+	Email    *Email
+	// EmailVerifiedAt is nil until the owner proves to read the email
+	EmailVerifiedAt *time.Time
+	CreatedAt       time.Time
+	// SessionVersion is bumped to revoke all the tokens issued for the account
+	SessionVersion int
+	// TwoFactor is the TOTP second factor of the login, zero if never enrolled
+	TwoFactor TwoFactor
 }
 
 func New(username string, hashedPassword string, opt ...Option) (*Account, error) {
@@ -48,8 +57,39 @@ func New(username string, hashedPassword string, opt ...Option) (*Account, error
 	return acc, nil
 }
 
+// UpdateEmail changes the email of the account, a new email must be verified again
 func (a *Account) UpdateEmail(email Email) {
+	if a.Email != nil && a.Email.String() == email.String() {
+		return
+	}
 	a.Email = &email
+	a.EmailVerifiedAt = nil
+}
+
+func (a *Account) HasEmail() bool {
+	return a.Email != nil && a.Email.String() != ""
+}
+
+func (a *Account) IsEmailVerified() bool {
+	return a.HasEmail() && a.EmailVerifiedAt != nil
+}
+
+// VerifyEmail marks the current email as verified
+func (a *Account) VerifyEmail(at time.Time) error {
+	if !a.HasEmail() {
+		return ErrNoEmail
+	}
+	if a.EmailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+	a.EmailVerifiedAt = &at
+	return nil
+}
+
+// ChangePassword sets the new hashed password and revokes all the sessions of the account
+func (a *Account) ChangePassword(hashedPassword string) {
+	a.Password = hashedPassword
+	a.SessionVersion++
 }
 
 // UpdateUsername I think should not be possible. For now the api doesnt offer this
@@ -70,6 +110,10 @@ func validateUsername(value string) error {
 	if len(value) > UsernameMaxCharacters || len(value) < UsernameMinCharacters {
 		return ErrInvalidUsername
 	}
+	// kept for the deleted accounts
+	if strings.HasPrefix(value, player.DeletedNamePrefix) {
+		return ErrInvalidUsername
+	}
 	return nil
 }
 
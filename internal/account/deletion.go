@@ -0,0 +1,306 @@
+package account
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"beldur/internal/player"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/logger"
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	// DeletionGracePeriod is the time the owner has to change their mind before the account is purged
+	DeletionGracePeriod = 30 * 24 * time.Hour
+	// purgeBatchSize is the number of accounts purged at most in a single run
+	purgeBatchSize = 50
+)
+
+// Handover gives a campaign mastered by an account being deleted to another of its players
+type Handover struct {
+	CampaignId id.CampaignId
+	NewMaster  id.PlayerId
+}
+
+// Deletion of an account requested by its owner. Until PurgeAfter the account works as usual
+// and the deletion can be cancelled.
+type Deletion struct {
+	AccountId   id.AccountId
+	RequestedAt time.Time
+	PurgeAfter  time.Time
+	Handovers   []Handover
+}
+
+func (d *Deletion) handoverOf(campaignId id.CampaignId) (id.PlayerId, bool) {
+	for _, h := range d.Handovers {
+		if h.CampaignId == campaignId {
+			return h.NewMaster, true
+		}
+	}
+	return 0, false
+}
+
+// DeletedName is the name given to the account or player of the id once deleted
+func DeletedName(id int) string {
+	return player.DeletedNamePrefix + strconv.Itoa(id)
+}
+
+// MasteredCampaigns are the campaigns handed over or archived when their master is deleted
+type MasteredCampaigns interface {
+	campaign.MasterFinder
+	campaign.Updater
+}
+
+// AccountDeletion is an USE CASE where the owner of an account asks for its deletion. The
+// campaigns they master are handed over to other players, or archived, once the grace period is over.
+type AccountDeletion struct {
+	accFinder    Finder
+	playerFinder player.Finder
+	campaigns    MasteredCampaigns
+	deletions    DeletionStore
+}
+
+func NewAccountDeletion(
+	accFinder Finder,
+	playerFinder player.Finder,
+	campaigns MasteredCampaigns,
+	deletions DeletionStore,
+) *AccountDeletion {
+	return &AccountDeletion{
+		accFinder:    accFinder,
+		playerFinder: playerFinder,
+		campaigns:    campaigns,
+		deletions:    deletions,
+	}
+}
+
+// Request schedules the deletion of the account at the end of the grace period. A new request
+// replaces the pending one, along with its handovers.
+func (uc *AccountDeletion) Request(ctx context.Context, req DeleteAccountRequest, accountId id.AccountId) (AccountDeletionResponse, error) {
+	acc, err := uc.accFinder.FindById(ctx, accountId)
+	if err != nil {
+		logger.Debug("could not find account by id", "err", err)
+		return AccountDeletionResponse{}, ErrDatabaseError
+	}
+	if acc == nil {
+		return AccountDeletionResponse{}, ErrAccountDoesNotExist
+	}
+	// the accounts of an identity provider have no password
+	if acc.Password != "" && !CheckPasswordHash(acc.Password, req.Password) {
+		return AccountDeletionResponse{}, ErrWrongPassword
+	}
+
+	playerId, mastered, err := uc.openMasteredCampaigns(ctx, accountId)
+	if err != nil {
+		return AccountDeletionResponse{}, err
+	}
+
+	handovers := make([]Handover, 0, len(req.Handovers))
+	handedOver := make(map[id.CampaignId]struct{})
+	for _, h := range req.Handovers {
+		campaignId, newMaster := id.CampaignId(h.CampaignId), id.PlayerId(h.NewMasterId)
+
+		c, ok := mastered[campaignId]
+		if !ok {
+			return AccountDeletionResponse{}, ErrNotCampaignMaster
+		}
+		if _, exists := handedOver[campaignId]; exists || newMaster == playerId {
+			return AccountDeletionResponse{}, ErrInvalidHandover
+		}
+		// checked on the campaign, which is saved only at the purge
+		if err := c.HandOver(newMaster); err != nil {
+			return AccountDeletionResponse{}, errors.Join(ErrInvalidHandover, err)
+		}
+		handovers = append(handovers, Handover{CampaignId: campaignId, NewMaster: newMaster})
+		handedOver[campaignId] = struct{}{}
+	}
+
+	now := time.Now()
+	deletion := &Deletion{
+		AccountId:   accountId,
+		RequestedAt: now,
+		PurgeAfter:  now.Add(DeletionGracePeriod),
+		Handovers:   handovers,
+	}
+	if err := uc.deletions.SaveDeletion(ctx, deletion); err != nil {
+		logger.Debug("failed to save account deletion", "account", accountId, "err", err)
+		return AccountDeletionResponse{}, ErrDatabaseError
+	}
+	return deletionResponse(deletion, mastered), nil
+}
+
+// Find gives back the pending deletion of the account
+func (uc *AccountDeletion) Find(ctx context.Context, accountId id.AccountId) (AccountDeletionResponse, error) {
+	deletion, err := uc.deletions.FindDeletion(ctx, accountId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return AccountDeletionResponse{}, ErrDeletionNotFound
+		}
+		logger.Debug("failed to find account deletion", "account", accountId, "err", err)
+		return AccountDeletionResponse{}, ErrDatabaseError
+	}
+
+	_, mastered, err := uc.openMasteredCampaigns(ctx, accountId)
+	if err != nil {
+		return AccountDeletionResponse{}, err
+	}
+	return deletionResponse(deletion, mastered), nil
+}
+
+// Cancel keeps the account, during the grace period
+func (uc *AccountDeletion) Cancel(ctx context.Context, accountId id.AccountId) error {
+	if err := uc.deletions.CancelDeletion(ctx, accountId); err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return ErrDeletionNotFound
+		}
+		logger.Debug("failed to cancel account deletion", "account", accountId, "err", err)
+		return ErrDatabaseError
+	}
+	return nil
+}
+
+func (uc *AccountDeletion) openMasteredCampaigns(ctx context.Context, accountId id.AccountId) (id.PlayerId, map[id.CampaignId]*campaign.Campaign, error) {
+	p, err := uc.playerFinder.FindByAccountId(ctx, accountId)
+	if err != nil || p == nil {
+		logger.Debug("failed to find player", "account", accountId, "err", err)
+		return 0, nil, ErrDatabaseError
+	}
+
+	campaigns, err := uc.campaigns.FindByMaster(ctx, p.Id)
+	if err != nil {
+		logger.Debug("failed to find mastered campaigns", "player", p.Id, "err", err)
+		return 0, nil, ErrDatabaseError
+	}
+
+	open := make(map[id.CampaignId]*campaign.Campaign)
+	for _, c := range campaigns {
+		if c.IsOpen() {
+			open[c.Id()] = c
+		}
+	}
+	return p.Id, open, nil
+}
+
+func deletionResponse(deletion *Deletion, mastered map[id.CampaignId]*campaign.Campaign) AccountDeletionResponse {
+	resp := AccountDeletionResponse{
+		RequestedAt:       deletion.RequestedAt,
+		PurgeAfter:        deletion.PurgeAfter,
+		Handovers:         make([]HandoverRequest, 0, len(deletion.Handovers)),
+		ArchivedCampaigns: make([]int, 0),
+	}
+	for _, h := range deletion.Handovers {
+		resp.Handovers = append(resp.Handovers, HandoverRequest{CampaignId: int(h.CampaignId), NewMasterId: int(h.NewMaster)})
+	}
+	for campaignId := range mastered {
+		if _, ok := deletion.handoverOf(campaignId); !ok {
+			resp.ArchivedCampaigns = append(resp.ArchivedCampaigns, int(campaignId))
+		}
+	}
+	slices.Sort(resp.ArchivedCampaigns)
+	return resp
+}
+
+// DeletionPurge deletes the accounts at the end of their grace period. The account and its
+// player are not removed but anonymized, so the chat messages, journal entries and the rest of
+// the content they wrote stay in the campaigns without telling who wrote them.
+type DeletionPurge struct {
+	playerFinder player.Finder
+	campaigns    MasteredCampaigns
+	deletions    DeletionStore
+	eraser       AccountEraser
+	tx           tx.Transactor
+}
+
+func NewDeletionPurge(
+	playerFinder player.Finder,
+	campaigns MasteredCampaigns,
+	deletions DeletionStore,
+	eraser AccountEraser,
+	tx tx.Transactor,
+) *DeletionPurge {
+	return &DeletionPurge{
+		playerFinder: playerFinder,
+		campaigns:    campaigns,
+		deletions:    deletions,
+		eraser:       eraser,
+		tx:           tx,
+	}
+}
+
+// Run purges the due deletions every interval, until ctx is done
+func (uc *DeletionPurge) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := uc.Purge(ctx, time.Now()); err != nil {
+			logger.Error("failed to purge deleted accounts", err)
+		} else if purged > 0 {
+			logger.Info("purged deleted accounts", "count", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes the accounts whose grace period is over at now, and gives back how many.
+// An account failing to be purged is tried again at the next run.
+func (uc *DeletionPurge) Purge(ctx context.Context, now time.Time) (int, error) {
+	due, err := uc.deletions.FindDueDeletions(ctx, now, purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, deletion := range due {
+		if err := uc.purge(ctx, deletion); err != nil {
+			logger.Error("failed to purge account", err, "account", deletion.AccountId)
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (uc *DeletionPurge) purge(ctx context.Context, deletion Deletion) error {
+	return uc.tx.WithTransaction(ctx, func(txCtx context.Context) error {
+		p, err := uc.playerFinder.FindByAccountId(txCtx, deletion.AccountId)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return errors.New("account has no player")
+		}
+
+		campaigns, err := uc.campaigns.FindByMaster(txCtx, p.Id)
+		if err != nil {
+			return err
+		}
+		for _, c := range campaigns {
+			if !c.IsOpen() {
+				continue
+			}
+			// the new master may have left the campaign since the request
+			newMaster, ok := deletion.handoverOf(c.Id())
+			if !ok || c.HandOver(newMaster) != nil {
+				if err := c.Archive(); err != nil {
+					return err
+				}
+			}
+			if err := uc.campaigns.Update(txCtx, c); err != nil {
+				return err
+			}
+		}
+
+		return uc.eraser.EraseAccount(txCtx, deletion.AccountId, p.Id)
+	})
+}
@@ -0,0 +1,250 @@
+package account
+
+import (
+	"archive/zip"
+	"beldur/internal/campaign"
+	"beldur/internal/chat"
+	"beldur/internal/id"
+	"beldur/internal/player"
+	"beldur/pkg/db/postgres"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newMasteredCampaign gives back a started campaign of master 7 with the players 8 and 9,
+// never saved so its id is 0
+func newMasteredCampaign(t *testing.T) *campaign.Campaign {
+	t.Helper()
+	c, err := campaign.New("campaign", "description", 7)
+	require.NoError(t, err)
+	require.NoError(t, c.AddPlayer(8))
+	require.NoError(t, c.AddPlayer(9))
+	require.NoError(t, c.Start())
+	return c
+}
+
+func TestAccountDeletion_Request(t *testing.T) {
+	ctx := context.Background()
+	hashed, err := HashPassword("password")
+	require.NoError(t, err)
+
+	setup := func(t *testing.T) (*AccountDeletion, *MockDeletionStore) {
+		accFinder, playerFinder := new(MockFinder), new(MockPlayerFinder)
+		campaigns, deletions := new(MockMasteredCampaigns), new(MockDeletionStore)
+
+		accFinder.On("FindById", mock.Anything, id.AccountId(1)).Return(&Account{Id: 1, Password: hashed}, nil)
+		playerFinder.On("FindByAccountId", mock.Anything, id.AccountId(1)).Return(&player.Player{Id: 7}, nil)
+		campaigns.On("FindByMaster", mock.Anything, id.PlayerId(7)).Return([]*campaign.Campaign{newMasteredCampaign(t)}, nil)
+		return NewAccountDeletion(accFinder, playerFinder, campaigns, deletions), deletions
+	}
+
+	t.Run("handover", func(t *testing.T) {
+		uc, deletions := setup(t)
+		deletions.On("SaveDeletion", mock.Anything, mock.MatchedBy(func(d *Deletion) bool {
+			return d.AccountId == 1 && len(d.Handovers) == 1 && d.Handovers[0] == Handover{CampaignId: 0, NewMaster: 8}
+		})).Return(nil).Once()
+
+		resp, err := uc.Request(ctx, DeleteAccountRequest{
+			Password:  "password",
+			Handovers: []HandoverRequest{{CampaignId: 0, NewMasterId: 8}},
+		}, 1)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(DeletionGracePeriod), resp.PurgeAfter, time.Minute)
+		assert.Equal(t, []HandoverRequest{{CampaignId: 0, NewMasterId: 8}}, resp.Handovers)
+		assert.Empty(t, resp.ArchivedCampaigns)
+		deletions.AssertExpectations(t)
+	})
+
+	t.Run("archive without handover", func(t *testing.T) {
+		uc, deletions := setup(t)
+		deletions.On("SaveDeletion", mock.Anything, mock.Anything).Return(nil).Once()
+
+		resp, err := uc.Request(ctx, DeleteAccountRequest{Password: "password"}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []int{0}, resp.ArchivedCampaigns)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		uc, deletions := setup(t)
+
+		_, err := uc.Request(ctx, DeleteAccountRequest{Password: "wrong"}, 1)
+		assert.ErrorIs(t, err, ErrWrongPassword)
+
+		_, err = uc.Request(ctx, DeleteAccountRequest{
+			Password:  "password",
+			Handovers: []HandoverRequest{{CampaignId: 5, NewMasterId: 8}},
+		}, 1)
+		assert.ErrorIs(t, err, ErrNotCampaignMaster)
+
+		for _, newMaster := range []int{7, 10} {
+			_, err = uc.Request(ctx, DeleteAccountRequest{
+				Password:  "password",
+				Handovers: []HandoverRequest{{CampaignId: 0, NewMasterId: newMaster}},
+			}, 1)
+			assert.ErrorIs(t, err, ErrInvalidHandover)
+		}
+		deletions.AssertNotCalled(t, "SaveDeletion", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAccountDeletion_Cancel(t *testing.T) {
+	deletions := new(MockDeletionStore)
+	uc := NewAccountDeletion(nil, nil, nil, deletions)
+
+	deletions.On("CancelDeletion", mock.Anything, id.AccountId(1)).Return(nil).Once()
+	deletions.On("CancelDeletion", mock.Anything, id.AccountId(2)).Return(postgres.ErrNoRowFound).Once()
+
+	assert.NoError(t, uc.Cancel(context.Background(), 1))
+	assert.ErrorIs(t, uc.Cancel(context.Background(), 2), ErrDeletionNotFound)
+}
+
+func TestDeletionPurge(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("handover", func(t *testing.T) {
+		playerFinder, campaigns := new(MockPlayerFinder), new(MockMasteredCampaigns)
+		deletions, eraser := new(MockDeletionStore), new(MockAccountEraser)
+		uc := NewDeletionPurge(playerFinder, campaigns, deletions, eraser, FnTransactor{})
+
+		deletions.On("FindDueDeletions", mock.Anything, now, purgeBatchSize).Return([]Deletion{
+			{AccountId: 1, Handovers: []Handover{{CampaignId: 0, NewMaster: 8}}},
+		}, nil).Once()
+		playerFinder.On("FindByAccountId", mock.Anything, id.AccountId(1)).Return(&player.Player{Id: 7}, nil)
+		campaigns.On("FindByMaster", mock.Anything, id.PlayerId(7)).Return([]*campaign.Campaign{newMasteredCampaign(t)}, nil).Once()
+		campaigns.On("Update", mock.Anything, mock.MatchedBy(func(c *campaign.Campaign) bool {
+			return c.IsMaster(8) && c.Status() == campaign.StatusStarted
+		})).Return(nil).Once()
+		eraser.On("EraseAccount", mock.Anything, id.AccountId(1), id.PlayerId(7)).Return(nil).Once()
+
+		purged, err := uc.Purge(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+		campaigns.AssertExpectations(t)
+		eraser.AssertExpectations(t)
+	})
+
+	t.Run("archive when the new master left", func(t *testing.T) {
+		playerFinder, campaigns := new(MockPlayerFinder), new(MockMasteredCampaigns)
+		deletions, eraser := new(MockDeletionStore), new(MockAccountEraser)
+		uc := NewDeletionPurge(playerFinder, campaigns, deletions, eraser, FnTransactor{})
+
+		deletions.On("FindDueDeletions", mock.Anything, now, purgeBatchSize).Return([]Deletion{
+			{AccountId: 1, Handovers: []Handover{{CampaignId: 0, NewMaster: 10}}},
+			{AccountId: 2},
+		}, nil).Once()
+		playerFinder.On("FindByAccountId", mock.Anything, id.AccountId(1)).Return(&player.Player{Id: 7}, nil)
+		playerFinder.On("FindByAccountId", mock.Anything, id.AccountId(2)).Return(&player.Player{Id: 9}, nil)
+		campaigns.On("FindByMaster", mock.Anything, id.PlayerId(7)).Return([]*campaign.Campaign{newMasteredCampaign(t)}, nil).Once()
+		campaigns.On("FindByMaster", mock.Anything, id.PlayerId(9)).Return([]*campaign.Campaign{}, nil).Once()
+		campaigns.On("Update", mock.Anything, mock.MatchedBy(func(c *campaign.Campaign) bool {
+			return c.IsMaster(7) && c.Status() == campaign.StatusArchived
+		})).Return(nil).Once()
+		eraser.On("EraseAccount", mock.Anything, id.AccountId(1), id.PlayerId(7)).Return(nil).Once()
+		// tried again at the next run
+		eraser.On("EraseAccount", mock.Anything, id.AccountId(2), id.PlayerId(9)).Return(errors.New("db down")).Once()
+
+		purged, err := uc.Purge(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+		campaigns.AssertExpectations(t)
+		eraser.AssertExpectations(t)
+	})
+}
+
+func TestWriteExportZip(t *testing.T) {
+	export := ExportResponse{
+		Account:      ExportAccount{AccountID: 1, Username: "frodo"},
+		Player:       PlayerCreateResponse{PlayerID: 7, Name: "frodo"},
+		ChatMessages: []chat.ExportedMessage{{MessageId: 3, Body: "hello"}},
+		ReceivedWhispers: []chat.ExportedMessage{
+			{MessageId: 4, AuthorId: 8, Audience: "PLAYER", Body: "psst"},
+		},
+		ExportedAt: time.Now(),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteExportZip(&buf, export))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	assert.Len(t, files, 14)
+
+	read := func(name string, v any) {
+		require.Contains(t, files, name)
+		r, err := files[name].Open()
+		require.NoError(t, err)
+		defer r.Close()
+		require.NoError(t, json.NewDecoder(r).Decode(v))
+	}
+
+	var account struct {
+		Account ExportAccount `json:"account"`
+	}
+	read("account.json", &account)
+	assert.Equal(t, "frodo", account.Account.Username)
+
+	var messages []chat.ExportedMessage
+	read("chat_messages.json", &messages)
+	assert.Equal(t, export.ChatMessages, messages)
+
+	var whispers []chat.ExportedMessage
+	read("received_whispers.json", &whispers)
+	assert.Equal(t, export.ReceivedWhispers, whispers)
+}
+
+type MockMasteredCampaigns struct{ mock.Mock }
+type MockDeletionStore struct{ mock.Mock }
+type MockAccountEraser struct{ mock.Mock }
+
+func (m *MockMasteredCampaigns) FindByMaster(ctx context.Context, masterId id.PlayerId) ([]*campaign.Campaign, error) {
+	args := m.Called(ctx, masterId)
+	return args.Get(0).([]*campaign.Campaign), args.Error(1)
+}
+
+func (m *MockMasteredCampaigns) Update(ctx context.Context, c *campaign.Campaign) error {
+	args := m.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (m *MockDeletionStore) SaveDeletion(ctx context.Context, deletion *Deletion) error {
+	args := m.Called(ctx, deletion)
+	return args.Error(0)
+}
+
+func (m *MockDeletionStore) FindDeletion(ctx context.Context, accountId id.AccountId) (*Deletion, error) {
+	args := m.Called(ctx, accountId)
+	var deletion *Deletion
+	if v := args.Get(0); v != nil {
+		deletion = v.(*Deletion)
+	}
+	return deletion, args.Error(1)
+}
+
+func (m *MockDeletionStore) CancelDeletion(ctx context.Context, accountId id.AccountId) error {
+	args := m.Called(ctx, accountId)
+	return args.Error(0)
+}
+
+func (m *MockDeletionStore) FindDueDeletions(ctx context.Context, now time.Time, limit int) ([]Deletion, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]Deletion), args.Error(1)
+}
+
+func (m *MockAccountEraser) EraseAccount(ctx context.Context, accountId id.AccountId, playerId id.PlayerId) error {
+	args := m.Called(ctx, accountId, playerId)
+	return args.Error(0)
+}
@@ -1,6 +1,16 @@
 package account
 
-import "time"
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/internal/chat"
+	"beldur/internal/encounter"
+	"beldur/internal/handout"
+	"beldur/internal/journal"
+	"beldur/internal/schedule"
+	"beldur/internal/wiki"
+	"time"
+)
 
 // #### ACCOUNT CREATION
 
@@ -11,11 +21,12 @@ type CreateAccountRequest struct {
 }
 
 type CreateAccountResponse struct {
-	AccountID   int                  `json:"account_id"`
-	AccountName string               `json:"username"`
-	Email       *string              `json:"email"`
-	CreatedAt   time.Time            `json:"created_at"`
-	Player      PlayerCreateResponse `json:"player"`
+	AccountID     int                  `json:"account_id"`
+	AccountName   string               `json:"username"`
+	Email         *string              `json:"email"`
+	EmailVerified bool                 `json:"email_verified"`
+	CreatedAt     time.Time            `json:"created_at"`
+	Player        PlayerCreateResponse `json:"player"`
 }
 
 type PlayerCreateResponse struct {
@@ -30,12 +41,184 @@ type UsernamePasswordLoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// LoginChallengeResponse is given instead of the cookie when the account has two-factor
+// authentication, the challenge token is sent back with the code
+type LoginChallengeResponse struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	// Code of the authenticator app, or a recovery code
+	Code string `json:"code" validate:"required"`
+}
+
+// OIDCCallbackRequest carries the query parameters given by the provider to the redirect URL
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
 // UpdateAccountRequest 's fields will be nullable when more than one.
 // For now only email can be updated
 type UpdateAccountRequest struct {
-	Email string `json:"email"`
+	Email string `json:"email" validate:"required"`
 }
 
 type UpdateAccountResponse struct {
-	Email string `json:"email"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+// #### Password
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// #### Email verification
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// #### Two-factor authentication
+
+type EnrollTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type EnrollTwoFactorResponse struct {
+	Secret string `json:"secret"`
+	// URI is the otpauth:// URI read by the authenticator apps, usually shown as a QR code
+	URI string `json:"uri"`
+}
+
+type ConfirmTwoFactorRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type ConfirmTwoFactorResponse struct {
+	// RecoveryCodes are shown only once
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	// Code of the authenticator app, or a recovery code
+	Code string `json:"code" validate:"required"`
+}
+
+// #### Personal access tokens
+
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=50"`
+	Scopes        []string `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"required,min=1,max=365"`
+}
+
+type AccessTokenResponse struct {
+	Id         int        `json:"token_id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreateAccessTokenResponse struct {
+	AccessTokenResponse
+	// Token is shown only once, it is sent in the Authorization header as a Bearer token
+	Token string `json:"token"`
+}
+
+// #### Sessions
+
+type SessionResponse struct {
+	Id         int       `json:"session_id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// Current is the session of the request
+	Current bool `json:"current"`
+}
+
+// #### Deletion
+
+type DeleteAccountRequest struct {
+	// Password is required when the account has one
+	Password string `json:"password"`
+	// Handovers give the open campaigns mastered by the account to other players, the others
+	// are archived
+	Handovers []HandoverRequest `json:"handovers" validate:"max=100,dive"`
+}
+
+type HandoverRequest struct {
+	CampaignId  int `json:"campaign_id" validate:"required"`
+	NewMasterId int `json:"new_master_id" validate:"required"`
+}
+
+type AccountDeletionResponse struct {
+	RequestedAt time.Time `json:"requested_at"`
+	// PurgeAfter is the end of the grace period, the deletion can be cancelled until then
+	PurgeAfter time.Time         `json:"purge_after"`
+	Handovers  []HandoverRequest `json:"handovers"`
+	// ArchivedCampaigns are the open campaigns mastered by the account without handover
+	ArchivedCampaigns []int `json:"archived_campaigns"`
+}
+
+// #### Data export
+
+// ExportResponse is all the personal data kept about an account
+type ExportResponse struct {
+	Account           ExportAccount                 `json:"account"`
+	Player            PlayerCreateResponse          `json:"player"`
+	Identities        []ExportIdentity              `json:"identities"`
+	Sessions          []SessionResponse             `json:"sessions"`
+	AccessTokens      []AccessTokenResponse         `json:"access_tokens"`
+	Campaigns         []campaign.ExportedCampaign   `json:"campaigns"`
+	Characters        []character.ExportedCharacter `json:"characters"`
+	CharacterVersions []character.ExportedVersion   `json:"character_versions"`
+	JournalEntries    []journal.ExportedEntry       `json:"journal_entries"`
+	WikiEntries       []wiki.ExportedEntry          `json:"wiki_entries"`
+	WikiRevisions     []wiki.ExportedRevision       `json:"wiki_revisions"`
+	ChatMessages      []chat.ExportedMessage        `json:"chat_messages"`
+	ChatEdits         []chat.ExportedEdit           `json:"chat_edits"`
+	ReceivedWhispers  []chat.ExportedMessage        `json:"received_whispers"`
+	SessionVotes      []schedule.ExportedVote       `json:"session_votes"`
+	SessionAttendance []schedule.ExportedAttendance `json:"session_attendance"`
+	EncounterActions  []encounter.ExportedAction    `json:"encounter_actions"`
+	Handouts          []handout.ExportedHandout     `json:"handouts"`
+	ExportedAt        time.Time                     `json:"exported_at"`
+}
+
+type ExportAccount struct {
+	AccountID        int        `json:"account_id"`
+	Username         string     `json:"username"`
+	Email            *string    `json:"email"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	// DeletionPurgeAfter is set while a deletion of the account is pending
+	DeletionPurgeAfter *time.Time `json:"deletion_purge_after"`
+}
+
+type ExportIdentity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     *string   `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -28,3 +28,17 @@ func NewEmail(email string) (Email, error) {
 func (e Email) String() string {
 	return e.value
 }
+
+// EmailPolicy tells if the email of the account can be used by features such as the
+// password reset
+type EmailPolicy func(acc *Account) bool
+
+// RequireVerifiedEmail accepts only the emails verified by their owner
+func RequireVerifiedEmail(acc *Account) bool {
+	return acc.IsEmailVerified()
+}
+
+// AllowUnverifiedEmail accepts any email, verified or not
+func AllowUnverifiedEmail(acc *Account) bool {
+	return acc.HasEmail()
+}
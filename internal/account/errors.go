@@ -15,10 +15,36 @@ var (
 )
 
 var (
-	ErrDatabaseError           = errors.New("database error when executing use case")
-	ErrAccountNameAlreadyTaken = errors.New("account name already taken")
-	ErrAccountDoesNotExist     = errors.New("account does not exist")
-	ErrInvalidCredentials      = errors.New("invalid login credentials")
+	ErrDatabaseError            = errors.New("database error when executing use case")
+	ErrAccountNameAlreadyTaken  = errors.New("account name already taken")
+	ErrAccountDoesNotExist      = errors.New("account does not exist")
+	ErrInvalidCredentials       = errors.New("invalid login credentials")
+	ErrWrongPassword            = errors.New("current password is wrong")
+	ErrInvalidResetToken        = errors.New("password reset token is invalid or expired")
+	ErrSessionRevoked           = errors.New("session has been revoked")
+	ErrEmailAlreadyTaken        = errors.New("email already used by another account")
+	ErrNoEmail                  = errors.New("account has no email")
+	ErrEmailAlreadyVerified     = errors.New("email is already verified")
+	ErrInvalidVerificationToken = errors.New("email verification token is invalid or expired")
+	ErrVerificationThrottled    = errors.New("verification email sent too recently")
+	ErrTwoFactorAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled     = errors.New("two-factor authentication is not enrolled")
+	ErrTwoFactorNotEnabled      = errors.New("two-factor authentication is not enabled")
+	ErrInvalidTwoFactorCode     = errors.New("invalid two-factor code")
+	ErrInvalidLoginChallenge    = errors.New("login challenge is invalid or expired")
+	ErrTwoFactorLocked          = errors.New("too many wrong two-factor codes")
+	ErrUnknownIdentityProvider  = errors.New("unknown identity provider")
+	ErrInvalidOIDCState         = errors.New("oidc login state is invalid or expired")
+	ErrIdentityProvider         = errors.New("identity provider refused the login")
+	ErrInvalidAccessTokenName   = errors.New("invalid access token name")
+	ErrInvalidScope             = errors.New("invalid access token scope")
+	ErrTooManyAccessTokens      = errors.New("too many access tokens")
+	ErrAccessTokenNotFound      = errors.New("access token not found")
+	ErrInvalidAccessToken       = errors.New("access token is invalid or expired")
+	ErrSessionNotFound          = errors.New("session not found")
+	ErrNotCampaignMaster        = errors.New("campaign is not mastered by the account")
+	ErrInvalidHandover          = errors.New("campaign cannot be handed over to the player")
+	ErrDeletionNotFound         = errors.New("no deletion of the account is pending")
 )
 
 func NewAccountApiErrorManager() *httperr.Manager {
@@ -60,5 +86,149 @@ func NewAccountApiErrorManager() *httperr.Manager {
 		Message: ErrInvalidEmailFormat.Error(),
 	})
 
+	mng.Add(ErrWrongPassword, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "wrong_password",
+		Message: "Current password is wrong",
+	})
+
+	mng.Add(ErrInvalidResetToken, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_reset_token",
+		Message: "Password reset link is invalid or expired",
+	})
+
+	mng.Add(ErrEmailAlreadyTaken, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "email_taken",
+		Message: "Email is already used by another account",
+	})
+
+	mng.Add(ErrNoEmail, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "no_email",
+		Message: "Account has no email",
+	})
+
+	mng.Add(ErrEmailAlreadyVerified, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "email_already_verified",
+		Message: "Email is already verified",
+	})
+
+	mng.Add(ErrInvalidVerificationToken, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_verification_token",
+		Message: "Email verification link is invalid or expired",
+	})
+
+	mng.Add(ErrVerificationThrottled, httperr.Mapped{
+		Status:  http.StatusTooManyRequests,
+		Code:    "verification_throttled",
+		Message: "A verification email was sent recently, try again later",
+	})
+
+	mng.Add(ErrTwoFactorAlreadyEnabled, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "two_factor_already_enabled",
+		Message: "Two-factor authentication is already enabled",
+	})
+
+	mng.Add(ErrTwoFactorNotEnrolled, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "two_factor_not_enrolled",
+		Message: "Two-factor authentication must be enrolled first",
+	})
+
+	mng.Add(ErrTwoFactorNotEnabled, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "two_factor_not_enabled",
+		Message: "Two-factor authentication is not enabled",
+	})
+
+	mng.Add(ErrInvalidTwoFactorCode, httperr.Mapped{
+		Status:  http.StatusUnauthorized,
+		Code:    "invalid_two_factor_code",
+		Message: "Two-factor code is invalid",
+	})
+
+	mng.Add(ErrInvalidLoginChallenge, httperr.Mapped{
+		Status:  http.StatusUnauthorized,
+		Code:    "invalid_login_challenge",
+		Message: "Login expired, log in again",
+	})
+
+	mng.Add(ErrTwoFactorLocked, httperr.Mapped{
+		Status:  http.StatusTooManyRequests,
+		Code:    "two_factor_locked",
+		Message: "Too many wrong codes, try again later",
+	})
+
+	mng.Add(ErrUnknownIdentityProvider, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "unknown_identity_provider",
+		Message: "Identity provider is not configured",
+	})
+
+	mng.Add(ErrInvalidOIDCState, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_oidc_state",
+		Message: "Sign in expired, try again",
+	})
+
+	mng.Add(ErrIdentityProvider, httperr.Mapped{
+		Status:  http.StatusBadGateway,
+		Code:    "identity_provider_error",
+		Message: "Identity provider refused the sign in",
+	})
+
+	mng.Add(ErrInvalidAccessTokenName, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_access_token_name",
+		Message: "Access token name is invalid",
+	})
+
+	mng.Add(ErrInvalidScope, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_scope",
+		Message: "Access token scopes are invalid",
+	})
+
+	mng.Add(ErrTooManyAccessTokens, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "too_many_access_tokens",
+		Message: "Too many access tokens, revoke one first",
+	})
+
+	mng.Add(ErrAccessTokenNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "access_token_not_found",
+		Message: "Access token not found",
+	})
+
+	mng.Add(ErrSessionNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "session_not_found",
+		Message: "Session not found",
+	})
+
+	mng.Add(ErrNotCampaignMaster, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "not_campaign_master",
+		Message: "Only the campaigns you master can be handed over",
+	})
+
+	mng.Add(ErrInvalidHandover, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_handover",
+		Message: "The new master must be another player of the campaign",
+	})
+
+	mng.Add(ErrDeletionNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "deletion_not_found",
+		Message: "No deletion of the account is pending",
+	})
+
 	return mng
 }
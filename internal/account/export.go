@@ -0,0 +1,214 @@
+package account
+
+import (
+	"archive/zip"
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/internal/chat"
+	"beldur/internal/encounter"
+	"beldur/internal/handout"
+	"beldur/internal/id"
+	"beldur/internal/journal"
+	"beldur/internal/player"
+	"beldur/internal/schedule"
+	"beldur/internal/wiki"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// DataExport is an USE CASE where the owner of an account downloads all the personal data
+// kept about it, along with the content written by its player and the whispers it received.
+// Left out are the files of the handouts, listed without their content as they belong to the
+// campaign and can be large, and the content of the campaigns not written by anyone in
+// particular: quests, battle maps and the encounters themselves.
+type DataExport struct {
+	accFinder    Finder
+	playerFinder player.Finder
+	deletions    DeletionStore
+	sessions     *SessionManagement
+	tokens       *AccessTokenManagement
+	store        ExportStore
+	sources      ExportSources
+}
+
+// ExportSources read the content of the player kept by the other domains
+type ExportSources struct {
+	Campaigns  campaign.Exporter
+	Characters character.Exporter
+	Journal    journal.Exporter
+	Wiki       wiki.Exporter
+	Chat       chat.Exporter
+	Schedule   schedule.Exporter
+	Encounters encounter.Exporter
+	Handouts   handout.Exporter
+}
+
+func NewDataExport(
+	accFinder Finder,
+	playerFinder player.Finder,
+	deletions DeletionStore,
+	sessions *SessionManagement,
+	tokens *AccessTokenManagement,
+	store ExportStore,
+	sources ExportSources,
+) *DataExport {
+	return &DataExport{
+		accFinder:    accFinder,
+		playerFinder: playerFinder,
+		deletions:    deletions,
+		sessions:     sessions,
+		tokens:       tokens,
+		store:        store,
+		sources:      sources,
+	}
+}
+
+// Export gathers the data of the account, the session of currentTokenId is marked as current
+func (uc *DataExport) Export(ctx context.Context, accountId id.AccountId, currentTokenId string) (ExportResponse, error) {
+	acc, err := uc.accFinder.FindById(ctx, accountId)
+	if err != nil {
+		logger.Debug("could not find account by id", "err", err)
+		return ExportResponse{}, ErrDatabaseError
+	}
+	if acc == nil {
+		return ExportResponse{}, ErrAccountDoesNotExist
+	}
+	p, err := uc.playerFinder.FindByAccountId(ctx, accountId)
+	if err != nil || p == nil {
+		logger.Debug("failed to find player", "account", accountId, "err", err)
+		return ExportResponse{}, ErrDatabaseError
+	}
+
+	resp := ExportResponse{
+		Account: ExportAccount{
+			AccountID:        int(acc.Id),
+			Username:         acc.Username,
+			Email:            emailValue(acc.Email),
+			EmailVerifiedAt:  acc.EmailVerifiedAt,
+			CreatedAt:        acc.CreatedAt,
+			TwoFactorEnabled: acc.IsTwoFactorEnabled(),
+		},
+		Player: PlayerCreateResponse{
+			PlayerID: int(p.Id),
+			Name:     p.Name,
+		},
+		ExportedAt: time.Now(),
+	}
+
+	deletion, err := uc.deletions.FindDeletion(ctx, accountId)
+	switch {
+	case err == nil:
+		resp.Account.DeletionPurgeAfter = &deletion.PurgeAfter
+	case !errors.Is(err, postgres.ErrNoRowFound):
+		logger.Debug("failed to find account deletion", "account", accountId, "err", err)
+		return ExportResponse{}, ErrDatabaseError
+	}
+
+	if resp.Sessions, err = uc.sessions.List(ctx, accountId, currentTokenId); err != nil {
+		return ExportResponse{}, err
+	}
+	if resp.AccessTokens, err = uc.tokens.List(ctx, accountId); err != nil {
+		return ExportResponse{}, err
+	}
+
+	if err := uc.findContent(ctx, &resp, accountId, p.Id); err != nil {
+		logger.Debug("failed to export account data", "account", accountId, "err", err)
+		return ExportResponse{}, ErrDatabaseError
+	}
+	return resp, nil
+}
+
+func (uc *DataExport) findContent(ctx context.Context, resp *ExportResponse, accountId id.AccountId, playerId id.PlayerId) error {
+	var err error
+	if resp.Identities, err = uc.store.FindExportIdentities(ctx, accountId); err != nil {
+		return err
+	}
+	if resp.Campaigns, err = uc.sources.Campaigns.FindExportCampaigns(ctx, playerId); err != nil {
+		return err
+	}
+	if resp.Characters, err = uc.sources.Characters.FindExportCharacters(ctx, playerId); err != nil {
+		return err
+	}
+	if resp.CharacterVersions, err = uc.sources.Characters.FindExportVersions(ctx, playerId); err != nil {
+		return err
+	}
+	if resp.JournalEntries, err = uc.sources.Journal.FindExportEntries(ctx, playerId); err != nil {
+		return err
+	}
+	if resp.WikiEntries, err = uc.sources.Wiki.FindExportEntries(ctx, playerId); err != nil {
+		return err
+	}
+	if resp.WikiRevisions, err = uc.sources.Wiki.FindExportRevisions(ctx, playerId); err != nil {
+		return err
+	}
+	if resp.ChatMessages, err = uc.sources.Chat.FindExportMessages(ctx, playerId); err != nil {
+		return err
+	}
+	if resp.ChatEdits, err = uc.sources.Chat.FindExportEdits(ctx, playerId); err != nil {
+		return err
+	}
+	if resp.ReceivedWhispers, err = uc.sources.Chat.FindExportReceivedWhispers(ctx, playerId); err != nil {
+		return err
+	}
+	if resp.SessionVotes, err = uc.sources.Schedule.FindExportVotes(ctx, playerId); err != nil {
+		return err
+	}
+	if resp.SessionAttendance, err = uc.sources.Schedule.FindExportAttendance(ctx, playerId); err != nil {
+		return err
+	}
+	if resp.EncounterActions, err = uc.sources.Encounters.FindExportActions(ctx, playerId); err != nil {
+		return err
+	}
+	resp.Handouts, err = uc.sources.Handouts.FindExportHandouts(ctx, playerId)
+	return err
+}
+
+// WriteExportZip writes the export as a ZIP archive, with a JSON file for the account and one
+// for each kind of content
+func WriteExportZip(w io.Writer, export ExportResponse) error {
+	files := []struct {
+		name    string
+		content any
+	}{
+		{"account.json", struct {
+			Account      ExportAccount         `json:"account"`
+			Player       PlayerCreateResponse  `json:"player"`
+			Identities   []ExportIdentity      `json:"identities"`
+			Sessions     []SessionResponse     `json:"sessions"`
+			AccessTokens []AccessTokenResponse `json:"access_tokens"`
+			ExportedAt   time.Time             `json:"exported_at"`
+		}{export.Account, export.Player, export.Identities, export.Sessions, export.AccessTokens, export.ExportedAt}},
+		{"campaigns.json", export.Campaigns},
+		{"characters.json", export.Characters},
+		{"character_versions.json", export.CharacterVersions},
+		{"journal_entries.json", export.JournalEntries},
+		{"wiki_entries.json", export.WikiEntries},
+		{"wiki_revisions.json", export.WikiRevisions},
+		{"chat_messages.json", export.ChatMessages},
+		{"chat_edits.json", export.ChatEdits},
+		{"received_whispers.json", export.ReceivedWhispers},
+		{"session_votes.json", export.SessionVotes},
+		{"session_attendance.json", export.SessionAttendance},
+		{"encounter_actions.json", export.EncounterActions},
+		{"handouts.json", export.Handouts},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
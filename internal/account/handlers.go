@@ -1,10 +1,13 @@
 package account
 
 import (
+	"beldur/internal/id"
 	"beldur/pkg/httperr"
 	"beldur/pkg/middleware"
+	"bytes"
+	"context"
 	"errors"
-	"time"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,14 +16,45 @@ type HttpHandler struct {
 	registrationUC *Registration
 	loginUC        *UsernamePasswordLogin
 	manageUC       *Management
+	passwordUC     *PasswordManagement
+	verificationUC *EmailVerification
+	twoFactorUC    *TwoFactorManagement
+	oidcUC         *OIDCLogin
+	accessTokenUC  *AccessTokenManagement
+	sessionUC      *SessionManagement
+	deletionUC     *AccountDeletion
+	exportUC       *DataExport
+	cookie         middleware.SessionCookie
 	errManager     *httperr.Manager
 }
 
-func NewHttpHandler(registrationUC *Registration, loginUC *UsernamePasswordLogin, accountManagement *Management) *HttpHandler {
+func NewHttpHandler(
+	registrationUC *Registration,
+	loginUC *UsernamePasswordLogin,
+	accountManagement *Management,
+	passwordManagement *PasswordManagement,
+	emailVerification *EmailVerification,
+	twoFactorManagement *TwoFactorManagement,
+	oidcLogin *OIDCLogin,
+	accessTokenManagement *AccessTokenManagement,
+	sessionManagement *SessionManagement,
+	accountDeletion *AccountDeletion,
+	dataExport *DataExport,
+	cookie middleware.SessionCookie,
+) *HttpHandler {
 	return &HttpHandler{
 		registrationUC: registrationUC,
 		loginUC:        loginUC,
 		manageUC:       accountManagement,
+		passwordUC:     passwordManagement,
+		verificationUC: emailVerification,
+		twoFactorUC:    twoFactorManagement,
+		oidcUC:         oidcLogin,
+		accessTokenUC:  accessTokenManagement,
+		sessionUC:      sessionManagement,
+		deletionUC:     accountDeletion,
+		exportUC:       dataExport,
+		cookie:         cookie,
 		errManager:     NewAccountApiErrorManager(),
 	}
 }
@@ -29,7 +63,7 @@ func NewHttpHandler(registrationUC *Registration, loginUC *UsernamePasswordLogin
 func (h *HttpHandler) Register(c *fiber.Ctx) error {
 	req := c.Locals("body").(CreateAccountRequest)
 
-	response, token, err := h.registrationUC.RegisterAccount(c.Context(), req)
+	response, token, err := h.registrationUC.RegisterAccount(clientContext(c), req)
 	if err != nil {
 		status, body := h.errManager.Map(err)
 		return c.Status(status).JSON(body)
@@ -46,7 +80,29 @@ func (h *HttpHandler) Register(c *fiber.Ctx) error {
 func (h *HttpHandler) Login(c *fiber.Ctx) error {
 	req := c.Locals("body").(UsernamePasswordLoginRequest)
 
-	jwt, err := h.loginUC.Login(c.Context(), req)
+	jwt, challenge, err := h.loginUC.Login(clientContext(c), req)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	// the cookie is given by LoginWithCode once the second factor is checked
+	if challenge != nil {
+		return c.Status(fiber.StatusAccepted).JSON(challenge)
+	}
+
+	if err := h.attachTokenToCookie(c, jwt); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// LoginWithCode completes the login of an account with two-factor authentication and gives the cookie
+func (h *HttpHandler) LoginWithCode(c *fiber.Ctx) error {
+	req := c.Locals("body").(TwoFactorLoginRequest)
+
+	jwt, err := h.loginUC.LoginWithCode(clientContext(c), req)
 	if err != nil {
 		status, body := h.errManager.Map(err)
 		return c.Status(status).JSON(body)
@@ -60,6 +116,37 @@ func (h *HttpHandler) Login(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusOK)
 }
 
+// StartOIDCLogin redirects to the sign in page of the provider
+func (h *HttpHandler) StartOIDCLogin(c *fiber.Ctx) error {
+	url, err := h.oidcUC.Start(c.Context(), providerParam(c))
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Redirect(url, fiber.StatusFound)
+}
+
+// OIDCCallback logs in with the code the provider gave to the redirect page of the client,
+// like Login it gives the cookie or a login challenge
+func (h *HttpHandler) OIDCCallback(c *fiber.Ctx) error {
+	req := c.Locals("body").(OIDCCallbackRequest)
+
+	jwt, challenge, err := h.oidcUC.Callback(clientContext(c), providerParam(c), req)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	if challenge != nil {
+		return c.Status(fiber.StatusAccepted).JSON(challenge)
+	}
+
+	if err := h.attachTokenToCookie(c, jwt); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
 func (h *HttpHandler) UpdateAccount(c *fiber.Ctx) error {
 	req := c.Locals("body").(UpdateAccountRequest)
 
@@ -76,23 +163,331 @@ func (h *HttpHandler) UpdateAccount(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
+// ChangePassword replaces the password and gives a new cookie, the other sessions are logged out
+func (h *HttpHandler) ChangePassword(c *fiber.Ctx) error {
+	req := c.Locals("body").(ChangePasswordRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	token, err := h.passwordUC.ChangePassword(clientContext(c), req, p.AccountID, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+
+	if err := h.attachTokenToCookie(c, token); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ForgotPassword always answers 202, whether an account has the email or not
+func (h *HttpHandler) ForgotPassword(c *fiber.Ctx) error {
+	req := c.Locals("body").(ForgotPasswordRequest)
+
+	if err := h.passwordUC.RequestReset(c.Context(), req); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// ResetPassword sets a new password with the token of a reset link, the user must log in again
+func (h *HttpHandler) ResetPassword(c *fiber.Ctx) error {
+	req := c.Locals("body").(ResetPasswordRequest)
+
+	if err := h.passwordUC.ResetPassword(c.Context(), req); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// VerifyEmail verifies the email with the token of a verification link, no login is needed
+func (h *HttpHandler) VerifyEmail(c *fiber.Ctx) error {
+	req := c.Locals("body").(VerifyEmailRequest)
+
+	if err := h.verificationUC.Verify(c.Context(), req); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResendVerification sends a new verification link to the email of the logged account
+func (h *HttpHandler) ResendVerification(c *fiber.Ctx) error {
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.verificationUC.Resend(c.Context(), p.AccountID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// EnrollTwoFactor gives a new TOTP secret, to confirm with ConfirmTwoFactor
+func (h *HttpHandler) EnrollTwoFactor(c *fiber.Ctx) error {
+	req := c.Locals("body").(EnrollTwoFactorRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.twoFactorUC.Enroll(c.Context(), req, p.AccountID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// ConfirmTwoFactor enables two-factor authentication and gives the recovery codes, shown only once
+func (h *HttpHandler) ConfirmTwoFactor(c *fiber.Ctx) error {
+	req := c.Locals("body").(ConfirmTwoFactorRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.twoFactorUC.Confirm(c.Context(), req, p.AccountID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) DisableTwoFactor(c *fiber.Ctx) error {
+	req := c.Locals("body").(DisableTwoFactorRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.twoFactorUC.Disable(c.Context(), req, p.AccountID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CreateAccessToken gives a new personal access token, shown only once
+func (h *HttpHandler) CreateAccessToken(c *fiber.Ctx) error {
+	req := c.Locals("body").(CreateAccessTokenRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.accessTokenUC.Create(c.Context(), req, p.AccountID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *HttpHandler) GetAccessTokens(c *fiber.Ctx) error {
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.accessTokenUC.List(c.Context(), p.AccountID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) RevokeAccessToken(c *fiber.Ctx) error {
+	tokenId, err := accessTokenIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.accessTokenUC.Revoke(c.Context(), tokenId, p.AccountID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetSessions lists the devices where the account is logged in
+func (h *HttpHandler) GetSessions(c *fiber.Ctx) error {
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.sessionUC.List(c.Context(), p.AccountID, p.SessionID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// RevokeSession signs out the session, the current one included
+func (h *HttpHandler) RevokeSession(c *fiber.Ctx) error {
+	sessionId, err := sessionIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.sessionUC.Revoke(c.Context(), sessionId, p.AccountID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RequestDeletion schedules the deletion of the account at the end of the grace period
+func (h *HttpHandler) RequestDeletion(c *fiber.Ctx) error {
+	req := c.Locals("body").(DeleteAccountRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.deletionUC.Request(c.Context(), req, p.AccountID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusAccepted).JSON(resp)
+}
+
+func (h *HttpHandler) GetDeletion(c *fiber.Ctx) error {
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.deletionUC.Find(c.Context(), p.AccountID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// CancelDeletion keeps the account, during the grace period
+func (h *HttpHandler) CancelDeletion(c *fiber.Ctx) error {
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.deletionUC.Cancel(c.Context(), p.AccountID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ExportData sends all the personal data of the account as a ZIP archive of JSON files,
+// or as a single JSON document with ?format=json
+func (h *HttpHandler) ExportData(c *fiber.Ctx) error {
+	format := c.Query("format", "zip")
+	if format != "zip" && format != "json" {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	export, err := h.exportUC.Export(c.Context(), p.AccountID, p.SessionID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if format == "json" {
+		c.Attachment("beldur-export.json")
+		return c.Status(fiber.StatusOK).JSON(export)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteExportZip(&buf, export); err != nil {
+		return err
+	}
+	c.Attachment("beldur-export.zip")
+	return c.Status(fiber.StatusOK).Send(buf.Bytes())
+}
+
+// attachTokenToCookie gives the session cookie, along with the CSRF cookie
 func (h *HttpHandler) attachTokenToCookie(c *fiber.Ctx, token string) error {
 	if token == "" {
 		return errors.New("empty token")
 	}
 
-	// TODO CHANGE
-	exp := time.Now().Add(24 * time.Hour)
+	h.cookie.Set(c, token)
+	return nil
+}
+
+func providerParam(c *fiber.Ctx) string {
+	provider := c.Params("provider")
+	if provider == "" {
+		panic("wrong parameter naming")
+	}
+	return provider
+}
+
+func accessTokenIdParam(c *fiber.Ctx) (id.AccessTokenId, error) {
+	tokenInstr := c.Params("tokenId")
+	if tokenInstr == "" {
+		panic("wrong parameter naming")
+	}
+	tokenId, err := strconv.Atoi(tokenInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.AccessTokenId(tokenId), nil
+}
 
-	c.Cookie(&fiber.Cookie{
-		Name:     "jwt",
-		Value:    token,
-		Path:     "/",
-		HTTPOnly: true,
-		Secure:   false,
-		SameSite: "Lax",
-		Expires:  exp,
-	})
+func sessionIdParam(c *fiber.Ctx) (id.AccountSessionId, error) {
+	sessionInstr := c.Params("sessionId")
+	if sessionInstr == "" {
+		panic("wrong parameter naming")
+	}
+	sessionId, err := strconv.Atoi(sessionInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.AccountSessionId(sessionId), nil
+}
 
-	return nil
+// clientContext gives the device of the request to the use cases opening a session
+func clientContext(c *fiber.Ctx) context.Context {
+	return WithClient(c.Context(), Client{
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IP:        c.IP(),
+	})
 }
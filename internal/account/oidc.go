@@ -0,0 +1,266 @@
+package account
+
+import (
+	"beldur/internal/id"
+	"beldur/internal/player"
+	"beldur/pkg/auth"
+	"beldur/pkg/auth/oidc"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/logger"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// OIDCStateDuration is how long the user has to sign in at the provider
+const OIDCStateDuration = 10 * time.Minute
+
+// Identity links an account to the user of an OpenID Connect provider
+type Identity struct {
+	AccountId id.AccountId
+	// Provider is the name of the provider in the configuration
+	Provider string
+	// Subject identifies the user at the provider
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
+
+// OIDCState is kept between the redirect to the provider and the callback
+type OIDCState struct {
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+}
+
+// IdentityProvider is an OpenID Connect provider, see oidc.Provider
+type IdentityProvider interface {
+	AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code string, codeVerifier string) (oidc.Claims, error)
+}
+
+// OIDCLogin is a login USE CASE delegated to an OpenID Connect provider, with the authorization
+// code flow and PKCE. The first login creates an account, unless a verified email links it to
+// an existing one.
+type OIDCLogin struct {
+	providers    map[string]IdentityProvider
+	states       OIDCStateStore
+	identities   IdentityStore
+	accFinder    Finder
+	emailFinder  EmailFinder
+	registration *Registration
+	sessions     sessionStarter
+}
+
+func NewOIDCLogin(
+	providers map[string]IdentityProvider,
+	states OIDCStateStore,
+	identities IdentityStore,
+	accFinder Finder,
+	emailFinder EmailFinder,
+	registration *Registration,
+	accUpdater Updater,
+	playerFinder player.Finder,
+	tokenIssuer auth.TokenIssuer,
+	challenges LoginChallengeStore,
+) *OIDCLogin {
+	return &OIDCLogin{
+		providers:    providers,
+		states:       states,
+		identities:   identities,
+		accFinder:    accFinder,
+		emailFinder:  emailFinder,
+		registration: registration,
+		sessions: sessionStarter{
+			accUpdater:   accUpdater,
+			playerFinder: playerFinder,
+			tokenIssuer:  tokenIssuer,
+			challenges:   challenges,
+		},
+	}
+}
+
+// Start gives back the URL of the provider where the user is redirected to sign in
+func (l *OIDCLogin) Start(ctx context.Context, providerName string) (string, error) {
+	provider, ok := l.providers[providerName]
+	if !ok {
+		return "", ErrUnknownIdentityProvider
+	}
+
+	state, stateHash := auth.NewSecretToken()
+	st := OIDCState{
+		Provider:     providerName,
+		Nonce:        oidc.NewNonce(),
+		CodeVerifier: oidc.NewCodeVerifier(),
+	}
+	if err := l.states.SaveOIDCState(ctx, stateHash, st, time.Now().Add(OIDCStateDuration)); err != nil {
+		logger.Debug("failed to save oidc state", "provider", providerName, "err", err)
+		return "", ErrDatabaseError
+	}
+
+	url, err := provider.AuthCodeURL(ctx, state, st.Nonce, oidc.CodeChallenge(st.CodeVerifier))
+	if err != nil {
+		logger.Error("failed to build oidc authorization url", err, "provider", providerName)
+		return "", ErrIdentityProvider
+	}
+	return url, nil
+}
+
+// Callback completes the login with the code given by the provider. Like Login, it gives back
+// a login challenge instead of the token when the account has two-factor authentication.
+func (l *OIDCLogin) Callback(ctx context.Context, providerName string, req OIDCCallbackRequest) (string, *LoginChallengeResponse, error) {
+	provider, ok := l.providers[providerName]
+	if !ok {
+		return "", nil, ErrUnknownIdentityProvider
+	}
+
+	st, err := l.states.ConsumeOIDCState(ctx, auth.HashSecretToken(req.State))
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return "", nil, ErrInvalidOIDCState
+		}
+		logger.Debug("failed to consume oidc state", "err", err)
+		return "", nil, ErrDatabaseError
+	}
+	if st.Provider != providerName {
+		return "", nil, ErrInvalidOIDCState
+	}
+
+	claims, err := provider.Exchange(ctx, req.Code, st.CodeVerifier)
+	if err != nil {
+		logger.Debug("failed to exchange oidc code", "provider", providerName, "err", err)
+		return "", nil, ErrIdentityProvider
+	}
+	if claims.Nonce != st.Nonce {
+		logger.Debug("oidc nonce mismatch", "provider", providerName)
+		return "", nil, ErrInvalidOIDCState
+	}
+
+	acc, err := l.findOrRegister(ctx, providerName, claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return l.sessions.start(ctx, acc)
+}
+
+// findOrRegister gives back the account linked to the identity. An identity seen for the first
+// time is linked to the account with the same email, when both the provider and the account
+// have verified it, otherwise a new account is created.
+func (l *OIDCLogin) findOrRegister(ctx context.Context, providerName string, claims oidc.Claims) (*Account, error) {
+	accountId, err := l.identities.FindIdentity(ctx, providerName, claims.Subject)
+	if err == nil {
+		acc, err := l.accFinder.FindById(ctx, accountId)
+		if err != nil || acc == nil {
+			logger.Debug("could not find account of identity", "account", accountId, "err", err)
+			return nil, ErrDatabaseError
+		}
+		return acc, nil
+	}
+	if !errors.Is(err, postgres.ErrNoRowFound) {
+		logger.Debug("failed to find identity", "provider", providerName, "err", err)
+		return nil, ErrDatabaseError
+	}
+
+	identity := Identity{Provider: providerName, Subject: claims.Subject, Email: claims.Email, CreatedAt: time.Now()}
+
+	em, emailErr := NewEmail(claims.Email)
+	if emailErr == nil {
+		existing, err := l.emailFinder.FindByEmail(ctx, em)
+		if err != nil {
+			logger.Debug("failed to find account by email", "err", err)
+			return nil, ErrDatabaseError
+		}
+		if existing != nil {
+			if claims.EmailVerified && RequireVerifiedEmail(existing) {
+				identity.AccountId = existing.Id
+				if err := l.identities.SaveIdentity(ctx, identity); err != nil {
+					logger.Debug("failed to link identity", "account", existing.Id, "err", err)
+					return nil, ErrDatabaseError
+				}
+				return existing, nil
+			}
+			// the email belongs to another account, the new one goes without it
+			emailErr = ErrEmailAlreadyTaken
+		}
+	}
+
+	username, err := l.availableUsername(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	newAcc, err := New(username, "")
+	if err != nil {
+		return nil, err
+	}
+	if emailErr == nil {
+		newAcc.UpdateEmail(em)
+		if claims.EmailVerified {
+			now := time.Now()
+			newAcc.EmailVerifiedAt = &now
+		}
+	}
+
+	return l.registration.RegisterExternalAccount(ctx, newAcc, func(txCtx context.Context, acc *Account) error {
+		identity.AccountId = acc.Id
+		return l.identities.SaveIdentity(txCtx, identity)
+	})
+}
+
+// availableUsername derives a free username from the profile of the user at the provider
+func (l *OIDCLogin) availableUsername(ctx context.Context, claims oidc.Claims) (string, error) {
+	const maxTrials = 5
+
+	base := usernameFromProfile(claims)
+	candidate := base
+	for range maxTrials {
+		acc, err := l.accFinder.FindByUsername(ctx, candidate)
+		if err != nil {
+			logger.Debug("failed to find account", "username", candidate, "err", err)
+			return "", ErrDatabaseError
+		}
+		if acc == nil {
+			return candidate, nil
+		}
+
+		suffix := fmt.Sprintf("_%04d", randomInt(10000))
+		candidate = base[:min(len(base), UsernameMaxCharacters-len(suffix))] + suffix
+	}
+	return "", ErrAccountNameAlreadyTaken
+}
+
+// usernameFromProfile keeps the letters, digits and underscores of the preferred username,
+// of the email or of the name
+func usernameFromProfile(claims oidc.Claims) string {
+	localPart, _, _ := strings.Cut(claims.Email, "@")
+	for _, candidate := range []string{claims.PreferredUsername, localPart, claims.Name} {
+		var b strings.Builder
+		for _, r := range strings.ToLower(candidate) {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+				b.WriteRune(r)
+			case r == ' ', r == '.', r == '-':
+				b.WriteRune('_')
+			}
+		}
+		name := strings.Trim(b.String(), "_")
+		if len(name) > UsernameMaxCharacters {
+			name = name[:UsernameMaxCharacters]
+		}
+		if len(name) >= UsernameMinCharacters {
+			return name
+		}
+	}
+	return fmt.Sprintf("user_%06d", randomInt(1000000))
+}
+
+func randomInt(n int64) int64 {
+	v, err := rand.Int(rand.Reader, big.NewInt(n))
+	if err != nil {
+		panic(err)
+	}
+	return v.Int64()
+}
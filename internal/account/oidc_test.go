@@ -0,0 +1,232 @@
+package account
+
+import (
+	"beldur/internal/id"
+	"beldur/internal/player"
+	"beldur/pkg/auth"
+	"beldur/pkg/auth/oidc"
+	"beldur/pkg/auth/oidc/oidctest"
+	"beldur/pkg/db/postgres"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type oidcMocks struct {
+	server       *oidctest.Server
+	states       *MockOIDCStateStore
+	identities   *MockIdentityStore
+	accFinder    *MockFinder
+	emailFinder  *MockEmailFinder
+	saver        *MockSaver
+	uniquePlayer *MockUniquePlayerCreator
+	verification *MockVerificationSender
+	accUpdater   *MockUpdater
+	playerFinder *MockPlayerFinder
+	tokenIssuer  *MockTokenIssuer
+}
+
+// newOIDCLogin logs in against a local fake provider named "test"
+func newOIDCLogin(t *testing.T) (*OIDCLogin, oidcMocks) {
+	m := oidcMocks{
+		server:       oidctest.NewServer(),
+		states:       new(MockOIDCStateStore),
+		identities:   new(MockIdentityStore),
+		accFinder:    new(MockFinder),
+		emailFinder:  new(MockEmailFinder),
+		saver:        new(MockSaver),
+		uniquePlayer: new(MockUniquePlayerCreator),
+		verification: new(MockVerificationSender),
+		accUpdater:   new(MockUpdater),
+		playerFinder: new(MockPlayerFinder),
+		tokenIssuer:  new(MockTokenIssuer),
+	}
+	t.Cleanup(m.server.Close)
+
+	provider := oidc.NewProvider(oidc.Config{
+		Issuer:       m.server.Issuer(),
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  "http://localhost:5173/oidc/test/callback",
+	}, nil)
+	registration := NewAccountRegistration(FnTransactor{}, m.saver, m.uniquePlayer, m.tokenIssuer, m.verification)
+
+	uc := NewOIDCLogin(
+		map[string]IdentityProvider{"test": provider},
+		m.states,
+		m.identities,
+		m.accFinder,
+		m.emailFinder,
+		registration,
+		m.accUpdater,
+		m.playerFinder,
+		m.tokenIssuer,
+		new(MockLoginChallengeStore),
+	)
+	return uc, m
+}
+
+// signIn goes through the provider as the user and gives back the callback request
+func signIn(t *testing.T, uc *OIDCLogin, m oidcMocks, user oidctest.User) OIDCCallbackRequest {
+	var (
+		stateHash string
+		state     OIDCState
+	)
+	m.states.On("SaveOIDCState", mock.Anything, mock.AnythingOfType("string"), mock.AnythingOfType("account.OIDCState"), mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) {
+			stateHash = args.String(1)
+			state = args.Get(2).(OIDCState)
+		}).
+		Return(nil).Once()
+
+	authURL, err := uc.Start(context.Background(), "test")
+	require.NoError(t, err)
+
+	m.server.SignIn(user)
+	code, plainState, err := m.server.Authorize(authURL)
+	require.NoError(t, err)
+	require.Equal(t, stateHash, auth.HashSecretToken(plainState))
+
+	m.states.On("ConsumeOIDCState", mock.Anything, stateHash).Return(state, nil).Once()
+	return OIDCCallbackRequest{Code: code, State: plainState}
+}
+
+func expectSession(m oidcMocks, accountId id.AccountId) {
+	m.playerFinder.On("FindByAccountId", mock.Anything, accountId).Return(&player.Player{Id: 7}, nil).Once()
+	m.accUpdater.On("UpdateLastAccess", mock.Anything, accountId).Return(nil).Once()
+	m.tokenIssuer.On("Issue", mock.Anything, mock.MatchedBy(func(c auth.Claims) bool {
+		return c.Subject == accountId && c.PlayerID == 7
+	})).Return("jwt-token", nil).Once()
+}
+
+func TestOIDCLogin_RegistersNewUser(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newOIDCLogin(t)
+	req := signIn(t, uc, m, oidctest.User{
+		Subject:       "user-1",
+		Email:         "Dungeon.Master@example.com",
+		EmailVerified: true,
+	})
+
+	m.identities.On("FindIdentity", mock.Anything, "test", "user-1").Return(id.AccountId(0), postgres.ErrNoRowFound).Once()
+	m.emailFinder.On("FindByEmail", mock.Anything, mock.Anything).Return(nil, nil).Once()
+	m.accFinder.On("FindByUsername", mock.Anything, "dungeon_master").Return(nil, nil).Once()
+	m.saver.On("Save", mock.Anything, mock.MatchedBy(func(a *Account) bool {
+		return a.Username == "dungeon_master" && a.Password == "" &&
+			a.Email.String() == "dungeon.master@example.com" && a.IsEmailVerified()
+	})).
+		Return(&Account{Id: 3, Username: "dungeon_master"}, nil).Once()
+	m.uniquePlayer.On("CreateUniquePlayer", mock.Anything, mock.Anything, id.AccountId(3)).Return(&player.Player{Id: 7}, nil).Once()
+	m.identities.On("SaveIdentity", mock.Anything, mock.MatchedBy(func(i Identity) bool {
+		return i.AccountId == 3 && i.Provider == "test" && i.Subject == "user-1"
+	})).Return(nil).Once()
+	expectSession(m, 3)
+
+	token, challenge, err := uc.Callback(ctx, "test", req)
+	require.NoError(t, err)
+	assert.Nil(t, challenge)
+	assert.Equal(t, "jwt-token", token)
+	m.identities.AssertExpectations(t)
+	// the provider has verified the email already
+	m.verification.AssertNotCalled(t, "SendVerification", mock.Anything, mock.Anything)
+}
+
+func TestOIDCLogin_KnownIdentity(t *testing.T) {
+	uc, m := newOIDCLogin(t)
+	req := signIn(t, uc, m, oidctest.User{Subject: "user-1"})
+
+	m.identities.On("FindIdentity", mock.Anything, "test", "user-1").Return(id.AccountId(2), nil).Once()
+	m.accFinder.On("FindById", mock.Anything, id.AccountId(2)).Return(&Account{Id: 2, Username: "username123"}, nil).Once()
+	expectSession(m, 2)
+
+	token, _, err := uc.Callback(context.Background(), "test", req)
+	require.NoError(t, err)
+	assert.Equal(t, "jwt-token", token)
+	m.saver.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestOIDCLogin_LinksVerifiedEmail(t *testing.T) {
+	uc, m := newOIDCLogin(t)
+	req := signIn(t, uc, m, oidctest.User{Subject: "user-1", Email: "dm@example.com", EmailVerified: true})
+
+	em, _ := NewEmail("dm@example.com")
+	verifiedAt := time.Now()
+	m.identities.On("FindIdentity", mock.Anything, "test", "user-1").Return(id.AccountId(0), postgres.ErrNoRowFound).Once()
+	m.emailFinder.On("FindByEmail", mock.Anything, em).Return(&Account{Id: 2, Email: &em, EmailVerifiedAt: &verifiedAt}, nil).Once()
+	m.identities.On("SaveIdentity", mock.Anything, mock.MatchedBy(func(i Identity) bool { return i.AccountId == 2 })).Return(nil).Once()
+	expectSession(m, 2)
+
+	_, _, err := uc.Callback(context.Background(), "test", req)
+	require.NoError(t, err)
+	m.identities.AssertExpectations(t)
+	m.saver.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestOIDCLogin_UnverifiedEmailIsNotLinked(t *testing.T) {
+	uc, m := newOIDCLogin(t)
+	req := signIn(t, uc, m, oidctest.User{Subject: "user-1", Email: "dm@example.com", EmailVerified: true, PreferredUsername: "dm"})
+
+	em, _ := NewEmail("dm@example.com")
+	m.identities.On("FindIdentity", mock.Anything, "test", "user-1").Return(id.AccountId(0), postgres.ErrNoRowFound).Once()
+	m.emailFinder.On("FindByEmail", mock.Anything, em).Return(&Account{Id: 2, Email: &em}, nil).Once()
+	// "dm" is too short, the local part of the email too: the username is generated
+	m.accFinder.On("FindByUsername", mock.Anything, mock.MatchedBy(func(u string) bool { return len(u) == 11 })).Return(nil, nil).Once()
+	m.saver.On("Save", mock.Anything, mock.MatchedBy(func(a *Account) bool { return !a.HasEmail() })).
+		Return(&Account{Id: 3}, nil).Once()
+	m.uniquePlayer.On("CreateUniquePlayer", mock.Anything, mock.Anything, id.AccountId(3)).Return(&player.Player{Id: 7}, nil).Once()
+	m.identities.On("SaveIdentity", mock.Anything, mock.MatchedBy(func(i Identity) bool { return i.AccountId == 3 })).Return(nil).Once()
+	expectSession(m, 3)
+
+	_, _, err := uc.Callback(context.Background(), "test", req)
+	require.NoError(t, err)
+	m.saver.AssertExpectations(t)
+}
+
+func TestOIDCLogin_InvalidState(t *testing.T) {
+	uc, m := newOIDCLogin(t)
+	m.states.On("ConsumeOIDCState", mock.Anything, mock.Anything).Return(OIDCState{}, postgres.ErrNoRowFound).Once()
+
+	_, _, err := uc.Callback(context.Background(), "test", OIDCCallbackRequest{Code: "code", State: "state"})
+	assert.ErrorIs(t, err, ErrInvalidOIDCState)
+}
+
+func TestOIDCLogin_UnknownProvider(t *testing.T) {
+	uc, _ := newOIDCLogin(t)
+
+	_, err := uc.Start(context.Background(), "other")
+	assert.ErrorIs(t, err, ErrUnknownIdentityProvider)
+}
+
+func TestUsernameFromProfile(t *testing.T) {
+	assert.Equal(t, "dungeon_master", usernameFromProfile(oidc.Claims{Name: "Dungeon Master"}))
+	assert.Equal(t, "gm_bob", usernameFromProfile(oidc.Claims{PreferredUsername: "gm-bob"}))
+	assert.Equal(t, "averyveryverylongnam", usernameFromProfile(oidc.Claims{Email: "averyveryverylongname@example.com"}))
+	assert.Regexp(t, `^user_\d{6}$`, usernameFromProfile(oidc.Claims{Name: "Bo"}))
+}
+
+type MockOIDCStateStore struct{ mock.Mock }
+type MockIdentityStore struct{ mock.Mock }
+
+func (m *MockOIDCStateStore) SaveOIDCState(ctx context.Context, stateHash string, state OIDCState, expiresAt time.Time) error {
+	args := m.Called(ctx, stateHash, state, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockOIDCStateStore) ConsumeOIDCState(ctx context.Context, stateHash string) (OIDCState, error) {
+	args := m.Called(ctx, stateHash)
+	return args.Get(0).(OIDCState), args.Error(1)
+}
+
+func (m *MockIdentityStore) FindIdentity(ctx context.Context, provider string, subject string) (id.AccountId, error) {
+	args := m.Called(ctx, provider, subject)
+	return args.Get(0).(id.AccountId), args.Error(1)
+}
+
+func (m *MockIdentityStore) SaveIdentity(ctx context.Context, identity Identity) error {
+	args := m.Called(ctx, identity)
+	return args.Error(0)
+}
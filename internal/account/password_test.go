@@ -0,0 +1,277 @@
+package account
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/auth"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/mail"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type passwordMocks struct {
+	accFinder       *MockFinder
+	emailFinder     *MockEmailFinder
+	passwordUpdater *MockPasswordUpdater
+	resetTokens     *MockResetTokenStore
+	mailer          *MockMailer
+	tokenIssuer     *MockTokenIssuer
+}
+
+func newPasswordManagement() (*PasswordManagement, passwordMocks) {
+	m := passwordMocks{
+		accFinder:       new(MockFinder),
+		emailFinder:     new(MockEmailFinder),
+		passwordUpdater: new(MockPasswordUpdater),
+		resetTokens:     new(MockResetTokenStore),
+		mailer:          new(MockMailer),
+		tokenIssuer:     new(MockTokenIssuer),
+	}
+	uc := NewPasswordManagement(
+		m.accFinder,
+		m.emailFinder,
+		m.passwordUpdater,
+		m.resetTokens,
+		m.mailer,
+		m.tokenIssuer,
+		FnTransactor{},
+		nil,
+		"https://beldur.example/reset",
+	)
+	return uc, m
+}
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+	hash, err := HashPassword("password123")
+	require.NoError(t, err)
+
+	t.Run("success revokes the other sessions", func(t *testing.T) {
+		uc, m := newPasswordManagement()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).
+			Return(&Account{Id: 1, Username: "username123", Password: hash, SessionVersion: 3}, nil).Once()
+		m.passwordUpdater.On("UpdatePassword", mock.Anything, mock.MatchedBy(func(a *Account) bool {
+			return a.SessionVersion == 4 && CheckPasswordHash(a.Password, "newpassword")
+		})).Return(nil).Once()
+		m.tokenIssuer.On("Issue", mock.Anything, auth.Claims{Subject: 1, PlayerID: 7, SessionVersion: 4}).
+			Return("jwt-token", nil).Once()
+
+		token, err := uc.ChangePassword(ctx, ChangePasswordRequest{
+			CurrentPassword: "password123",
+			NewPassword:     "newpassword",
+		}, 1, 7)
+
+		require.NoError(t, err)
+		assert.Equal(t, "jwt-token", token)
+		m.passwordUpdater.AssertExpectations(t)
+		m.tokenIssuer.AssertExpectations(t)
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		uc, m := newPasswordManagement()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).
+			Return(&Account{Id: 1, Password: hash}, nil).Once()
+
+		_, err := uc.ChangePassword(ctx, ChangePasswordRequest{
+			CurrentPassword: "password124",
+			NewPassword:     "newpassword",
+		}, 1, 7)
+
+		assert.ErrorIs(t, err, ErrWrongPassword)
+		m.passwordUpdater.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid new password", func(t *testing.T) {
+		uc, m := newPasswordManagement()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).
+			Return(&Account{Id: 1, Password: hash}, nil).Once()
+
+		_, err := uc.ChangePassword(ctx, ChangePasswordRequest{
+			CurrentPassword: "password123",
+			NewPassword:     "short",
+		}, 1, 7)
+
+		assert.ErrorIs(t, err, ErrInvalidPassword)
+		m.passwordUpdater.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRequestReset(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends a link with a token stored hashed", func(t *testing.T) {
+		uc, m := newPasswordManagement()
+		em, _ := NewEmail("dm@example.com")
+		verifiedAt := time.Now()
+		m.emailFinder.On("FindByEmail", mock.Anything, em).
+			Return(&Account{Id: 1, Email: &em, EmailVerifiedAt: &verifiedAt}, nil).Once()
+		m.resetTokens.On("LastResetSentAt", mock.Anything, id.AccountId(1)).Return(nil, nil).Once()
+
+		var storedHash string
+		m.resetTokens.On("SaveResetToken", mock.Anything, id.AccountId(1), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+			Run(func(args mock.Arguments) {
+				storedHash = args.String(2)
+				assert.WithinDuration(t, time.Now().Add(ResetTokenDuration), args.Get(3).(time.Time), time.Minute)
+			}).
+			Return(nil).Once()
+
+		var sent mail.Message
+		m.mailer.On("Send", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { sent = args.Get(1).(mail.Message) }).
+			Return(nil).Once()
+
+		require.NoError(t, uc.RequestReset(ctx, ForgotPasswordRequest{Email: "DM@example.com"}))
+
+		assert.Equal(t, "dm@example.com", sent.To)
+		_, after, found := strings.Cut(sent.Body, "https://beldur.example/reset?token=")
+		require.True(t, found)
+		token, _, _ := strings.Cut(after, "\n")
+		assert.Equal(t, auth.HashSecretToken(token), storedHash)
+		assert.NotContains(t, sent.Body, storedHash)
+	})
+
+	t.Run("throttled request succeeds silently", func(t *testing.T) {
+		uc, m := newPasswordManagement()
+		em, _ := NewEmail("dm@example.com")
+		verifiedAt := time.Now()
+		sentAt := time.Now().Add(-ResetRequestInterval / 2)
+		m.emailFinder.On("FindByEmail", mock.Anything, em).
+			Return(&Account{Id: 1, Email: &em, EmailVerifiedAt: &verifiedAt}, nil).Once()
+		m.resetTokens.On("LastResetSentAt", mock.Anything, id.AccountId(1)).Return(&sentAt, nil).Once()
+
+		require.NoError(t, uc.RequestReset(ctx, ForgotPasswordRequest{Email: "dm@example.com"}))
+		m.resetTokens.AssertNotCalled(t, "SaveResetToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unverified email succeeds silently", func(t *testing.T) {
+		uc, m := newPasswordManagement()
+		em, _ := NewEmail("dm@example.com")
+		m.emailFinder.On("FindByEmail", mock.Anything, em).Return(&Account{Id: 1, Email: &em}, nil).Once()
+
+		require.NoError(t, uc.RequestReset(ctx, ForgotPasswordRequest{Email: "dm@example.com"}))
+		m.resetTokens.AssertNotCalled(t, "SaveResetToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown email succeeds silently", func(t *testing.T) {
+		uc, m := newPasswordManagement()
+		m.emailFinder.On("FindByEmail", mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+		require.NoError(t, uc.RequestReset(ctx, ForgotPasswordRequest{Email: "nobody@example.com"}))
+		m.resetTokens.AssertNotCalled(t, "SaveResetToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success revokes every session", func(t *testing.T) {
+		uc, m := newPasswordManagement()
+		m.resetTokens.On("ConsumeResetToken", mock.Anything, auth.HashSecretToken("reset-token")).
+			Return(id.AccountId(1), nil).Once()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).
+			Return(&Account{Id: 1, Password: "old", SessionVersion: 2}, nil).Once()
+		m.passwordUpdater.On("UpdatePassword", mock.Anything, mock.MatchedBy(func(a *Account) bool {
+			return a.SessionVersion == 3 && CheckPasswordHash(a.Password, "newpassword")
+		})).Return(nil).Once()
+
+		require.NoError(t, uc.ResetPassword(ctx, ResetPasswordRequest{Token: "reset-token", NewPassword: "newpassword"}))
+		m.passwordUpdater.AssertExpectations(t)
+	})
+
+	t.Run("used or expired token", func(t *testing.T) {
+		uc, m := newPasswordManagement()
+		m.resetTokens.On("ConsumeResetToken", mock.Anything, mock.Anything).
+			Return(id.AccountId(0), postgres.ErrNoRowFound).Once()
+
+		err := uc.ResetPassword(ctx, ResetPasswordRequest{Token: "reset-token", NewPassword: "newpassword"})
+		assert.ErrorIs(t, err, ErrInvalidResetToken)
+		m.passwordUpdater.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid password keeps the token", func(t *testing.T) {
+		uc, m := newPasswordManagement()
+
+		err := uc.ResetPassword(ctx, ResetPasswordRequest{Token: "reset-token", NewPassword: "short"})
+		assert.ErrorIs(t, err, ErrInvalidPassword)
+		m.resetTokens.AssertNotCalled(t, "ConsumeResetToken", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSessionVerifier(t *testing.T) {
+	ctx := context.Background()
+	verifier := new(MockTokenVerifier)
+	versions := new(MockSessionVersionFinder)
+	sv := NewSessionVerifier(verifier, versions, new(MockSessionStore))
+
+	verifier.On("Verify", mock.Anything, "current").Return(auth.Verified{Subject: 1, PlayerId: 7, SessionVersion: 4}, nil)
+	verifier.On("Verify", mock.Anything, "revoked").Return(auth.Verified{Subject: 1, PlayerId: 7, SessionVersion: 3}, nil)
+	versions.On("FindSessionVersion", mock.Anything, id.AccountId(1)).Return(4, nil)
+
+	verified, err := sv.Verify(ctx, "current")
+	require.NoError(t, err)
+	assert.Equal(t, id.PlayerId(7), verified.PlayerId)
+
+	_, err = sv.Verify(ctx, "revoked")
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+}
+
+type MockEmailFinder struct{ mock.Mock }
+type MockPasswordUpdater struct{ mock.Mock }
+type MockResetTokenStore struct{ mock.Mock }
+type MockMailer struct{ mock.Mock }
+type MockTokenVerifier struct{ mock.Mock }
+type MockSessionVersionFinder struct{ mock.Mock }
+
+func (m *MockEmailFinder) FindByEmail(ctx context.Context, email Email) (*Account, error) {
+	args := m.Called(ctx, email)
+	var acc *Account
+	if v := args.Get(0); v != nil {
+		acc = v.(*Account)
+	}
+	return acc, args.Error(1)
+}
+
+func (m *MockPasswordUpdater) UpdatePassword(ctx context.Context, account *Account) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockResetTokenStore) SaveResetToken(ctx context.Context, accountId id.AccountId, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(ctx, accountId, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockResetTokenStore) LastResetSentAt(ctx context.Context, accountId id.AccountId) (*time.Time, error) {
+	args := m.Called(ctx, accountId)
+	sentAt, _ := args.Get(0).(*time.Time)
+	return sentAt, args.Error(1)
+}
+
+func (m *MockResetTokenStore) ConsumeResetToken(ctx context.Context, tokenHash string) (id.AccountId, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(id.AccountId), args.Error(1)
+}
+
+func (m *MockMailer) Send(ctx context.Context, msg mail.Message) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockTokenVerifier) Verify(ctx context.Context, token string) (auth.Verified, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(auth.Verified), args.Error(1)
+}
+
+func (m *MockSessionVersionFinder) FindSessionVersion(ctx context.Context, accountId id.AccountId) (int, error) {
+	args := m.Called(ctx, accountId)
+	return args.Int(0), args.Error(1)
+}
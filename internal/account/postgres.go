@@ -8,21 +8,27 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type PostgresRepository struct {
 	q postgres.QuerierProvider
 }
 
-// Update updates for now only email
+// Update updates for now only email, along with its verification
 func (a *PostgresRepository) Update(ctx context.Context, account *Account) error {
 	const query = `
 		UPDATE accounts
-		SET email = $1
-		WHERE account_id = $2
+		SET email = $1,
+		    email_verified_at = $2
+		WHERE account_id = $3
 	`
-	cmd, err := a.q(ctx).Exec(ctx, query, account.Email.String(), account.Id)
+	cmd, err := a.q(ctx).Exec(ctx, query, emailValue(account.Email), account.EmailVerifiedAt, account.Id)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return postgres.ErrUniqueValueViolation
+		}
 		return err
 	}
 	if cmd.RowsAffected() == 0 {
@@ -56,14 +62,19 @@ func NewPostgresRepository(q postgres.QuerierProvider) *PostgresRepository {
 
 func (a *PostgresRepository) Save(ctx context.Context, acc *Account) (*Account, error) {
 	const query = `
-		INSERT INTO accounts (username, password, email)
-		VALUES ($1, $2, $3)
-		RETURNING account_id, username, password, email, created_at
+		INSERT INTO accounts (username, password, email, email_verified_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING account_id, username, password, email, email_verified_at, created_at, session_version,
+		       totp_secret, two_factor_enabled_at, totp_last_step
 	`
-	row := a.q(ctx).QueryRow(ctx, query, acc.Username, acc.Password, acc.Email)
+	row := a.q(ctx).QueryRow(ctx, query, acc.Username, acc.Password, emailValue(acc.Email), acc.EmailVerifiedAt)
 
 	saved, err := a.scanAccount(row)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			err = postgres.ErrUniqueValueViolation
+		}
 		// IMPORTANT: rely on DB uniqueness constraint; map it to a domain-level repo error
 		// so the usecase can errors.Is() it and return a service error.
 		if errors.Is(err, postgres.ErrUniqueValueViolation) {
@@ -84,7 +95,8 @@ func (a *PostgresRepository) Save(ctx context.Context, acc *Account) (*Account,
 
 func (a *PostgresRepository) FindByUsername(ctx context.Context, username string) (*Account, error) {
 	const query = `
-		SELECT account_id, username, password, email, created_at
+		SELECT account_id, username, password, email, email_verified_at, created_at, session_version,
+		       totp_secret, two_factor_enabled_at, totp_last_step
 		FROM accounts
 		WHERE username = $1
 		LIMIT 1
@@ -96,7 +108,8 @@ func (a *PostgresRepository) FindByUsername(ctx context.Context, username string
 
 func (a *PostgresRepository) FindById(ctx context.Context, accountId id2.AccountId) (*Account, error) {
 	const query = `
-		SELECT account_id, username, password, email, created_at
+		SELECT account_id, username, password, email, email_verified_at, created_at, session_version,
+		       totp_secret, two_factor_enabled_at, totp_last_step
 		FROM accounts
 		WHERE account_id = $1
 		LIMIT 1
@@ -106,18 +119,855 @@ func (a *PostgresRepository) FindById(ctx context.Context, accountId id2.Account
 	return a.scanAccount(row)
 }
 
+func (a *PostgresRepository) FindByEmail(ctx context.Context, email Email) (*Account, error) {
+	const query = `
+		SELECT account_id, username, password, email, email_verified_at, created_at, session_version,
+		       totp_secret, two_factor_enabled_at, totp_last_step
+		FROM accounts
+		WHERE email = $1
+		LIMIT 1
+	`
+
+	row := a.q(ctx).QueryRow(ctx, query, email.String())
+	return a.scanAccount(row)
+}
+
+func (a *PostgresRepository) UpdatePassword(ctx context.Context, account *Account) error {
+	const query = `
+		UPDATE accounts
+		SET password = $1,
+		    session_version = $2
+		WHERE account_id = $3
+	`
+	cmd, err := a.q(ctx).Exec(ctx, query, account.Password, account.SessionVersion, account.Id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (a *PostgresRepository) UpdateEmailVerification(ctx context.Context, account *Account) error {
+	const query = `
+		UPDATE accounts
+		SET email_verified_at = $1
+		WHERE account_id = $2
+	`
+	cmd, err := a.q(ctx).Exec(ctx, query, account.EmailVerifiedAt, account.Id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (a *PostgresRepository) FindSessionVersion(ctx context.Context, accountId id2.AccountId) (int, error) {
+	const query = `SELECT session_version FROM accounts WHERE account_id = $1`
+
+	var version int
+	if err := a.q(ctx).QueryRow(ctx, query, accountId).Scan(&version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, postgres.ErrNoRowFound
+		}
+		return 0, err
+	}
+	return version, nil
+}
+
+func (a *PostgresRepository) SaveResetToken(ctx context.Context, accountId id2.AccountId, tokenHash string, expiresAt time.Time) error {
+	const sqlDelete = `DELETE FROM password_reset_tokens WHERE account_id = $1 AND used_at IS NULL`
+	const sqlInsert = `
+		INSERT INTO password_reset_tokens (account_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := a.q(ctx).Exec(ctx, sqlDelete, accountId); err != nil {
+		return err
+	}
+	_, err := a.q(ctx).Exec(ctx, sqlInsert, accountId, tokenHash, expiresAt)
+	return err
+}
+
+func (a *PostgresRepository) LastResetSentAt(ctx context.Context, accountId id2.AccountId) (*time.Time, error) {
+	const query = `SELECT MAX(created_at) FROM password_reset_tokens WHERE account_id = $1`
+
+	var sentAt *time.Time
+	if err := a.q(ctx).QueryRow(ctx, query, accountId).Scan(&sentAt); err != nil {
+		return nil, err
+	}
+	return sentAt, nil
+}
+
+// ConsumeResetToken uses the token in a single statement, so it can be used only once
+func (a *PostgresRepository) ConsumeResetToken(ctx context.Context, tokenHash string) (id2.AccountId, error) {
+	const query = `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1
+		  AND used_at IS NULL
+		  AND expires_at > NOW()
+		RETURNING account_id
+	`
+
+	var accountID int
+	if err := a.q(ctx).QueryRow(ctx, query, tokenHash).Scan(&accountID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, postgres.ErrNoRowFound
+		}
+		return 0, err
+	}
+	return id2.AccountId(accountID), nil
+}
+
+func (a *PostgresRepository) SaveVerificationToken(ctx context.Context, accountId id2.AccountId, email Email, tokenHash string, expiresAt time.Time) error {
+	const sqlDelete = `DELETE FROM email_verification_tokens WHERE account_id = $1 AND used_at IS NULL`
+	const sqlInsert = `
+		INSERT INTO email_verification_tokens (account_id, email, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := a.q(ctx).Exec(ctx, sqlDelete, accountId); err != nil {
+		return err
+	}
+	_, err := a.q(ctx).Exec(ctx, sqlInsert, accountId, email.String(), tokenHash, expiresAt)
+	return err
+}
+
+func (a *PostgresRepository) LastVerificationSentAt(ctx context.Context, accountId id2.AccountId) (*time.Time, error) {
+	const query = `SELECT MAX(created_at) FROM email_verification_tokens WHERE account_id = $1`
+
+	var sentAt *time.Time
+	if err := a.q(ctx).QueryRow(ctx, query, accountId).Scan(&sentAt); err != nil {
+		return nil, err
+	}
+	return sentAt, nil
+}
+
+// ConsumeVerificationToken uses the token in a single statement, so it can be used only once
+func (a *PostgresRepository) ConsumeVerificationToken(ctx context.Context, tokenHash string) (id2.AccountId, Email, error) {
+	const query = `
+		UPDATE email_verification_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1
+		  AND used_at IS NULL
+		  AND expires_at > NOW()
+		RETURNING account_id, email
+	`
+
+	var (
+		accountID int
+		em        string
+	)
+	if err := a.q(ctx).QueryRow(ctx, query, tokenHash).Scan(&accountID, &em); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, Email{}, postgres.ErrNoRowFound
+		}
+		return 0, Email{}, err
+	}
+	// Already validated when stored; ignore error defensively
+	accEmail, _ := NewEmail(em)
+	return id2.AccountId(accountID), accEmail, nil
+}
+
+func (a *PostgresRepository) UpdateTwoFactor(ctx context.Context, account *Account) error {
+	const query = `
+		UPDATE accounts
+		SET totp_secret = $1,
+		    two_factor_enabled_at = $2,
+		    totp_last_step = $3
+		WHERE account_id = $4
+	`
+	var secret *string
+	if account.TwoFactor.Secret != "" {
+		secret = &account.TwoFactor.Secret
+	}
+	cmd, err := a.q(ctx).Exec(ctx, query, secret, account.TwoFactor.EnabledAt, account.TwoFactor.LastStep, account.Id)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (a *PostgresRepository) UseTOTPStep(ctx context.Context, accountId id2.AccountId, step int64) error {
+	const query = `
+		UPDATE accounts
+		SET totp_last_step = $2
+		WHERE account_id = $1
+		  AND totp_last_step < $2
+	`
+
+	cmd, err := a.q(ctx).Exec(ctx, query, accountId, step)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (a *PostgresRepository) ReplaceRecoveryCodes(ctx context.Context, accountId id2.AccountId, codeHashes []string) error {
+	const sqlDelete = `DELETE FROM account_recovery_codes WHERE account_id = $1`
+	const sqlInsert = `
+		INSERT INTO account_recovery_codes (account_id, code_hash)
+		VALUES ($1, $2)
+	`
+
+	if _, err := a.q(ctx).Exec(ctx, sqlDelete, accountId); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := a.q(ctx).Exec(ctx, sqlInsert, accountId, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConsumeRecoveryCode uses the code in a single statement, so it can be used only once
+func (a *PostgresRepository) ConsumeRecoveryCode(ctx context.Context, accountId id2.AccountId, codeHash string) error {
+	const query = `
+		UPDATE account_recovery_codes
+		SET used_at = NOW()
+		WHERE account_id = $1
+		  AND code_hash = $2
+		  AND used_at IS NULL
+	`
+
+	cmd, err := a.q(ctx).Exec(ctx, query, accountId, codeHash)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowFound
+	}
+	return nil
+}
+
+func (a *PostgresRepository) SaveLoginChallenge(ctx context.Context, accountId id2.AccountId, tokenHash string, expiresAt time.Time) error {
+	// the expired challenges of the account are cleaned up along the way
+	const sqlDelete = `DELETE FROM login_challenges WHERE account_id = $1 AND expires_at <= NOW()`
+	const sqlInsert = `
+		INSERT INTO login_challenges (account_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := a.q(ctx).Exec(ctx, sqlDelete, accountId); err != nil {
+		return err
+	}
+	_, err := a.q(ctx).Exec(ctx, sqlInsert, accountId, tokenHash, expiresAt)
+	return err
+}
+
+// AttemptLoginChallenge counts the attempt in the statement checking the limit, so that
+// concurrent codes cannot go over it
+func (a *PostgresRepository) AttemptLoginChallenge(ctx context.Context, tokenHash string) (id2.AccountId, error) {
+	const query = `
+		UPDATE login_challenges
+		SET attempts = attempts + 1
+		WHERE token_hash = $1
+		  AND expires_at > NOW()
+		  AND attempts < $2
+		RETURNING account_id
+	`
+
+	var accountID int
+	if err := a.q(ctx).QueryRow(ctx, query, tokenHash, MaxLoginChallengeAttempts).Scan(&accountID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, postgres.ErrNoRowFound
+		}
+		return 0, err
+	}
+	return id2.AccountId(accountID), nil
+}
+
+func (a *PostgresRepository) AttemptTwoFactor(ctx context.Context, accountId id2.AccountId) error {
+	const query = `
+		UPDATE accounts
+		SET two_factor_failures = CASE WHEN two_factor_failures + 1 >= $2 THEN 0 ELSE two_factor_failures + 1 END,
+		    two_factor_locked_until = CASE
+		        WHEN two_factor_failures + 1 >= $2 THEN NOW() + make_interval(secs => $3)
+		        ELSE two_factor_locked_until
+		    END
+		WHERE account_id = $1
+		  AND (two_factor_locked_until IS NULL OR two_factor_locked_until <= NOW())
+	`
+
+	cmd, err := a.q(ctx).Exec(ctx, query, accountId, MaxTwoFactorFailures, TwoFactorLockout.Seconds())
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (a *PostgresRepository) ResetTwoFactorAttempts(ctx context.Context, accountId id2.AccountId) error {
+	const query = `
+		UPDATE accounts
+		SET two_factor_failures = 0,
+		    two_factor_locked_until = NULL
+		WHERE account_id = $1
+	`
+
+	_, err := a.q(ctx).Exec(ctx, query, accountId)
+	return err
+}
+
+func (a *PostgresRepository) DeleteLoginChallenge(ctx context.Context, tokenHash string) error {
+	const query = `DELETE FROM login_challenges WHERE token_hash = $1`
+
+	cmd, err := a.q(ctx).Exec(ctx, query, tokenHash)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowFound
+	}
+	return nil
+}
+
+func (a *PostgresRepository) FindIdentity(ctx context.Context, provider string, subject string) (id2.AccountId, error) {
+	const query = `
+		SELECT account_id
+		FROM account_identities
+		WHERE provider = $1 AND subject = $2
+	`
+
+	var accountID int
+	if err := a.q(ctx).QueryRow(ctx, query, provider, subject).Scan(&accountID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, postgres.ErrNoRowFound
+		}
+		return 0, err
+	}
+	return id2.AccountId(accountID), nil
+}
+
+func (a *PostgresRepository) SaveIdentity(ctx context.Context, identity Identity) error {
+	const query = `
+		INSERT INTO account_identities (account_id, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := a.q(ctx).Exec(ctx, query,
+		identity.AccountId,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+	)
+	return err
+}
+
+func (a *PostgresRepository) SaveOIDCState(ctx context.Context, stateHash string, state OIDCState, expiresAt time.Time) error {
+	// the abandoned logins are cleaned up along the way
+	const sqlDelete = `DELETE FROM oidc_login_states WHERE expires_at <= NOW()`
+	const sqlInsert = `
+		INSERT INTO oidc_login_states (state_hash, provider, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	if _, err := a.q(ctx).Exec(ctx, sqlDelete); err != nil {
+		return err
+	}
+	_, err := a.q(ctx).Exec(ctx, sqlInsert, stateHash, state.Provider, state.Nonce, state.CodeVerifier, expiresAt)
+	return err
+}
+
+// ConsumeOIDCState uses the state in a single statement, so it can be used only once
+func (a *PostgresRepository) ConsumeOIDCState(ctx context.Context, stateHash string) (OIDCState, error) {
+	const query = `
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1
+		  AND expires_at > NOW()
+		RETURNING provider, nonce, code_verifier
+	`
+
+	var st OIDCState
+	if err := a.q(ctx).QueryRow(ctx, query, stateHash).Scan(&st.Provider, &st.Nonce, &st.CodeVerifier); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return OIDCState{}, postgres.ErrNoRowFound
+		}
+		return OIDCState{}, err
+	}
+	return st, nil
+}
+
+func (a *PostgresRepository) SaveAccessToken(ctx context.Context, token *AccessToken, tokenHash string) (*AccessToken, error) {
+	const query = `
+		INSERT INTO personal_access_tokens (account_id, name, token_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING token_id
+	`
+
+	var tokenID int
+	err := a.q(ctx).QueryRow(ctx, query,
+		token.AccountId,
+		token.Name,
+		tokenHash,
+		token.Scopes,
+		token.ExpiresAt,
+		token.CreatedAt,
+	).Scan(&tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := *token
+	saved.Id = id2.AccessTokenId(tokenID)
+	return &saved, nil
+}
+
+func (a *PostgresRepository) CountAccessTokens(ctx context.Context, accountId id2.AccountId) (int, error) {
+	const query = `SELECT COUNT(*) FROM personal_access_tokens WHERE account_id = $1`
+
+	var count int
+	err := a.q(ctx).QueryRow(ctx, query, accountId).Scan(&count)
+	return count, err
+}
+
+func (a *PostgresRepository) FindAccessTokens(ctx context.Context, accountId id2.AccountId) ([]AccessToken, error) {
+	const query = `
+		SELECT token_id, account_id, name, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE account_id = $1
+		ORDER BY created_at DESC, token_id DESC
+	`
+
+	rows, err := a.q(ctx).Query(ctx, query, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := make([]AccessToken, 0)
+	for rows.Next() {
+		t, err := scanAccessToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *t)
+	}
+	return tokens, rows.Err()
+}
+
+func (a *PostgresRepository) FindAccessTokenByHash(ctx context.Context, tokenHash string) (*AccessToken, error) {
+	const query = `
+		SELECT token_id, account_id, name, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1
+	`
+
+	t, err := scanAccessToken(a.q(ctx).QueryRow(ctx, query, tokenHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func (a *PostgresRepository) TouchAccessToken(ctx context.Context, tokenId id2.AccessTokenId) error {
+	const query = `
+		UPDATE personal_access_tokens
+		SET last_used_at = NOW()
+		WHERE token_id = $1
+		  AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	_, err := a.q(ctx).Exec(ctx, query, tokenId)
+	return err
+}
+
+func (a *PostgresRepository) DeleteAccessToken(ctx context.Context, tokenId id2.AccessTokenId, accountId id2.AccountId) error {
+	const query = `DELETE FROM personal_access_tokens WHERE token_id = $1 AND account_id = $2`
+
+	cmd, err := a.q(ctx).Exec(ctx, query, tokenId, accountId)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowFound
+	}
+	return nil
+}
+
+func scanAccessToken(row pgx.Row) (*AccessToken, error) {
+	var (
+		t         AccessToken
+		tokenID   int
+		accountID int
+	)
+	err := row.Scan(&tokenID, &accountID, &t.Name, &t.Scopes, &t.ExpiresAt, &t.LastUsedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.Id = id2.AccessTokenId(tokenID)
+	t.AccountId = id2.AccountId(accountID)
+	return &t, nil
+}
+
+func (a *PostgresRepository) SaveSession(ctx context.Context, session *Session) error {
+	// the expired sessions of the account are cleaned up along the way
+	const sqlDelete = `DELETE FROM account_sessions WHERE account_id = $1 AND expires_at <= NOW()`
+	const sqlInsert = `
+		INSERT INTO account_sessions (account_id, token_id, session_version, user_agent, ip, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING session_id
+	`
+
+	if _, err := a.q(ctx).Exec(ctx, sqlDelete, session.AccountId); err != nil {
+		return err
+	}
+
+	var sessionID int
+	err := a.q(ctx).QueryRow(ctx, sqlInsert,
+		session.AccountId,
+		session.TokenId,
+		session.SessionVersion,
+		session.UserAgent,
+		session.IP,
+		session.CreatedAt,
+		session.LastSeenAt,
+		session.ExpiresAt,
+	).Scan(&sessionID)
+	if err != nil {
+		return err
+	}
+	session.Id = id2.AccountSessionId(sessionID)
+	return nil
+}
+
+// TouchSession checks the session and updates its last use in a single statement, the update
+// is skipped when the session has been seen in the last minute
+func (a *PostgresRepository) TouchSession(ctx context.Context, accountId id2.AccountId, tokenId string, sessionVersion int) error {
+	const query = `
+		WITH active AS (
+			SELECT s.session_id, s.last_seen_at
+			FROM account_sessions s
+			JOIN accounts a ON a.account_id = s.account_id
+			WHERE s.token_id = $1
+			  AND s.account_id = $2
+			  AND s.expires_at > NOW()
+			  AND a.session_version = $3
+		), touched AS (
+			UPDATE account_sessions
+			SET last_seen_at = NOW()
+			WHERE session_id IN (
+				SELECT session_id FROM active WHERE last_seen_at < NOW() - INTERVAL '1 minute'
+			)
+		)
+		SELECT session_id FROM active
+	`
+
+	var sessionID int
+	if err := a.q(ctx).QueryRow(ctx, query, tokenId, accountId, sessionVersion).Scan(&sessionID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return postgres.ErrNoRowFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (a *PostgresRepository) FindSessions(ctx context.Context, accountId id2.AccountId) ([]Session, error) {
+	const query = `
+		SELECT s.session_id, s.account_id, s.token_id, s.session_version, s.user_agent, s.ip,
+		       s.created_at, s.last_seen_at, s.expires_at
+		FROM account_sessions s
+		JOIN accounts a ON a.account_id = s.account_id
+		WHERE s.account_id = $1
+		  AND s.expires_at > NOW()
+		  AND s.session_version = a.session_version
+		ORDER BY s.last_seen_at DESC, s.session_id DESC
+	`
+
+	rows, err := a.q(ctx).Query(ctx, query, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]Session, 0)
+	for rows.Next() {
+		var (
+			s         Session
+			sessionID int
+			accountID int
+		)
+		err := rows.Scan(&sessionID, &accountID, &s.TokenId, &s.SessionVersion, &s.UserAgent, &s.IP,
+			&s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		s.Id = id2.AccountSessionId(sessionID)
+		s.AccountId = id2.AccountId(accountID)
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+func (a *PostgresRepository) DeleteSession(ctx context.Context, sessionId id2.AccountSessionId, accountId id2.AccountId) error {
+	const query = `DELETE FROM account_sessions WHERE session_id = $1 AND account_id = $2`
+
+	cmd, err := a.q(ctx).Exec(ctx, query, sessionId, accountId)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowFound
+	}
+	return nil
+}
+
+func (a *PostgresRepository) DeleteExpiredSessions(ctx context.Context, now time.Time) (int, error) {
+	const query = `DELETE FROM account_sessions WHERE expires_at <= $1`
+
+	cmd, err := a.q(ctx).Exec(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+	return int(cmd.RowsAffected()), nil
+}
+
+func (a *PostgresRepository) SaveDeletion(ctx context.Context, deletion *Deletion) error {
+	const sqlDeletion = `
+		INSERT INTO account_deletions (account_id, requested_at, purge_after)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (account_id) DO UPDATE
+		SET requested_at = EXCLUDED.requested_at,
+		    purge_after = EXCLUDED.purge_after,
+		    purged_at = NULL
+	`
+	const sqlDeleteHandovers = `DELETE FROM account_deletion_handovers WHERE account_id = $1`
+	const sqlInsertHandover = `
+		INSERT INTO account_deletion_handovers (account_id, campaign_id, new_master_id)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := a.q(ctx).Exec(ctx, sqlDeletion, deletion.AccountId, deletion.RequestedAt, deletion.PurgeAfter); err != nil {
+		return err
+	}
+	if _, err := a.q(ctx).Exec(ctx, sqlDeleteHandovers, deletion.AccountId); err != nil {
+		return err
+	}
+	for _, h := range deletion.Handovers {
+		if _, err := a.q(ctx).Exec(ctx, sqlInsertHandover, deletion.AccountId, h.CampaignId, h.NewMaster); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *PostgresRepository) FindDeletion(ctx context.Context, accountId id2.AccountId) (*Deletion, error) {
+	const query = `
+		SELECT requested_at, purge_after
+		FROM account_deletions
+		WHERE account_id = $1 AND purged_at IS NULL
+	`
+
+	deletion := Deletion{AccountId: accountId}
+	if err := a.q(ctx).QueryRow(ctx, query, accountId).Scan(&deletion.RequestedAt, &deletion.PurgeAfter); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+
+	handovers, err := a.findHandovers(ctx, accountId)
+	if err != nil {
+		return nil, err
+	}
+	deletion.Handovers = handovers
+	return &deletion, nil
+}
+
+func (a *PostgresRepository) CancelDeletion(ctx context.Context, accountId id2.AccountId) error {
+	const query = `DELETE FROM account_deletions WHERE account_id = $1 AND purged_at IS NULL`
+
+	cmd, err := a.q(ctx).Exec(ctx, query, accountId)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowFound
+	}
+	return nil
+}
+
+func (a *PostgresRepository) FindDueDeletions(ctx context.Context, now time.Time, limit int) ([]Deletion, error) {
+	const query = `
+		SELECT account_id, requested_at, purge_after
+		FROM account_deletions
+		WHERE purged_at IS NULL AND purge_after <= $1
+		ORDER BY purge_after, account_id
+		LIMIT $2
+	`
+
+	rows, err := a.q(ctx).Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deletions := make([]Deletion, 0)
+	for rows.Next() {
+		var (
+			d         Deletion
+			accountID int
+		)
+		if err := rows.Scan(&accountID, &d.RequestedAt, &d.PurgeAfter); err != nil {
+			return nil, err
+		}
+		d.AccountId = id2.AccountId(accountID)
+		deletions = append(deletions, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range deletions {
+		handovers, err := a.findHandovers(ctx, deletions[i].AccountId)
+		if err != nil {
+			return nil, err
+		}
+		deletions[i].Handovers = handovers
+	}
+	return deletions, nil
+}
+
+func (a *PostgresRepository) findHandovers(ctx context.Context, accountId id2.AccountId) ([]Handover, error) {
+	const query = `
+		SELECT campaign_id, new_master_id
+		FROM account_deletion_handovers
+		WHERE account_id = $1
+		ORDER BY campaign_id
+	`
+
+	rows, err := a.q(ctx).Query(ctx, query, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	handovers := make([]Handover, 0)
+	for rows.Next() {
+		var campaignID, newMasterID int
+		if err := rows.Scan(&campaignID, &newMasterID); err != nil {
+			return nil, err
+		}
+		handovers = append(handovers, Handover{
+			CampaignId: id2.CampaignId(campaignID),
+			NewMaster:  id2.PlayerId(newMasterID),
+		})
+	}
+	return handovers, rows.Err()
+}
+
+// EraseAccount keeps the rows of the account and the player, the campaigns content references them
+func (a *PostgresRepository) EraseAccount(ctx context.Context, accountId id2.AccountId, playerId id2.PlayerId) error {
+	const sqlAccount = `
+		UPDATE accounts
+		SET username = $2,
+		    password = '',
+		    email = NULL,
+		    email_verified_at = NULL,
+		    session_version = session_version + 1,
+		    totp_secret = NULL,
+		    two_factor_enabled_at = NULL,
+		    totp_last_step = 0
+		WHERE account_id = $1
+	`
+	const sqlPlayer = `UPDATE players SET name = $2 WHERE player_id = $1`
+	const sqlPurged = `UPDATE account_deletions SET purged_at = NOW() WHERE account_id = $1`
+
+	// everything else kept about the account
+	accountTables := []string{
+		"password_reset_tokens",
+		"email_verification_tokens",
+		"account_recovery_codes",
+		"login_challenges",
+		"account_identities",
+		"personal_access_tokens",
+		"account_sessions",
+		"account_deletion_handovers",
+	}
+
+	cmd, err := a.q(ctx).Exec(ctx, sqlAccount, accountId, DeletedName(int(accountId)))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	if _, err := a.q(ctx).Exec(ctx, sqlPlayer, playerId, DeletedName(int(playerId))); err != nil {
+		return err
+	}
+	for _, table := range accountTables {
+		if _, err := a.q(ctx).Exec(ctx, "DELETE FROM "+table+" WHERE account_id = $1", accountId); err != nil {
+			return err
+		}
+	}
+	if _, err := a.q(ctx).Exec(ctx, `DELETE FROM calendar_tokens WHERE player_id = $1`, playerId); err != nil {
+		return err
+	}
+	_, err = a.q(ctx).Exec(ctx, sqlPurged, accountId)
+	return err
+}
+
+func (a *PostgresRepository) FindExportIdentities(ctx context.Context, accountId id2.AccountId) ([]ExportIdentity, error) {
+	const query = `
+		SELECT provider, subject, email, created_at
+		FROM account_identities
+		WHERE account_id = $1
+		ORDER BY identity_id
+	`
+
+	rows, err := a.q(ctx).Query(ctx, query, accountId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := make([]ExportIdentity, 0)
+	for rows.Next() {
+		var i ExportIdentity
+		if err := rows.Scan(&i.Provider, &i.Subject, &i.Email, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		identities = append(identities, i)
+	}
+	return identities, rows.Err()
+}
+
 // scanAccount translates DB row -> domain model.
 // Returns (nil, nil) when no row is found.
 func (a *PostgresRepository) scanAccount(row pgx.Row) (*Account, error) {
 	var (
-		id        int
-		username  string
-		password  string
-		em        *string
-		createdAt time.Time
+		id         int
+		username   string
+		password   string
+		em         *string
+		verifiedAt *time.Time
+		createdAt  time.Time
+		version    int
+		secret     *string
+		enabledAt  *time.Time
+		lastStep   int64
 	)
 
-	err := row.Scan(&id, &username, &password, &em, &createdAt)
+	err := row.Scan(&id, &username, &password, &em, &verifiedAt, &createdAt, &version, &secret, &enabledAt, &lastStep)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil
@@ -133,11 +983,28 @@ func (a *PostgresRepository) scanAccount(row pgx.Row) (*Account, error) {
 		accEmail, _ = NewEmail(*em)
 	}
 
+	twoFactor := TwoFactor{EnabledAt: enabledAt, LastStep: lastStep}
+	if secret != nil {
+		twoFactor.Secret = *secret
+	}
+
 	return &Account{
-		Id:        id2.AccountId(id),
-		Username:  username,
-		Password:  password,
-		Email:     &accEmail,
-		CreatedAt: createdAt,
+		Id:              id2.AccountId(id),
+		Username:        username,
+		Password:        password,
+		Email:           &accEmail,
+		EmailVerifiedAt: verifiedAt,
+		CreatedAt:       createdAt,
+		SessionVersion:  version,
+		TwoFactor:       twoFactor,
 	}, nil
 }
+
+// emailValue stores a missing email as NULL, so that the unique constraint ignores it
+func emailValue(email *Email) *string {
+	if email == nil || email.String() == "" {
+		return nil
+	}
+	value := email.String()
+	return &value
+}
@@ -3,6 +3,7 @@ package account
 import (
 	"beldur/internal/id"
 	"context"
+	"time"
 )
 
 type Finder interface {
@@ -18,3 +19,152 @@ type Updater interface {
 type Saver interface {
 	Save(ctx context.Context, account *Account) (*Account, error)
 }
+
+type EmailFinder interface {
+	FindByEmail(ctx context.Context, email Email) (*Account, error)
+}
+
+type PasswordUpdater interface {
+	// UpdatePassword saves the password and the session version of the account
+	UpdatePassword(ctx context.Context, account *Account) error
+}
+
+type EmailVerificationUpdater interface {
+	// UpdateEmailVerification saves when the email of the account has been verified
+	UpdateEmailVerification(ctx context.Context, account *Account) error
+}
+
+type SessionVersionFinder interface {
+	FindSessionVersion(ctx context.Context, accountId id.AccountId) (int, error)
+}
+
+// ResetTokenStore keeps the hashes of the password reset tokens
+type ResetTokenStore interface {
+	// SaveResetToken replaces the unused tokens of the account
+	SaveResetToken(ctx context.Context, accountId id.AccountId, tokenHash string, expiresAt time.Time) error
+	// ConsumeResetToken marks the token as used and gives back its account.
+	// It gives back postgres.ErrNoRowFound for an unknown, used or expired token.
+	ConsumeResetToken(ctx context.Context, tokenHash string) (id.AccountId, error)
+	// LastResetSentAt gives back nil when no token has been sent to the account
+	LastResetSentAt(ctx context.Context, accountId id.AccountId) (*time.Time, error)
+}
+
+// VerificationTokenStore keeps the hashes of the email verification tokens, each one
+// bound to the email it was sent to
+type VerificationTokenStore interface {
+	// SaveVerificationToken replaces the unused tokens of the account
+	SaveVerificationToken(ctx context.Context, accountId id.AccountId, email Email, tokenHash string, expiresAt time.Time) error
+	// LastVerificationSentAt gives back nil when no token has been sent to the account
+	LastVerificationSentAt(ctx context.Context, accountId id.AccountId) (*time.Time, error)
+	// ConsumeVerificationToken marks the token as used and gives back its account and email.
+	// It gives back postgres.ErrNoRowFound for an unknown, used or expired token.
+	ConsumeVerificationToken(ctx context.Context, tokenHash string) (id.AccountId, Email, error)
+}
+
+type TwoFactorUpdater interface {
+	// UpdateTwoFactor saves the second factor of the account, along with its last used step
+	UpdateTwoFactor(ctx context.Context, account *Account) error
+	// UseTOTPStep saves the step of an accepted code when it is later than the last used one.
+	// It gives back postgres.ErrNoRowUpdated when the step was already used, so that concurrent
+	// logins cannot both accept the same code.
+	UseTOTPStep(ctx context.Context, accountId id.AccountId, step int64) error
+}
+
+// RecoveryCodeStore keeps the hashes of the two-factor recovery codes
+type RecoveryCodeStore interface {
+	// ReplaceRecoveryCodes deletes the codes of the account and saves the new ones
+	ReplaceRecoveryCodes(ctx context.Context, accountId id.AccountId, codeHashes []string) error
+	// ConsumeRecoveryCode marks the code as used. It gives back postgres.ErrNoRowFound
+	// for an unknown or used code.
+	ConsumeRecoveryCode(ctx context.Context, accountId id.AccountId, codeHash string) error
+}
+
+// LoginChallengeStore keeps the hashes of the tokens of the logins waiting for the second factor
+type LoginChallengeStore interface {
+	SaveLoginChallenge(ctx context.Context, accountId id.AccountId, tokenHash string, expiresAt time.Time) error
+	// AttemptLoginChallenge counts a code given for the challenge and gives back its account.
+	// It gives back postgres.ErrNoRowFound for an unknown or expired challenge, or one given
+	// MaxLoginChallengeAttempts codes already.
+	AttemptLoginChallenge(ctx context.Context, tokenHash string) (id.AccountId, error)
+	// AttemptTwoFactor counts a code given at the login of the account, and locks the account
+	// for TwoFactorLockout once MaxTwoFactorFailures are counted. It gives back
+	// postgres.ErrNoRowUpdated while the account is locked.
+	AttemptTwoFactor(ctx context.Context, accountId id.AccountId) error
+	// ResetTwoFactorAttempts forgets the codes counted once a right one is given
+	ResetTwoFactorAttempts(ctx context.Context, accountId id.AccountId) error
+	// DeleteLoginChallenge gives back postgres.ErrNoRowFound if the challenge is already deleted,
+	// so that a challenge opens one session only
+	DeleteLoginChallenge(ctx context.Context, tokenHash string) error
+}
+
+// IdentityStore links the accounts to their identities at the OpenID Connect providers
+type IdentityStore interface {
+	// FindIdentity gives back the account of the identity. It gives back
+	// postgres.ErrNoRowFound when the identity is not linked.
+	FindIdentity(ctx context.Context, provider string, subject string) (id.AccountId, error)
+	SaveIdentity(ctx context.Context, identity Identity) error
+}
+
+// OIDCStateStore keeps the secrets of the OpenID Connect logins between the redirect to the
+// provider and the callback
+type OIDCStateStore interface {
+	SaveOIDCState(ctx context.Context, stateHash string, state OIDCState, expiresAt time.Time) error
+	// ConsumeOIDCState deletes the state and gives it back. It gives back
+	// postgres.ErrNoRowFound for an unknown, used or expired state.
+	ConsumeOIDCState(ctx context.Context, stateHash string) (OIDCState, error)
+}
+
+// AccessTokenStore keeps the personal access tokens, along with the hash of the token
+type AccessTokenStore interface {
+	SaveAccessToken(ctx context.Context, token *AccessToken, tokenHash string) (*AccessToken, error)
+	CountAccessTokens(ctx context.Context, accountId id.AccountId) (int, error)
+	FindAccessTokens(ctx context.Context, accountId id.AccountId) ([]AccessToken, error)
+	// FindAccessTokenByHash gives back postgres.ErrNoRowFound for an unknown token
+	FindAccessTokenByHash(ctx context.Context, tokenHash string) (*AccessToken, error)
+	// TouchAccessToken records the use of the token, at most once a minute
+	TouchAccessToken(ctx context.Context, tokenId id.AccessTokenId) error
+	// DeleteAccessToken gives back postgres.ErrNoRowFound when the account has no such token
+	DeleteAccessToken(ctx context.Context, tokenId id.AccessTokenId, accountId id.AccountId) error
+}
+
+// SessionStore keeps the sessions opened at login
+type SessionStore interface {
+	SaveSession(ctx context.Context, session *Session) error
+	// TouchSession records the use of the session, at most once a minute. It gives back
+	// postgres.ErrNoRowFound for a deleted or expired session, or when the session version
+	// of the account has changed.
+	TouchSession(ctx context.Context, accountId id.AccountId, tokenId string, sessionVersion int) error
+	// FindSessions gives back the active sessions of the account, last seen first
+	FindSessions(ctx context.Context, accountId id.AccountId) ([]Session, error)
+	// DeleteSession gives back postgres.ErrNoRowFound when the account has no such session
+	DeleteSession(ctx context.Context, sessionId id.AccountSessionId, accountId id.AccountId) error
+	// DeleteExpiredSessions deletes the sessions of every account expired at now, and gives
+	// back how many
+	DeleteExpiredSessions(ctx context.Context, now time.Time) (int, error)
+}
+
+// DeletionStore keeps the deletions of accounts waiting for the end of their grace period
+type DeletionStore interface {
+	// SaveDeletion replaces the pending deletion of the account, along with its handovers
+	SaveDeletion(ctx context.Context, deletion *Deletion) error
+	// FindDeletion gives back postgres.ErrNoRowFound when no deletion of the account is pending
+	FindDeletion(ctx context.Context, accountId id.AccountId) (*Deletion, error)
+	// CancelDeletion gives back postgres.ErrNoRowFound when no deletion of the account is pending
+	CancelDeletion(ctx context.Context, accountId id.AccountId) error
+	// FindDueDeletions gives back the pending deletions whose grace period is over, oldest first
+	FindDueDeletions(ctx context.Context, now time.Time, limit int) ([]Deletion, error)
+}
+
+// AccountEraser removes the personal data of the accounts at the end of their deletion
+type AccountEraser interface {
+	// EraseAccount renames the account and its player after their ids, so that the content
+	// they wrote in the campaigns stays but is anonymous, and deletes everything else kept
+	// about the account. The deletion of the account is marked as purged.
+	EraseAccount(ctx context.Context, accountId id.AccountId, playerId id.PlayerId) error
+}
+
+// ExportStore reads the identities of an account for its export, the content of its player is
+// read from the other domains through ExportSources
+type ExportStore interface {
+	FindExportIdentities(ctx context.Context, accountId id.AccountId) ([]ExportIdentity, error)
+}
@@ -0,0 +1,209 @@
+package account
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/auth"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/logger"
+	"context"
+	"errors"
+	"time"
+)
+
+// userAgentMaxCharacters is the length of the user agent kept for a session
+const userAgentMaxCharacters = 255
+
+// Session is a login of the user on a device, identified in the token by its jti
+type Session struct {
+	Id        id.AccountSessionId
+	AccountId id.AccountId
+	// TokenId is the jti of the token of the session
+	TokenId string
+	// SessionVersion of the account at login, the session ends when the version changes
+	SessionVersion int
+	UserAgent      string
+	IP             string
+	CreatedAt      time.Time
+	LastSeenAt     time.Time
+	ExpiresAt      time.Time
+}
+
+// Client is the device a session is opened from
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
+type clientKey struct{}
+
+// WithClient gives the device of the request to the use cases opening a session
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+func clientFromCtx(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	if len(client.UserAgent) > userAgentMaxCharacters {
+		client.UserAgent = client.UserAgent[:userAgentMaxCharacters]
+	}
+	return client
+}
+
+// SessionIssuer records a session for each token it issues, with the device of WithClient
+type SessionIssuer struct {
+	issuer   auth.TokenIssuer
+	sessions SessionStore
+	// duration is the expiration of the tokens
+	duration time.Duration
+}
+
+func NewSessionIssuer(issuer auth.TokenIssuer, sessions SessionStore, duration time.Duration) *SessionIssuer {
+	return &SessionIssuer{
+		issuer:   issuer,
+		sessions: sessions,
+		duration: duration,
+	}
+}
+
+func (i *SessionIssuer) Issue(ctx context.Context, claims auth.Claims) (string, error) {
+	tokenId, _ := auth.NewSecretToken()
+	client := clientFromCtx(ctx)
+	now := time.Now()
+
+	err := i.sessions.SaveSession(ctx, &Session{
+		AccountId:      claims.Subject,
+		TokenId:        tokenId,
+		SessionVersion: claims.SessionVersion,
+		UserAgent:      client.UserAgent,
+		IP:             client.IP,
+		CreatedAt:      now,
+		LastSeenAt:     now,
+		ExpiresAt:      now.Add(i.duration),
+	})
+	if err != nil {
+		logger.Debug("failed to save session", "account", claims.Subject, "err", err)
+		return "", ErrDatabaseError
+	}
+
+	claims.SessionID = tokenId
+	return i.issuer.Issue(ctx, claims)
+}
+
+// SessionVerifier rejects the tokens of the sessions signed out, and the tokens issued before
+// the last revocation of the sessions of their account, such as a password change
+type SessionVerifier struct {
+	verifier      auth.TokenVerifier
+	versionFinder SessionVersionFinder
+	sessions      SessionStore
+}
+
+func NewSessionVerifier(verifier auth.TokenVerifier, versionFinder SessionVersionFinder, sessions SessionStore) *SessionVerifier {
+	return &SessionVerifier{
+		verifier:      verifier,
+		versionFinder: versionFinder,
+		sessions:      sessions,
+	}
+}
+
+func (v *SessionVerifier) Verify(ctx context.Context, token string) (auth.Verified, error) {
+	verified, err := v.verifier.Verify(ctx, token)
+	if err != nil {
+		return auth.Verified{}, err
+	}
+
+	if verified.SessionID != "" {
+		err := v.sessions.TouchSession(ctx, verified.Subject, verified.SessionID, verified.SessionVersion)
+		if err != nil {
+			if !errors.Is(err, postgres.ErrNoRowFound) {
+				logger.Debug("failed to find session", "account", verified.Subject, "error", err)
+			}
+			return auth.Verified{}, ErrSessionRevoked
+		}
+		return verified, nil
+	}
+
+	// the tokens issued before the sessions were recorded, until they expire
+	version, err := v.versionFinder.FindSessionVersion(ctx, verified.Subject)
+	if err != nil {
+		logger.Debug("failed to find session version", "account", verified.Subject, "error", err)
+		return auth.Verified{}, ErrSessionRevoked
+	}
+	if version != verified.SessionVersion {
+		return auth.Verified{}, ErrSessionRevoked
+	}
+	return verified, nil
+}
+
+// SessionManagement is an USE CASE where the owner of an account sees where it is logged in,
+// and signs out the sessions of the other devices
+type SessionManagement struct {
+	sessions SessionStore
+}
+
+func NewSessionManagement(sessions SessionStore) *SessionManagement {
+	return &SessionManagement{sessions: sessions}
+}
+
+// List gives back the active sessions, the one of currentTokenId is marked as current
+func (uc *SessionManagement) List(ctx context.Context, accountId id.AccountId, currentTokenId string) ([]SessionResponse, error) {
+	sessions, err := uc.sessions.FindSessions(ctx, accountId)
+	if err != nil {
+		logger.Debug("failed to find sessions", "account", accountId, "err", err)
+		return nil, ErrDatabaseError
+	}
+
+	resp := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		resp[i] = SessionResponse{
+			Id:         int(s.Id),
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			Current:    currentTokenId != "" && s.TokenId == currentTokenId,
+		}
+	}
+	return resp, nil
+}
+
+// Revoke signs out the session, its token is rejected from now on
+func (uc *SessionManagement) Revoke(ctx context.Context, sessionId id.AccountSessionId, accountId id.AccountId) error {
+	if err := uc.sessions.DeleteSession(ctx, sessionId, accountId); err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return ErrSessionNotFound
+		}
+		logger.Debug("failed to delete session", "session", sessionId, "err", err)
+		return ErrDatabaseError
+	}
+	return nil
+}
+
+// SessionPurge deletes the expired sessions. They are already rejected, and the ones of an
+// account are cleaned up at its next login, this removes the ones of the accounts not coming back.
+type SessionPurge struct {
+	sessions SessionStore
+}
+
+func NewSessionPurge(sessions SessionStore) *SessionPurge {
+	return &SessionPurge{sessions: sessions}
+}
+
+// Run purges the expired sessions every interval, until ctx is done
+func (uc *SessionPurge) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if purged, err := uc.sessions.DeleteExpiredSessions(ctx, time.Now()); err != nil {
+			logger.Error("failed to purge expired sessions", err)
+		} else if purged > 0 {
+			logger.Info("purged expired sessions", "count", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
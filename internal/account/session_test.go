@@ -0,0 +1,126 @@
+package account
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/auth"
+	"beldur/pkg/db/postgres"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionIssuer(t *testing.T) {
+	issuer, sessions := new(MockTokenIssuer), new(MockSessionStore)
+	si := NewSessionIssuer(issuer, sessions, 24*time.Hour)
+	ctx := WithClient(context.Background(), Client{UserAgent: strings.Repeat("a", 300), IP: "203.0.113.7"})
+
+	var saved *Session
+	sessions.On("SaveSession", mock.Anything, mock.AnythingOfType("*account.Session")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(*Session) }).
+		Return(nil).Once()
+	issuer.On("Issue", mock.Anything, mock.MatchedBy(func(c auth.Claims) bool {
+		return c.Subject == 1 && c.SessionVersion == 2 && c.SessionID != "" && c.SessionID == saved.TokenId
+	})).Return("jwt-token", nil).Once()
+
+	token, err := si.Issue(ctx, auth.Claims{Subject: 1, PlayerID: 7, SessionVersion: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "jwt-token", token)
+
+	assert.Equal(t, id.AccountId(1), saved.AccountId)
+	assert.Equal(t, 2, saved.SessionVersion)
+	assert.Equal(t, "203.0.113.7", saved.IP)
+	assert.Len(t, saved.UserAgent, userAgentMaxCharacters)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), saved.ExpiresAt, time.Minute)
+	issuer.AssertExpectations(t)
+}
+
+func TestSessionVerifier_Sessions(t *testing.T) {
+	ctx := context.Background()
+	verifier, versions, sessions := new(MockTokenVerifier), new(MockSessionVersionFinder), new(MockSessionStore)
+	sv := NewSessionVerifier(verifier, versions, sessions)
+
+	verifier.On("Verify", mock.Anything, "active").Return(auth.Verified{Subject: 1, PlayerId: 7, SessionVersion: 4, SessionID: "jti-1"}, nil)
+	verifier.On("Verify", mock.Anything, "signed-out").Return(auth.Verified{Subject: 1, PlayerId: 7, SessionVersion: 4, SessionID: "jti-2"}, nil)
+	sessions.On("TouchSession", mock.Anything, id.AccountId(1), "jti-1", 4).Return(nil)
+	sessions.On("TouchSession", mock.Anything, id.AccountId(1), "jti-2", 4).Return(postgres.ErrNoRowFound)
+
+	verified, err := sv.Verify(ctx, "active")
+	require.NoError(t, err)
+	assert.Equal(t, "jti-1", verified.SessionID)
+
+	_, err = sv.Verify(ctx, "signed-out")
+	assert.ErrorIs(t, err, ErrSessionRevoked)
+	// the session store checks the version along with the session
+	versions.AssertNotCalled(t, "FindSessionVersion", mock.Anything, mock.Anything)
+}
+
+func TestSessionManagement(t *testing.T) {
+	ctx := context.Background()
+	sessions := new(MockSessionStore)
+	uc := NewSessionManagement(sessions)
+
+	sessions.On("FindSessions", mock.Anything, id.AccountId(1)).Return([]Session{
+		{Id: 2, TokenId: "jti-2", UserAgent: "Firefox"},
+		{Id: 1, TokenId: "jti-1", UserAgent: "curl"},
+	}, nil).Once()
+
+	resp, err := uc.List(ctx, 1, "jti-1")
+	require.NoError(t, err)
+	require.Len(t, resp, 2)
+	assert.False(t, resp[0].Current)
+	assert.True(t, resp[1].Current)
+	assert.Equal(t, "curl", resp[1].UserAgent)
+
+	sessions.On("DeleteSession", mock.Anything, id.AccountSessionId(2), id.AccountId(1)).Return(nil).Once()
+	sessions.On("DeleteSession", mock.Anything, id.AccountSessionId(2), id.AccountId(3)).Return(postgres.ErrNoRowFound).Once()
+
+	require.NoError(t, uc.Revoke(ctx, 2, 1))
+	// the session of another account
+	assert.ErrorIs(t, uc.Revoke(ctx, 2, 3), ErrSessionNotFound)
+}
+
+func TestSessionPurge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sessions := new(MockSessionStore)
+	uc := NewSessionPurge(sessions)
+
+	sessions.On("DeleteExpiredSessions", mock.Anything, mock.AnythingOfType("time.Time")).
+		Run(func(mock.Arguments) { cancel() }).
+		Return(3, nil).Once()
+
+	// the first purge runs right away, then Run stops with ctx
+	uc.Run(ctx, time.Hour)
+	sessions.AssertExpectations(t)
+}
+
+type MockSessionStore struct{ mock.Mock }
+
+func (m *MockSessionStore) SaveSession(ctx context.Context, session *Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionStore) TouchSession(ctx context.Context, accountId id.AccountId, tokenId string, sessionVersion int) error {
+	args := m.Called(ctx, accountId, tokenId, sessionVersion)
+	return args.Error(0)
+}
+
+func (m *MockSessionStore) FindSessions(ctx context.Context, accountId id.AccountId) ([]Session, error) {
+	args := m.Called(ctx, accountId)
+	return args.Get(0).([]Session), args.Error(1)
+}
+
+func (m *MockSessionStore) DeleteSession(ctx context.Context, sessionId id.AccountSessionId, accountId id.AccountId) error {
+	args := m.Called(ctx, sessionId, accountId)
+	return args.Error(0)
+}
+
+func (m *MockSessionStore) DeleteExpiredSessions(ctx context.Context, now time.Time) (int, error) {
+	args := m.Called(ctx, now)
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,112 @@
+package account
+
+import (
+	"beldur/pkg/auth/totp"
+	"crypto/rand"
+	"strings"
+	"time"
+)
+
+const (
+	// RecoveryCodesCount is how many single use codes replace the authenticator app when lost
+	RecoveryCodesCount = 10
+	// totpSkew accepts the codes of the step before and after the current one
+	totpSkew = 1
+)
+
+// TwoFactor is the TOTP second factor of an account. The secret is set at the enrollment,
+// the second factor is required at login only once the owner has confirmed it with a code.
+type TwoFactor struct {
+	// Secret is base32 encoded, empty if not enrolled
+	Secret string
+	// EnabledAt is nil until the enrollment is confirmed
+	EnabledAt *time.Time
+	// LastStep is the time step of the last code accepted, a code cannot be used twice
+	LastStep int64
+}
+
+func (a *Account) IsTwoFactorEnabled() bool {
+	return a.TwoFactor.Secret != "" && a.TwoFactor.EnabledAt != nil
+}
+
+// EnrollTwoFactor sets a new secret, replacing the one of a previous unconfirmed enrollment
+func (a *Account) EnrollTwoFactor(secret string) error {
+	if a.IsTwoFactorEnabled() {
+		return ErrTwoFactorAlreadyEnabled
+	}
+	a.TwoFactor = TwoFactor{Secret: secret}
+	return nil
+}
+
+// ConfirmTwoFactor enables the second factor once the owner shows a code of the new secret
+func (a *Account) ConfirmTwoFactor(code string, at time.Time) error {
+	if a.IsTwoFactorEnabled() {
+		return ErrTwoFactorAlreadyEnabled
+	}
+	if a.TwoFactor.Secret == "" {
+		return ErrTwoFactorNotEnrolled
+	}
+	if !a.CheckTOTP(code, at) {
+		return ErrInvalidTwoFactorCode
+	}
+	a.TwoFactor.EnabledAt = &at
+	return nil
+}
+
+func (a *Account) DisableTwoFactor() error {
+	if !a.IsTwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+	a.TwoFactor = TwoFactor{}
+	return nil
+}
+
+// CheckTOTP tells if the code is valid at the time, and remembers its step so that it
+// cannot be used again
+func (a *Account) CheckTOTP(code string, at time.Time) bool {
+	step, ok := totp.Validate(a.TwoFactor.Secret, strings.TrimSpace(code), at, totpSkew)
+	if !ok || step <= a.TwoFactor.LastStep {
+		return false
+	}
+	a.TwoFactor.LastStep = step
+	return true
+}
+
+// NewRecoveryCodes generates the single use codes, formatted as xxxxx-xxxxx
+func NewRecoveryCodes() []string {
+	// no 0, 1, l, o to avoid mistakes when typed
+	const alphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+	codes := make([]string, RecoveryCodesCount)
+	for i := range codes {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			panic(err)
+		}
+		for j := range b {
+			b[j] = alphabet[int(b[j])%len(alphabet)]
+		}
+		codes[i] = string(b[:5]) + "-" + string(b[5:])
+	}
+	return codes
+}
+
+// NormalizeRecoveryCode makes a typed code comparable to the generated ones
+func NormalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.Join(strings.Fields(code), ""))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+// isTOTPCode tells apart the codes of the authenticator app from the recovery codes
+func isTOTPCode(code string) bool {
+	code = strings.TrimSpace(code)
+	if len(code) != totp.Digits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
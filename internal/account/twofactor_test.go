@@ -0,0 +1,320 @@
+package account
+
+import (
+	"beldur/internal/id"
+	"beldur/internal/player"
+	"beldur/pkg/auth"
+	"beldur/pkg/auth/totp"
+	"beldur/pkg/db/postgres"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccount_TwoFactor(t *testing.T) {
+	acc, err := New("username123", "hash")
+	require.NoError(t, err)
+	secret := totp.NewSecret()
+	now := time.Now()
+
+	assert.ErrorIs(t, acc.ConfirmTwoFactor("123456", now), ErrTwoFactorNotEnrolled)
+	require.NoError(t, acc.EnrollTwoFactor(secret))
+	assert.False(t, acc.IsTwoFactorEnabled())
+
+	assert.ErrorIs(t, acc.ConfirmTwoFactor("000000", now.Add(-time.Hour)), ErrInvalidTwoFactorCode)
+
+	code, err := totp.Code(secret, now)
+	require.NoError(t, err)
+	require.NoError(t, acc.ConfirmTwoFactor(code, now))
+	assert.True(t, acc.IsTwoFactorEnabled())
+	assert.ErrorIs(t, acc.EnrollTwoFactor(totp.NewSecret()), ErrTwoFactorAlreadyEnabled)
+
+	// a code cannot be used twice
+	assert.False(t, acc.CheckTOTP(code, now))
+
+	require.NoError(t, acc.DisableTwoFactor())
+	assert.False(t, acc.IsTwoFactorEnabled())
+	assert.ErrorIs(t, acc.DisableTwoFactor(), ErrTwoFactorNotEnabled)
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes := NewRecoveryCodes()
+	require.Len(t, codes, RecoveryCodesCount)
+
+	format := regexp.MustCompile(`^[a-z2-9]{5}-[a-z2-9]{5}$`)
+	seen := make(map[string]bool)
+	for _, c := range codes {
+		assert.Regexp(t, format, c)
+		assert.False(t, seen[c])
+		seen[c] = true
+	}
+	assert.Equal(t, "abcde23456", NormalizeRecoveryCode(" ABCDE-23456 "))
+}
+
+type loginMocks struct {
+	accFinder     *MockFinder
+	accUpdater    *MockUpdater
+	playerFinder  *MockPlayerFinder
+	tokenIssuer   *MockTokenIssuer
+	challenges    *MockLoginChallengeStore
+	twoFactor     *MockTwoFactorUpdater
+	recoveryCodes *MockRecoveryCodeStore
+}
+
+func newLogin() (*UsernamePasswordLogin, loginMocks) {
+	m := loginMocks{
+		accFinder:     new(MockFinder),
+		accUpdater:    new(MockUpdater),
+		playerFinder:  new(MockPlayerFinder),
+		tokenIssuer:   new(MockTokenIssuer),
+		challenges:    new(MockLoginChallengeStore),
+		twoFactor:     new(MockTwoFactorUpdater),
+		recoveryCodes: new(MockRecoveryCodeStore),
+	}
+	uc := NewUsernamePasswordLogin(m.accFinder, m.accUpdater, m.playerFinder, m.tokenIssuer, m.challenges, m.twoFactor, m.recoveryCodes)
+	return uc, m
+}
+
+func twoFactorAccount(t *testing.T) (*Account, string) {
+	hash, err := HashPassword("password123")
+	require.NoError(t, err)
+	secret := totp.NewSecret()
+	enabledAt := time.Now()
+	return &Account{
+		Id:        1,
+		Username:  "username123",
+		Password:  hash,
+		TwoFactor: TwoFactor{Secret: secret, EnabledAt: &enabledAt},
+	}, secret
+}
+
+func TestLogin_TwoFactor(t *testing.T) {
+	ctx := context.Background()
+	acc, secret := twoFactorAccount(t)
+
+	t.Run("password gives a challenge instead of the token", func(t *testing.T) {
+		uc, m := newLogin()
+		m.accFinder.On("FindByUsername", mock.Anything, "username123").Return(acc, nil).Once()
+
+		var storedHash string
+		m.challenges.On("SaveLoginChallenge", mock.Anything, id.AccountId(1), mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+			Run(func(args mock.Arguments) { storedHash = args.String(2) }).
+			Return(nil).Once()
+
+		token, challenge, err := uc.Login(ctx, UsernamePasswordLoginRequest{Username: "username123", Password: "password123"})
+		require.NoError(t, err)
+		assert.Empty(t, token)
+		require.NotNil(t, challenge)
+		assert.True(t, challenge.TwoFactorRequired)
+		assert.Equal(t, auth.HashSecretToken(challenge.ChallengeToken), storedHash)
+		m.tokenIssuer.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("code opens the session", func(t *testing.T) {
+		uc, m := newLogin()
+		hash := auth.HashSecretToken("challenge")
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+
+		m.challenges.On("AttemptLoginChallenge", mock.Anything, hash).Return(id.AccountId(1), nil).Once()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).Return(acc, nil).Once()
+		m.challenges.On("AttemptTwoFactor", mock.Anything, id.AccountId(1)).Return(nil).Once()
+		m.challenges.On("ResetTwoFactorAttempts", mock.Anything, id.AccountId(1)).Return(nil).Once()
+		m.twoFactor.On("UseTOTPStep", mock.Anything, id.AccountId(1), mock.MatchedBy(func(step int64) bool {
+			return step == totp.Step(time.Now()) || step == totp.Step(time.Now())-1
+		})).Return(nil).Once()
+		m.challenges.On("DeleteLoginChallenge", mock.Anything, hash).Return(nil).Once()
+		m.playerFinder.On("FindByAccountId", mock.Anything, id.AccountId(1)).Return(&player.Player{Id: 7}, nil).Once()
+		m.accUpdater.On("UpdateLastAccess", mock.Anything, id.AccountId(1)).Return(nil).Once()
+		m.tokenIssuer.On("Issue", mock.Anything, auth.Claims{Subject: 1, PlayerID: 7}).Return("jwt-token", nil).Once()
+
+		token, err := uc.LoginWithCode(ctx, TwoFactorLoginRequest{ChallengeToken: "challenge", Code: code})
+		require.NoError(t, err)
+		assert.Equal(t, "jwt-token", token)
+		m.challenges.AssertExpectations(t)
+	})
+
+	t.Run("recovery code opens the session", func(t *testing.T) {
+		uc, m := newLogin()
+		m.challenges.On("AttemptLoginChallenge", mock.Anything, mock.Anything).Return(id.AccountId(1), nil).Once()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).Return(acc, nil).Once()
+		m.challenges.On("AttemptTwoFactor", mock.Anything, id.AccountId(1)).Return(nil).Once()
+		m.challenges.On("ResetTwoFactorAttempts", mock.Anything, id.AccountId(1)).Return(nil).Once()
+		m.recoveryCodes.On("ConsumeRecoveryCode", mock.Anything, id.AccountId(1), auth.HashSecretToken("abcde23456")).Return(nil).Once()
+		m.challenges.On("DeleteLoginChallenge", mock.Anything, mock.Anything).Return(nil).Once()
+		m.playerFinder.On("FindByAccountId", mock.Anything, id.AccountId(1)).Return(&player.Player{Id: 7}, nil).Once()
+		m.accUpdater.On("UpdateLastAccess", mock.Anything, id.AccountId(1)).Return(nil).Once()
+		m.tokenIssuer.On("Issue", mock.Anything, mock.Anything).Return("jwt-token", nil).Once()
+
+		token, err := uc.LoginWithCode(ctx, TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "ABCDE-23456"})
+		require.NoError(t, err)
+		assert.Equal(t, "jwt-token", token)
+		m.recoveryCodes.AssertExpectations(t)
+	})
+
+	t.Run("wrong code counts an attempt", func(t *testing.T) {
+		uc, m := newLogin()
+		m.challenges.On("AttemptLoginChallenge", mock.Anything, auth.HashSecretToken("challenge")).Return(id.AccountId(1), nil).Once()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).Return(acc, nil).Once()
+		m.challenges.On("AttemptTwoFactor", mock.Anything, id.AccountId(1)).Return(nil).Once()
+		m.recoveryCodes.On("ConsumeRecoveryCode", mock.Anything, id.AccountId(1), mock.Anything).Return(postgres.ErrNoRowFound).Once()
+
+		_, err := uc.LoginWithCode(ctx, TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "wrong-code"})
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+		m.challenges.AssertExpectations(t)
+		m.challenges.AssertNotCalled(t, "ResetTwoFactorAttempts", mock.Anything, mock.Anything)
+		m.tokenIssuer.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("locked account refuses codes", func(t *testing.T) {
+		uc, m := newLogin()
+		m.challenges.On("AttemptLoginChallenge", mock.Anything, mock.Anything).Return(id.AccountId(1), nil).Once()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).Return(acc, nil).Once()
+		m.challenges.On("AttemptTwoFactor", mock.Anything, id.AccountId(1)).Return(postgres.ErrNoRowUpdated).Once()
+
+		_, err := uc.LoginWithCode(ctx, TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "ABCDE-23456"})
+		assert.ErrorIs(t, err, ErrTwoFactorLocked)
+		m.recoveryCodes.AssertNotCalled(t, "ConsumeRecoveryCode", mock.Anything, mock.Anything, mock.Anything)
+		m.tokenIssuer.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("code used meanwhile by another login", func(t *testing.T) {
+		uc, m := newLogin()
+		acc, secret := twoFactorAccount(t)
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+
+		m.challenges.On("AttemptLoginChallenge", mock.Anything, mock.Anything).Return(id.AccountId(1), nil).Once()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).Return(acc, nil).Once()
+		m.challenges.On("AttemptTwoFactor", mock.Anything, id.AccountId(1)).Return(nil).Once()
+		m.twoFactor.On("UseTOTPStep", mock.Anything, id.AccountId(1), mock.Anything).Return(postgres.ErrNoRowUpdated).Once()
+
+		_, err = uc.LoginWithCode(ctx, TwoFactorLoginRequest{ChallengeToken: "challenge", Code: code})
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+		m.challenges.AssertNotCalled(t, "DeleteLoginChallenge", mock.Anything, mock.Anything)
+		m.tokenIssuer.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired challenge", func(t *testing.T) {
+		uc, m := newLogin()
+		m.challenges.On("AttemptLoginChallenge", mock.Anything, mock.Anything).Return(id.AccountId(0), postgres.ErrNoRowFound).Once()
+
+		_, err := uc.LoginWithCode(ctx, TwoFactorLoginRequest{ChallengeToken: "challenge", Code: "123456"})
+		assert.ErrorIs(t, err, ErrInvalidLoginChallenge)
+	})
+}
+
+func TestTwoFactorManagement(t *testing.T) {
+	ctx := context.Background()
+	hash, err := HashPassword("password123")
+	require.NoError(t, err)
+
+	t.Run("enroll then confirm", func(t *testing.T) {
+		finder, updater, codes := new(MockFinder), new(MockTwoFactorUpdater), new(MockRecoveryCodeStore)
+		uc := NewTwoFactorManagement(finder, updater, codes, FnTransactor{})
+		acc := &Account{Id: 1, Username: "username123", Password: hash}
+		finder.On("FindById", mock.Anything, id.AccountId(1)).Return(acc, nil)
+		updater.On("UpdateTwoFactor", mock.Anything, acc).Return(nil)
+
+		enrolled, err := uc.Enroll(ctx, EnrollTwoFactorRequest{Password: "password123"}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, acc.TwoFactor.Secret, enrolled.Secret)
+		assert.Contains(t, enrolled.URI, "otpauth://totp/Beldur:username123?")
+		assert.False(t, acc.IsTwoFactorEnabled())
+
+		var storedHashes []string
+		codes.On("ReplaceRecoveryCodes", mock.Anything, id.AccountId(1), mock.Anything).
+			Run(func(args mock.Arguments) { storedHashes = args.Get(2).([]string) }).
+			Return(nil).Once()
+
+		code, err := totp.Code(enrolled.Secret, time.Now())
+		require.NoError(t, err)
+		confirmed, err := uc.Confirm(ctx, ConfirmTwoFactorRequest{Code: code}, 1)
+		require.NoError(t, err)
+		assert.True(t, acc.IsTwoFactorEnabled())
+		require.Len(t, confirmed.RecoveryCodes, RecoveryCodesCount)
+		require.Len(t, storedHashes, RecoveryCodesCount)
+		assert.Equal(t, auth.HashSecretToken(NormalizeRecoveryCode(confirmed.RecoveryCodes[0])), storedHashes[0])
+	})
+
+	t.Run("enroll needs the password", func(t *testing.T) {
+		finder, updater, codes := new(MockFinder), new(MockTwoFactorUpdater), new(MockRecoveryCodeStore)
+		uc := NewTwoFactorManagement(finder, updater, codes, FnTransactor{})
+		finder.On("FindById", mock.Anything, id.AccountId(1)).Return(&Account{Id: 1, Password: hash}, nil).Once()
+
+		_, err := uc.Enroll(ctx, EnrollTwoFactorRequest{Password: "password124"}, 1)
+		assert.ErrorIs(t, err, ErrWrongPassword)
+		updater.AssertNotCalled(t, "UpdateTwoFactor", mock.Anything, mock.Anything)
+	})
+
+	t.Run("disable deletes the recovery codes", func(t *testing.T) {
+		finder, updater, codes := new(MockFinder), new(MockTwoFactorUpdater), new(MockRecoveryCodeStore)
+		uc := NewTwoFactorManagement(finder, updater, codes, FnTransactor{})
+		acc, secret := twoFactorAccount(t)
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+
+		finder.On("FindById", mock.Anything, id.AccountId(1)).Return(acc, nil).Once()
+		updater.On("UseTOTPStep", mock.Anything, id.AccountId(1), mock.Anything).Return(nil).Once()
+		updater.On("UpdateTwoFactor", mock.Anything, acc).Return(nil)
+		codes.On("ReplaceRecoveryCodes", mock.Anything, id.AccountId(1), []string(nil)).Return(nil).Once()
+
+		require.NoError(t, uc.Disable(ctx, DisableTwoFactorRequest{Password: "password123", Code: code}, 1))
+		assert.False(t, acc.IsTwoFactorEnabled())
+		codes.AssertExpectations(t)
+	})
+}
+
+type MockLoginChallengeStore struct{ mock.Mock }
+type MockTwoFactorUpdater struct{ mock.Mock }
+type MockRecoveryCodeStore struct{ mock.Mock }
+
+func (m *MockLoginChallengeStore) SaveLoginChallenge(ctx context.Context, accountId id.AccountId, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(ctx, accountId, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockLoginChallengeStore) AttemptLoginChallenge(ctx context.Context, tokenHash string) (id.AccountId, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(id.AccountId), args.Error(1)
+}
+
+func (m *MockLoginChallengeStore) AttemptTwoFactor(ctx context.Context, accountId id.AccountId) error {
+	args := m.Called(ctx, accountId)
+	return args.Error(0)
+}
+
+func (m *MockLoginChallengeStore) ResetTwoFactorAttempts(ctx context.Context, accountId id.AccountId) error {
+	args := m.Called(ctx, accountId)
+	return args.Error(0)
+}
+
+func (m *MockLoginChallengeStore) DeleteLoginChallenge(ctx context.Context, tokenHash string) error {
+	args := m.Called(ctx, tokenHash)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorUpdater) UpdateTwoFactor(ctx context.Context, account *Account) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorUpdater) UseTOTPStep(ctx context.Context, accountId id.AccountId, step int64) error {
+	args := m.Called(ctx, accountId, step)
+	return args.Error(0)
+}
+
+func (m *MockRecoveryCodeStore) ReplaceRecoveryCodes(ctx context.Context, accountId id.AccountId, codeHashes []string) error {
+	args := m.Called(ctx, accountId, codeHashes)
+	return args.Error(0)
+}
+
+func (m *MockRecoveryCodeStore) ConsumeRecoveryCode(ctx context.Context, accountId id.AccountId, codeHash string) error {
+	args := m.Called(ctx, accountId, codeHash)
+	return args.Error(0)
+}
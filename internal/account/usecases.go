@@ -4,65 +4,116 @@ import (
 	"beldur/internal/id"
 	"beldur/internal/player"
 	"beldur/pkg/auth"
+	"beldur/pkg/auth/totp"
 	"beldur/pkg/db/postgres"
 	"beldur/pkg/db/tx"
 	"beldur/pkg/logger"
+	"beldur/pkg/mail"
 	"context"
 	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// ResetTokenDuration is how long a password reset link can be used
+	ResetTokenDuration = time.Hour
+	// ResetRequestInterval is the least time between two password reset emails of an account
+	ResetRequestInterval = time.Minute
+	// VerificationTokenDuration is how long an email verification link can be used
+	VerificationTokenDuration = 24 * time.Hour
+	// VerificationResendInterval is the least time between two verification emails of an account
+	VerificationResendInterval = time.Minute
+	// LoginChallengeDuration is how long the second factor can be given after the password
+	LoginChallengeDuration = 5 * time.Minute
+	// MaxLoginChallengeAttempts is how many codes a login challenge tolerates
+	MaxLoginChallengeAttempts = 5
+	// MaxTwoFactorFailures is how many codes an account tolerates at login, across its
+	// challenges, before being locked
+	MaxTwoFactorFailures = 10
+	// TwoFactorLockout is how long the login of an account refuses codes once locked
+	TwoFactorLockout = 15 * time.Minute
+	// TwoFactorIssuer names the accounts in the authenticator apps
+	TwoFactorIssuer = "Beldur"
 )
 
 type UniquePlayerCreator interface {
 	CreateUniquePlayer(ctx context.Context, pl *player.Player, accId id.AccountId) (*player.Player, error)
 }
 
+// VerificationSender sends the link verifying the email of the account
+type VerificationSender interface {
+	SendVerification(ctx context.Context, acc *Account) error
+}
+
 // Registration is an USE CASE where an account is created along with a player of that account
 type Registration struct {
 	accSaver        Saver
 	uniquePlayerSvc UniquePlayerCreator
 	tx              tx.Transactor
 	tokenIssuer     auth.TokenIssuer
+	verification    VerificationSender
 }
 
-// UsernamePasswordLogin is a login USE CASE
+// UsernamePasswordLogin is a login USE CASE. The accounts with two-factor authentication
+// log in in two steps: the password gives a login challenge, the code opens the session.
 type UsernamePasswordLogin struct {
 	accFinder    Finder
-	accUpdater   Updater
-	playerFinder player.Finder
-	tokenIssuer  auth.TokenIssuer
+	challenges   LoginChallengeStore
+	secondFactor secondFactor
+	sessions     sessionStarter
 }
 
 type Management struct {
-	accFinder  Finder
-	accUpdater Updater
+	accFinder    Finder
+	accUpdater   Updater
+	verification VerificationSender
 }
 
-func NewAccountManagement(accFinder Finder, accUpdater Updater) *Management {
+func NewAccountManagement(accFinder Finder, accUpdater Updater, verification VerificationSender) *Management {
 	return &Management{
-		accFinder:  accFinder,
-		accUpdater: accUpdater,
+		accFinder:    accFinder,
+		accUpdater:   accUpdater,
+		verification: verification,
 	}
 }
 
-// UpdateAccount updates the account of accountId
+// UpdateAccount updates the account of accountId. A changed email is unverified until the
+// link sent to it is used.
 func (uc *Management) UpdateAccount(ctx context.Context, req UpdateAccountRequest, accountId id.AccountId) (UpdateAccountResponse, error) {
 	acc, err := uc.accFinder.FindById(ctx, accountId)
 	if err != nil {
 		logger.Debug("could not find account by id", "err", err)
 		return UpdateAccountResponse{}, err
 	}
+	if acc == nil {
+		return UpdateAccountResponse{}, ErrAccountDoesNotExist
+	}
 	em, err := NewEmail(req.Email)
 	if err != nil {
 		return UpdateAccountResponse{}, err
 	}
 
+	changed := !acc.HasEmail() || acc.Email.String() != em.String()
 	acc.UpdateEmail(em)
 
 	if err := uc.accUpdater.Update(ctx, acc); err != nil {
+		if errors.Is(err, postgres.ErrUniqueValueViolation) {
+			return UpdateAccountResponse{}, ErrEmailAlreadyTaken
+		}
 		return UpdateAccountResponse{}, err
 	}
 
+	if changed {
+		if err := uc.verification.SendVerification(ctx, acc); err != nil {
+			// the email is saved anyway, the link can be sent again
+			logger.Error("failed to send verification email", err, "account", acc.Id)
+		}
+	}
+
 	return UpdateAccountResponse{
-		Email: acc.Email.String(),
+		Email:         acc.Email.String(),
+		EmailVerified: acc.IsEmailVerified(),
 	}, nil
 
 }
@@ -70,21 +121,36 @@ func (uc *Management) UpdateAccount(ctx context.Context, req UpdateAccountReques
 func NewAccountRegistration(tx tx.Transactor, accSaver Saver,
 	uniquePlayerSvc UniquePlayerCreator,
 	tokenIssuer auth.TokenIssuer,
+	verification VerificationSender,
 ) *Registration {
 	return &Registration{
 		accSaver:        accSaver,
 		uniquePlayerSvc: uniquePlayerSvc,
 		tx:              tx,
 		tokenIssuer:     tokenIssuer,
+		verification:    verification,
 	}
 }
 
-func NewUsernamePasswordLogin(accFinder Finder, accUpdater Updater, playerFinder player.Finder, tokenIssuer auth.TokenIssuer) *UsernamePasswordLogin {
+func NewUsernamePasswordLogin(
+	accFinder Finder,
+	accUpdater Updater,
+	playerFinder player.Finder,
+	tokenIssuer auth.TokenIssuer,
+	challenges LoginChallengeStore,
+	twoFactorUpdater TwoFactorUpdater,
+	recoveryCodes RecoveryCodeStore,
+) *UsernamePasswordLogin {
 	return &UsernamePasswordLogin{
 		accFinder:    accFinder,
-		accUpdater:   accUpdater,
-		playerFinder: playerFinder,
-		tokenIssuer:  tokenIssuer,
+		challenges:   challenges,
+		secondFactor: secondFactor{updater: twoFactorUpdater, recoveryCodes: recoveryCodes},
+		sessions: sessionStarter{
+			accUpdater:   accUpdater,
+			playerFinder: playerFinder,
+			tokenIssuer:  tokenIssuer,
+			challenges:   challenges,
+		},
 	}
 }
 
@@ -127,14 +193,22 @@ func (a *Registration) RegisterAccount(ctx context.Context, request CreateAccoun
 	}
 
 	token, err := a.tokenIssuer.Issue(ctx, auth.Claims{
-		Subject:  newAcc.Id,
-		PlayerID: newPl.Id,
+		Subject:        newAcc.Id,
+		PlayerID:       newPl.Id,
+		SessionVersion: newAcc.SessionVersion,
 	})
 	if err != nil {
 		logger.Debug("failed to issue token", "err", err)
 		return CreateAccountResponse{}, "", err
 	}
 
+	if newAcc.HasEmail() {
+		if err := a.verification.SendVerification(ctx, newAcc); err != nil {
+			// the account is created anyway, the link can be sent again
+			logger.Error("failed to send verification email", err, "account", newAcc.Id)
+		}
+	}
+
 	var emailVal *string
 	if newAcc.Email != nil {
 		emailStr := newAcc.Email.String()
@@ -142,10 +216,11 @@ func (a *Registration) RegisterAccount(ctx context.Context, request CreateAccoun
 	}
 
 	return CreateAccountResponse{
-		AccountID:   int(newAcc.Id),
-		AccountName: newAcc.Username,
-		Email:       emailVal,
-		CreatedAt:   newAcc.CreatedAt,
+		AccountID:     int(newAcc.Id),
+		AccountName:   newAcc.Username,
+		Email:         emailVal,
+		EmailVerified: newAcc.IsEmailVerified(),
+		CreatedAt:     newAcc.CreatedAt,
 		Player: PlayerCreateResponse{
 			PlayerID: int(newPl.Id),
 			Name:     newPl.Name,
@@ -153,6 +228,49 @@ func (a *Registration) RegisterAccount(ctx context.Context, request CreateAccoun
 	}, token, nil
 }
 
+// RegisterExternalAccount creates an account without password, along with its player, for a
+// login delegated to an identity provider. link is called in the same transaction with the
+// saved account, to bind it to the external identity.
+func (a *Registration) RegisterExternalAccount(
+	ctx context.Context,
+	newAcc *Account,
+	link func(txCtx context.Context, acc *Account) error,
+) (*Account, error) {
+	newPl, err := a.buildPlayer(newAcc.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.tx.WithTransaction(ctx, func(txCtx context.Context) error {
+		savedAcc, err := a.accSaver.Save(txCtx, newAcc)
+		if err != nil {
+			if errors.Is(err, postgres.ErrUniqueValueViolation) {
+				logger.Debug("account unique constraint violation", "username", newAcc.Username)
+				return ErrAccountNameAlreadyTaken
+			}
+			logger.Debug("failed to save new account", "err", err)
+			return errors.Join(ErrDatabaseError, err)
+		}
+		newAcc = savedAcc
+
+		if _, err := a.uniquePlayerSvc.CreateUniquePlayer(txCtx, newPl, newAcc.Id); err != nil {
+			return err
+		}
+		return link(txCtx, newAcc)
+	})
+	if err != nil {
+		logger.Debug("failed to register external account", "err", err)
+		return nil, err
+	}
+
+	if newAcc.HasEmail() && !newAcc.IsEmailVerified() {
+		if err := a.verification.SendVerification(ctx, newAcc); err != nil {
+			logger.Error("failed to send verification email", err, "account", newAcc.Id)
+		}
+	}
+	return newAcc, nil
+}
+
 func (a *Registration) buildNewAccountFromRequest(req CreateAccountRequest) (*Account, error) {
 	hashedPass, err := HashPassword(req.Password)
 	if err != nil {
@@ -191,37 +309,219 @@ func (a *Registration) buildPlayer(accountName string) (*player.Player, error) {
 	return pl, nil
 }
 
-// Login returns nil and a new JwtService authentication token if login is successful.
+// Login returns a new JwtService authentication token if login is successful. When the account
+// has two-factor authentication it returns a login challenge instead, see LoginWithCode.
 // Doesn't run in a transaction because readonly
 // On login update the last access.
-func (l *UsernamePasswordLogin) Login(ctx context.Context, request UsernamePasswordLoginRequest) (string, error) {
+func (l *UsernamePasswordLogin) Login(ctx context.Context, request UsernamePasswordLoginRequest) (string, *LoginChallengeResponse, error) {
 	username, pass := request.Username, request.Password
 
 	acc, err := l.accFinder.FindByUsername(ctx, username)
 	if err != nil {
 		logger.Debug("failed to find account", "username", username, "error", err)
-		return "", ErrDatabaseError // or wrap/map
+		return "", nil, ErrDatabaseError // or wrap/map
 	}
 
 	if acc == nil || !CheckPasswordHash(acc.Password, pass) {
-		return "", ErrInvalidCredentials
+		return "", nil, ErrInvalidCredentials
 	}
 
-	p, err := l.playerFinder.FindByAccountId(ctx, acc.Id)
+	return l.sessions.start(ctx, acc)
+}
+
+// LoginWithCode completes the login of an account with two-factor authentication, with a code
+// of the authenticator app or a recovery code. The challenge is dropped after too many codes,
+// and the account refuses codes for a while after too many wrong ones across its challenges.
+// Both are counted before the code is checked, so that parallel guesses cannot go over the limits.
+func (l *UsernamePasswordLogin) LoginWithCode(ctx context.Context, request TwoFactorLoginRequest) (string, error) {
+	hash := auth.HashSecretToken(request.ChallengeToken)
+
+	accountId, err := l.challenges.AttemptLoginChallenge(ctx, hash)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return "", ErrInvalidLoginChallenge
+		}
+		logger.Debug("failed to find login challenge", "error", err)
+		return "", ErrDatabaseError
+	}
+
+	acc, err := l.accFinder.FindById(ctx, accountId)
+	if err != nil || acc == nil || !acc.IsTwoFactorEnabled() {
+		logger.Debug("could not find account of login challenge", "account", accountId, "error", err)
+		return "", ErrInvalidLoginChallenge
+	}
+
+	if err := l.challenges.AttemptTwoFactor(ctx, accountId); err != nil {
+		if errors.Is(err, postgres.ErrNoRowUpdated) {
+			return "", ErrTwoFactorLocked
+		}
+		logger.Debug("failed to count two-factor attempt", "account", accountId, "error", err)
+		return "", ErrDatabaseError
+	}
+	if err := l.secondFactor.check(ctx, acc, request.Code); err != nil {
+		return "", err
+	}
+	if err := l.challenges.ResetTwoFactorAttempts(ctx, accountId); err != nil {
+		logger.Debug("failed to reset two-factor attempts", "account", accountId, "error", err)
+	}
+
+	if err := l.challenges.DeleteLoginChallenge(ctx, hash); err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return "", ErrInvalidLoginChallenge
+		}
+		logger.Debug("failed to delete login challenge", "account", accountId, "error", err)
+		return "", ErrDatabaseError
+	}
+
+	return l.sessions.open(ctx, acc)
+}
+
+// sessionStarter logs in the accounts once their first factor is checked
+type sessionStarter struct {
+	accUpdater   Updater
+	playerFinder player.Finder
+	tokenIssuer  auth.TokenIssuer
+	challenges   LoginChallengeStore
+}
+
+// start opens the session, or gives back a login challenge when the account has
+// two-factor authentication
+func (s sessionStarter) start(ctx context.Context, acc *Account) (string, *LoginChallengeResponse, error) {
+	if acc.IsTwoFactorEnabled() {
+		plain, hash := auth.NewSecretToken()
+		expiresAt := time.Now().Add(LoginChallengeDuration)
+		if err := s.challenges.SaveLoginChallenge(ctx, acc.Id, hash, expiresAt); err != nil {
+			logger.Debug("failed to save login challenge", "account", acc.Id, "error", err)
+			return "", nil, ErrDatabaseError
+		}
+		return "", &LoginChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    plain,
+			ExpiresAt:         expiresAt,
+		}, nil
+	}
+
+	token, err := s.open(ctx, acc)
+	return token, nil, err
+}
+
+// open issues the token of the account, once its credentials are checked
+func (s sessionStarter) open(ctx context.Context, acc *Account) (string, error) {
+	p, err := s.playerFinder.FindByAccountId(ctx, acc.Id)
 	if err != nil {
 		logger.Debug("failed to find player", "account", acc.Id, "error", err)
 		return "", errors.Join(ErrDatabaseError, errors.New("failed to fetch the player even if account is found"))
 	}
 
 	// login is successful, update the last access. This should never give an error
-	if err := l.accUpdater.UpdateLastAccess(ctx, acc.Id); err != nil {
+	if err := s.accUpdater.UpdateLastAccess(ctx, acc.Id); err != nil {
 		logger.Debug("failed to update last access", "error", err)
 		return "", ErrDatabaseError
 	}
 
-	token, err := l.tokenIssuer.Issue(ctx, auth.Claims{
-		Subject:  acc.Id,
-		PlayerID: p.Id,
+	token, err := s.tokenIssuer.Issue(ctx, auth.Claims{
+		Subject:        acc.Id,
+		PlayerID:       p.Id,
+		SessionVersion: acc.SessionVersion,
+	})
+	if err != nil {
+		logger.Error("failed to issue token", err)
+		return "", err
+	}
+	return token, nil
+}
+
+// PasswordManagement is an USE CASE where the password of an account is changed, or reset
+// through a single use link sent by email
+type PasswordManagement struct {
+	accFinder       Finder
+	emailFinder     EmailFinder
+	passwordUpdater PasswordUpdater
+	resetTokens     ResetTokenStore
+	mailer          mail.Mailer
+	tokenIssuer     auth.TokenIssuer
+	tx              tx.Transactor
+	// emailPolicy tells which emails can receive a reset link
+	emailPolicy EmailPolicy
+	// resetURL is the page of the client where the token is sent as query parameter
+	resetURL string
+}
+
+func NewPasswordManagement(
+	accFinder Finder,
+	emailFinder EmailFinder,
+	passwordUpdater PasswordUpdater,
+	resetTokens ResetTokenStore,
+	mailer mail.Mailer,
+	tokenIssuer auth.TokenIssuer,
+	tx tx.Transactor,
+	emailPolicy EmailPolicy,
+	resetURL string,
+) *PasswordManagement {
+	if emailPolicy == nil {
+		emailPolicy = RequireVerifiedEmail
+	}
+	return &PasswordManagement{
+		accFinder:       accFinder,
+		emailFinder:     emailFinder,
+		passwordUpdater: passwordUpdater,
+		resetTokens:     resetTokens,
+		mailer:          mailer,
+		tokenIssuer:     tokenIssuer,
+		tx:              tx,
+		emailPolicy:     emailPolicy,
+		resetURL:        resetURL,
+	}
+}
+
+// ChangePassword replaces the password after checking the current one. Every other session
+// of the account is revoked, the returned token keeps the current one alive.
+func (uc *PasswordManagement) ChangePassword(
+	ctx context.Context,
+	req ChangePasswordRequest,
+	accountId id.AccountId,
+	playerId id.PlayerId,
+) (string, error) {
+	var acc *Account
+	err := uc.tx.WithTransaction(ctx, func(txCtx context.Context) error {
+		var err error
+		acc, err = uc.accFinder.FindById(txCtx, accountId)
+		if err != nil {
+			logger.Debug("could not find account by id", "err", err)
+			return ErrDatabaseError
+		}
+		if acc == nil {
+			return ErrAccountDoesNotExist
+		}
+		if !CheckPasswordHash(acc.Password, req.CurrentPassword) {
+			return ErrWrongPassword
+		}
+		if err := validateRawPassword(req.NewPassword); err != nil {
+			return err
+		}
+
+		hashed, err := HashPassword(req.NewPassword)
+		if err != nil {
+			logger.Debug("failed to hash password", "err", err)
+			return ErrHashing
+		}
+		// the session version is bumped along with the password
+		acc.ChangePassword(hashed)
+
+		if err := uc.passwordUpdater.UpdatePassword(txCtx, acc); err != nil {
+			logger.Debug("failed to update password", "account", accountId, "err", err)
+			return ErrDatabaseError
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	token, err := uc.tokenIssuer.Issue(ctx, auth.Claims{
+		Subject:        acc.Id,
+		PlayerID:       playerId,
+		SessionVersion: acc.SessionVersion,
 	})
 	if err != nil {
 		logger.Error("failed to issue token", err)
@@ -229,3 +529,394 @@ func (l *UsernamePasswordLogin) Login(ctx context.Context, request UsernamePassw
 	}
 	return token, nil
 }
+
+// RequestReset sends a password reset link to the email of the account, if the email policy
+// accepts it, at most once every ResetRequestInterval. It succeeds even when no account has
+// the email, or when the request is throttled, so that it cannot be used to find out the
+// registered emails.
+func (uc *PasswordManagement) RequestReset(ctx context.Context, req ForgotPasswordRequest) error {
+	em, err := NewEmail(req.Email)
+	if err != nil {
+		return err
+	}
+
+	acc, err := uc.emailFinder.FindByEmail(ctx, em)
+	if err != nil {
+		logger.Debug("failed to find account by email", "err", err)
+		return ErrDatabaseError
+	}
+	if acc == nil {
+		logger.Debug("password reset requested for unknown email")
+		return nil
+	}
+	if !uc.emailPolicy(acc) {
+		logger.Debug("password reset requested for an email refused by the policy", "account", acc.Id)
+		return nil
+	}
+
+	sentAt, err := uc.resetTokens.LastResetSentAt(ctx, acc.Id)
+	if err != nil {
+		logger.Debug("failed to find last reset email", "account", acc.Id, "err", err)
+		return ErrDatabaseError
+	}
+	if sentAt != nil && time.Since(*sentAt) < ResetRequestInterval {
+		logger.Debug("password reset throttled", "account", acc.Id)
+		return nil
+	}
+
+	plain, hash := auth.NewSecretToken()
+	if err := uc.resetTokens.SaveResetToken(ctx, acc.Id, hash, time.Now().Add(ResetTokenDuration)); err != nil {
+		logger.Debug("failed to save reset token", "account", acc.Id, "err", err)
+		return ErrDatabaseError
+	}
+
+	if err := uc.mailer.Send(ctx, uc.resetMessage(em, plain)); err != nil {
+		logger.Error("failed to send password reset email", err, "account", acc.Id)
+	}
+	return nil
+}
+
+// ResetPassword sets the new password of the account of the reset token, which can be used once.
+// Every session of the account is revoked.
+func (uc *PasswordManagement) ResetPassword(ctx context.Context, req ResetPasswordRequest) error {
+	// a bad password must not burn the token
+	if err := validateRawPassword(req.NewPassword); err != nil {
+		return err
+	}
+	hashed, err := HashPassword(req.NewPassword)
+	if err != nil {
+		logger.Debug("failed to hash password", "err", err)
+		return ErrHashing
+	}
+
+	return uc.tx.WithTransaction(ctx, func(txCtx context.Context) error {
+		accountId, err := uc.resetTokens.ConsumeResetToken(txCtx, auth.HashSecretToken(req.Token))
+		if err != nil {
+			if errors.Is(err, postgres.ErrNoRowFound) {
+				return ErrInvalidResetToken
+			}
+			logger.Debug("failed to consume reset token", "err", err)
+			return ErrDatabaseError
+		}
+
+		acc, err := uc.accFinder.FindById(txCtx, accountId)
+		if err != nil || acc == nil {
+			logger.Debug("could not find account of reset token", "account", accountId, "err", err)
+			return ErrInvalidResetToken
+		}
+		acc.ChangePassword(hashed)
+
+		if err := uc.passwordUpdater.UpdatePassword(txCtx, acc); err != nil {
+			logger.Debug("failed to update password", "account", accountId, "err", err)
+			return ErrDatabaseError
+		}
+		return nil
+	})
+}
+
+func (uc *PasswordManagement) resetMessage(to Email, token string) mail.Message {
+	link := token
+	if uc.resetURL != "" {
+		link = uc.resetURL + "?token=" + token
+	}
+	return mail.Message{
+		To:      to.String(),
+		Subject: "Reset your Beldur password",
+		Body: fmt.Sprintf(
+			"Someone asked to reset the password of your account.\n\n"+
+				"Use this link within %d minutes to choose a new password:\n%s\n\n"+
+				"If it was not you, ignore this email.\n",
+			int(ResetTokenDuration.Minutes()), link,
+		),
+	}
+}
+
+// EmailVerification is an USE CASE where the owner of an account proves to read its email,
+// through a single use link sent to it
+type EmailVerification struct {
+	accFinder Finder
+	updater   EmailVerificationUpdater
+	tokens    VerificationTokenStore
+	mailer    mail.Mailer
+	tx        tx.Transactor
+	// verifyURL is the page of the client where the token is sent as query parameter
+	verifyURL string
+}
+
+func NewEmailVerification(
+	accFinder Finder,
+	updater EmailVerificationUpdater,
+	tokens VerificationTokenStore,
+	mailer mail.Mailer,
+	tx tx.Transactor,
+	verifyURL string,
+) *EmailVerification {
+	return &EmailVerification{
+		accFinder: accFinder,
+		updater:   updater,
+		tokens:    tokens,
+		mailer:    mailer,
+		tx:        tx,
+		verifyURL: verifyURL,
+	}
+}
+
+// SendVerification sends a new link to the email of the account, the previous ones can no longer be used
+func (uc *EmailVerification) SendVerification(ctx context.Context, acc *Account) error {
+	if !acc.HasEmail() {
+		return ErrNoEmail
+	}
+	if acc.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	plain, hash := auth.NewSecretToken()
+	if err := uc.tokens.SaveVerificationToken(ctx, acc.Id, *acc.Email, hash, time.Now().Add(VerificationTokenDuration)); err != nil {
+		logger.Debug("failed to save verification token", "account", acc.Id, "err", err)
+		return ErrDatabaseError
+	}
+	return uc.mailer.Send(ctx, uc.verificationMessage(*acc.Email, plain))
+}
+
+// Resend sends the link again, at most once every VerificationResendInterval
+func (uc *EmailVerification) Resend(ctx context.Context, accountId id.AccountId) error {
+	acc, err := uc.accFinder.FindById(ctx, accountId)
+	if err != nil {
+		logger.Debug("could not find account by id", "err", err)
+		return ErrDatabaseError
+	}
+	if acc == nil {
+		return ErrAccountDoesNotExist
+	}
+	if !acc.HasEmail() {
+		return ErrNoEmail
+	}
+	if acc.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	sentAt, err := uc.tokens.LastVerificationSentAt(ctx, accountId)
+	if err != nil {
+		logger.Debug("failed to find last verification", "account", accountId, "err", err)
+		return ErrDatabaseError
+	}
+	if sentAt != nil && time.Since(*sentAt) < VerificationResendInterval {
+		return ErrVerificationThrottled
+	}
+
+	if err := uc.SendVerification(ctx, acc); err != nil {
+		logger.Error("failed to send verification email", err, "account", accountId)
+		return err
+	}
+	return nil
+}
+
+// Verify marks the email of the token as verified. The token is refused if the email of the
+// account has changed since it was sent.
+func (uc *EmailVerification) Verify(ctx context.Context, req VerifyEmailRequest) error {
+	return uc.tx.WithTransaction(ctx, func(txCtx context.Context) error {
+		accountId, em, err := uc.tokens.ConsumeVerificationToken(txCtx, auth.HashSecretToken(req.Token))
+		if err != nil {
+			if errors.Is(err, postgres.ErrNoRowFound) {
+				return ErrInvalidVerificationToken
+			}
+			logger.Debug("failed to consume verification token", "err", err)
+			return ErrDatabaseError
+		}
+
+		acc, err := uc.accFinder.FindById(txCtx, accountId)
+		if err != nil || acc == nil {
+			logger.Debug("could not find account of verification token", "account", accountId, "err", err)
+			return ErrInvalidVerificationToken
+		}
+		if !acc.HasEmail() || acc.Email.String() != em.String() {
+			return ErrInvalidVerificationToken
+		}
+		if err := acc.VerifyEmail(time.Now()); err != nil {
+			return err
+		}
+
+		if err := uc.updater.UpdateEmailVerification(txCtx, acc); err != nil {
+			logger.Debug("failed to update email verification", "account", accountId, "err", err)
+			return ErrDatabaseError
+		}
+		return nil
+	})
+}
+
+func (uc *EmailVerification) verificationMessage(to Email, token string) mail.Message {
+	link := token
+	if uc.verifyURL != "" {
+		link = uc.verifyURL + "?token=" + token
+	}
+	return mail.Message{
+		To:      to.String(),
+		Subject: "Verify your Beldur email",
+		Body: fmt.Sprintf(
+			"This email has been added to a Beldur account.\n\n"+
+				"Use this link within %d hours to verify it:\n%s\n\n"+
+				"If it was not you, ignore this email.\n",
+			int(VerificationTokenDuration.Hours()), link,
+		),
+	}
+}
+
+// secondFactor checks the codes of the accounts with two-factor authentication
+type secondFactor struct {
+	updater       TwoFactorUpdater
+	recoveryCodes RecoveryCodeStore
+}
+
+// check accepts a code of the authenticator app or an unused recovery code, either
+// can be used only once
+func (f secondFactor) check(ctx context.Context, acc *Account, code string) error {
+	if isTOTPCode(code) {
+		if !acc.CheckTOTP(code, time.Now()) {
+			return ErrInvalidTwoFactorCode
+		}
+		// the step is checked again by the update, another request may have used it since the account was read
+		if err := f.updater.UseTOTPStep(ctx, acc.Id, acc.TwoFactor.LastStep); err != nil {
+			if errors.Is(err, postgres.ErrNoRowUpdated) {
+				return ErrInvalidTwoFactorCode
+			}
+			logger.Debug("failed to save last totp step", "account", acc.Id, "error", err)
+			return ErrDatabaseError
+		}
+		return nil
+	}
+
+	hash := auth.HashSecretToken(NormalizeRecoveryCode(code))
+	if err := f.recoveryCodes.ConsumeRecoveryCode(ctx, acc.Id, hash); err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return ErrInvalidTwoFactorCode
+		}
+		logger.Debug("failed to consume recovery code", "account", acc.Id, "error", err)
+		return ErrDatabaseError
+	}
+	return nil
+}
+
+// TwoFactorManagement is an USE CASE where the owner of an account enables or disables the
+// TOTP second factor of the login
+type TwoFactorManagement struct {
+	accFinder     Finder
+	updater       TwoFactorUpdater
+	recoveryCodes RecoveryCodeStore
+	secondFactor  secondFactor
+	tx            tx.Transactor
+}
+
+func NewTwoFactorManagement(
+	accFinder Finder,
+	updater TwoFactorUpdater,
+	recoveryCodes RecoveryCodeStore,
+	tx tx.Transactor,
+) *TwoFactorManagement {
+	return &TwoFactorManagement{
+		accFinder:     accFinder,
+		updater:       updater,
+		recoveryCodes: recoveryCodes,
+		secondFactor:  secondFactor{updater: updater, recoveryCodes: recoveryCodes},
+		tx:            tx,
+	}
+}
+
+// Enroll gives a new secret to add to the authenticator app. The second factor is required
+// at login only once confirmed.
+func (uc *TwoFactorManagement) Enroll(ctx context.Context, req EnrollTwoFactorRequest, accountId id.AccountId) (EnrollTwoFactorResponse, error) {
+	acc, err := uc.findAccount(ctx, accountId)
+	if err != nil {
+		return EnrollTwoFactorResponse{}, err
+	}
+	if !CheckPasswordHash(acc.Password, req.Password) {
+		return EnrollTwoFactorResponse{}, ErrWrongPassword
+	}
+
+	secret := totp.NewSecret()
+	if err := acc.EnrollTwoFactor(secret); err != nil {
+		return EnrollTwoFactorResponse{}, err
+	}
+	if err := uc.updater.UpdateTwoFactor(ctx, acc); err != nil {
+		logger.Debug("failed to save two-factor enrollment", "account", accountId, "err", err)
+		return EnrollTwoFactorResponse{}, ErrDatabaseError
+	}
+
+	return EnrollTwoFactorResponse{
+		Secret: secret,
+		URI:    totp.URI(TwoFactorIssuer, acc.Username, secret),
+	}, nil
+}
+
+// Confirm enables the second factor with a first code of the authenticator app, and gives
+// the recovery codes. Only their hashes are stored.
+func (uc *TwoFactorManagement) Confirm(ctx context.Context, req ConfirmTwoFactorRequest, accountId id.AccountId) (ConfirmTwoFactorResponse, error) {
+	acc, err := uc.findAccount(ctx, accountId)
+	if err != nil {
+		return ConfirmTwoFactorResponse{}, err
+	}
+	if err := acc.ConfirmTwoFactor(req.Code, time.Now()); err != nil {
+		return ConfirmTwoFactorResponse{}, err
+	}
+
+	codes := NewRecoveryCodes()
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashSecretToken(NormalizeRecoveryCode(code))
+	}
+
+	err = uc.tx.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := uc.updater.UpdateTwoFactor(txCtx, acc); err != nil {
+			return err
+		}
+		return uc.recoveryCodes.ReplaceRecoveryCodes(txCtx, acc.Id, hashes)
+	})
+	if err != nil {
+		logger.Debug("failed to enable two-factor", "account", accountId, "err", err)
+		return ConfirmTwoFactorResponse{}, ErrDatabaseError
+	}
+	return ConfirmTwoFactorResponse{RecoveryCodes: codes}, nil
+}
+
+// Disable removes the second factor, it needs both the password and a code
+func (uc *TwoFactorManagement) Disable(ctx context.Context, req DisableTwoFactorRequest, accountId id.AccountId) error {
+	acc, err := uc.findAccount(ctx, accountId)
+	if err != nil {
+		return err
+	}
+	if !acc.IsTwoFactorEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+	if !CheckPasswordHash(acc.Password, req.Password) {
+		return ErrWrongPassword
+	}
+	if err := uc.secondFactor.check(ctx, acc, req.Code); err != nil {
+		return err
+	}
+	if err := acc.DisableTwoFactor(); err != nil {
+		return err
+	}
+
+	err = uc.tx.WithTransaction(ctx, func(txCtx context.Context) error {
+		if err := uc.updater.UpdateTwoFactor(txCtx, acc); err != nil {
+			return err
+		}
+		return uc.recoveryCodes.ReplaceRecoveryCodes(txCtx, acc.Id, nil)
+	})
+	if err != nil {
+		logger.Debug("failed to disable two-factor", "account", accountId, "err", err)
+		return ErrDatabaseError
+	}
+	return nil
+}
+
+func (uc *TwoFactorManagement) findAccount(ctx context.Context, accountId id.AccountId) (*Account, error) {
+	acc, err := uc.accFinder.FindById(ctx, accountId)
+	if err != nil {
+		logger.Debug("could not find account by id", "err", err)
+		return nil, ErrDatabaseError
+	}
+	if acc == nil {
+		return nil, ErrAccountDoesNotExist
+	}
+	return acc, nil
+}
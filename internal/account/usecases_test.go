@@ -87,8 +87,18 @@ func TestRegisterAccount_Success(t *testing.T) {
 			uniquePlayer := new(MockUniquePlayerCreator)
 			transactor := new(MockTransactor)
 			issuer := new(MockTokenIssuer)
+			verification := new(MockVerificationSender)
 
-			svc := NewAccountRegistration(transactor, saver, uniquePlayer, issuer)
+			svc := NewAccountRegistration(transactor, saver, uniquePlayer, issuer, verification)
+
+			if tc.input.Email != nil {
+				verification.
+					On("SendVerification", mock.Anything, mock.MatchedBy(func(a *Account) bool {
+						return a.Id == tc.savedAcc.Id && !a.IsEmailVerified()
+					})).
+					Return(nil).
+					Once()
+			}
 
 			transactor.
 				On("WithTransaction", mock.Anything, mock.Anything).
@@ -141,6 +151,10 @@ func TestRegisterAccount_Success(t *testing.T) {
 			saver.AssertExpectations(t)
 			uniquePlayer.AssertExpectations(t)
 			issuer.AssertExpectations(t)
+			verification.AssertExpectations(t)
+			if tc.input.Email == nil {
+				verification.AssertNotCalled(t, "SendVerification", mock.Anything, mock.Anything)
+			}
 		})
 	}
 }
@@ -175,8 +189,9 @@ func TestRegisterAccount_Failure(t *testing.T) {
 			uniquePlayer := new(MockUniquePlayerCreator)
 			issuer := new(MockTokenIssuer)
 			transactor := new(MockTransactor) // Use mock instead of FnTransactor
+			verification := new(MockVerificationSender)
 
-			svc := NewAccountRegistration(transactor, saver, uniquePlayer, issuer)
+			svc := NewAccountRegistration(transactor, saver, uniquePlayer, issuer, verification)
 
 			_, token, err := svc.RegisterAccount(ctx, tc.input)
 
@@ -188,6 +203,7 @@ func TestRegisterAccount_Failure(t *testing.T) {
 			saver.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
 			uniquePlayer.AssertNotCalled(t, "CreateUniquePlayer", mock.Anything, mock.Anything, mock.Anything)
 			issuer.AssertNotCalled(t, "Issue", mock.Anything, mock.Anything)
+			verification.AssertNotCalled(t, "SendVerification", mock.Anything, mock.Anything)
 		})
 	}
 }
@@ -239,7 +255,15 @@ func TestLogin_Success(t *testing.T) {
 			playerFinder := new(MockPlayerFinder)
 			tokenIssuer := new(MockTokenIssuer)
 
-			svc := NewUsernamePasswordLogin(accFinder, accUpdater, playerFinder, tokenIssuer)
+			svc := NewUsernamePasswordLogin(
+				accFinder,
+				accUpdater,
+				playerFinder,
+				tokenIssuer,
+				new(MockLoginChallengeStore),
+				new(MockTwoFactorUpdater),
+				new(MockRecoveryCodeStore),
+			)
 
 			// accFinder expectation
 			accFinder.
@@ -268,7 +292,8 @@ func TestLogin_Success(t *testing.T) {
 				Once()
 
 			// act
-			tok, err := svc.Login(context.Background(), tc.input)
+			tok, challenge, err := svc.Login(context.Background(), tc.input)
+			assert.Nil(t, challenge)
 
 			// assert
 			if tc.errResponse != nil {
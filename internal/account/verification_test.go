@@ -0,0 +1,255 @@
+package account
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/auth"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/mail"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type verificationMocks struct {
+	accFinder *MockFinder
+	updater   *MockEmailVerificationUpdater
+	tokens    *MockVerificationTokenStore
+	mailer    *MockMailer
+}
+
+func newEmailVerification() (*EmailVerification, verificationMocks) {
+	m := verificationMocks{
+		accFinder: new(MockFinder),
+		updater:   new(MockEmailVerificationUpdater),
+		tokens:    new(MockVerificationTokenStore),
+		mailer:    new(MockMailer),
+	}
+	uc := NewEmailVerification(
+		m.accFinder,
+		m.updater,
+		m.tokens,
+		m.mailer,
+		FnTransactor{},
+		"https://beldur.example/verify",
+	)
+	return uc, m
+}
+
+func TestAccount_UpdateEmailResetsVerification(t *testing.T) {
+	em, _ := NewEmail("dm@example.com")
+	acc, err := New("username123", "hash", WithEmail(em))
+	require.NoError(t, err)
+	require.NoError(t, acc.VerifyEmail(time.Now()))
+	assert.True(t, acc.IsEmailVerified())
+
+	// same email, case insensitive
+	same, _ := NewEmail("DM@example.com")
+	acc.UpdateEmail(same)
+	assert.True(t, acc.IsEmailVerified())
+
+	other, _ := NewEmail("gm@example.com")
+	acc.UpdateEmail(other)
+	assert.False(t, acc.IsEmailVerified())
+	assert.Nil(t, acc.EmailVerifiedAt)
+}
+
+func TestEmailPolicy(t *testing.T) {
+	em, _ := NewEmail("dm@example.com")
+	now := time.Now()
+
+	assert.False(t, RequireVerifiedEmail(&Account{Email: &em}))
+	assert.True(t, RequireVerifiedEmail(&Account{Email: &em, EmailVerifiedAt: &now}))
+	assert.True(t, AllowUnverifiedEmail(&Account{Email: &em}))
+	assert.False(t, AllowUnverifiedEmail(&Account{Email: &Email{}}))
+}
+
+func TestSendVerification(t *testing.T) {
+	ctx := context.Background()
+	uc, m := newEmailVerification()
+	em, _ := NewEmail("dm@example.com")
+
+	var storedHash string
+	m.tokens.On("SaveVerificationToken", mock.Anything, id.AccountId(1), em, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) {
+			storedHash = args.String(3)
+			assert.WithinDuration(t, time.Now().Add(VerificationTokenDuration), args.Get(4).(time.Time), time.Minute)
+		}).
+		Return(nil).Once()
+
+	var sent mail.Message
+	m.mailer.On("Send", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { sent = args.Get(1).(mail.Message) }).
+		Return(nil).Once()
+
+	require.NoError(t, uc.SendVerification(ctx, &Account{Id: 1, Email: &em}))
+
+	assert.Equal(t, "dm@example.com", sent.To)
+	_, after, found := strings.Cut(sent.Body, "https://beldur.example/verify?token=")
+	require.True(t, found)
+	token, _, _ := strings.Cut(after, "\n")
+	assert.Equal(t, auth.HashSecretToken(token), storedHash)
+}
+
+func TestResendVerification(t *testing.T) {
+	ctx := context.Background()
+	em, _ := NewEmail("dm@example.com")
+
+	t.Run("sends a new link", func(t *testing.T) {
+		uc, m := newEmailVerification()
+		sentAt := time.Now().Add(-2 * VerificationResendInterval)
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).Return(&Account{Id: 1, Email: &em}, nil).Once()
+		m.tokens.On("LastVerificationSentAt", mock.Anything, id.AccountId(1)).Return(&sentAt, nil).Once()
+		m.tokens.On("SaveVerificationToken", mock.Anything, id.AccountId(1), em, mock.Anything, mock.Anything).Return(nil).Once()
+		m.mailer.On("Send", mock.Anything, mock.Anything).Return(nil).Once()
+
+		require.NoError(t, uc.Resend(ctx, 1))
+		m.mailer.AssertExpectations(t)
+	})
+
+	t.Run("throttled", func(t *testing.T) {
+		uc, m := newEmailVerification()
+		sentAt := time.Now().Add(-time.Second)
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).Return(&Account{Id: 1, Email: &em}, nil).Once()
+		m.tokens.On("LastVerificationSentAt", mock.Anything, id.AccountId(1)).Return(&sentAt, nil).Once()
+
+		assert.ErrorIs(t, uc.Resend(ctx, 1), ErrVerificationThrottled)
+		m.mailer.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+	})
+
+	t.Run("already verified", func(t *testing.T) {
+		uc, m := newEmailVerification()
+		verifiedAt := time.Now()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).
+			Return(&Account{Id: 1, Email: &em, EmailVerifiedAt: &verifiedAt}, nil).Once()
+
+		assert.ErrorIs(t, uc.Resend(ctx, 1), ErrEmailAlreadyVerified)
+	})
+
+	t.Run("no email", func(t *testing.T) {
+		uc, m := newEmailVerification()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).Return(&Account{Id: 1, Email: &Email{}}, nil).Once()
+
+		assert.ErrorIs(t, uc.Resend(ctx, 1), ErrNoEmail)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	em, _ := NewEmail("dm@example.com")
+
+	t.Run("success", func(t *testing.T) {
+		uc, m := newEmailVerification()
+		m.tokens.On("ConsumeVerificationToken", mock.Anything, auth.HashSecretToken("verify-token")).
+			Return(id.AccountId(1), em, nil).Once()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).Return(&Account{Id: 1, Email: &em}, nil).Once()
+		m.updater.On("UpdateEmailVerification", mock.Anything, mock.MatchedBy(func(a *Account) bool {
+			return a.IsEmailVerified()
+		})).Return(nil).Once()
+
+		require.NoError(t, uc.Verify(ctx, VerifyEmailRequest{Token: "verify-token"}))
+		m.updater.AssertExpectations(t)
+	})
+
+	t.Run("email changed since the link was sent", func(t *testing.T) {
+		uc, m := newEmailVerification()
+		other, _ := NewEmail("gm@example.com")
+		m.tokens.On("ConsumeVerificationToken", mock.Anything, mock.Anything).Return(id.AccountId(1), em, nil).Once()
+		m.accFinder.On("FindById", mock.Anything, id.AccountId(1)).Return(&Account{Id: 1, Email: &other}, nil).Once()
+
+		assert.ErrorIs(t, uc.Verify(ctx, VerifyEmailRequest{Token: "verify-token"}), ErrInvalidVerificationToken)
+		m.updater.AssertNotCalled(t, "UpdateEmailVerification", mock.Anything, mock.Anything)
+	})
+
+	t.Run("used or expired token", func(t *testing.T) {
+		uc, m := newEmailVerification()
+		m.tokens.On("ConsumeVerificationToken", mock.Anything, mock.Anything).
+			Return(id.AccountId(0), Email{}, postgres.ErrNoRowFound).Once()
+
+		assert.ErrorIs(t, uc.Verify(ctx, VerifyEmailRequest{Token: "verify-token"}), ErrInvalidVerificationToken)
+	})
+}
+
+func TestUpdateAccount_SendsVerification(t *testing.T) {
+	ctx := context.Background()
+	em, _ := NewEmail("dm@example.com")
+
+	t.Run("changed email", func(t *testing.T) {
+		finder, updater, verification := new(MockFinder), new(MockUpdater), new(MockVerificationSender)
+		uc := NewAccountManagement(finder, updater, verification)
+		verifiedAt := time.Now()
+		finder.On("FindById", mock.Anything, id.AccountId(1)).
+			Return(&Account{Id: 1, Email: &em, EmailVerifiedAt: &verifiedAt}, nil).Once()
+		updater.On("Update", mock.Anything, mock.MatchedBy(func(a *Account) bool {
+			return a.Email.String() == "gm@example.com" && a.EmailVerifiedAt == nil
+		})).Return(nil).Once()
+		verification.On("SendVerification", mock.Anything, mock.Anything).Return(nil).Once()
+
+		resp, err := uc.UpdateAccount(ctx, UpdateAccountRequest{Email: "gm@example.com"}, 1)
+		require.NoError(t, err)
+		assert.False(t, resp.EmailVerified)
+		verification.AssertExpectations(t)
+	})
+
+	t.Run("same email keeps the verification", func(t *testing.T) {
+		finder, updater, verification := new(MockFinder), new(MockUpdater), new(MockVerificationSender)
+		uc := NewAccountManagement(finder, updater, verification)
+		verifiedAt := time.Now()
+		finder.On("FindById", mock.Anything, id.AccountId(1)).
+			Return(&Account{Id: 1, Email: &em, EmailVerifiedAt: &verifiedAt}, nil).Once()
+		updater.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+
+		resp, err := uc.UpdateAccount(ctx, UpdateAccountRequest{Email: "dm@example.com"}, 1)
+		require.NoError(t, err)
+		assert.True(t, resp.EmailVerified)
+		verification.AssertNotCalled(t, "SendVerification", mock.Anything, mock.Anything)
+	})
+
+	t.Run("email of another account", func(t *testing.T) {
+		finder, updater, verification := new(MockFinder), new(MockUpdater), new(MockVerificationSender)
+		uc := NewAccountManagement(finder, updater, verification)
+		finder.On("FindById", mock.Anything, id.AccountId(1)).Return(&Account{Id: 1, Email: &em}, nil).Once()
+		updater.On("Update", mock.Anything, mock.Anything).Return(postgres.ErrUniqueValueViolation).Once()
+
+		_, err := uc.UpdateAccount(ctx, UpdateAccountRequest{Email: "gm@example.com"}, 1)
+		assert.ErrorIs(t, err, ErrEmailAlreadyTaken)
+		verification.AssertNotCalled(t, "SendVerification", mock.Anything, mock.Anything)
+	})
+}
+
+type MockVerificationSender struct{ mock.Mock }
+type MockEmailVerificationUpdater struct{ mock.Mock }
+type MockVerificationTokenStore struct{ mock.Mock }
+
+func (m *MockVerificationSender) SendVerification(ctx context.Context, acc *Account) error {
+	args := m.Called(ctx, acc)
+	return args.Error(0)
+}
+
+func (m *MockEmailVerificationUpdater) UpdateEmailVerification(ctx context.Context, account *Account) error {
+	args := m.Called(ctx, account)
+	return args.Error(0)
+}
+
+func (m *MockVerificationTokenStore) SaveVerificationToken(ctx context.Context, accountId id.AccountId, email Email, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(ctx, accountId, email, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockVerificationTokenStore) LastVerificationSentAt(ctx context.Context, accountId id.AccountId) (*time.Time, error) {
+	args := m.Called(ctx, accountId)
+	var sentAt *time.Time
+	if v := args.Get(0); v != nil {
+		sentAt = v.(*time.Time)
+	}
+	return sentAt, args.Error(1)
+}
+
+func (m *MockVerificationTokenStore) ConsumeVerificationToken(ctx context.Context, tokenHash string) (id.AccountId, Email, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Get(0).(id.AccountId), args.Get(1).(Email), args.Error(2)
+}
@@ -1,26 +1,146 @@
 package account
 
 import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/internal/chat"
+	"beldur/internal/encounter"
+	"beldur/internal/handout"
+	"beldur/internal/journal"
 	"beldur/internal/player"
+	"beldur/internal/schedule"
+	"beldur/internal/wiki"
 	"beldur/pkg/auth"
 	"beldur/pkg/db/postgres"
 	"beldur/pkg/db/tx"
+	"beldur/pkg/mail"
+	"beldur/pkg/middleware"
+	"time"
 )
 
 type Deps struct {
 	Transactor tx.Transactor
 	QProvider  postgres.QuerierProvider
 	Issuer     auth.TokenIssuer
+	Mailer     mail.Mailer
+	// PasswordResetURL is the page of the client linked in the password reset emails
+	PasswordResetURL string
+	// EmailVerifyURL is the page of the client linked in the email verification emails
+	EmailVerifyURL string
+	// EmailPolicy tells which emails can be used by the password reset, RequireVerifiedEmail if nil
+	EmailPolicy EmailPolicy
+	// IdentityProviders are the OpenID Connect providers offered to log in, by name
+	IdentityProviders map[string]IdentityProvider
+	// SessionCookie sets the attributes of the cookie given at login
+	SessionCookie middleware.SessionCookie
+	// SessionDuration is the expiration of the tokens of Issuer, the sessions last as long
+	SessionDuration time.Duration
 }
 
 func NewHandlerFromDeps(deps Deps) *HttpHandler {
 	accountRepo := NewPostgresRepository(deps.QProvider)
 	playerRepo := player.NewPostgresRepository(deps.QProvider)
 	uniquePlayerSvc := player.NewUniquePlayerService(playerRepo)
+	sessionIssuer := NewSessionIssuer(deps.Issuer, accountRepo, deps.SessionDuration)
 
-	registerUC := NewAccountRegistration(deps.Transactor, accountRepo, uniquePlayerSvc, deps.Issuer)
-	loginUC := NewUsernamePasswordLogin(accountRepo, accountRepo, playerRepo, deps.Issuer)
-	manageUC := NewAccountManagement(accountRepo, accountRepo)
+	verificationUC := NewEmailVerification(
+		accountRepo,
+		accountRepo,
+		accountRepo,
+		deps.Mailer,
+		deps.Transactor,
+		deps.EmailVerifyURL,
+	)
+	registerUC := NewAccountRegistration(deps.Transactor, accountRepo, uniquePlayerSvc, sessionIssuer, verificationUC)
+	loginUC := NewUsernamePasswordLogin(
+		accountRepo,
+		accountRepo,
+		playerRepo,
+		sessionIssuer,
+		accountRepo,
+		accountRepo,
+		accountRepo,
+	)
+	manageUC := NewAccountManagement(accountRepo, accountRepo, verificationUC)
+	passwordUC := NewPasswordManagement(
+		accountRepo,
+		accountRepo,
+		accountRepo,
+		accountRepo,
+		deps.Mailer,
+		sessionIssuer,
+		deps.Transactor,
+		deps.EmailPolicy,
+		deps.PasswordResetURL,
+	)
 
-	return NewHttpHandler(registerUC, loginUC, manageUC)
+	twoFactorUC := NewTwoFactorManagement(accountRepo, accountRepo, accountRepo, deps.Transactor)
+
+	oidcUC := NewOIDCLogin(
+		deps.IdentityProviders,
+		accountRepo,
+		accountRepo,
+		accountRepo,
+		accountRepo,
+		registerUC,
+		accountRepo,
+		playerRepo,
+		sessionIssuer,
+		accountRepo,
+	)
+
+	accessTokenUC := NewAccessTokenManagement(accountRepo)
+	sessionUC := NewSessionManagement(accountRepo)
+
+	deletionUC := NewAccountDeletion(accountRepo, playerRepo, campaign.NewPostgresRepository(deps.QProvider), accountRepo)
+	exportUC := NewDataExport(accountRepo, playerRepo, accountRepo, sessionUC, accessTokenUC, accountRepo, ExportSources{
+		Campaigns:  campaign.NewPostgresRepository(deps.QProvider),
+		Characters: character.NewPostgresRepository(deps.QProvider),
+		Journal:    journal.NewPostgresRepository(deps.QProvider),
+		Wiki:       wiki.NewPostgresRepository(deps.QProvider),
+		Chat:       chat.NewPostgresRepository(deps.QProvider),
+		Schedule:   schedule.NewPostgresRepository(deps.QProvider),
+		Encounters: encounter.NewPostgresRepository(deps.QProvider),
+		Handouts:   handout.NewPostgresRepository(deps.QProvider),
+	})
+
+	return NewHttpHandler(
+		registerUC,
+		loginUC,
+		manageUC,
+		passwordUC,
+		verificationUC,
+		twoFactorUC,
+		oidcUC,
+		accessTokenUC,
+		sessionUC,
+		deletionUC,
+		exportUC,
+		deps.SessionCookie,
+	)
+}
+
+// NewDeletionPurgeFromDeps builds the purge of the accounts whose deletion grace period is over
+func NewDeletionPurgeFromDeps(deps Deps) *DeletionPurge {
+	accountRepo := NewPostgresRepository(deps.QProvider)
+	return NewDeletionPurge(
+		player.NewPostgresRepository(deps.QProvider),
+		campaign.NewPostgresRepository(deps.QProvider),
+		accountRepo,
+		accountRepo,
+		deps.Transactor,
+	)
+}
+
+// NewSessionPurgeFromDeps builds the purge of the expired sessions
+func NewSessionPurgeFromDeps(deps Deps) *SessionPurge {
+	return NewSessionPurge(NewPostgresRepository(deps.QProvider))
+}
+
+// NewVerifierFromDeps wraps the verifier so that the revoked sessions are rejected, and the
+// personal access tokens are accepted
+func NewVerifierFromDeps(deps Deps, verifier auth.TokenVerifier) *AccessTokenVerifier {
+	accountRepo := NewPostgresRepository(deps.QProvider)
+	sessions := NewSessionVerifier(verifier, accountRepo, accountRepo)
+	return NewAccessTokenVerifier(sessions, accountRepo, player.NewPostgresRepository(deps.QProvider))
 }
@@ -2,13 +2,28 @@ package app
 
 import (
 	"beldur/internal/account"
+	"beldur/internal/battlemap"
+	"beldur/internal/bestiary"
 	"beldur/internal/campaign"
 	"beldur/internal/character"
+	"beldur/internal/chat"
+	"beldur/internal/encounter"
+	"beldur/internal/handout"
+	"beldur/internal/journal"
+	"beldur/internal/quest"
+	"beldur/internal/schedule"
+	"beldur/internal/wiki"
+	"beldur/pkg/auth"
 	"beldur/pkg/auth/jwt"
 	"beldur/pkg/db/postgres"
 	"beldur/pkg/db/tx"
+	"beldur/pkg/live"
+	"beldur/pkg/mail"
 	"beldur/pkg/middleware"
+	"context"
 	"fmt"
+	"regexp"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/healthcheck"
@@ -19,10 +34,29 @@ type Deps struct {
 	JwtService *jwt.Service
 	Transactor tx.Transactor
 	QProvider  postgres.QuerierProvider
+	BlobStore  handout.BlobStore
+	Mailer     mail.Mailer
+	// PasswordResetURL is the page of the client linked in the password reset emails
+	PasswordResetURL string
+	// EmailVerifyURL is the page of the client linked in the email verification emails
+	EmailVerifyURL string
+	// IdentityProviders are the OpenID Connect providers users can log in with, by name
+	IdentityProviders map[string]account.IdentityProvider
+	// SessionCookie sets the attributes of the session cookie, its MaxAge defaults to the
+	// expiration of the tokens of JwtService
+	SessionCookie middleware.SessionCookie
 }
 
+// deletionPurgeInterval is how often the accounts at the end of their deletion grace period are purged
+const deletionPurgeInterval = time.Hour
+
+// sessionPurgeInterval is how often the expired sessions are deleted
+const sessionPurgeInterval = time.Hour
+
 type FiberApp struct {
-	app *fiber.App
+	app           *fiber.App
+	deletionPurge *account.DeletionPurge
+	sessionPurge  *account.SessionPurge
 }
 
 func NewDev(deps Deps) *FiberApp {
@@ -33,8 +67,14 @@ func NewTest(deps Deps) *FiberApp {
 	return build(deps, true)
 }
 
+// uploadRoutes accept the bodies up to the server limit, the other routes keep the default one
+var uploadRoutes = regexp.MustCompile(`(?i)^/campaign/[^/]+/(handouts|maps)/?$`)
+
 func build(deps Deps, test bool) *FiberApp {
-	cfg := fiber.Config{}
+	cfg := fiber.Config{
+		// room for a handout or map upload with its multipart envelope
+		BodyLimit: max(handout.MaxFileSize, battlemap.MaxImageSize) + 1<<20,
+	}
 	if test {
 		cfg.DisableStartupMessage = true
 	}
@@ -46,36 +86,232 @@ func build(deps Deps, test bool) *FiberApp {
 	if !test {
 		app.Use(fiberlogger.New())
 	}
+	app.Use(middleware.BodyLimit(fiber.DefaultBodyLimit, func(c *fiber.Ctx) bool {
+		return c.Method() == fiber.MethodPost && uploadRoutes.MatchString(c.Path())
+	}))
+
+	sessionCookie := deps.SessionCookie
+	if sessionCookie.MaxAge == 0 {
+		sessionCookie.MaxAge = deps.JwtService.Expiration()
+	}
 
 	// handlers
-	accountHandler := account.NewHandlerFromDeps(account.Deps{
+	accountDeps := account.Deps{
+		Transactor:       deps.Transactor,
+		QProvider:        deps.QProvider,
+		Issuer:           deps.JwtService,
+		Mailer:           deps.Mailer,
+		PasswordResetURL: deps.PasswordResetURL,
+		EmailVerifyURL:   deps.EmailVerifyURL,
+
+		IdentityProviders: deps.IdentityProviders,
+		SessionCookie:     sessionCookie,
+		SessionDuration:   deps.JwtService.Expiration(),
+	}
+	accountHandler := account.NewHandlerFromDeps(accountDeps)
+	campaignHandler := campaign.NewHandlerFromDeps(campaign.Deps{
+		QProvider:  deps.QProvider,
+		Transactor: deps.Transactor,
+	})
+
+	characterHandler := character.NewHandlerFromDeps(character.Deps{
+		QProvider:  deps.QProvider,
 		Transactor: deps.Transactor,
+	})
+
+	bestiaryHandler := bestiary.NewHandlerFromDeps(bestiary.Deps{
 		QProvider:  deps.QProvider,
-		Issuer:     deps.JwtService,
+		Transactor: deps.Transactor,
 	})
-	campaignHandler := campaign.NewHandlerFromDeps(campaign.Deps{
+
+	encounterHandler := encounter.NewHandlerFromDeps(encounter.Deps{
 		QProvider:  deps.QProvider,
 		Transactor: deps.Transactor,
 	})
 
-	characterHandler := character.NewHandlerFromDeps(character.Deps{
-		QProvider: deps.QProvider,
+	scheduleHandler := schedule.NewHandlerFromDeps(schedule.Deps{
+		QProvider:  deps.QProvider,
+		Transactor: deps.Transactor,
 	})
 
-	authMiddleware := middleware.Auth(deps.JwtService)
+	// live events of all the campaigns, shared by the handlers delivering them
+	broker := live.NewBroker()
+
+	chatHandler := chat.NewHandlerFromDeps(chat.Deps{
+		QProvider:  deps.QProvider,
+		Transactor: deps.Transactor,
+		Broker:     broker,
+	})
+
+	questHandler := quest.NewHandlerFromDeps(quest.Deps{
+		QProvider:  deps.QProvider,
+		Transactor: deps.Transactor,
+	})
+
+	journalHandler := journal.NewHandlerFromDeps(journal.Deps{
+		QProvider:  deps.QProvider,
+		Transactor: deps.Transactor,
+	})
+
+	wikiHandler := wiki.NewHandlerFromDeps(wiki.Deps{
+		QProvider:  deps.QProvider,
+		Transactor: deps.Transactor,
+	})
+
+	handoutHandler := handout.NewHandlerFromDeps(handout.Deps{
+		QProvider:  deps.QProvider,
+		Transactor: deps.Transactor,
+		BlobStore:  deps.BlobStore,
+	})
+
+	mapHandler := battlemap.NewHandlerFromDeps(battlemap.Deps{
+		QProvider:  deps.QProvider,
+		Transactor: deps.Transactor,
+		BlobStore:  deps.BlobStore,
+		Broker:     broker,
+	})
+
+	authMiddleware := middleware.Auth(account.NewVerifierFromDeps(accountDeps, deps.JwtService), sessionCookie)
+	// what the personal access tokens can do, the sessions can do everything
+	sessionOnly := middleware.SessionOnly()
+	campaignRead := middleware.RequireScope(auth.ScopeCampaignRead)
+	campaignWrite := middleware.RequireScope(auth.ScopeCampaignWrite)
+	charactersRead := middleware.RequireScope(auth.ScopeCharactersRead)
+	charactersWrite := middleware.RequireScope(auth.ScopeCharactersWrite)
 
 	// routes
+	app.Get("/.well-known/jwks.json", jwt.JWKSHandler(deps.JwtService))
 	app.Post("/auth/signup", middleware.Validation[account.CreateAccountRequest](), accountHandler.Register)
 	app.Post("/auth/login", middleware.Validation[account.UsernamePasswordLoginRequest](), accountHandler.Login)
-	app.Post("/campaign", authMiddleware, middleware.Validation[campaign.CreationRequest](), campaignHandler.HandleCreateCampaign)
+	app.Post("/auth/login/2fa", middleware.Validation[account.TwoFactorLoginRequest](), accountHandler.LoginWithCode)
+	app.Get("/auth/oidc/:provider", accountHandler.StartOIDCLogin)
+	app.Post("/auth/oidc/:provider/callback", middleware.Validation[account.OIDCCallbackRequest](), accountHandler.OIDCCallback)
+	app.Post("/auth/password/forgot", middleware.Validation[account.ForgotPasswordRequest](), accountHandler.ForgotPassword)
+	app.Post("/auth/password/reset", middleware.Validation[account.ResetPasswordRequest](), accountHandler.ResetPassword)
+	app.Post("/account/email/verify", middleware.Validation[account.VerifyEmailRequest](), accountHandler.VerifyEmail)
+	app.Post("/campaign", authMiddleware, campaignWrite, middleware.Validation[campaign.CreationRequest](), campaignHandler.HandleCreateCampaign)
 	app.Get("/campaign", campaignHandler.HandleGetCampaign)
-	app.Patch("/account", authMiddleware, accountHandler.UpdateAccount)
-	app.Post("/campaign/:campaignId", authMiddleware, middleware.Validation[campaign.JoinRequest](), campaignHandler.HandleJoinCampaign)
-	app.Post("/campaign/:campaignId/npc", authMiddleware, characterHandler.HandleNpcCreation)
+	app.Patch("/account", authMiddleware, sessionOnly, middleware.Validation[account.UpdateAccountRequest](), accountHandler.UpdateAccount)
+	app.Post("/account/email/verification", authMiddleware, sessionOnly, accountHandler.ResendVerification)
+	app.Post("/account/password", authMiddleware, sessionOnly, middleware.Validation[account.ChangePasswordRequest](), accountHandler.ChangePassword)
+	app.Post("/account/2fa", authMiddleware, sessionOnly, middleware.Validation[account.EnrollTwoFactorRequest](), accountHandler.EnrollTwoFactor)
+	app.Post("/account/2fa/confirm", authMiddleware, sessionOnly, middleware.Validation[account.ConfirmTwoFactorRequest](), accountHandler.ConfirmTwoFactor)
+	app.Delete("/account/2fa", authMiddleware, sessionOnly, middleware.Validation[account.DisableTwoFactorRequest](), accountHandler.DisableTwoFactor)
+	app.Post("/account/tokens", authMiddleware, sessionOnly, middleware.Validation[account.CreateAccessTokenRequest](), accountHandler.CreateAccessToken)
+	app.Get("/account/tokens", authMiddleware, sessionOnly, accountHandler.GetAccessTokens)
+	app.Delete("/account/tokens/:tokenId", authMiddleware, sessionOnly, accountHandler.RevokeAccessToken)
+	app.Get("/account/sessions", authMiddleware, sessionOnly, accountHandler.GetSessions)
+	app.Delete("/account/sessions/:sessionId", authMiddleware, sessionOnly, accountHandler.RevokeSession)
+	app.Post("/account/deletion", authMiddleware, sessionOnly, middleware.Validation[account.DeleteAccountRequest](), accountHandler.RequestDeletion)
+	app.Get("/account/deletion", authMiddleware, sessionOnly, accountHandler.GetDeletion)
+	app.Delete("/account/deletion", authMiddleware, sessionOnly, accountHandler.CancelDeletion)
+	app.Get("/account/export", authMiddleware, sessionOnly, accountHandler.ExportData)
+	app.Post("/campaign/:campaignId", authMiddleware, campaignWrite, middleware.Validation[campaign.JoinRequest](), campaignHandler.HandleJoinCampaign)
+	app.Post("/campaign/:campaignId/npc", authMiddleware, charactersWrite, middleware.Validation[character.CreateCharacterRequest](), characterHandler.HandleNpcCreation)
+	app.Post("/campaign/:campaignId/npc/generate", authMiddleware, charactersWrite, middleware.Validation[character.GenerateNPCRequest](), characterHandler.HandleNpcGeneration)
+	app.Post("/campaign/:campaignId/characters/import", authMiddleware, charactersWrite, characterHandler.CheckImportVersion, middleware.Validation[character.ExportDocument](), characterHandler.HandleImport)
+	app.Get("/characters/export/schema.json", characterHandler.HandleExportSchema)
+	app.Get("/characters/:id/export", authMiddleware, charactersRead, characterHandler.HandleExport)
+	app.Get("/characters/:id/sheet.pdf", authMiddleware, charactersRead, characterHandler.HandleSheetPDF)
+	app.Patch("/characters/:id", authMiddleware, charactersWrite, middleware.Validation[character.UpdateCharacterRequest](), characterHandler.HandleUpdate)
+	app.Get("/characters/:id/history", authMiddleware, charactersRead, characterHandler.HandleHistory)
+	app.Post("/characters/:id/history/:version/restore", authMiddleware, charactersWrite, characterHandler.HandleRestore)
+	app.Post("/characters/:id/death", authMiddleware, charactersWrite, characterHandler.HandleDeath)
+	app.Post("/characters/:id/retirement", authMiddleware, charactersWrite, characterHandler.HandleRetirement)
+	app.Post("/characters/:id/resurrection", authMiddleware, charactersWrite, characterHandler.HandleResurrection)
+	app.Delete("/characters/:id", authMiddleware, charactersWrite, characterHandler.HandleDelete)
+	app.Get("/campaign/:campaignId/graveyard", authMiddleware, charactersRead, characterHandler.HandleGraveyard)
+	app.Post("/characters/:id/transfers", authMiddleware, charactersWrite, middleware.Validation[character.TransferRequest](), characterHandler.HandleTransferRequest)
+	app.Get("/campaign/:campaignId/transfers", authMiddleware, charactersRead, characterHandler.HandlePendingTransfers)
+	app.Post("/campaign/:campaignId/transfers/:transferId/approve", authMiddleware, charactersWrite, characterHandler.HandleTransferApproval)
+	app.Post("/campaign/:campaignId/transfers/:transferId/reject", authMiddleware, charactersWrite, characterHandler.HandleTransferRejection)
+	app.Post("/bestiary", authMiddleware, campaignWrite, middleware.Validation[bestiary.TemplateRequest](), bestiaryHandler.HandleCreateSharedTemplate)
+	app.Get("/bestiary", authMiddleware, campaignRead, bestiaryHandler.HandleGetSharedTemplates)
+	app.Post("/campaign/:campaignId/bestiary", authMiddleware, campaignWrite, middleware.Validation[bestiary.TemplateRequest](), bestiaryHandler.HandleCreateCampaignTemplate)
+	app.Get("/campaign/:campaignId/bestiary", authMiddleware, campaignRead, bestiaryHandler.HandleGetCampaignTemplates)
+	app.Post("/campaign/:campaignId/bestiary/:templateId/spawn", authMiddleware, campaignWrite, middleware.Validation[bestiary.SpawnRequest](), bestiaryHandler.HandleSpawn)
+	app.Post("/campaign/:campaignId/encounters", authMiddleware, campaignWrite, middleware.Validation[encounter.CreateEncounterRequest](), encounterHandler.HandleCreateEncounter)
+	app.Get("/campaign/:campaignId/encounters", authMiddleware, campaignRead, encounterHandler.HandleGetEncounters)
+	app.Get("/encounters/:encounterId", authMiddleware, campaignRead, encounterHandler.HandleGetEncounter)
+	app.Get("/encounters/:encounterId/actions", authMiddleware, campaignRead, encounterHandler.HandleGetActions)
+	app.Post("/encounters/:encounterId/next-turn", authMiddleware, campaignWrite, encounterHandler.HandleNextTurn)
+	app.Post("/encounters/:encounterId/delay", authMiddleware, campaignWrite, middleware.Validation[encounter.DelayRequest](), encounterHandler.HandleDelay)
+	app.Post("/encounters/:encounterId/end", authMiddleware, campaignWrite, encounterHandler.HandleEnd)
+	app.Patch("/encounters/:encounterId/combatants/:characterId", authMiddleware, campaignWrite, middleware.Validation[encounter.UpdateCombatantRequest](), encounterHandler.HandleUpdateCombatant)
+	app.Delete("/encounters/:encounterId/combatants/:characterId", authMiddleware, campaignWrite, encounterHandler.HandleRemoveCombatant)
+	app.Post("/campaign/:campaignId/sessions", authMiddleware, campaignWrite, middleware.Validation[schedule.CreateSessionRequest](), scheduleHandler.HandleCreateSession)
+	app.Get("/campaign/:campaignId/sessions", authMiddleware, campaignRead, scheduleHandler.HandleGetSessions)
+	app.Get("/sessions/:sessionId", authMiddleware, campaignRead, scheduleHandler.HandleGetSession)
+	app.Put("/sessions/:sessionId/slots/:slotId/vote", authMiddleware, campaignWrite, middleware.Validation[schedule.VoteRequest](), scheduleHandler.HandleVote)
+	app.Post("/sessions/:sessionId/schedule", authMiddleware, campaignWrite, middleware.Validation[schedule.ScheduleRequest](), scheduleHandler.HandleSchedule)
+	app.Post("/sessions/:sessionId/complete", authMiddleware, campaignWrite, scheduleHandler.HandleComplete)
+	app.Post("/sessions/:sessionId/cancel", authMiddleware, campaignWrite, scheduleHandler.HandleCancel)
+	app.Put("/sessions/:sessionId/attendance", authMiddleware, campaignWrite, middleware.Validation[schedule.AttendanceRequest](), scheduleHandler.HandleAttendance)
+	app.Get("/sessions/:sessionId/calendar.ics", authMiddleware, campaignRead, scheduleHandler.HandleSessionCalendar)
+	app.Post("/calendar/token", authMiddleware, sessionOnly, scheduleHandler.HandleCreateCalendarToken)
+	app.Delete("/calendar/token", authMiddleware, sessionOnly, scheduleHandler.HandleRevokeCalendarToken)
+	app.Get("/calendar/:token.ics", scheduleHandler.HandleCalendarFeed)
+	app.Post("/campaign/:campaignId/journal", authMiddleware, campaignWrite, middleware.Validation[journal.CreateEntryRequest](), journalHandler.HandleCreateEntry)
+	app.Get("/campaign/:campaignId/journal", authMiddleware, campaignRead, journalHandler.HandleGetJournal)
+	app.Get("/journal/:entryId", authMiddleware, campaignRead, journalHandler.HandleGetEntry)
+	app.Put("/journal/:entryId", authMiddleware, campaignWrite, middleware.Validation[journal.UpdateEntryRequest](), journalHandler.HandleUpdateEntry)
+	app.Delete("/journal/:entryId", authMiddleware, campaignWrite, journalHandler.HandleDeleteEntry)
+	app.Post("/campaign/:campaignId/chat", authMiddleware, middleware.Validation[chat.SendMessageRequest](), chatHandler.HandleSendMessage)
+	app.Get("/campaign/:campaignId/chat", authMiddleware, campaignRead, chatHandler.HandleGetHistory)
+	app.Get("/campaign/:campaignId/chat/stream", authMiddleware, campaignRead, chatHandler.HandleStream)
+	app.Patch("/chat/messages/:messageId", authMiddleware, campaignWrite, middleware.Validation[chat.EditMessageRequest](), chatHandler.HandleEditMessage)
+	app.Delete("/chat/messages/:messageId", authMiddleware, campaignWrite, chatHandler.HandleDeleteMessage)
+	app.Get("/chat/messages/:messageId/audit", authMiddleware, campaignRead, chatHandler.HandleGetAudit)
+	app.Post("/campaign/:campaignId/quests", authMiddleware, campaignWrite, middleware.Validation[quest.CreateQuestRequest](), questHandler.HandleCreateQuest)
+	app.Get("/campaign/:campaignId/quests", authMiddleware, campaignRead, questHandler.HandleGetQuests)
+	app.Get("/campaign/:campaignId/quests/:questId", authMiddleware, campaignRead, questHandler.HandleGetQuest)
+	app.Put("/campaign/:campaignId/quests/:questId", authMiddleware, campaignWrite, middleware.Validation[quest.UpdateQuestRequest](), questHandler.HandleUpdateQuest)
+	app.Delete("/campaign/:campaignId/quests/:questId", authMiddleware, campaignWrite, questHandler.HandleDeleteQuest)
+	app.Post("/campaign/:campaignId/quests/:questId/reveal", authMiddleware, campaignWrite, questHandler.HandleReveal)
+	app.Post("/campaign/:campaignId/quests/:questId/complete", authMiddleware, campaignWrite, questHandler.HandleComplete)
+	app.Post("/campaign/:campaignId/quests/:questId/fail", authMiddleware, campaignWrite, questHandler.HandleFail)
+	app.Post("/campaign/:campaignId/quests/:questId/objectives", authMiddleware, campaignWrite, middleware.Validation[quest.AddObjectiveRequest](), questHandler.HandleAddObjective)
+	app.Put("/campaign/:campaignId/quests/:questId/objectives/:objectiveId", authMiddleware, campaignWrite, middleware.Validation[quest.UpdateObjectiveRequest](), questHandler.HandleUpdateObjective)
+	app.Delete("/campaign/:campaignId/quests/:questId/objectives/:objectiveId", authMiddleware, campaignWrite, questHandler.HandleRemoveObjective)
+	app.Post("/campaign/:campaignId/wiki", authMiddleware, campaignWrite, middleware.Validation[wiki.CreateEntryRequest](), wikiHandler.HandleCreateEntry)
+	app.Get("/campaign/:campaignId/wiki", authMiddleware, campaignRead, wikiHandler.HandleSearch)
+	app.Get("/wiki/:entryId", authMiddleware, campaignRead, wikiHandler.HandleGetEntry)
+	app.Put("/wiki/:entryId", authMiddleware, campaignWrite, middleware.Validation[wiki.UpdateEntryRequest](), wikiHandler.HandleUpdateEntry)
+	app.Delete("/wiki/:entryId", authMiddleware, campaignWrite, wikiHandler.HandleDeleteEntry)
+	app.Get("/wiki/:entryId/revisions", authMiddleware, campaignRead, wikiHandler.HandleRevisions)
+	app.Post("/campaign/:campaignId/handouts", authMiddleware, campaignWrite, middleware.Validation[handout.CreateHandoutRequest](), handoutHandler.HandleUpload)
+	app.Get("/campaign/:campaignId/handouts", authMiddleware, campaignRead, handoutHandler.HandleGetHandouts)
+	app.Get("/handouts/:handoutId", authMiddleware, campaignRead, handoutHandler.HandleGetHandout)
+	app.Delete("/handouts/:handoutId", authMiddleware, campaignWrite, handoutHandler.HandleDeleteHandout)
+	app.Put("/handouts/:handoutId/reveal", authMiddleware, campaignWrite, middleware.Validation[handout.RevealRequest](), handoutHandler.HandleReveal)
+	app.Delete("/handouts/:handoutId/reveal", authMiddleware, campaignWrite, handoutHandler.HandleHide)
+	app.Get("/handouts/:handoutId/file", authMiddleware, campaignRead, handoutHandler.HandleDownload)
+	app.Post("/campaign/:campaignId/maps", authMiddleware, campaignWrite, middleware.Validation[battlemap.CreateMapRequest](), mapHandler.HandleCreateMap)
+	app.Get("/campaign/:campaignId/maps", authMiddleware, campaignRead, mapHandler.HandleGetMaps)
+	app.Get("/campaign/:campaignId/maps/stream", authMiddleware, campaignRead, mapHandler.HandleStream)
+	app.Get("/maps/:mapId", authMiddleware, campaignRead, mapHandler.HandleGetMap)
+	app.Put("/maps/:mapId", authMiddleware, campaignWrite, middleware.Validation[battlemap.UpdateMapRequest](), mapHandler.HandleUpdateMap)
+	app.Delete("/maps/:mapId", authMiddleware, campaignWrite, mapHandler.HandleDeleteMap)
+	app.Get("/maps/:mapId/image", authMiddleware, campaignRead, mapHandler.HandleImage)
+	app.Post("/maps/:mapId/tokens", authMiddleware, campaignWrite, middleware.Validation[battlemap.PlaceTokenRequest](), mapHandler.HandlePlaceToken)
+	app.Put("/maps/:mapId/tokens/:tokenId", authMiddleware, campaignWrite, middleware.Validation[battlemap.MoveTokenRequest](), mapHandler.HandleMoveToken)
+	app.Delete("/maps/:mapId/tokens/:tokenId", authMiddleware, campaignWrite, mapHandler.HandleRemoveToken)
+	app.Post("/maps/:mapId/fog/reveal", authMiddleware, campaignWrite, middleware.Validation[battlemap.RegionRequest](), mapHandler.HandleReveal)
+	app.Post("/maps/:mapId/fog/hide", authMiddleware, campaignWrite, middleware.Validation[battlemap.RegionRequest](), mapHandler.HandleHide)
 
-	return &FiberApp{app: app}
+	return &FiberApp{
+		app:           app,
+		deletionPurge: account.NewDeletionPurgeFromDeps(accountDeps),
+		sessionPurge:  account.NewSessionPurgeFromDeps(accountDeps),
+	}
 }
 
 func (app *FiberApp) Listen(port string) error {
 	return app.app.Listen(fmt.Sprintf(":%s", port))
 }
+
+// RunJobs runs the background jobs of the app until ctx is done
+func (app *FiberApp) RunJobs(ctx context.Context) {
+	go app.sessionPurge.Run(ctx, sessionPurgeInterval)
+	app.deletionPurge.Run(ctx, deletionPurgeInterval)
+}
@@ -4,15 +4,18 @@ package app
 
 import (
 	"beldur/pkg/auth/jwt"
+	"beldur/pkg/blob"
 	"beldur/pkg/db/postgres"
 	"beldur/pkg/httperr"
 	"beldur/pkg/logger"
+	"beldur/pkg/mail"
 	"bytes"
 	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -97,10 +100,22 @@ func buildFiberApp() {
 
 	transactor, querier := postgres.NewTransactor(testPool)
 
+	blobStore, err := blob.NewLocalStore(filepath.Join(os.TempDir(), "beldur-test-uploads"))
+	if err != nil {
+		panic(err)
+	}
+
+	mailer, err := mail.NewOutbox("")
+	if err != nil {
+		panic(err)
+	}
+
 	fiberApp = NewTest(Deps{
 		JwtService: jwtService,
 		Transactor: transactor,
 		QProvider:  querier,
+		BlobStore:  blobStore,
+		Mailer:     mailer,
 	})
 }
 
@@ -0,0 +1,273 @@
+package battlemap
+
+import (
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"image"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const (
+	MaxNameCharacters = 100
+	// MaxImageSize is the size limit of an uploaded map image, in bytes
+	MaxImageSize = 10 << 20
+	// MaxImagePixels is the size limit of a map image once decoded, to be masked for the
+	// players. The decoded image takes 4 bytes per pixel.
+	MaxImagePixels = 25_000_000
+	MaxGridCells   = 200
+	MinCellSize    = 10
+	MaxCellSize    = 500
+)
+
+var allowedImageTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+}
+
+// Grid splits the map image in square cells of CellSize pixels
+type Grid struct {
+	Columns  int
+	Rows     int
+	CellSize int
+}
+
+func (g Grid) validate() error {
+	if g.Columns < 1 || g.Columns > MaxGridCells || g.Rows < 1 || g.Rows > MaxGridCells {
+		return ErrInvalidGrid
+	}
+	if g.CellSize < MinCellSize || g.CellSize > MaxCellSize {
+		return ErrInvalidGrid
+	}
+	return nil
+}
+
+func (g Grid) contains(x, y int) bool {
+	return x >= 0 && y >= 0 && x < g.Columns && y < g.Rows
+}
+
+// Image is the background of the map, its content lives in the blob store under the key
+type Image struct {
+	key         string
+	contentType string
+	size        int64
+}
+
+// NewImage checks the size, the sniffed content and the dimensions of an uploaded image and
+// picks its blob key
+func NewImage(campaignId id.CampaignId, data []byte) (Image, error) {
+	if len(data) == 0 || len(data) > MaxImageSize {
+		return Image{}, ErrInvalidImageSize
+	}
+	mt := mimetype.Detect(data)
+	if !slices.ContainsFunc(allowedImageTypes, mt.Is) {
+		return Image{}, ErrUnsupportedImageType
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, ErrUnsupportedImageType
+	}
+	if cfg.Width*cfg.Height > MaxImagePixels {
+		return Image{}, ErrImageTooLarge
+	}
+
+	return Image{
+		key:         fmt.Sprintf("campaigns/%d/maps/%s%s", campaignId, rand.Text(), mt.Extension()),
+		contentType: mt.String(),
+		size:        int64(len(data)),
+	}, nil
+}
+
+func (i Image) Key() string { return i.key }
+
+// Token stands for a character on the map, at the cell X, Y
+type Token struct {
+	id          id.TokenId
+	characterId id.CharacterId
+	name        string
+	// player controlling the character, 0 for an NPC
+	ownerId id.PlayerId
+	x       int
+	y       int
+}
+
+// Map is a battle map of a campaign: an image with a grid, the tokens of the characters
+// and the fog of war hiding the regions the master has not revealed yet
+type Map struct {
+	id         id.MapId
+	campaignId id.CampaignId
+	name       string
+	image      Image
+	grid       Grid
+	fog        fog
+	tokens     []*Token
+	createdAt  time.Time
+	updatedAt  time.Time
+}
+
+// New creates a map entirely covered by the fog
+func New(campaignId id.CampaignId, name string, image Image, grid Grid) (*Map, error) {
+	name, err := validateName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := grid.validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &Map{
+		campaignId: campaignId,
+		name:       name,
+		image:      image,
+		grid:       grid,
+		fog:        newFog(grid),
+		tokens:     make([]*Token, 0),
+		createdAt:  now,
+		updatedAt:  now,
+	}, nil
+}
+
+// Edit renames the map and changes its grid. The fog of the cells kept by the new grid
+// is preserved, every token must still fit in it.
+func (m *Map) Edit(name string, grid Grid) error {
+	name, err := validateName(name)
+	if err != nil {
+		return err
+	}
+	if err := grid.validate(); err != nil {
+		return err
+	}
+	for _, t := range m.tokens {
+		if !grid.contains(t.x, t.y) {
+			return ErrTokenOutOfGrid
+		}
+	}
+
+	m.fog = m.fog.resize(m.grid, grid)
+	m.grid = grid
+	m.name = name
+	m.touch()
+	return nil
+}
+
+// PlaceToken puts a character of the campaign on the map, once
+func (m *Map) PlaceToken(c *character.Character, x, y int) (*Token, error) {
+	if c.CampaignId() != m.campaignId {
+		return nil, ErrCharacterNotInCampaign
+	}
+	if slices.ContainsFunc(m.tokens, func(t *Token) bool { return t.characterId == c.Id() }) {
+		return nil, ErrTokenAlreadyPlaced
+	}
+	if !m.grid.contains(x, y) {
+		return nil, ErrTokenOutOfGrid
+	}
+
+	t := &Token{characterId: c.Id(), name: c.Name(), x: x, y: y}
+	if !c.IsNPC() {
+		t.ownerId = c.PlayerId()
+	}
+	m.tokens = append(m.tokens, t)
+	m.touch()
+	return t, nil
+}
+
+// MoveToken moves a token to another cell. The master moves every token, a player only their own.
+func (m *Map) MoveToken(tokenId id.TokenId, x, y int, playerId id.PlayerId, isMaster bool) error {
+	t, err := m.token(tokenId)
+	if err != nil {
+		return err
+	}
+	if !isMaster && (t.ownerId == 0 || t.ownerId != playerId) {
+		return ErrTokenOfAnotherPlayer
+	}
+	if !m.grid.contains(x, y) {
+		return ErrTokenOutOfGrid
+	}
+
+	t.x, t.y = x, y
+	m.touch()
+	return nil
+}
+
+func (m *Map) RemoveToken(tokenId id.TokenId) error {
+	i := slices.IndexFunc(m.tokens, func(t *Token) bool { return t.id == tokenId })
+	if i < 0 {
+		return ErrTokenNotFound
+	}
+	m.tokens = slices.Delete(m.tokens, i, i+1)
+	m.touch()
+	return nil
+}
+
+// Reveal lifts the fog from the region
+func (m *Map) Reveal(r Region) error {
+	if !r.within(m.grid) {
+		return ErrInvalidRegion
+	}
+	m.fog.set(m.grid, r, true)
+	m.touch()
+	return nil
+}
+
+// Hide covers the region with the fog again
+func (m *Map) Hide(r Region) error {
+	if !r.within(m.grid) {
+		return ErrInvalidRegion
+	}
+	m.fog.set(m.grid, r, false)
+	m.touch()
+	return nil
+}
+
+func (m *Map) IsRevealed(x, y int) bool {
+	return m.grid.contains(x, y) && m.fog[y*m.grid.Columns+x]
+}
+
+// TokensFor gives back the tokens the player can see: the master sees them all, a player
+// their own ones and the ones standing on revealed cells
+func (m *Map) TokensFor(playerId id.PlayerId, isMaster bool) []*Token {
+	if isMaster {
+		return m.tokens
+	}
+	visible := make([]*Token, 0, len(m.tokens))
+	for _, t := range m.tokens {
+		if (t.ownerId != 0 && t.ownerId == playerId) || m.IsRevealed(t.x, t.y) {
+			visible = append(visible, t)
+		}
+	}
+	return visible
+}
+
+func (m *Map) Id() id.MapId { return m.id }
+
+func (m *Map) CampaignId() id.CampaignId { return m.campaignId }
+
+func (m *Map) token(tokenId id.TokenId) (*Token, error) {
+	i := slices.IndexFunc(m.tokens, func(t *Token) bool { return t.id == tokenId })
+	if i < 0 {
+		return nil, ErrTokenNotFound
+	}
+	return m.tokens[i], nil
+}
+
+func (m *Map) touch() {
+	m.updatedAt = time.Now().UTC()
+}
+
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxNameCharacters {
+		return "", ErrInvalidName
+	}
+	return name, nil
+}
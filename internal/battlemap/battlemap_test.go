@@ -0,0 +1,134 @@
+package battlemap
+
+import (
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMap creates a 4x3 map with a token of player 1 at 0,0 and an NPC token at 3,2
+func newMap(t *testing.T) *Map {
+	t.Helper()
+	m, err := New(1, "Goblin cave", Image{}, Grid{Columns: 4, Rows: 3, CellSize: 50})
+	require.NoError(t, err)
+	m.tokens = []*Token{
+		{id: 1, characterId: 10, name: "Aria", ownerId: 1, x: 0, y: 0},
+		{id: 2, characterId: 20, name: "Goblin", x: 3, y: 2},
+	}
+	return m
+}
+
+func TestNewImage(t *testing.T) {
+	encode := func(width, height int) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))))
+		return buf.Bytes()
+	}
+
+	img, err := NewImage(1, encode(1, 1))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", img.contentType)
+
+	// players could never download it masked
+	_, err = NewImage(1, encode(MaxImagePixels/1000+1, 1000))
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+	// only the signature is right
+	_, err = NewImage(1, []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01"))
+	assert.ErrorIs(t, err, ErrUnsupportedImageType)
+
+	_, err = NewImage(1, []byte("%PDF-1.7\n"))
+	assert.ErrorIs(t, err, ErrUnsupportedImageType)
+	_, err = NewImage(1, nil)
+	assert.ErrorIs(t, err, ErrInvalidImageSize)
+}
+
+func TestNewInvalidGrid(t *testing.T) {
+	grids := []Grid{
+		{Columns: 0, Rows: 10, CellSize: 50},
+		{Columns: 10, Rows: MaxGridCells + 1, CellSize: 50},
+		{Columns: 10, Rows: 10, CellSize: MinCellSize - 1},
+	}
+	for _, g := range grids {
+		_, err := New(1, "Goblin cave", Image{}, g)
+		assert.ErrorIs(t, err, ErrInvalidGrid)
+	}
+}
+
+func TestFog(t *testing.T) {
+	m := newMap(t)
+	assert.Equal(t, []string{"0000", "0000", "0000"}, m.fog.rows(m.grid))
+
+	require.NoError(t, m.Reveal(Region{X: 1, Y: 0, Width: 3, Height: 2}))
+	require.NoError(t, m.Hide(Region{X: 2, Y: 1, Width: 1, Height: 1}))
+	assert.Equal(t, []string{"0111", "0101", "0000"}, m.fog.rows(m.grid))
+
+	assert.ErrorIs(t, m.Reveal(Region{X: 3, Y: 0, Width: 2, Height: 1}), ErrInvalidRegion)
+	assert.ErrorIs(t, m.Reveal(Region{X: 0, Y: 0, Width: 0, Height: 1}), ErrInvalidRegion)
+
+	assert.Equal(t, m.fog, unpackFog(m.fog.pack(), m.grid))
+}
+
+func TestEditKeepsFog(t *testing.T) {
+	m := newMap(t)
+	require.NoError(t, m.Reveal(Region{X: 0, Y: 0, Width: 4, Height: 3}))
+
+	require.NoError(t, m.Edit("Goblin cave", Grid{Columns: 5, Rows: 3, CellSize: 50}))
+	assert.Equal(t, []string{"11110", "11110", "11110"}, m.fog.rows(m.grid))
+
+	assert.ErrorIs(t, m.Edit("Goblin cave", Grid{Columns: 2, Rows: 2, CellSize: 50}), ErrTokenOutOfGrid)
+}
+
+func TestTokensFor(t *testing.T) {
+	m := newMap(t)
+
+	assert.Len(t, m.TokensFor(2, true), 2)
+
+	own := m.TokensFor(1, false)
+	require.Len(t, own, 1)
+	assert.Equal(t, id.TokenId(1), own[0].id)
+	assert.Empty(t, m.TokensFor(2, false))
+
+	require.NoError(t, m.Reveal(Region{X: 3, Y: 2, Width: 1, Height: 1}))
+	assert.Len(t, m.TokensFor(1, false), 2)
+	assert.Len(t, m.TokensFor(2, false), 1)
+}
+
+func TestMoveToken(t *testing.T) {
+	m := newMap(t)
+
+	require.NoError(t, m.MoveToken(1, 1, 1, 1, false))
+	assert.Equal(t, 1, m.tokens[0].x)
+
+	assert.ErrorIs(t, m.MoveToken(1, 2, 2, 2, false), ErrTokenOfAnotherPlayer)
+	assert.ErrorIs(t, m.MoveToken(2, 2, 2, 1, false), ErrTokenOfAnotherPlayer)
+	require.NoError(t, m.MoveToken(2, 2, 2, 2, true))
+
+	assert.ErrorIs(t, m.MoveToken(1, 4, 0, 1, false), ErrTokenOutOfGrid)
+	assert.ErrorIs(t, m.MoveToken(9, 0, 0, 1, true), ErrTokenNotFound)
+}
+
+func TestPlaceToken(t *testing.T) {
+	c := character.New("Borin", "")
+
+	m := newMap(t)
+	_, err := m.PlaceToken(c, 0, 0)
+	assert.ErrorIs(t, err, ErrCharacterNotInCampaign)
+
+	m, err = New(c.CampaignId(), "Goblin cave", Image{}, Grid{Columns: 4, Rows: 3, CellSize: 50})
+	require.NoError(t, err)
+	_, err = m.PlaceToken(c, 4, 0)
+	assert.ErrorIs(t, err, ErrTokenOutOfGrid)
+	_, err = m.PlaceToken(c, 1, 2)
+	require.NoError(t, err)
+	_, err = m.PlaceToken(c, 2, 2)
+	assert.ErrorIs(t, err, ErrTokenAlreadyPlaced)
+
+	require.NoError(t, m.RemoveToken(0))
+	assert.Empty(t, m.tokens)
+}
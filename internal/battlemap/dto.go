@@ -0,0 +1,90 @@
+package battlemap
+
+import (
+	"io"
+	"time"
+)
+
+// CreateMapRequest is sent as multipart form, along with the image in the "image" field
+type CreateMapRequest struct {
+	Name     string `json:"name" form:"name" validate:"required,max=100"`
+	Columns  int    `json:"columns" form:"columns" validate:"required,min=1,max=200"`
+	Rows     int    `json:"rows" form:"rows" validate:"required,min=1,max=200"`
+	CellSize int    `json:"cell_size" form:"cell_size" validate:"required,min=10,max=500"`
+}
+
+type UpdateMapRequest struct {
+	Name string  `json:"name" validate:"required,max=100"`
+	Grid GridDto `json:"grid"`
+}
+
+type GridDto struct {
+	Columns  int `json:"columns" validate:"required,min=1,max=200"`
+	Rows     int `json:"rows" validate:"required,min=1,max=200"`
+	CellSize int `json:"cell_size" validate:"required,min=10,max=500"`
+}
+
+type PlaceTokenRequest struct {
+	CharacterId int `json:"character_id" validate:"required"`
+	X           int `json:"x" validate:"min=0"`
+	Y           int `json:"y" validate:"min=0"`
+}
+
+type MoveTokenRequest struct {
+	X int `json:"x" validate:"min=0"`
+	Y int `json:"y" validate:"min=0"`
+}
+
+// RegionRequest is a rectangle of cells, X and Y being its top left cell
+type RegionRequest struct {
+	X      int `json:"x" validate:"min=0"`
+	Y      int `json:"y" validate:"min=0"`
+	Width  int `json:"width" validate:"required,min=1"`
+	Height int `json:"height" validate:"required,min=1"`
+}
+
+// MapResponse is the map as seen by a player: the fog is the same for everyone,
+// the tokens under the fog are left out for the players
+type MapResponse struct {
+	Id         int     `json:"id"`
+	CampaignId int     `json:"campaign_id"`
+	Name       string  `json:"name"`
+	Grid       GridDto `json:"grid"`
+	ImageUrl   string  `json:"image_url"`
+	// a string per row, '1' for a revealed cell and '0' for a hidden one
+	Fog       []string        `json:"fog"`
+	Tokens    []TokenResponse `json:"tokens"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type TokenResponse struct {
+	Id          int    `json:"id"`
+	CharacterId int    `json:"character_id"`
+	Name        string `json:"name"`
+	// nil for an NPC
+	OwnerId *int `json:"owner_id"`
+	X       int  `json:"x"`
+	Y       int  `json:"y"`
+}
+
+type MapSummary struct {
+	Id        int       `json:"id"`
+	Name      string    `json:"name"`
+	Grid      GridDto   `json:"grid"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MapDeletedEvent is delivered live when the master deletes a map
+type MapDeletedEvent struct {
+	Id int `json:"id"`
+}
+
+// ImageDownload is the content of a map image, to be closed by the caller
+type ImageDownload struct {
+	ContentType string
+	Size        int64
+	Content     io.ReadCloser
+	// the hidden cells are painted over, the image changes along with the fog
+	Masked bool
+}
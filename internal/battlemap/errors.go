@@ -0,0 +1,138 @@
+package battlemap
+
+import (
+	"beldur/pkg/httperr"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidName          = errors.New("map name must be between 1 and 100 characters")
+	ErrInvalidGrid          = errors.New("map grid must have between 1 and 200 columns and rows, with cells between 10 and 500 pixels")
+	ErrInvalidImageSize     = errors.New("map image must be between 1 byte and 10 MiB")
+	ErrUnsupportedImageType = errors.New("map image must be a PNG, JPEG, GIF or WebP image")
+	ErrImageTooLarge        = errors.New("map image must be at most 25 million pixels")
+	ErrInvalidRegion        = errors.New("region must be a non empty rectangle within the grid")
+	ErrTokenOutOfGrid       = errors.New("token must be within the grid")
+	ErrTokenAlreadyPlaced   = errors.New("character already has a token on the map")
+)
+
+var (
+	ErrMapNotFound              = errors.New("map not found")
+	ErrTokenNotFound            = errors.New("token not found")
+	ErrImageNotFound            = errors.New("map image not found")
+	ErrCharacterNotFound        = errors.New("character not found")
+	ErrCharacterNotInCampaign   = errors.New("character is not in the campaign of the map")
+	ErrCampaignNotFound         = errors.New("campaign not found")
+	ErrCampaignHasAnotherMaster = errors.New("campaign has another master")
+	ErrPlayerNotInCampaign      = errors.New("player is not in the campaign")
+	ErrTokenOfAnotherPlayer     = errors.New("token can be moved only by its player or the master")
+)
+
+func NewBattleMapApiErrorManager() *httperr.Manager {
+	mng := httperr.NewManager()
+
+	mng.Add(ErrInvalidName, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_name",
+		Message: ErrInvalidName.Error(),
+	})
+
+	mng.Add(ErrInvalidGrid, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_grid",
+		Message: ErrInvalidGrid.Error(),
+	})
+
+	mng.Add(ErrInvalidImageSize, httperr.Mapped{
+		Status:  http.StatusRequestEntityTooLarge,
+		Code:    "invalid_image_size",
+		Message: ErrInvalidImageSize.Error(),
+	})
+
+	mng.Add(ErrUnsupportedImageType, httperr.Mapped{
+		Status:  http.StatusUnsupportedMediaType,
+		Code:    "unsupported_image_type",
+		Message: ErrUnsupportedImageType.Error(),
+	})
+
+	mng.Add(ErrImageTooLarge, httperr.Mapped{
+		Status:  http.StatusRequestEntityTooLarge,
+		Code:    "image_too_large",
+		Message: ErrImageTooLarge.Error(),
+	})
+
+	mng.Add(ErrInvalidRegion, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_region",
+		Message: ErrInvalidRegion.Error(),
+	})
+
+	mng.Add(ErrTokenOutOfGrid, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "token_out_of_grid",
+		Message: ErrTokenOutOfGrid.Error(),
+	})
+
+	mng.Add(ErrTokenAlreadyPlaced, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "token_already_placed",
+		Message: ErrTokenAlreadyPlaced.Error(),
+	})
+
+	mng.Add(ErrMapNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "map_not_found",
+		Message: ErrMapNotFound.Error(),
+	})
+
+	mng.Add(ErrTokenNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "token_not_found",
+		Message: ErrTokenNotFound.Error(),
+	})
+
+	mng.Add(ErrImageNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "image_not_found",
+		Message: ErrImageNotFound.Error(),
+	})
+
+	mng.Add(ErrCharacterNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "character_not_found",
+		Message: ErrCharacterNotFound.Error(),
+	})
+
+	mng.Add(ErrCharacterNotInCampaign, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "character_not_in_campaign",
+		Message: ErrCharacterNotInCampaign.Error(),
+	})
+
+	mng.Add(ErrCampaignNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "campaign_not_found",
+		Message: ErrCampaignNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignHasAnotherMaster, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "campaign_has_another_master",
+		Message: ErrCampaignHasAnotherMaster.Error(),
+	})
+
+	mng.Add(ErrPlayerNotInCampaign, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "player_not_in_campaign",
+		Message: ErrPlayerNotInCampaign.Error(),
+	})
+
+	mng.Add(ErrTokenOfAnotherPlayer, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "token_of_another_player",
+		Message: ErrTokenOfAnotherPlayer.Error(),
+	})
+
+	return mng
+}
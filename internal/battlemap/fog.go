@@ -0,0 +1,83 @@
+package battlemap
+
+import "strings"
+
+// Region is a rectangle of cells of the grid, X and Y being its top left cell
+type Region struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+func (r Region) within(g Grid) bool {
+	return r.Width > 0 && r.Height > 0 &&
+		r.X >= 0 && r.Y >= 0 &&
+		r.X+r.Width <= g.Columns && r.Y+r.Height <= g.Rows
+}
+
+// fog keeps a flag per cell of the grid, row by row: true when the cell is revealed to the players
+type fog []bool
+
+func newFog(g Grid) fog {
+	return make(fog, g.Columns*g.Rows)
+}
+
+func (f fog) set(g Grid, r Region, revealed bool) {
+	for y := r.Y; y < r.Y+r.Height; y++ {
+		for x := r.X; x < r.X+r.Width; x++ {
+			f[y*g.Columns+x] = revealed
+		}
+	}
+}
+
+// resize keeps the cells shared by the old and the new grid, the new ones are hidden
+func (f fog) resize(from, to Grid) fog {
+	resized := newFog(to)
+	for y := 0; y < min(from.Rows, to.Rows); y++ {
+		for x := 0; x < min(from.Columns, to.Columns); x++ {
+			resized[y*to.Columns+x] = f[y*from.Columns+x]
+		}
+	}
+	return resized
+}
+
+// rows renders the fog as a string per row, '1' for a revealed cell and '0' for a hidden one
+func (f fog) rows(g Grid) []string {
+	rows := make([]string, g.Rows)
+	var b strings.Builder
+	for y := range g.Rows {
+		b.Reset()
+		for x := range g.Columns {
+			if f[y*g.Columns+x] {
+				b.WriteByte('1')
+			} else {
+				b.WriteByte('0')
+			}
+		}
+		rows[y] = b.String()
+	}
+	return rows
+}
+
+// pack stores a cell per bit
+func (f fog) pack() []byte {
+	data := make([]byte, (len(f)+7)/8)
+	for i, revealed := range f {
+		if revealed {
+			data[i/8] |= 1 << (i % 8)
+		}
+	}
+	return data
+}
+
+// unpackFog reads the cells stored by pack, missing bits are hidden cells
+func unpackFog(data []byte, g Grid) fog {
+	f := newFog(g)
+	for i := range f {
+		if i/8 < len(data) {
+			f[i] = data[i/8]&(1<<(i%8)) != 0
+		}
+	}
+	return f
+}
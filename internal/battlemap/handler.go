@@ -0,0 +1,336 @@
+package battlemap
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/httperr"
+	"beldur/pkg/live"
+	"beldur/pkg/middleware"
+	"io"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpHandler struct {
+	mapUC      *UseCase
+	errManager *httperr.Manager
+}
+
+func NewHttpHandler(mapUC *UseCase) *HttpHandler {
+	return &HttpHandler{
+		mapUC:      mapUC,
+		errManager: NewBattleMapApiErrorManager(),
+	}
+}
+
+// HandleCreateMap reads the multipart form with the name, the grid and the image
+func (h *HttpHandler) HandleCreateMap(c *fiber.Ctx) error {
+	campaignId, err := campaignIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(CreateMapRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	fh, err := c.FormFile("image")
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	if fh.Size > MaxImageSize {
+		status, body := h.errManager.Map(ErrInvalidImageSize)
+		return c.Status(status).JSON(body)
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, MaxImageSize+1))
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	resp, err := h.mapUC.Create(c.Context(), req, data, campaignId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetMaps(c *fiber.Ctx) error {
+	campaignId, err := campaignIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.mapUC.List(c.Context(), campaignId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// HandleStream delivers the changes of the maps of the campaign as Server-Sent Events
+func (h *HttpHandler) HandleStream(c *fiber.Ctx) error {
+	campaignId, err := campaignIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	events, unsubscribe, err := h.mapUC.Subscribe(c.Context(), campaignId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return live.Stream(c, events, unsubscribe)
+}
+
+func (h *HttpHandler) HandleGetMap(c *fiber.Ctx) error {
+	mapId, err := mapIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.mapUC.Get(c.Context(), mapId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleUpdateMap(c *fiber.Ctx) error {
+	mapId, err := mapIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(UpdateMapRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.mapUC.Update(c.Context(), req, mapId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleDeleteMap(c *fiber.Ctx) error {
+	mapId, err := mapIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.mapUC.Delete(c.Context(), mapId, p.PlayerID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *HttpHandler) HandleImage(c *fiber.Ctx) error {
+	mapId, err := mapIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	img, err := h.mapUC.Image(c.Context(), mapId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+
+	c.Set(fiber.HeaderContentType, img.ContentType)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	if img.Masked {
+		c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	} else {
+		c.Set(fiber.HeaderCacheControl, "private, max-age=3600")
+	}
+	// the stream is closed once sent
+	return c.SendStream(img.Content, int(img.Size))
+}
+
+func (h *HttpHandler) HandlePlaceToken(c *fiber.Ctx) error {
+	mapId, err := mapIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(PlaceTokenRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.mapUC.PlaceToken(c.Context(), req, mapId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *HttpHandler) HandleMoveToken(c *fiber.Ctx) error {
+	mapId, err := mapIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	tokenId, err := tokenIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(MoveTokenRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.mapUC.MoveToken(c.Context(), req, mapId, tokenId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleRemoveToken(c *fiber.Ctx) error {
+	mapId, err := mapIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	tokenId, err := tokenIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.mapUC.RemoveToken(c.Context(), mapId, tokenId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleReveal(c *fiber.Ctx) error {
+	mapId, err := mapIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(RegionRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.mapUC.Reveal(c.Context(), req, mapId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleHide(c *fiber.Ctx) error {
+	mapId, err := mapIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(RegionRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.mapUC.Hide(c.Context(), req, mapId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func campaignIdParam(c *fiber.Ctx) (id.CampaignId, error) {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.CampaignId(campaignId), nil
+}
+
+func mapIdParam(c *fiber.Ctx) (id.MapId, error) {
+	mapInstr := c.Params("mapId")
+	if mapInstr == "" {
+		panic("wrong parameter naming")
+	}
+	mapId, err := strconv.Atoi(mapInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.MapId(mapId), nil
+}
+
+func tokenIdParam(c *fiber.Ctx) (id.TokenId, error) {
+	tokenInstr := c.Params("tokenId")
+	if tokenInstr == "" {
+		panic("wrong parameter naming")
+	}
+	tokenId, err := strconv.Atoi(tokenInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.TokenId(tokenId), nil
+}
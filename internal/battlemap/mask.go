@@ -0,0 +1,124 @@
+package battlemap
+
+import (
+	"beldur/internal/id"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"sync"
+
+	_ "golang.org/x/image/webp"
+)
+
+// maskColor paints the hidden cells
+var maskColor = image.NewUniform(color.Black)
+
+// maskImage decodes the image of the map and keeps only the cells revealed from the fog,
+// the rest of the image is painted over so that the players cannot see it by downloading
+// the image. It gives back a PNG.
+func (m *Map) maskImage(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxImageSize))
+	if err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	// everything starts hidden, the image outside the grid too, and only the revealed
+	// cells are copied
+	bounds := src.Bounds()
+	dst := image.NewRGBA(bounds)
+	draw.Draw(dst, bounds, maskColor, image.Point{}, draw.Src)
+
+	size := m.grid.CellSize
+	for y := range m.grid.Rows {
+		for x := range m.grid.Columns {
+			if !m.fog[y*m.grid.Columns+x] {
+				continue
+			}
+			cell := image.Rect(x*size, y*size, (x+1)*size, (y+1)*size).Add(bounds.Min).Intersect(bounds)
+			draw.Draw(dst, cell, src, cell.Min, draw.Src)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// maxCachedMaskBytes is the size limit of the masked images kept in memory
+const maxCachedMaskBytes = 256 << 20
+
+// maskKey changes with everything the masked image depends on: the image, the grid and the fog
+func (m *Map) maskKey() string {
+	return fmt.Sprintf("%s|%dx%dx%d|%x", m.image.key, m.grid.Columns, m.grid.Rows, m.grid.CellSize, m.fog.pack())
+}
+
+type maskedImage struct {
+	key  string
+	data []byte
+}
+
+// maskCache keeps the last masked image of each map, so that the players downloading the
+// map don't decode and encode it again until the fog changes
+type maskCache struct {
+	mu     sync.Mutex
+	images map[id.MapId]maskedImage
+	size   int
+}
+
+func newMaskCache() *maskCache {
+	return &maskCache{images: make(map[id.MapId]maskedImage)}
+}
+
+func (c *maskCache) get(mapId id.MapId, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	img, ok := c.images[mapId]
+	if !ok || img.key != key {
+		return nil, false
+	}
+	return img.data, true
+}
+
+// put replaces the masked image of the map, other maps are evicted while the cache is too big
+func (c *maskCache) put(mapId id.MapId, key string, data []byte) {
+	if len(data) > maxCachedMaskBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(mapId)
+	for other := range c.images {
+		if c.size+len(data) <= maxCachedMaskBytes {
+			break
+		}
+		c.remove(other)
+	}
+	c.images[mapId] = maskedImage{key: key, data: data}
+	c.size += len(data)
+}
+
+func (c *maskCache) remove(mapId id.MapId) {
+	if img, ok := c.images[mapId]; ok {
+		c.size -= len(img.data)
+		delete(c.images, mapId)
+	}
+}
@@ -0,0 +1,64 @@
+package battlemap
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskImage(t *testing.T) {
+	// 4x3 cells of 50 pixels, the image is a bit narrower than the grid and goes below it
+	src := image.NewRGBA(image.Rect(0, 0, 190, 180))
+	for y := range 180 {
+		for x := range 190 {
+			src.Set(x, y, color.White)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, src))
+
+	m := newMap(t)
+	require.NoError(t, m.Reveal(Region{X: 1, Y: 0, Width: 3, Height: 1}))
+
+	masked, err := m.maskImage(&buf)
+	require.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(masked))
+	require.NoError(t, err)
+	assert.Equal(t, src.Bounds(), img.Bounds())
+
+	black := color.RGBA{A: 0xff}
+	white := color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	assert.Equal(t, black, color.RGBAModel.Convert(img.At(25, 25)))
+	assert.Equal(t, white, color.RGBAModel.Convert(img.At(75, 25)))
+	assert.Equal(t, white, color.RGBAModel.Convert(img.At(185, 49)))
+	assert.Equal(t, black, color.RGBAModel.Convert(img.At(75, 50)))
+	assert.Equal(t, black, color.RGBAModel.Convert(img.At(185, 149)))
+	// below the grid
+	assert.Equal(t, black, color.RGBAModel.Convert(img.At(75, 170)))
+
+	_, err = m.maskImage(bytes.NewReader([]byte("%PDF-1.7\n")))
+	assert.Error(t, err)
+}
+
+func TestMaskCache(t *testing.T) {
+	m := newMap(t)
+	c := newMaskCache()
+
+	c.put(m.id, m.maskKey(), []byte("masked"))
+	data, ok := c.get(m.id, m.maskKey())
+	require.True(t, ok)
+	assert.Equal(t, []byte("masked"), data)
+
+	// revealing a region changes the key, the image is masked again
+	require.NoError(t, m.Reveal(Region{X: 0, Y: 0, Width: 1, Height: 1}))
+	_, ok = c.get(m.id, m.maskKey())
+	assert.False(t, ok)
+
+	c.put(m.id, m.maskKey(), []byte("revealed"))
+	assert.Equal(t, len("revealed"), c.size)
+}
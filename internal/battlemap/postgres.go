@@ -0,0 +1,254 @@
+package battlemap
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresRepository struct {
+	q postgres.QuerierProvider
+}
+
+func NewPostgresRepository(q postgres.QuerierProvider) *PostgresRepository {
+	return &PostgresRepository{q: q}
+}
+
+const selectMap = `
+	SELECT
+	    map_id,
+	    campaign_id,
+	    name,
+	    image_key,
+	    content_type,
+	    size,
+	    columns,
+	    rows,
+	    cell_size,
+	    fog,
+	    created_at,
+	    updated_at
+	FROM battle_maps
+`
+
+func (p *PostgresRepository) Save(ctx context.Context, m *Map) error {
+	const query = `
+		INSERT INTO battle_maps (campaign_id, name, image_key, content_type, size, columns, rows, cell_size, fog, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING map_id
+	`
+
+	var mapID int
+	if err := p.q(ctx).QueryRow(ctx, query,
+		int(m.campaignId),
+		m.name,
+		m.image.key,
+		m.image.contentType,
+		m.image.size,
+		m.grid.Columns,
+		m.grid.Rows,
+		m.grid.CellSize,
+		m.fog.pack(),
+		m.createdAt,
+		m.updatedAt,
+	).Scan(&mapID); err != nil {
+		return err
+	}
+	m.id = id.MapId(mapID)
+	return p.saveTokens(ctx, m)
+}
+
+func (p *PostgresRepository) Update(ctx context.Context, m *Map) error {
+	const query = `
+		UPDATE battle_maps
+		SET name = $1,
+		    columns = $2,
+		    rows = $3,
+		    cell_size = $4,
+		    fog = $5,
+		    updated_at = $6
+		WHERE map_id = $7
+	`
+
+	cmd, err := p.q(ctx).Exec(ctx, query,
+		m.name,
+		m.grid.Columns,
+		m.grid.Rows,
+		m.grid.CellSize,
+		m.fog.pack(),
+		m.updatedAt,
+		int(m.id),
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return p.saveTokens(ctx, m)
+}
+
+func (p *PostgresRepository) Delete(ctx context.Context, mapId id.MapId) error {
+	const query = `DELETE FROM battle_maps WHERE map_id = $1`
+
+	cmd, err := p.q(ctx).Exec(ctx, query, int(mapId))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (p *PostgresRepository) FindById(ctx context.Context, mapId id.MapId) (*Map, error) {
+	const query = selectMap + `WHERE map_id = $1`
+
+	m, err := scanMap(p.q(ctx).QueryRow(ctx, query, int(mapId)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+	if err := p.loadTokens(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FindByCampaign does not load the tokens of the maps
+func (p *PostgresRepository) FindByCampaign(ctx context.Context, campaignId id.CampaignId) ([]*Map, error) {
+	const query = selectMap + `
+		WHERE campaign_id = $1
+		ORDER BY updated_at DESC, map_id DESC
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(campaignId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	maps := make([]*Map, 0)
+	for rows.Next() {
+		m, err := scanMap(rows)
+		if err != nil {
+			return nil, err
+		}
+		maps = append(maps, m)
+	}
+	return maps, rows.Err()
+}
+
+// saveTokens removes the tokens no longer on the map, moves the other ones and inserts the new ones
+func (p *PostgresRepository) saveTokens(ctx context.Context, m *Map) error {
+	const sqlDelete = `DELETE FROM battle_map_tokens WHERE map_id = $1 AND NOT token_id = ANY($2)`
+	const sqlUpdate = `UPDATE battle_map_tokens SET x = $1, y = $2 WHERE token_id = $3`
+	const sqlInsert = `
+		INSERT INTO battle_map_tokens (map_id, character_id, owner_id, x, y)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING token_id
+	`
+
+	kept := make([]int, 0, len(m.tokens))
+	for _, t := range m.tokens {
+		if t.id != 0 {
+			kept = append(kept, int(t.id))
+		}
+	}
+	if _, err := p.q(ctx).Exec(ctx, sqlDelete, int(m.id), kept); err != nil {
+		return err
+	}
+
+	for _, t := range m.tokens {
+		if t.id != 0 {
+			if _, err := p.q(ctx).Exec(ctx, sqlUpdate, t.x, t.y, int(t.id)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var ownerID *int
+		if t.ownerId != 0 {
+			owner := int(t.ownerId)
+			ownerID = &owner
+		}
+		var tokenID int
+		if err := p.q(ctx).QueryRow(ctx, sqlInsert, int(m.id), int(t.characterId), ownerID, t.x, t.y).Scan(&tokenID); err != nil {
+			return err
+		}
+		t.id = id.TokenId(tokenID)
+	}
+	return nil
+}
+
+func (p *PostgresRepository) loadTokens(ctx context.Context, m *Map) error {
+	const query = `
+		SELECT t.token_id, t.character_id, c.name, t.owner_id, t.x, t.y
+		FROM battle_map_tokens t
+		JOIN characters c ON c.character_id = t.character_id
+		WHERE t.map_id = $1
+		ORDER BY t.token_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(m.id))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	m.tokens = make([]*Token, 0)
+	for rows.Next() {
+		var (
+			t           Token
+			tokenID     int
+			characterID int
+			ownerID     *int
+		)
+		if err := rows.Scan(&tokenID, &characterID, &t.name, &ownerID, &t.x, &t.y); err != nil {
+			return err
+		}
+		t.id = id.TokenId(tokenID)
+		t.characterId = id.CharacterId(characterID)
+		if ownerID != nil {
+			t.ownerId = id.PlayerId(*ownerID)
+		}
+		m.tokens = append(m.tokens, &t)
+	}
+	return rows.Err()
+}
+
+func scanMap(row pgx.Row) (*Map, error) {
+	var (
+		m          Map
+		mapID      int
+		campaignID int
+		fogData    []byte
+	)
+	if err := row.Scan(
+		&mapID,
+		&campaignID,
+		&m.name,
+		&m.image.key,
+		&m.image.contentType,
+		&m.image.size,
+		&m.grid.Columns,
+		&m.grid.Rows,
+		&m.grid.CellSize,
+		&fogData,
+		&m.createdAt,
+		&m.updatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	m.id = id.MapId(mapID)
+	m.campaignId = id.CampaignId(campaignID)
+	m.fog = unpackFog(fogData, m.grid)
+	m.tokens = make([]*Token, 0)
+	return &m, nil
+}
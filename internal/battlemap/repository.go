@@ -0,0 +1,37 @@
+package battlemap
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"context"
+	"io"
+)
+
+type Saver interface {
+	Save(ctx context.Context, m *Map) error
+	// Update saves the map with its fog and its tokens, giving an id to the new tokens
+	Update(ctx context.Context, m *Map) error
+	Delete(ctx context.Context, mapId id.MapId) error
+}
+
+type Finder interface {
+	FindById(ctx context.Context, mapId id.MapId) (*Map, error)
+	// FindByCampaign gives back the maps of the campaign, most recently updated first
+	FindByCampaign(ctx context.Context, campaignId id.CampaignId) ([]*Map, error)
+}
+
+// BlobStore keeps the map images. Open gives back blob.ErrNotFound for a missing key.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type CampaignFinder interface {
+	FindById(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error)
+}
+
+type CharacterFinder interface {
+	FindById(ctx context.Context, characterId id.CharacterId) (*character.Character, error)
+}
@@ -0,0 +1,449 @@
+package battlemap
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"beldur/pkg/blob"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dto"
+	"beldur/pkg/live"
+	"beldur/pkg/logger"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	EventMapUpdated = "map_updated"
+	EventMapDeleted = "map_deleted"
+)
+
+// Publisher delivers the events live to the connected players
+type Publisher interface {
+	Publish(topic string, event live.Event, to func(playerId id.PlayerId) bool)
+}
+
+// Subscriber connects a player to the live events
+type Subscriber interface {
+	Subscribe(topic string, playerId id.PlayerId) (<-chan live.Event, func())
+}
+
+type UseCase struct {
+	mapSaver        Saver
+	mapFinder       Finder
+	blobStore       BlobStore
+	campaignFinder  CampaignFinder
+	characterFinder CharacterFinder
+	publisher       Publisher
+	subscriber      Subscriber
+	tx              tx.Transactor
+	masks           *maskCache
+}
+
+func NewUseCase(
+	mapSaver Saver,
+	mapFinder Finder,
+	blobStore BlobStore,
+	campaignFinder CampaignFinder,
+	characterFinder CharacterFinder,
+	publisher Publisher,
+	subscriber Subscriber,
+	tx tx.Transactor,
+) *UseCase {
+	return &UseCase{
+		mapSaver:        mapSaver,
+		mapFinder:       mapFinder,
+		blobStore:       blobStore,
+		campaignFinder:  campaignFinder,
+		characterFinder: characterFinder,
+		publisher:       publisher,
+		subscriber:      subscriber,
+		tx:              tx,
+		masks:           newMaskCache(),
+	}
+}
+
+// Create stores the image and creates a map covered by the fog. Only the master of the campaign can do it.
+func (uc *UseCase) Create(ctx context.Context, req CreateMapRequest, data []byte, campaignId id.CampaignId, masterId id.PlayerId) (MapResponse, error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return MapResponse{}, err
+	}
+	if !camp.IsMaster(masterId) {
+		return MapResponse{}, ErrCampaignHasAnotherMaster
+	}
+
+	image, err := NewImage(campaignId, data)
+	if err != nil {
+		return MapResponse{}, err
+	}
+	m, err := New(campaignId, req.Name, image, Grid{Columns: req.Columns, Rows: req.Rows, CellSize: req.CellSize})
+	if err != nil {
+		return MapResponse{}, err
+	}
+
+	if err := uc.blobStore.Put(ctx, image.key, bytes.NewReader(data)); err != nil {
+		logger.Debug("failed to store map image", "campaign_id", campaignId, "error", err)
+		return MapResponse{}, err
+	}
+
+	err = uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		return uc.mapSaver.Save(ctx, m)
+	})
+	if err != nil {
+		logger.Debug("failed to save map", "campaign_id", campaignId, "error", err)
+		uc.deleteImage(ctx, image.key)
+		return MapResponse{}, err
+	}
+
+	uc.publish(m, camp)
+	return toMapResponse(m, masterId, true), nil
+}
+
+// List gives back the maps of the campaign to its players
+func (uc *UseCase) List(ctx context.Context, campaignId id.CampaignId, playerId id.PlayerId) (dto.ListResponse[MapSummary], error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return dto.ListResponse[MapSummary]{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return dto.ListResponse[MapSummary]{}, ErrPlayerNotInCampaign
+	}
+
+	maps, err := uc.mapFinder.FindByCampaign(ctx, campaignId)
+	if err != nil {
+		logger.Debug("failed to find maps", "campaign_id", campaignId, "error", err)
+		return dto.ListResponse[MapSummary]{}, err
+	}
+
+	list := make([]MapSummary, len(maps))
+	for i, m := range maps {
+		list[i] = MapSummary{
+			Id:        int(m.id),
+			Name:      m.name,
+			Grid:      toGridDto(m.grid),
+			UpdatedAt: m.updatedAt,
+		}
+	}
+	return dto.ListResponse[MapSummary]{Data: list}, nil
+}
+
+// Get gives back the map as seen by the player
+func (uc *UseCase) Get(ctx context.Context, mapId id.MapId, playerId id.PlayerId) (MapResponse, error) {
+	m, camp, err := uc.findMap(ctx, mapId)
+	if err != nil {
+		return MapResponse{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return MapResponse{}, ErrPlayerNotInCampaign
+	}
+	return toMapResponse(m, playerId, camp.IsMaster(playerId)), nil
+}
+
+// Update renames the map and changes its grid. Only the master of the campaign can do it.
+func (uc *UseCase) Update(ctx context.Context, req UpdateMapRequest, mapId id.MapId, masterId id.PlayerId) (MapResponse, error) {
+	return uc.modify(ctx, mapId, masterId, func(m *Map, camp *campaign.Campaign) error {
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		return m.Edit(req.Name, Grid{Columns: req.Grid.Columns, Rows: req.Grid.Rows, CellSize: req.Grid.CellSize})
+	})
+}
+
+// PlaceToken puts a character of the campaign on the map. Only the master of the campaign can do it.
+func (uc *UseCase) PlaceToken(ctx context.Context, req PlaceTokenRequest, mapId id.MapId, masterId id.PlayerId) (MapResponse, error) {
+	return uc.modify(ctx, mapId, masterId, func(m *Map, camp *campaign.Campaign) error {
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		c, err := uc.characterFinder.FindById(ctx, id.CharacterId(req.CharacterId))
+		if err != nil {
+			logger.Debug("no character found", "character_id", req.CharacterId)
+			return errors.Join(ErrCharacterNotFound, err)
+		}
+		_, err = m.PlaceToken(c, req.X, req.Y)
+		return err
+	})
+}
+
+// MoveToken moves a token of the map. The master moves every token, a player only their own ones.
+func (uc *UseCase) MoveToken(ctx context.Context, req MoveTokenRequest, mapId id.MapId, tokenId id.TokenId, playerId id.PlayerId) (MapResponse, error) {
+	return uc.modify(ctx, mapId, playerId, func(m *Map, camp *campaign.Campaign) error {
+		return m.MoveToken(tokenId, req.X, req.Y, playerId, camp.IsMaster(playerId))
+	})
+}
+
+// RemoveToken takes a token off the map. Only the master of the campaign can do it.
+func (uc *UseCase) RemoveToken(ctx context.Context, mapId id.MapId, tokenId id.TokenId, masterId id.PlayerId) (MapResponse, error) {
+	return uc.modify(ctx, mapId, masterId, func(m *Map, camp *campaign.Campaign) error {
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		return m.RemoveToken(tokenId)
+	})
+}
+
+// Reveal lifts the fog from the region. Only the master of the campaign can do it.
+func (uc *UseCase) Reveal(ctx context.Context, req RegionRequest, mapId id.MapId, masterId id.PlayerId) (MapResponse, error) {
+	return uc.modify(ctx, mapId, masterId, func(m *Map, camp *campaign.Campaign) error {
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		return m.Reveal(toRegion(req))
+	})
+}
+
+// Hide covers the region with the fog again. Only the master of the campaign can do it.
+func (uc *UseCase) Hide(ctx context.Context, req RegionRequest, mapId id.MapId, masterId id.PlayerId) (MapResponse, error) {
+	return uc.modify(ctx, mapId, masterId, func(m *Map, camp *campaign.Campaign) error {
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		return m.Hide(toRegion(req))
+	})
+}
+
+// Delete removes the map and its image. Only the master of the campaign can do it.
+func (uc *UseCase) Delete(ctx context.Context, mapId id.MapId, masterId id.PlayerId) error {
+	var (
+		m    *Map
+		camp *campaign.Campaign
+	)
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		m, camp, err = uc.findMap(ctx, mapId)
+		if err != nil {
+			return err
+		}
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		if err := uc.mapSaver.Delete(ctx, mapId); err != nil {
+			logger.Debug("failed to delete map", "map_id", mapId, "error", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the image goes only once the map is gone, a leftover image is harmless
+	uc.deleteImage(ctx, m.image.key)
+	uc.publisher.Publish(topic(camp.Id()), live.Event{Type: EventMapDeleted, Data: MapDeletedEvent{Id: int(mapId)}}, nil)
+	return nil
+}
+
+// Image opens the image of the map for a player of the campaign. The master gets the image
+// as uploaded, the players get it with the hidden cells painted over.
+func (uc *UseCase) Image(ctx context.Context, mapId id.MapId, playerId id.PlayerId) (ImageDownload, error) {
+	m, camp, err := uc.findMap(ctx, mapId)
+	if err != nil {
+		return ImageDownload{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return ImageDownload{}, ErrPlayerNotInCampaign
+	}
+
+	if !camp.IsMaster(playerId) {
+		masked, err := uc.maskedImage(ctx, m)
+		if err != nil {
+			return ImageDownload{}, err
+		}
+		return ImageDownload{
+			ContentType: "image/png",
+			Size:        int64(len(masked)),
+			Content:     io.NopCloser(bytes.NewReader(masked)),
+			Masked:      true,
+		}, nil
+	}
+
+	content, err := uc.openImage(ctx, m)
+	if err != nil {
+		return ImageDownload{}, err
+	}
+
+	return ImageDownload{
+		ContentType: m.image.contentType,
+		Size:        m.image.size,
+		Content:     content,
+	}, nil
+}
+
+// maskedImage gives back the image of the map masked by its fog, from the cache while the
+// image, the grid and the fog stay the same
+func (uc *UseCase) maskedImage(ctx context.Context, m *Map) ([]byte, error) {
+	key := m.maskKey()
+	if masked, ok := uc.masks.get(m.id, key); ok {
+		return masked, nil
+	}
+
+	content, err := uc.openImage(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	masked, err := m.maskImage(content)
+	if err != nil {
+		logger.Debug("failed to mask map image", "map_id", m.id, "error", err)
+		return nil, err
+	}
+	uc.masks.put(m.id, key, masked)
+	return masked, nil
+}
+
+func (uc *UseCase) openImage(ctx context.Context, m *Map) (io.ReadCloser, error) {
+	content, err := uc.blobStore.Open(ctx, m.image.key)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, ErrImageNotFound
+		}
+		logger.Debug("failed to open map image", "map_id", m.id, "error", err)
+		return nil, err
+	}
+	return content, nil
+}
+
+// Subscribe connects the player to the live changes of the maps of the campaign.
+// The returned function must be called on disconnection.
+func (uc *UseCase) Subscribe(ctx context.Context, campaignId id.CampaignId, playerId id.PlayerId) (<-chan live.Event, func(), error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return nil, nil, ErrPlayerNotInCampaign
+	}
+
+	events, unsubscribe := uc.subscriber.Subscribe(topic(campaignId), playerId)
+	return events, unsubscribe, nil
+}
+
+// modify loads the map with its campaign, applies the change, persists it and delivers it live.
+// The change checks the permissions of the player.
+func (uc *UseCase) modify(
+	ctx context.Context,
+	mapId id.MapId,
+	playerId id.PlayerId,
+	change func(*Map, *campaign.Campaign) error,
+) (MapResponse, error) {
+	var (
+		m    *Map
+		camp *campaign.Campaign
+	)
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		m, camp, err = uc.findMap(ctx, mapId)
+		if err != nil {
+			return err
+		}
+		if !camp.HasPlayer(playerId) {
+			return ErrPlayerNotInCampaign
+		}
+		if err := change(m, camp); err != nil {
+			return err
+		}
+		if err := uc.mapSaver.Update(ctx, m); err != nil {
+			logger.Debug("failed to update map", "map_id", mapId, "error", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return MapResponse{}, err
+	}
+
+	uc.publish(m, camp)
+	return toMapResponse(m, playerId, camp.IsMaster(playerId)), nil
+}
+
+// publish delivers to every player of the campaign the map as they see it
+func (uc *UseCase) publish(m *Map, camp *campaign.Campaign) {
+	for _, playerId := range camp.Players() {
+		event := live.Event{Type: EventMapUpdated, Data: toMapResponse(m, playerId, camp.IsMaster(playerId))}
+		uc.publisher.Publish(topic(camp.Id()), event, func(subscriber id.PlayerId) bool {
+			return subscriber == playerId
+		})
+	}
+}
+
+func (uc *UseCase) findMap(ctx context.Context, mapId id.MapId) (*Map, *campaign.Campaign, error) {
+	m, err := uc.mapFinder.FindById(ctx, mapId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, nil, ErrMapNotFound
+		}
+		logger.Debug("failed to find map", "map_id", mapId, "error", err)
+		return nil, nil, err
+	}
+
+	camp, err := uc.findCampaign(ctx, m.campaignId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, camp, nil
+}
+
+func (uc *UseCase) findCampaign(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		logger.Debug("no campaign found", "campaign_id", campaignId)
+		return nil, errors.Join(ErrCampaignNotFound, err)
+	}
+	return camp, nil
+}
+
+func (uc *UseCase) deleteImage(ctx context.Context, key string) {
+	if err := uc.blobStore.Delete(ctx, key); err != nil {
+		logger.Debug("failed to delete map image", "key", key, "error", err)
+	}
+}
+
+func topic(campaignId id.CampaignId) string {
+	return fmt.Sprintf("campaign/%d/maps", campaignId)
+}
+
+func toRegion(req RegionRequest) Region {
+	return Region{X: req.X, Y: req.Y, Width: req.Width, Height: req.Height}
+}
+
+func toGridDto(g Grid) GridDto {
+	return GridDto{Columns: g.Columns, Rows: g.Rows, CellSize: g.CellSize}
+}
+
+func toMapResponse(m *Map, playerId id.PlayerId, isMaster bool) MapResponse {
+	tokens := m.TokensFor(playerId, isMaster)
+	tokenResponses := make([]TokenResponse, len(tokens))
+	for i, t := range tokens {
+		resp := TokenResponse{
+			Id:          int(t.id),
+			CharacterId: int(t.characterId),
+			Name:        t.name,
+			X:           t.x,
+			Y:           t.y,
+		}
+		if t.ownerId != 0 {
+			ownerId := int(t.ownerId)
+			resp.OwnerId = &ownerId
+		}
+		tokenResponses[i] = resp
+	}
+
+	return MapResponse{
+		Id:         int(m.id),
+		CampaignId: int(m.campaignId),
+		Name:       m.name,
+		Grid:       toGridDto(m.grid),
+		ImageUrl:   fmt.Sprintf("/maps/%d/image", m.id),
+		Fog:        m.fog.rows(m.grid),
+		Tokens:     tokenResponses,
+		CreatedAt:  m.createdAt,
+		UpdatedAt:  m.updatedAt,
+	}
+}
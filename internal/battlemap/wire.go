@@ -0,0 +1,34 @@
+package battlemap
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/live"
+)
+
+type Deps struct {
+	QProvider  postgres.QuerierProvider
+	Transactor tx.Transactor
+	BlobStore  BlobStore
+	Broker     *live.Broker
+}
+
+func NewHandlerFromDeps(deps Deps) *HttpHandler {
+	mapRepo := NewPostgresRepository(deps.QProvider)
+	campaignRepo := campaign.NewPostgresRepository(deps.QProvider)
+	characterRepo := character.NewPostgresRepository(deps.QProvider)
+
+	mapUC := NewUseCase(
+		mapRepo,
+		mapRepo,
+		deps.BlobStore,
+		campaignRepo,
+		characterRepo,
+		deps.Broker,
+		deps.Broker,
+		deps.Transactor,
+	)
+	return NewHttpHandler(mapUC)
+}
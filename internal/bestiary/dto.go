@@ -0,0 +1,66 @@
+package bestiary
+
+import (
+	"beldur/internal/character"
+	"time"
+)
+
+type RangeDto struct {
+	Min int `json:"min" validate:"min=0"`
+	Max int `json:"max" validate:"gtefield=Min"`
+}
+
+type AbilityRangesDto struct {
+	Strength     RangeDto `json:"strength"`
+	Dexterity    RangeDto `json:"dexterity"`
+	Constitution RangeDto `json:"constitution"`
+	Intelligence RangeDto `json:"intelligence"`
+	Wisdom       RangeDto `json:"wisdom"`
+	Charisma     RangeDto `json:"charisma"`
+}
+
+type AttackDto struct {
+	Name   string `json:"name" validate:"required,max=50"`
+	Bonus  int    `json:"bonus"`
+	Damage string `json:"damage" validate:"required,max=20"`
+}
+
+type LootDto struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=500"`
+	Quantity    int    `json:"quantity" validate:"min=1"`
+}
+
+type TemplateRequest struct {
+	Name        string           `json:"name" validate:"required,max=40"`
+	Description string           `json:"description" validate:"max=500"`
+	Abilities   AbilityRangesDto `json:"abilities"`
+	HitPoints   RangeDto         `json:"hit_points"`
+	Attacks     []AttackDto      `json:"attacks" validate:"dive"`
+	Loot        []LootDto        `json:"loot" validate:"dive"`
+}
+
+type TemplateResponse struct {
+	ID          int              `json:"id"`
+	CampaignID  *int             `json:"campaign_id"`
+	AuthorID    int              `json:"author_id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Abilities   AbilityRangesDto `json:"abilities"`
+	HitPoints   RangeDto         `json:"hit_points"`
+	Attacks     []AttackDto      `json:"attacks"`
+	Loot        []LootDto        `json:"loot"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+type SpawnRequest struct {
+	Count int `json:"count" validate:"required,min=1,max=20"`
+}
+
+type SpawnedNPCResponse struct {
+	ID         int                  `json:"character_id"`
+	CampaignID int                  `json:"campaign_id"`
+	Name       string               `json:"name"`
+	HitPoints  int                  `json:"hit_points"`
+	Abilities  character.AbilityDto `json:"abilities"`
+}
@@ -0,0 +1,93 @@
+package bestiary
+
+import (
+	"beldur/internal/character"
+	"beldur/pkg/httperr"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidTemplateName        = errors.New("invalid template name")
+	ErrInvalidTemplateDescription = errors.New("invalid template description")
+	ErrInvalidRange               = errors.New("invalid stat range")
+	ErrInvalidSpawnCount          = errors.New("invalid number of NPCs to spawn")
+)
+
+var (
+	ErrTemplateNotFound         = errors.New("npc template not found")
+	ErrCampaignNotFound         = errors.New("campaign not found")
+	ErrCampaignHasAnotherMaster = errors.New("campaign has another master")
+)
+
+func NewBestiaryApiErrorManager() *httperr.Manager {
+	mng := httperr.NewManager()
+
+	mng.Add(ErrInvalidTemplateName, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_template_name",
+		Message: ErrInvalidTemplateName.Error(),
+	})
+
+	mng.Add(ErrInvalidTemplateDescription, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_template_description",
+		Message: ErrInvalidTemplateDescription.Error(),
+	})
+
+	mng.Add(ErrInvalidRange, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_range",
+		Message: ErrInvalidRange.Error(),
+	})
+
+	mng.Add(ErrInvalidSpawnCount, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_spawn_count",
+		Message: ErrInvalidSpawnCount.Error(),
+	})
+
+	mng.Add(character.ErrInvalidAttack, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_attack",
+		Message: character.ErrInvalidAttack.Error(),
+	})
+
+	mng.Add(character.ErrInvalidItem, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_item",
+		Message: character.ErrInvalidItem.Error(),
+	})
+
+	mng.Add(character.ErrInvalidItemQuantity, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_item_quantity",
+		Message: character.ErrInvalidItemQuantity.Error(),
+	})
+
+	mng.Add(character.ErrInventoryFull, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "inventory_full",
+		Message: character.ErrInventoryFull.Error(),
+	})
+
+	mng.Add(ErrTemplateNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "template_not_found",
+		Message: ErrTemplateNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "campaign_not_found",
+		Message: ErrCampaignNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignHasAnotherMaster, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "campaign_has_another_master",
+		Message: ErrCampaignHasAnotherMaster.Error(),
+	})
+
+	return mng
+}
@@ -0,0 +1,125 @@
+package bestiary
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/httperr"
+	"beldur/pkg/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpHandler struct {
+	bestiaryUC *UseCase
+	errManager *httperr.Manager
+}
+
+func NewHttpHandler(bestiaryUC *UseCase) *HttpHandler {
+	return &HttpHandler{
+		bestiaryUC: bestiaryUC,
+		errManager: NewBestiaryApiErrorManager(),
+	}
+}
+
+func (h *HttpHandler) HandleCreateCampaignTemplate(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(TemplateRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.bestiaryUC.CreateCampaignTemplate(c.Context(), req, id.CampaignId(campaignId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetCampaignTemplates(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.bestiaryUC.ListCampaignTemplates(c.Context(), id.CampaignId(campaignId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleCreateSharedTemplate(c *fiber.Ctx) error {
+	req := c.Locals("body").(TemplateRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.bestiaryUC.CreateSharedTemplate(c.Context(), req, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetSharedTemplates(c *fiber.Ctx) error {
+	resp, err := h.bestiaryUC.ListSharedTemplates(c.Context())
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleSpawn(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	templateInstr := c.Params("templateId")
+	if campaignInstr == "" || templateInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	templateId, err := strconv.Atoi(templateInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(SpawnRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.bestiaryUC.Spawn(c.Context(), req, id.CampaignId(campaignId), id.NpcTemplateId(templateId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
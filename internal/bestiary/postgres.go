@@ -0,0 +1,252 @@
+package bestiary
+
+import (
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresRepository struct {
+	q postgres.QuerierProvider
+}
+
+func NewPostgresRepository(q postgres.QuerierProvider) *PostgresRepository {
+	return &PostgresRepository{q: q}
+}
+
+// attackRow and lootRow are the JSONB representation of attacks and loot
+type attackRow struct {
+	Name   string `json:"name"`
+	Bonus  int    `json:"bonus"`
+	Damage string `json:"damage"`
+}
+
+type lootRow struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+}
+
+const selectTemplate = `
+	SELECT
+	    template_id,
+	    campaign_id,
+	    author_id,
+	    name,
+	    description,
+	    strength_min, strength_max,
+	    dexterity_min, dexterity_max,
+	    constitution_min, constitution_max,
+	    intelligence_min, intelligence_max,
+	    wisdom_min, wisdom_max,
+	    charisma_min, charisma_max,
+	    hit_points_min, hit_points_max,
+	    attacks,
+	    loot,
+	    created_at
+	FROM npc_templates
+`
+
+func (p *PostgresRepository) Save(ctx context.Context, t *Template) error {
+	const query = `
+		INSERT INTO npc_templates
+		    (campaign_id, author_id, name, description,
+		     strength_min, strength_max,
+		     dexterity_min, dexterity_max,
+		     constitution_min, constitution_max,
+		     intelligence_min, intelligence_max,
+		     wisdom_min, wisdom_max,
+		     charisma_min, charisma_max,
+		     hit_points_min, hit_points_max,
+		     attacks, loot, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		RETURNING template_id
+	`
+
+	attacks := make([]attackRow, len(t.attacks))
+	for i, a := range t.attacks {
+		attacks[i] = attackRow{Name: a.Name(), Bonus: a.Bonus(), Damage: a.Damage().String()}
+	}
+	loot := make([]lootRow, len(t.loot))
+	for i, it := range t.loot {
+		loot[i] = lootRow{Name: it.Name(), Description: it.Description(), Quantity: it.Quantity()}
+	}
+	attacksJSON, err := json.Marshal(attacks)
+	if err != nil {
+		return err
+	}
+	lootJSON, err := json.Marshal(loot)
+	if err != nil {
+		return err
+	}
+
+	var campaignID *int
+	if t.campaignId != nil {
+		cid := int(*t.campaignId)
+		campaignID = &cid
+	}
+
+	a := t.abilities
+	var templateID int
+	if err := p.q(ctx).QueryRow(ctx, query,
+		campaignID,
+		int(t.authorId),
+		t.name,
+		t.description,
+		a[character.AbilityStrength].Min, a[character.AbilityStrength].Max,
+		a[character.AbilityDexterity].Min, a[character.AbilityDexterity].Max,
+		a[character.AbilityConstitution].Min, a[character.AbilityConstitution].Max,
+		a[character.AbilityIntelligence].Min, a[character.AbilityIntelligence].Max,
+		a[character.AbilityWisdom].Min, a[character.AbilityWisdom].Max,
+		a[character.AbilityCharisma].Min, a[character.AbilityCharisma].Max,
+		t.hitPoints.Min, t.hitPoints.Max,
+		attacksJSON,
+		lootJSON,
+		t.createdAt,
+	).Scan(&templateID); err != nil {
+		return err
+	}
+	t.id = id.NpcTemplateId(templateID)
+	return nil
+}
+
+func (p *PostgresRepository) FindById(ctx context.Context, templateId id.NpcTemplateId) (*Template, error) {
+	const query = selectTemplate + `WHERE template_id = $1`
+
+	t, err := p.scanTemplate(p.q(ctx).QueryRow(ctx, query, int(templateId)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+func (p *PostgresRepository) FindUsableIn(ctx context.Context, campaignId id.CampaignId) ([]*Template, error) {
+	const query = selectTemplate + `
+		WHERE campaign_id = $1 OR campaign_id IS NULL
+		ORDER BY campaign_id NULLS LAST, name
+	`
+	return p.findMany(ctx, query, int(campaignId))
+}
+
+func (p *PostgresRepository) FindShared(ctx context.Context) ([]*Template, error) {
+	const query = selectTemplate + `
+		WHERE campaign_id IS NULL
+		ORDER BY name
+	`
+	return p.findMany(ctx, query)
+}
+
+func (p *PostgresRepository) findMany(ctx context.Context, query string, args ...any) ([]*Template, error) {
+	rows, err := p.q(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := make([]*Template, 0)
+	for rows.Next() {
+		t, err := p.scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// scanTemplate translates DB row -> domain model.
+func (p *PostgresRepository) scanTemplate(row pgx.Row) (*Template, error) {
+	var (
+		templateID  int
+		campaignID  *int
+		authorID    int
+		name        string
+		description string
+		ranges      [6]Range
+		hitPoints   Range
+		attacksJSON []byte
+		lootJSON    []byte
+		createdAt   time.Time
+	)
+
+	if err := row.Scan(
+		&templateID,
+		&campaignID,
+		&authorID,
+		&name,
+		&description,
+		&ranges[0].Min, &ranges[0].Max,
+		&ranges[1].Min, &ranges[1].Max,
+		&ranges[2].Min, &ranges[2].Max,
+		&ranges[3].Min, &ranges[3].Max,
+		&ranges[4].Min, &ranges[4].Max,
+		&ranges[5].Min, &ranges[5].Max,
+		&hitPoints.Min, &hitPoints.Max,
+		&attacksJSON,
+		&lootJSON,
+		&createdAt,
+	); err != nil {
+		return nil, err
+	}
+
+	var attackRows []attackRow
+	if err := json.Unmarshal(attacksJSON, &attackRows); err != nil {
+		return nil, err
+	}
+	attacks := make([]character.Attack, len(attackRows))
+	for i, a := range attackRows {
+		attack, err := character.NewAttack(a.Name, a.Bonus, a.Damage)
+		if err != nil {
+			return nil, err
+		}
+		attacks[i] = attack
+	}
+
+	var lootRows []lootRow
+	if err := json.Unmarshal(lootJSON, &lootRows); err != nil {
+		return nil, err
+	}
+	loot := make([]character.Item, len(lootRows))
+	for i, l := range lootRows {
+		item, err := character.NewItem(l.Name, l.Description, l.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		loot[i] = item
+	}
+
+	// same order of the SELECT
+	abilities := make(map[character.AbilityStat]Range, len(character.AllAbilityStats))
+	for i, ability := range character.AllAbilityStats {
+		abilities[ability] = ranges[i]
+	}
+
+	t := &Template{
+		id:          id.NpcTemplateId(templateID),
+		authorId:    id.PlayerId(authorID),
+		name:        name,
+		description: description,
+		abilities:   abilities,
+		hitPoints:   hitPoints,
+		attacks:     attacks,
+		loot:        loot,
+		createdAt:   createdAt,
+	}
+	if campaignID != nil {
+		cid := id.CampaignId(*campaignID)
+		t.campaignId = &cid
+	}
+	return t, nil
+}
@@ -0,0 +1,33 @@
+package bestiary
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"context"
+)
+
+type Saver interface {
+	Save(ctx context.Context, template *Template) error
+}
+
+type Finder interface {
+	FindById(ctx context.Context, templateId id.NpcTemplateId) (*Template, error)
+	// FindUsableIn gives back the templates of the campaign and the ones of the shared library
+	FindUsableIn(ctx context.Context, campaignId id.CampaignId) ([]*Template, error)
+	FindShared(ctx context.Context) ([]*Template, error)
+}
+
+type CampaignFinder interface {
+	FindById(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error)
+}
+
+type NPCSaver interface {
+	SaveNPC(ctx context.Context, character *character.Character, campaignId id.CampaignId, masterId id.PlayerId) error
+}
+
+type NPCNameFinder interface {
+	// LockNPCNames must be called inside the transaction, before FindNPCNames
+	LockNPCNames(ctx context.Context, campaignId id.CampaignId) error
+	FindNPCNames(ctx context.Context, campaignId id.CampaignId, prefix string) ([]string, error)
+}
@@ -0,0 +1,189 @@
+package bestiary
+
+import (
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"beldur/pkg/dice"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxNameCharacters leaves room in the character name for the number of the spawned instance
+	MaxNameCharacters        = 40
+	MaxDescriptionCharacters = 500
+
+	MaxSpawnCount = 20
+)
+
+// Range is an inclusive interval of values, a stat is rolled inside it when spawning.
+// A fixed stat has Min equal to Max.
+type Range struct {
+	Min int
+	Max int
+}
+
+func NewRange(min, max int) (Range, error) {
+	if min < 0 || max < min {
+		return Range{}, ErrInvalidRange
+	}
+	return Range{Min: min, Max: max}, nil
+}
+
+func (r Range) roll(roller dice.Roller) int {
+	return dice.Between(roller, r.Min, r.Max)
+}
+
+type Option func(*Template) error
+
+// InCampaign makes the template private to the campaign.
+// Templates without a campaign belong to the shared library.
+func InCampaign(campaignId id.CampaignId) Option {
+	return func(t *Template) error {
+		t.campaignId = &campaignId
+		return nil
+	}
+}
+
+func WithAttacks(attacks []character.Attack) Option {
+	return func(t *Template) error {
+		t.attacks = attacks
+		return nil
+	}
+}
+
+// WithLoot sets the items carried by every spawned instance
+func WithLoot(loot []character.Item) Option {
+	return func(t *Template) error {
+		if len(loot) > character.ItemDefaultCapacity {
+			return character.ErrInventoryFull
+		}
+		t.loot = loot
+		return nil
+	}
+}
+
+// Template is a reusable NPC stat block from which NPC characters are spawned
+type Template struct {
+	id id.NpcTemplateId
+	// nil if the template is in the shared library
+	campaignId  *id.CampaignId
+	authorId    id.PlayerId
+	name        string
+	description string
+	abilities   map[character.AbilityStat]Range
+	hitPoints   Range
+	attacks     []character.Attack
+	loot        []character.Item
+	createdAt   time.Time
+}
+
+// New creates a template. Abilities not present in the map are fixed at 0.
+func New(
+	name, description string,
+	authorId id.PlayerId,
+	abilities map[character.AbilityStat]Range,
+	hitPoints Range,
+	opt ...Option,
+) (*Template, error) {
+	if name == "" || len(name) > MaxNameCharacters {
+		return nil, ErrInvalidTemplateName
+	}
+	if len(description) > MaxDescriptionCharacters {
+		return nil, ErrInvalidTemplateDescription
+	}
+
+	ranges := make(map[character.AbilityStat]Range, len(character.AllAbilityStats))
+	for _, ability := range character.AllAbilityStats {
+		r := abilities[ability]
+		if _, err := NewRange(r.Min, r.Max); err != nil {
+			return nil, err
+		}
+		ranges[ability] = r
+	}
+	if _, err := NewRange(hitPoints.Min, hitPoints.Max); err != nil {
+		return nil, err
+	}
+
+	t := &Template{
+		authorId:    authorId,
+		name:        name,
+		description: description,
+		abilities:   ranges,
+		hitPoints:   hitPoints,
+		createdAt:   time.Now(),
+	}
+	for _, o := range opt {
+		if err := o(t); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// IsUsableIn reports if NPCs of the template can be spawned in the campaign
+func (t *Template) IsUsableIn(campaignId id.CampaignId) bool {
+	return t.campaignId == nil || *t.campaignId == campaignId
+}
+
+func (t *Template) IsShared() bool {
+	return t.campaignId == nil
+}
+
+// Spawn creates count NPC characters, not yet persisted, named "<template name> <n>"
+// with n starting from firstNumber. Every stat is rolled inside its range.
+func (t *Template) Spawn(count, firstNumber int, roller dice.Roller) ([]*character.Character, error) {
+	if count < 1 || count > MaxSpawnCount {
+		return nil, ErrInvalidSpawnCount
+	}
+
+	npcs := make([]*character.Character, count)
+	for i := range npcs {
+		abilities := character.NewDefaultAbilities()
+		for _, ability := range character.AllAbilityStats {
+			abilities.Set(ability, t.abilities[ability].roll(roller))
+		}
+
+		loot := make([]character.Item, len(t.loot))
+		copy(loot, t.loot)
+		inventory, err := character.NewInventory(loot)
+		if err != nil {
+			return nil, err
+		}
+
+		attacks := make([]character.Attack, len(t.attacks))
+		copy(attacks, t.attacks)
+
+		npcs[i] = character.New(
+			fmt.Sprintf("%s %d", t.name, firstNumber+i),
+			t.description,
+			character.WithAbilities(abilities),
+			character.WithHitPoints(t.hitPoints.roll(roller)),
+			character.WithAttacks(attacks),
+			character.WithInventory(inventory),
+		)
+	}
+	return npcs, nil
+}
+
+func (t *Template) Id() id.NpcTemplateId { return t.id }
+
+// nextSpawnNumber finds the first free number for the instances of the template,
+// given the names of the NPCs already in the campaign that start with the template name.
+func nextSpawnNumber(templateName string, names []string) int {
+	last := 0
+	for _, name := range names {
+		suffix, ok := strings.CutPrefix(name, templateName+" ")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(suffix)
+		if err != nil {
+			continue
+		}
+		last = max(last, n)
+	}
+	return last + 1
+}
@@ -0,0 +1,104 @@
+package bestiary
+
+import (
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"beldur/pkg/dice"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGoblinTemplate(t *testing.T) *Template {
+	t.Helper()
+
+	scimitar, err := character.NewAttack("scimitar", 4, "1d6+2")
+	require.NoError(t, err)
+	coins, err := character.NewItem("copper coins", "", 5)
+	require.NoError(t, err)
+
+	abilities := map[character.AbilityStat]Range{
+		character.AbilityStrength:     {Min: 7, Max: 9},
+		character.AbilityDexterity:    {Min: 13, Max: 15},
+		character.AbilityConstitution: {Min: 10, Max: 10},
+		character.AbilityIntelligence: {Min: 9, Max: 11},
+		character.AbilityWisdom:       {Min: 8, Max: 8},
+		character.AbilityCharisma:     {Min: 7, Max: 9},
+	}
+
+	tmpl, err := New("Goblin", "small and nasty", id.PlayerId(1), abilities, Range{Min: 5, Max: 9},
+		InCampaign(id.CampaignId(3)),
+		WithAttacks([]character.Attack{scimitar}),
+		WithLoot([]character.Item{coins}),
+	)
+	require.NoError(t, err)
+	return tmpl
+}
+
+func TestNew(t *testing.T) {
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := New(strings.Repeat("a", MaxNameCharacters+1), "", id.PlayerId(1), nil, Range{})
+		assert.ErrorIs(t, err, ErrInvalidTemplateName)
+	})
+
+	t.Run("invalid ability range", func(t *testing.T) {
+		abilities := map[character.AbilityStat]Range{character.AbilityWisdom: {Min: 10, Max: 8}}
+		_, err := New("Orc", "", id.PlayerId(1), abilities, Range{})
+		assert.ErrorIs(t, err, ErrInvalidRange)
+	})
+
+	t.Run("too much loot", func(t *testing.T) {
+		coins, err := character.NewItem("coin", "", 1)
+		require.NoError(t, err)
+		loot := make([]character.Item, character.ItemDefaultCapacity+1)
+		for i := range loot {
+			loot[i] = coins
+		}
+		_, err = New("Dragon", "", id.PlayerId(1), nil, Range{}, WithLoot(loot))
+		assert.ErrorIs(t, err, character.ErrInventoryFull)
+	})
+}
+
+func TestTemplate_IsUsableIn(t *testing.T) {
+	tmpl := newGoblinTemplate(t)
+	assert.True(t, tmpl.IsUsableIn(id.CampaignId(3)))
+	assert.False(t, tmpl.IsUsableIn(id.CampaignId(4)))
+
+	shared, err := New("Wolf", "", id.PlayerId(1), nil, Range{Min: 11, Max: 11})
+	require.NoError(t, err)
+	assert.True(t, shared.IsShared())
+	assert.True(t, shared.IsUsableIn(id.CampaignId(4)))
+}
+
+func TestTemplate_Spawn(t *testing.T) {
+	tmpl := newGoblinTemplate(t)
+
+	npcs, err := tmpl.Spawn(3, 4, dice.NewSeededRoller(1))
+	require.NoError(t, err)
+	require.Len(t, npcs, 3)
+
+	for i, npc := range npcs {
+		assert.Equal(t, []string{"Goblin 4", "Goblin 5", "Goblin 6"}[i], npc.Name())
+		assert.GreaterOrEqual(t, npc.HitPoints(), 5)
+		assert.LessOrEqual(t, npc.HitPoints(), 9)
+		assert.GreaterOrEqual(t, npc.AbilityPoint(character.AbilityDexterity), 13)
+		assert.LessOrEqual(t, npc.AbilityPoint(character.AbilityDexterity), 15)
+		assert.Equal(t, 10, npc.AbilityPoint(character.AbilityConstitution))
+		assert.Len(t, npc.Attacks(), 1)
+	}
+
+	t.Run("invalid count", func(t *testing.T) {
+		_, err := tmpl.Spawn(0, 1, dice.NewSeededRoller(1))
+		assert.ErrorIs(t, err, ErrInvalidSpawnCount)
+		_, err = tmpl.Spawn(MaxSpawnCount+1, 1, dice.NewSeededRoller(1))
+		assert.ErrorIs(t, err, ErrInvalidSpawnCount)
+	})
+}
+
+func TestNextSpawnNumber(t *testing.T) {
+	assert.Equal(t, 1, nextSpawnNumber("Goblin", nil))
+	assert.Equal(t, 4, nextSpawnNumber("Goblin", []string{"Goblin 1", "Goblin 3", "Goblin boss", "Goblin 2"}))
+	assert.Equal(t, 1, nextSpawnNumber("Goblin", []string{"Goblin King 7"}))
+}
@@ -0,0 +1,260 @@
+package bestiary
+
+import (
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dice"
+	"beldur/pkg/dto"
+	"beldur/pkg/logger"
+	"context"
+	"errors"
+)
+
+type UseCase struct {
+	tSaver         Saver
+	tFinder        Finder
+	campaignFinder CampaignFinder
+	npcSaver       NPCSaver
+	npcNameFinder  NPCNameFinder
+	roller         dice.Roller
+	tx             tx.Transactor
+}
+
+func NewUseCase(
+	templateSaver Saver,
+	templateFinder Finder,
+	campaignFinder CampaignFinder,
+	npcSaver NPCSaver,
+	npcNameFinder NPCNameFinder,
+	roller dice.Roller,
+	tx tx.Transactor,
+) *UseCase {
+	return &UseCase{
+		tSaver:         templateSaver,
+		tFinder:        templateFinder,
+		campaignFinder: campaignFinder,
+		npcSaver:       npcSaver,
+		npcNameFinder:  npcNameFinder,
+		roller:         roller,
+		tx:             tx,
+	}
+}
+
+// CreateCampaignTemplate adds a template to the bestiary of the campaign.
+// Only the master of the campaign can do it.
+func (uc *UseCase) CreateCampaignTemplate(ctx context.Context, req TemplateRequest, campaignId id.CampaignId, masterId id.PlayerId) (TemplateResponse, error) {
+	if err := uc.checkMaster(ctx, campaignId, masterId); err != nil {
+		return TemplateResponse{}, err
+	}
+
+	t, err := buildTemplate(req, masterId, InCampaign(campaignId))
+	if err != nil {
+		return TemplateResponse{}, err
+	}
+
+	if err := uc.tSaver.Save(ctx, t); err != nil {
+		logger.Debug("failed to save npc template", "error", err)
+		return TemplateResponse{}, err
+	}
+	return toTemplateResponse(t), nil
+}
+
+// CreateSharedTemplate adds a template to the shared library, usable in every campaign.
+func (uc *UseCase) CreateSharedTemplate(ctx context.Context, req TemplateRequest, authorId id.PlayerId) (TemplateResponse, error) {
+	t, err := buildTemplate(req, authorId)
+	if err != nil {
+		return TemplateResponse{}, err
+	}
+
+	if err := uc.tSaver.Save(ctx, t); err != nil {
+		logger.Debug("failed to save npc template", "error", err)
+		return TemplateResponse{}, err
+	}
+	return toTemplateResponse(t), nil
+}
+
+// ListCampaignTemplates gives back the bestiary of the campaign, shared library included.
+// Stat blocks are secret, only the master can read them.
+func (uc *UseCase) ListCampaignTemplates(ctx context.Context, campaignId id.CampaignId, masterId id.PlayerId) (dto.ListResponse[TemplateResponse], error) {
+	if err := uc.checkMaster(ctx, campaignId, masterId); err != nil {
+		return dto.ListResponse[TemplateResponse]{}, err
+	}
+
+	templates, err := uc.tFinder.FindUsableIn(ctx, campaignId)
+	if err != nil {
+		logger.Debug("failed to find npc templates", "campaign_id", campaignId, "error", err)
+		return dto.ListResponse[TemplateResponse]{}, err
+	}
+	return toTemplateListResponse(templates), nil
+}
+
+// ListSharedTemplates gives back the shared library
+func (uc *UseCase) ListSharedTemplates(ctx context.Context) (dto.ListResponse[TemplateResponse], error) {
+	templates, err := uc.tFinder.FindShared(ctx)
+	if err != nil {
+		logger.Debug("failed to find shared npc templates", "error", err)
+		return dto.ListResponse[TemplateResponse]{}, err
+	}
+	return toTemplateListResponse(templates), nil
+}
+
+// Spawn creates req.Count NPCs of the campaign from the template, numbering them after
+// the instances already present. Only the master of the campaign can spawn NPCs.
+func (uc *UseCase) Spawn(
+	ctx context.Context,
+	req SpawnRequest,
+	campaignId id.CampaignId,
+	templateId id.NpcTemplateId,
+	masterId id.PlayerId,
+) (dto.ListResponse[SpawnedNPCResponse], error) {
+	var resp []SpawnedNPCResponse
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.checkMaster(ctx, campaignId, masterId); err != nil {
+			return err
+		}
+
+		t, err := uc.tFinder.FindById(ctx, templateId)
+		if err != nil {
+			if errors.Is(err, postgres.ErrNoRowFound) {
+				return ErrTemplateNotFound
+			}
+			return err
+		}
+		if !t.IsUsableIn(campaignId) {
+			return ErrTemplateNotFound
+		}
+
+		if err := uc.npcNameFinder.LockNPCNames(ctx, campaignId); err != nil {
+			logger.Debug("failed to lock npc names", "campaign_id", campaignId, "error", err)
+			return err
+		}
+		names, err := uc.npcNameFinder.FindNPCNames(ctx, campaignId, t.name)
+		if err != nil {
+			return err
+		}
+
+		npcs, err := t.Spawn(req.Count, nextSpawnNumber(t.name, names), uc.roller)
+		if err != nil {
+			return err
+		}
+
+		resp = make([]SpawnedNPCResponse, len(npcs))
+		for i, npc := range npcs {
+			if err := uc.npcSaver.SaveNPC(ctx, npc, campaignId, masterId); err != nil {
+				logger.Debug("failed to save spawned npc", "template_id", templateId, "error", err)
+				return err
+			}
+			resp[i] = SpawnedNPCResponse{
+				ID:         int(npc.Id()),
+				CampaignID: int(campaignId),
+				Name:       npc.Name(),
+				HitPoints:  npc.HitPoints(),
+				Abilities: character.AbilityDto{
+					Strength:     npc.AbilityPoint(character.AbilityStrength),
+					Dexterity:    npc.AbilityPoint(character.AbilityDexterity),
+					Constitution: npc.AbilityPoint(character.AbilityConstitution),
+					Intelligence: npc.AbilityPoint(character.AbilityIntelligence),
+					Wisdom:       npc.AbilityPoint(character.AbilityWisdom),
+					Charisma:     npc.AbilityPoint(character.AbilityCharisma),
+				},
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return dto.ListResponse[SpawnedNPCResponse]{}, err
+	}
+	return dto.ListResponse[SpawnedNPCResponse]{Data: resp}, nil
+}
+
+func (uc *UseCase) checkMaster(ctx context.Context, campaignId id.CampaignId, playerId id.PlayerId) error {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		logger.Debug("no campaign found", "campaign_id", campaignId)
+		return errors.Join(ErrCampaignNotFound, err)
+	}
+	if !camp.IsMaster(playerId) {
+		return ErrCampaignHasAnotherMaster
+	}
+	return nil
+}
+
+func buildTemplate(req TemplateRequest, authorId id.PlayerId, opt ...Option) (*Template, error) {
+	attacks := make([]character.Attack, len(req.Attacks))
+	for i, a := range req.Attacks {
+		attack, err := character.NewAttack(a.Name, a.Bonus, a.Damage)
+		if err != nil {
+			return nil, err
+		}
+		attacks[i] = attack
+	}
+
+	loot := make([]character.Item, len(req.Loot))
+	for i, l := range req.Loot {
+		item, err := character.NewItem(l.Name, l.Description, l.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		loot[i] = item
+	}
+
+	abilities := map[character.AbilityStat]Range{
+		character.AbilityStrength:     Range(req.Abilities.Strength),
+		character.AbilityDexterity:    Range(req.Abilities.Dexterity),
+		character.AbilityConstitution: Range(req.Abilities.Constitution),
+		character.AbilityIntelligence: Range(req.Abilities.Intelligence),
+		character.AbilityWisdom:       Range(req.Abilities.Wisdom),
+		character.AbilityCharisma:     Range(req.Abilities.Charisma),
+	}
+
+	opt = append(opt, WithAttacks(attacks), WithLoot(loot))
+	return New(req.Name, req.Description, authorId, abilities, Range(req.HitPoints), opt...)
+}
+
+func toTemplateResponse(t *Template) TemplateResponse {
+	attacks := make([]AttackDto, len(t.attacks))
+	for i, a := range t.attacks {
+		attacks[i] = AttackDto{Name: a.Name(), Bonus: a.Bonus(), Damage: a.Damage().String()}
+	}
+	loot := make([]LootDto, len(t.loot))
+	for i, it := range t.loot {
+		loot[i] = LootDto{Name: it.Name(), Description: it.Description(), Quantity: it.Quantity()}
+	}
+
+	var campaignID *int
+	if t.campaignId != nil {
+		cid := int(*t.campaignId)
+		campaignID = &cid
+	}
+
+	return TemplateResponse{
+		ID:          int(t.id),
+		CampaignID:  campaignID,
+		AuthorID:    int(t.authorId),
+		Name:        t.name,
+		Description: t.description,
+		Abilities: AbilityRangesDto{
+			Strength:     RangeDto(t.abilities[character.AbilityStrength]),
+			Dexterity:    RangeDto(t.abilities[character.AbilityDexterity]),
+			Constitution: RangeDto(t.abilities[character.AbilityConstitution]),
+			Intelligence: RangeDto(t.abilities[character.AbilityIntelligence]),
+			Wisdom:       RangeDto(t.abilities[character.AbilityWisdom]),
+			Charisma:     RangeDto(t.abilities[character.AbilityCharisma]),
+		},
+		HitPoints: RangeDto(t.hitPoints),
+		Attacks:   attacks,
+		Loot:      loot,
+		CreatedAt: t.createdAt,
+	}
+}
+
+func toTemplateListResponse(templates []*Template) dto.ListResponse[TemplateResponse] {
+	list := make([]TemplateResponse, len(templates))
+	for i, t := range templates {
+		list[i] = toTemplateResponse(t)
+	}
+	return dto.ListResponse[TemplateResponse]{Data: list}
+}
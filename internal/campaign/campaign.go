@@ -71,6 +71,10 @@ func (c *Campaign) Start() error {
 		return ErrCampaignCancelled
 	}
 
+	if c.status == StatusArchived {
+		return ErrCampaignArchived
+	}
+
 	if c.status != StatusCreated {
 		return ErrCampaignNotCreated
 	}
@@ -114,6 +118,10 @@ func (c *Campaign) Finish() error {
 		return ErrCampaignCancelled
 	}
 
+	if c.status == StatusArchived {
+		return ErrCampaignArchived
+	}
+
 	if c.status != StatusStarted {
 		return ErrCampaignNotStarted
 	}
@@ -133,6 +141,10 @@ func (c *Campaign) Cancel() error {
 		return ErrCampaignFinished
 	}
 
+	if c.status == StatusArchived {
+		return ErrCampaignArchived
+	}
+
 	if c.status == StatusStarted {
 		return ErrCampaignAlreadyStarted
 	}
@@ -156,6 +168,10 @@ func (c *Campaign) AddPlayer(playerId id.PlayerId) error {
 		return ErrCampaignCancelled
 	}
 
+	if c.status == StatusArchived {
+		return ErrCampaignArchived
+	}
+
 	if c.status == StatusStarted {
 		return ErrCampaignAlreadyStarted
 	}
@@ -168,8 +184,73 @@ func (c *Campaign) AddPlayer(playerId id.PlayerId) error {
 	return nil
 }
 
+// HandOver makes another player of the campaign its master, when the master leaves.
+// Only a created or started campaign can change master.
+func (c *Campaign) HandOver(newMaster id.PlayerId) error {
+	if err := c.checkOpen(); err != nil {
+		return err
+	}
+
+	if _, exists := c.players[newMaster]; !exists {
+		return ErrPlayerNotInCampaign
+	}
+
+	c.master = newMaster
+	return nil
+}
+
+// Archive closes a created or started campaign left without master, its content is kept
+// but it cannot be played anymore
+func (c *Campaign) Archive() error {
+	// idempotent first
+	if c.status == StatusArchived {
+		return nil
+	}
+
+	if err := c.checkOpen(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	c.status = StatusArchived
+	c.finishedAt = &now
+	return nil
+}
+
+// IsOpen tells if the campaign is created or started, so still playable
+func (c *Campaign) IsOpen() bool {
+	return c.status == StatusCreated || c.status == StatusStarted
+}
+
+func (c *Campaign) checkOpen() error {
+	switch c.status {
+	case StatusFinished:
+		return ErrCampaignFinished
+	case StatusCancelled:
+		return ErrCampaignCancelled
+	case StatusArchived:
+		return ErrCampaignArchived
+	}
+	return nil
+}
+
 func (c *Campaign) Id() id.CampaignId { return c.id }
 
+func (c *Campaign) Master() id.PlayerId { return c.master }
+
+func (c *Campaign) Status() StatusCampaign { return c.status }
+
+func (c *Campaign) Name() string { return c.name }
+
+// Players gives back all the players of the campaign, master included
+func (c *Campaign) Players() []id.PlayerId {
+	players := make([]id.PlayerId, 0, len(c.players))
+	for playerId := range c.players {
+		players = append(players, playerId)
+	}
+	return players
+}
+
 func validateName(name string) error {
 	if len(name) > MaxNameCharacters {
 		return ErrInvalidCampaignName
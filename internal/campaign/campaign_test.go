@@ -328,3 +328,61 @@ func TestCampaign_Cancel(t *testing.T) {
 		assert.ErrorIs(t, err, ErrCampaignFinished)
 	})
 }
+
+func TestCampaign_HandOver(t *testing.T) {
+	newCampaign := func(t *testing.T) *Campaign {
+		t.Helper()
+		c, err := New("ok name", "ok description", id.PlayerId(1))
+		require.NoError(t, err)
+		require.NoError(t, c.AddPlayer(id.PlayerId(2)))
+		return c
+	}
+
+	t.Run("success", func(t *testing.T) {
+		c := newCampaign(t)
+		require.NoError(t, c.Start())
+
+		require.NoError(t, c.HandOver(id.PlayerId(2)))
+		assert.True(t, c.IsMaster(id.PlayerId(2)))
+		assert.False(t, c.IsMaster(id.PlayerId(1)))
+		// the previous master stays a player
+		assert.True(t, c.HasPlayer(id.PlayerId(1)))
+	})
+
+	t.Run("new master must be a player", func(t *testing.T) {
+		c := newCampaign(t)
+		assert.ErrorIs(t, c.HandOver(id.PlayerId(3)), ErrPlayerNotInCampaign)
+		assert.True(t, c.IsMaster(id.PlayerId(1)))
+	})
+
+	t.Run("closed campaign", func(t *testing.T) {
+		c := newCampaign(t)
+		require.NoError(t, c.Cancel())
+		assert.ErrorIs(t, c.HandOver(id.PlayerId(2)), ErrCampaignCancelled)
+	})
+}
+
+func TestCampaign_Archive(t *testing.T) {
+	c, err := New("ok name", "ok description", id.PlayerId(1))
+	require.NoError(t, err)
+	require.NoError(t, c.AddPlayer(id.PlayerId(2)))
+	require.NoError(t, c.Start())
+
+	require.NoError(t, c.Archive())
+	assert.Equal(t, StatusArchived, c.Status())
+	assert.NotNil(t, c.finishedAt)
+	assert.False(t, c.IsOpen())
+
+	// idempotent
+	assert.NoError(t, c.Archive())
+
+	assert.ErrorIs(t, c.Finish(), ErrCampaignArchived)
+	assert.ErrorIs(t, c.HandOver(id.PlayerId(2)), ErrCampaignArchived)
+
+	finished, err := New("ok name", "ok description", id.PlayerId(1))
+	require.NoError(t, err)
+	require.NoError(t, finished.AddPlayer(id.PlayerId(2)))
+	require.NoError(t, finished.Start())
+	require.NoError(t, finished.Finish())
+	assert.ErrorIs(t, finished.Archive(), ErrCampaignFinished)
+}
@@ -43,3 +43,12 @@ type SimpleCampaignInfoResponse struct {
 	NumberPlayers int  `json:"number_players"`
 	CanBeJoined   bool `json:"can_be_joined"`
 }
+
+// ExportedCampaign is a campaign the player is a member of, for the export of its account
+type ExportedCampaign struct {
+	CampaignId int        `json:"campaign_id"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	IsMaster   bool       `json:"is_master"`
+	JoinedAt   *time.Time `json:"joined_at"`
+}
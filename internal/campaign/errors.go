@@ -16,6 +16,8 @@ var (
 	ErrCampaignNotStarted         = errors.New("campaign is still not started")
 	ErrCampaignAlreadyStarted     = errors.New("campaign is already started")
 	ErrNotEnoughPlayersToStart    = errors.New("not enough players to start the campaign")
+	ErrCampaignArchived           = errors.New("campaign is archived")
+	ErrPlayerNotInCampaign        = errors.New("player is not in campaign")
 
 	ErrCampaignNotFound = errors.New("campaign not found")
 	ErrWrongAccessCode  = errors.New("wrong access code joining campaign")
@@ -66,6 +68,12 @@ func NewCampaignApiErrorManager() *httperr.Manager {
 		Message: ErrCampaignCancelled.Error(),
 	})
 
+	mng.Add(ErrCampaignArchived, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "campaign_archived",
+		Message: ErrCampaignArchived.Error(),
+	})
+
 	mng.Add(ErrCampaignNotCreated, httperr.Mapped{
 		Status:  http.StatusConflict,
 		Code:    "campaign_not_created",
@@ -158,8 +158,9 @@ func (p *PostgresRepository) Update(ctx context.Context, campaign *Campaign) err
             description = $2,
             started_at = $3,
             finished_at = $4,
-            status = $5
-        WHERE campaign_id = $6
+            status = $5,
+            master_id = $6
+        WHERE campaign_id = $7
     `
 
 	if _, err := p.q(ctx).Exec(ctx,
@@ -169,11 +170,23 @@ func (p *PostgresRepository) Update(ctx context.Context, campaign *Campaign) err
 		campaign.startedAt,
 		campaign.finishedAt,
 		string(campaign.status),
+		campaign.master,
 		campaign.id,
 	); err != nil {
 		return err
 	}
 
+	// the master may have been handed over
+	const sqlUpdateMaster = `
+        UPDATE campaigns_players
+        SET is_master = (player_id = $1)
+        WHERE campaign_id = $2
+    `
+
+	if _, err := p.q(ctx).Exec(ctx, sqlUpdateMaster, campaign.master, campaign.id); err != nil {
+		return err
+	}
+
 	const sqlInsertPlayer = `
         INSERT INTO campaigns_players (campaign_id, player_id, is_master)
         VALUES ($1, $2, $3)
@@ -193,6 +206,44 @@ func (p *PostgresRepository) Update(ctx context.Context, campaign *Campaign) err
 	return nil
 }
 
+func (p *PostgresRepository) FindByMaster(ctx context.Context, masterId id.PlayerId) ([]*Campaign, error) {
+	const sql = `
+		SELECT campaign_id
+		FROM campaigns
+		WHERE master_id = $1
+		ORDER BY campaign_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, sql, masterId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var campaignIds []int
+	for rows.Next() {
+		var campaignId int
+		if err := rows.Scan(&campaignId); err != nil {
+			return nil, err
+		}
+		campaignIds = append(campaignIds, campaignId)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	campaigns := make([]*Campaign, 0, len(campaignIds))
+	for _, campaignId := range campaignIds {
+		c, err := p.FindById(ctx, id.CampaignId(campaignId))
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, c)
+	}
+	return campaigns, nil
+}
+
 func (p *PostgresRepository) FindAll(ctx context.Context) ([]*Campaign, error) {
 	const sql = `
 		SELECT 
@@ -287,3 +338,29 @@ func (p *PostgresRepository) FindAll(ctx context.Context) ([]*Campaign, error) {
 
 	return campaigns, nil
 }
+
+func (p *PostgresRepository) FindExportCampaigns(ctx context.Context, playerId id.PlayerId) ([]ExportedCampaign, error) {
+	const query = `
+		SELECT c.campaign_id, c.name, c.status, c.master_id = cp.player_id, cp.joined_at
+		FROM campaigns_players cp
+		JOIN campaigns c ON c.campaign_id = cp.campaign_id
+		WHERE cp.player_id = $1
+		ORDER BY c.campaign_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	campaigns := make([]ExportedCampaign, 0)
+	for rows.Next() {
+		var c ExportedCampaign
+		if err := rows.Scan(&c.CampaignId, &c.Name, &c.Status, &c.IsMaster, &c.JoinedAt); err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, c)
+	}
+	return campaigns, rows.Err()
+}
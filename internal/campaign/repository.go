@@ -11,6 +11,11 @@ type Finder interface {
 	FindAll(ctx context.Context) ([]*Campaign, error)
 }
 
+type MasterFinder interface {
+	// FindByMaster gives back the campaigns the player is master of, whatever their status
+	FindByMaster(ctx context.Context, masterId id.PlayerId) ([]*Campaign, error)
+}
+
 type Updater interface {
 	Update(ctx context.Context, campaign *Campaign) error
 }
@@ -18,3 +23,9 @@ type Updater interface {
 type Saver interface {
 	Save(ctx context.Context, campaign *Campaign, accessCode string) error
 }
+
+// Exporter reads the campaigns of a player for the export of its account
+type Exporter interface {
+	// FindExportCampaigns gives back the campaigns the player is a member of
+	FindExportCampaigns(ctx context.Context, playerId id.PlayerId) ([]ExportedCampaign, error)
+}
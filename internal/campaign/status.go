@@ -8,4 +8,6 @@ const (
 	StatusStarted   StatusCampaign = "STARTED"
 	StatusFinished  StatusCampaign = "FINISHED"
 	StatusCancelled StatusCampaign = "CANCELLED"
+	// StatusArchived campaign lost its master, whose account has been deleted
+	StatusArchived StatusCampaign = "ARCHIVED"
 )
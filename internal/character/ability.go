@@ -11,6 +11,26 @@ var (
 	AbilityCharisma     AbilityStat = "charisma"
 )
 
+// AllAbilityStats lists the abilities in the order they appear on a character sheet.
+var AllAbilityStats = []AbilityStat{
+	AbilityStrength,
+	AbilityDexterity,
+	AbilityConstitution,
+	AbilityIntelligence,
+	AbilityWisdom,
+	AbilityCharisma,
+}
+
+// Modifier is the bonus (or malus) given by an ability score: 10 and 11 give 0,
+// every two points above or below move it by one.
+func Modifier(score int) int {
+	diff := score - 10
+	if diff < 0 {
+		return (diff - 1) / 2
+	}
+	return diff / 2
+}
+
 type Abilities struct {
 	abilityMap map[AbilityStat]int
 }
@@ -53,3 +73,18 @@ func (a *Abilities) Adjust(ability AbilityStat, val int) {
 func (a *Abilities) Get(ability AbilityStat) int {
 	return a.abilityMap[ability]
 }
+
+// Validate checks that no ability has a negative score.
+func (a *Abilities) Validate() error {
+	for _, v := range a.abilityMap {
+		if v < 0 {
+			return ErrNegativeAbility
+		}
+	}
+	return nil
+}
+
+// Modifier returns the modifier of the ability
+func (a *Abilities) Modifier(ability AbilityStat) int {
+	return Modifier(a.abilityMap[ability])
+}
@@ -0,0 +1,32 @@
+package character
+
+import "beldur/pkg/dice"
+
+// Attack is an offensive action of the character, like a weapon or a natural attack.
+type Attack struct {
+	name   string
+	bonus  int
+	damage dice.Expr
+}
+
+// NewAttack creates an attack with a to-hit bonus and the damage in dice notation (e.g. 1d6+2)
+func NewAttack(name string, bonus int, damage string) (Attack, error) {
+	if name == "" {
+		return Attack{}, ErrInvalidAttack
+	}
+	expr, err := dice.Parse(damage)
+	if err != nil {
+		return Attack{}, ErrInvalidAttack
+	}
+	return Attack{
+		name:   name,
+		bonus:  bonus,
+		damage: expr,
+	}, nil
+}
+
+func (a Attack) Name() string { return a.name }
+
+func (a Attack) Bonus() int { return a.bonus }
+
+func (a Attack) Damage() dice.Expr { return a.damage }
@@ -1,6 +1,14 @@
 package character
 
-import "beldur/internal/id"
+import (
+	"beldur/internal/id"
+	"time"
+)
+
+const (
+	MaxNameCharacters        = 50
+	MaxDescriptionCharacters = 500
+)
 
 // TODO probably... I dont think description is important
 //type CharacterProfile struct {
@@ -17,12 +25,51 @@ func WithAbilities(abilities Abilities) Option {
 	}
 }
 
+func WithInventory(inventory Inventory) Option {
+	return func(char *Character) {
+		char.inventory = inventory
+	}
+}
+
+func WithHitPoints(hitPoints int) Option {
+	return func(char *Character) {
+		char.hitPoints = hitPoints
+	}
+}
+
+func WithAttacks(attacks []Attack) Option {
+	return func(char *Character) {
+		char.attacks = attacks
+	}
+}
+
+func WithNotes(notes string) Option {
+	return func(char *Character) {
+		char.notes = notes
+	}
+}
+
 type Character struct {
 	id          id.CharacterId
 	name        string
 	description string
+	notes       string
 	abilities   Abilities
 	inventory   Inventory
+	// maximum hit points, 0 when not tracked
+	hitPoints int
+	attacks   []Attack
+
+	status StatusCharacter
+	// nil if the character is not dead
+	diedAt *time.Time
+	// nil if the character is not retired
+	retiredAt *time.Time
+
+	// set by the repository once the character is persisted
+	campaignId id.CampaignId
+	playerId   id.PlayerId
+	npc        bool
 }
 
 // New should have validation? Or does the creator have full creativity right?
@@ -32,6 +79,7 @@ func New(name, description string, opt ...Option) *Character {
 		description: description,
 		abilities:   NewDefaultAbilities(),
 		inventory:   NewEmptyInventory(),
+		status:      StatusActive,
 	}
 	for _, o := range opt {
 		o(c)
@@ -47,3 +95,119 @@ func (c *Character) AbilityPoint(ability AbilityStat) int {
 func (c *Character) AddItem(item Item) {
 	// 1. calculate how much can carry
 }
+
+func (c *Character) Rename(name string) error {
+	if name == "" || len(name) > MaxNameCharacters {
+		return ErrInvalidCharacterName
+	}
+	c.name = name
+	return nil
+}
+
+func (c *Character) UpdateDescription(description string) error {
+	if len(description) > MaxDescriptionCharacters {
+		return ErrInvalidCharacterDescription
+	}
+	c.description = description
+	return nil
+}
+
+func (c *Character) UpdateNotes(notes string) {
+	c.notes = notes
+}
+
+func (c *Character) UpdateAbilities(abilities Abilities) error {
+	if err := abilities.Validate(); err != nil {
+		return err
+	}
+	c.abilities = abilities
+	return nil
+}
+
+func (c *Character) UpdateHitPoints(hitPoints int) error {
+	if hitPoints < 0 {
+		return ErrNegativeHitPoints
+	}
+	c.hitPoints = hitPoints
+	return nil
+}
+
+// Kill marks an active character as dead
+func (c *Character) Kill() error {
+	if err := c.checkActive(); err != nil {
+		return err
+	}
+	now := time.Now()
+	c.status = StatusDead
+	c.diedAt = &now
+	return nil
+}
+
+// Retire takes an active character out of play, it stays in the graveyard of the campaign
+func (c *Character) Retire() error {
+	if err := c.checkActive(); err != nil {
+		return err
+	}
+	now := time.Now()
+	c.status = StatusRetired
+	c.retiredAt = &now
+	return nil
+}
+
+// Resurrect brings a dead character back to play
+func (c *Character) Resurrect() error {
+	if c.status != StatusDead {
+		return ErrCharacterNotDead
+	}
+	c.status = StatusActive
+	c.diedAt = nil
+	return nil
+}
+
+func (c *Character) checkActive() error {
+	switch c.status {
+	case StatusDead:
+		return ErrCharacterDead
+	case StatusRetired:
+		return ErrCharacterRetired
+	}
+	return nil
+}
+
+func (c *Character) Id() id.CharacterId { return c.id }
+
+func (c *Character) Name() string { return c.name }
+
+func (c *Character) HitPoints() int { return c.hitPoints }
+
+func (c *Character) Attacks() []Attack { return c.attacks }
+
+func (c *Character) CampaignId() id.CampaignId { return c.campaignId }
+
+// PlayerId is the owner of the character. For NPCs it is the master that created it.
+func (c *Character) PlayerId() id.PlayerId { return c.playerId }
+
+func (c *Character) IsNPC() bool { return c.npc }
+
+func (c *Character) Status() StatusCharacter { return c.status }
+
+func (c *Character) IsActive() bool { return c.status == StatusActive }
+
+// CanBeViewedBy reports if the player can read the full sheet of the character.
+// The owner and the master of the campaign can, the other players cannot.
+func (c *Character) CanBeViewedBy(playerId id.PlayerId, isMaster bool) bool {
+	if isMaster {
+		return true
+	}
+	return !c.npc && c.playerId == playerId
+}
+
+// CanBeEditedBy reports if the player can change the sheet of the character.
+// The owner and the master of the campaign can, the other players cannot.
+// Dead and retired characters can be changed only by the master.
+func (c *Character) CanBeEditedBy(playerId id.PlayerId, isMaster bool) bool {
+	if isMaster {
+		return true
+	}
+	return !c.npc && c.playerId == playerId && c.IsActive()
+}
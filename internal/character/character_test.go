@@ -0,0 +1,50 @@
+package character
+
+import (
+	"beldur/internal/id"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharacterLifecycle(t *testing.T) {
+	t.Run("death and resurrection", func(t *testing.T) {
+		c := New("Gandalf", "the grey")
+		assert.True(t, c.IsActive())
+		assert.ErrorIs(t, c.Resurrect(), ErrCharacterNotDead)
+
+		require.NoError(t, c.Kill())
+		assert.Equal(t, StatusDead, c.Status())
+		assert.NotNil(t, c.diedAt)
+		assert.ErrorIs(t, c.Kill(), ErrCharacterDead)
+		assert.ErrorIs(t, c.Retire(), ErrCharacterDead)
+
+		require.NoError(t, c.Resurrect())
+		assert.Equal(t, StatusActive, c.Status())
+		assert.Nil(t, c.diedAt)
+	})
+
+	t.Run("retirement", func(t *testing.T) {
+		c := New("Bilbo", "the burglar")
+		require.NoError(t, c.Retire())
+		assert.Equal(t, StatusRetired, c.Status())
+		assert.NotNil(t, c.retiredAt)
+		assert.ErrorIs(t, c.Kill(), ErrCharacterRetired)
+		assert.ErrorIs(t, c.Resurrect(), ErrCharacterNotDead)
+	})
+}
+
+func TestCharacterCanBeEditedBy(t *testing.T) {
+	owner, other := id.PlayerId(1), id.PlayerId(2)
+
+	c := New("Frodo", "ring bearer")
+	c.playerId = owner
+
+	assert.True(t, c.CanBeEditedBy(owner, false))
+	assert.False(t, c.CanBeEditedBy(other, false))
+
+	require.NoError(t, c.Kill())
+	assert.False(t, c.CanBeEditedBy(owner, false))
+	assert.True(t, c.CanBeEditedBy(other, true))
+}
@@ -1,5 +1,10 @@
 package character
 
+import (
+	"encoding/json"
+	"time"
+)
+
 type CreateCharacterRequest struct {
 	Name        string     `json:"name" validate:"required"`
 	Description string     `json:"description" validate:"required"`
@@ -22,3 +27,96 @@ type CreateCharacterResponse struct {
 	Description string     `json:"description" validate:"required"`
 	Abilities   AbilityDto `json:"abilities" validate:"required"`
 }
+
+type GenerateNPCRequest struct {
+	// Culture of the name table, random if empty
+	Culture string `json:"culture"`
+	// Seed makes the generation reproducible, random if nil
+	Seed *uint64 `json:"seed"`
+}
+
+// GenerateNPCResponse is a preview of a random NPC, the NPC field can be sent
+// as it is to the NPC creation endpoint to save it.
+type GenerateNPCResponse struct {
+	Seed       uint64                 `json:"seed"`
+	Culture    string                 `json:"culture"`
+	Occupation string                 `json:"occupation"`
+	Quirk      string                 `json:"quirk"`
+	NPC        CreateCharacterRequest `json:"npc"`
+}
+
+// UpdateCharacterRequest changes only the non nil fields
+type UpdateCharacterRequest struct {
+	Name        *string          `json:"name" validate:"omitempty,max=50"`
+	Description *string          `json:"description" validate:"omitempty,max=500"`
+	Notes       *string          `json:"notes"`
+	HitPoints   *int             `json:"hit_points" validate:"omitempty,min=0"`
+	Abilities   *ExportAbilities `json:"abilities"`
+}
+
+type CharacterResponse struct {
+	Id         int             `json:"character_id"`
+	CampaignId int             `json:"campaign_id"`
+	PlayerId   int             `json:"player_id"`
+	Status     StatusCharacter `json:"status"`
+	Character  ExportCharacter `json:"character"`
+}
+
+type VersionResponse struct {
+	Version      int       `json:"version"`
+	AuthorId     int       `json:"author_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	RestoredFrom *int      `json:"restored_from"`
+	Changes      []Change  `json:"changes"`
+}
+
+// LifecycleResponse is the status of a character, it is also an entry of the graveyard
+type LifecycleResponse struct {
+	Id         int             `json:"character_id"`
+	CampaignId int             `json:"campaign_id"`
+	PlayerId   int             `json:"player_id"`
+	Name       string          `json:"name"`
+	NPC        bool            `json:"npc"`
+	Status     StatusCharacter `json:"status"`
+	DiedAt     *time.Time      `json:"died_at"`
+	RetiredAt  *time.Time      `json:"retired_at"`
+}
+
+type TransferRequest struct {
+	TargetCampaignId int          `json:"target_campaign_id" validate:"required"`
+	Mode             TransferMode `json:"mode" validate:"required,oneof=CLONE MOVE"`
+}
+
+type TransferResponse struct {
+	Id                int            `json:"transfer_id"`
+	CharacterId       int            `json:"character_id"`
+	PlayerId          int            `json:"player_id"`
+	SourceCampaignId  int            `json:"source_campaign_id"`
+	TargetCampaignId  int            `json:"target_campaign_id"`
+	Mode              TransferMode   `json:"mode"`
+	Status            TransferStatus `json:"status"`
+	CreatedAt         time.Time      `json:"created_at"`
+	DecidedAt         *time.Time     `json:"decided_at"`
+	ResultCharacterId *int           `json:"result_character_id"`
+}
+
+// ExportedCharacter is a character of the player, for the export of its account
+type ExportedCharacter struct {
+	CharacterId int     `json:"character_id"`
+	CampaignId  int     `json:"campaign_id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Notes       string  `json:"notes"`
+	Status      string  `json:"status"`
+}
+
+// ExportedVersion is a version of a character of the player, or one it wrote, for the export of its account
+type ExportedVersion struct {
+	CharacterId  int             `json:"character_id"`
+	Version      int             `json:"version"`
+	AuthorId     int             `json:"author_id"`
+	Snapshot     json.RawMessage `json:"snapshot"`
+	Changes      json.RawMessage `json:"changes"`
+	RestoredFrom *int            `json:"restored_from"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
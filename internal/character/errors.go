@@ -8,13 +8,40 @@ import (
 
 // Item error
 var (
-	ErrNegativeAbility = errors.New("negative ability")
+	ErrNegativeAbility     = errors.New("negative ability")
+	ErrInvalidItem         = errors.New("invalid item")
+	ErrInvalidItemQuantity = errors.New("invalid item quantity")
+	ErrInventoryFull       = errors.New("inventory is full")
+	ErrInvalidAttack       = errors.New("invalid attack")
+	ErrNegativeHitPoints   = errors.New("negative hit points")
 
 	// duplication with campaign?
 	ErrCampaignNotFound               = errors.New("campaign not found")
 	ErrCampaignHasAnotherMaster error = errors.New("campaign has another master")
 )
 
+var (
+	ErrInvalidCharacterName        = errors.New("invalid character name")
+	ErrInvalidCharacterDescription = errors.New("invalid character description")
+	ErrVersionNotFound             = errors.New("character version not found")
+	ErrCharacterNotFound           = errors.New("character not found")
+	ErrCharacterAccessDenied       = errors.New("character is not accessible by the player")
+	ErrPlayerNotInCampaign         = errors.New("player is not in the campaign")
+	ErrUnsupportedExportVersion    = errors.New("unsupported character export version")
+	ErrUnknownCulture              = errors.New("unknown culture")
+	ErrCharacterDead               = errors.New("character is dead")
+	ErrCharacterRetired            = errors.New("character is retired")
+	ErrCharacterNotDead            = errors.New("character is not dead")
+	ErrCharacterNotNPC             = errors.New("character is not a npc")
+	ErrInvalidTransferMode         = errors.New("invalid transfer mode")
+	ErrTransferToSameCampaign      = errors.New("character is already in the campaign")
+	ErrTransferNotFound            = errors.New("transfer not found")
+	ErrTransferAlreadyDecided      = errors.New("transfer is already approved or rejected")
+	ErrTransferAlreadyPending      = errors.New("a transfer of the character to the campaign is already pending")
+	ErrCampaignNotOpen             = errors.New("campaign is not open")
+	ErrPlayerAlreadyHasCharacter   = errors.New("player already has an active character in the campaign")
+)
+
 func NewCharacterApiErrorManager() *httperr.Manager {
 	mng := httperr.NewManager()
 
@@ -30,5 +57,155 @@ func NewCharacterApiErrorManager() *httperr.Manager {
 		Message: ErrCampaignNotFound.Error(),
 	})
 
+	mng.Add(ErrCharacterNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "character_not_found",
+		Message: ErrCharacterNotFound.Error(),
+	})
+
+	mng.Add(ErrVersionNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "character_version_not_found",
+		Message: ErrVersionNotFound.Error(),
+	})
+
+	mng.Add(ErrInvalidCharacterName, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_character_name",
+		Message: ErrInvalidCharacterName.Error(),
+	})
+
+	mng.Add(ErrInvalidCharacterDescription, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_character_description",
+		Message: ErrInvalidCharacterDescription.Error(),
+	})
+
+	mng.Add(ErrCharacterAccessDenied, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "character_access_denied",
+		Message: ErrCharacterAccessDenied.Error(),
+	})
+
+	mng.Add(ErrPlayerNotInCampaign, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "player_not_in_campaign",
+		Message: ErrPlayerNotInCampaign.Error(),
+	})
+
+	mng.Add(ErrUnsupportedExportVersion, httperr.Mapped{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    "unsupported_export_version",
+		Message: ErrUnsupportedExportVersion.Error(),
+	})
+
+	mng.Add(ErrUnknownCulture, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "unknown_culture",
+		Message: ErrUnknownCulture.Error(),
+	})
+
+	mng.Add(ErrCharacterDead, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "character_dead",
+		Message: ErrCharacterDead.Error(),
+	})
+
+	mng.Add(ErrCharacterRetired, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "character_retired",
+		Message: ErrCharacterRetired.Error(),
+	})
+
+	mng.Add(ErrCharacterNotDead, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "character_not_dead",
+		Message: ErrCharacterNotDead.Error(),
+	})
+
+	mng.Add(ErrCharacterNotNPC, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "character_not_npc",
+		Message: ErrCharacterNotNPC.Error(),
+	})
+
+	mng.Add(ErrInvalidTransferMode, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_transfer_mode",
+		Message: ErrInvalidTransferMode.Error(),
+	})
+
+	mng.Add(ErrTransferToSameCampaign, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "transfer_to_same_campaign",
+		Message: ErrTransferToSameCampaign.Error(),
+	})
+
+	mng.Add(ErrTransferNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "transfer_not_found",
+		Message: ErrTransferNotFound.Error(),
+	})
+
+	mng.Add(ErrTransferAlreadyDecided, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "transfer_already_decided",
+		Message: ErrTransferAlreadyDecided.Error(),
+	})
+
+	mng.Add(ErrTransferAlreadyPending, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "transfer_already_pending",
+		Message: ErrTransferAlreadyPending.Error(),
+	})
+
+	mng.Add(ErrCampaignNotOpen, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "campaign_not_open",
+		Message: ErrCampaignNotOpen.Error(),
+	})
+
+	mng.Add(ErrPlayerAlreadyHasCharacter, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "player_already_has_character",
+		Message: ErrPlayerAlreadyHasCharacter.Error(),
+	})
+
+	mng.Add(ErrNegativeAbility, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "negative_ability",
+		Message: ErrNegativeAbility.Error(),
+	})
+
+	mng.Add(ErrInvalidItem, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_item",
+		Message: ErrInvalidItem.Error(),
+	})
+
+	mng.Add(ErrInvalidItemQuantity, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_item_quantity",
+		Message: ErrInvalidItemQuantity.Error(),
+	})
+
+	mng.Add(ErrInventoryFull, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "inventory_full",
+		Message: ErrInventoryFull.Error(),
+	})
+
+	mng.Add(ErrInvalidAttack, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_attack",
+		Message: ErrInvalidAttack.Error(),
+	})
+
+	mng.Add(ErrNegativeHitPoints, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "negative_hit_points",
+		Message: ErrNegativeHitPoints.Error(),
+	})
+
 	return mng
 }
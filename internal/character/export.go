@@ -0,0 +1,178 @@
+package character
+
+import (
+	_ "embed"
+	"time"
+)
+
+// ExportVersion is the version of the portable character format produced by Export.
+// Bump it every time a field is renamed or removed, adding optional fields does not
+// require a new version.
+const ExportVersion = 1
+
+// ExportSchemaId identifies the JSON schema describing the ExportDocument of ExportVersion.
+const ExportSchemaId = "https://beldur.app/schemas/character-export.v1.json"
+
+// ExportSchema is the JSON schema (draft 2020-12) of the export format.
+//
+//go:embed export_schema.v1.json
+var ExportSchema []byte
+
+// ExportDocument is the portable representation of a full character sheet.
+// It does not contain any reference to the campaign or the player so that
+// it can be imported in another table.
+type ExportDocument struct {
+	Schema     string          `json:"$schema"`
+	Version    int             `json:"version" validate:"required"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Character  ExportCharacter `json:"character" validate:"required"`
+}
+
+type ExportCharacter struct {
+	Name        string          `json:"name" validate:"required,max=50"`
+	Description string          `json:"description" validate:"max=500"`
+	Notes       string          `json:"notes"`
+	NPC         bool            `json:"npc"`
+	Abilities   ExportAbilities `json:"abilities"`
+	HitPoints   int             `json:"hit_points" validate:"min=0"`
+	Attacks     []ExportAttack  `json:"attacks" validate:"dive"`
+	Inventory   []ExportItem    `json:"inventory" validate:"dive"`
+}
+
+type ExportAbilities struct {
+	Strength     int `json:"strength" validate:"min=0"`
+	Dexterity    int `json:"dexterity" validate:"min=0"`
+	Constitution int `json:"constitution" validate:"min=0"`
+	Intelligence int `json:"intelligence" validate:"min=0"`
+	Wisdom       int `json:"wisdom" validate:"min=0"`
+	Charisma     int `json:"charisma" validate:"min=0"`
+}
+
+type ExportAttack struct {
+	Name   string `json:"name" validate:"required,max=50"`
+	Bonus  int    `json:"bonus"`
+	Damage string `json:"damage" validate:"required,max=20"`
+}
+
+type ExportItem struct {
+	Name        string `json:"name" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=500"`
+	Quantity    int    `json:"quantity" validate:"min=1"`
+}
+
+// ImportResponse is returned once an ExportDocument has been recreated in a campaign.
+type ImportResponse struct {
+	Id         int    `json:"character_id"`
+	CampaignId int    `json:"campaign_id"`
+	Name       string `json:"name"`
+	NPC        bool   `json:"npc"`
+}
+
+func newExportDocument(c *Character) ExportDocument {
+	return ExportDocument{
+		Schema:     ExportSchemaId,
+		Version:    ExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Character:  newSnapshot(c),
+	}
+}
+
+// newSnapshot gives back the full sheet of the character, without any reference to
+// the campaign or the player. It is also the format of the stored character versions.
+func newSnapshot(c *Character) ExportCharacter {
+	items := make([]ExportItem, len(c.inventory.items))
+	for i, it := range c.inventory.items {
+		items[i] = ExportItem{
+			Name:        it.name,
+			Description: it.description,
+			Quantity:    it.quantity,
+		}
+	}
+
+	attacks := make([]ExportAttack, len(c.attacks))
+	for i, a := range c.attacks {
+		attacks[i] = ExportAttack{
+			Name:   a.name,
+			Bonus:  a.bonus,
+			Damage: a.damage.String(),
+		}
+	}
+
+	return ExportCharacter{
+		Name:        c.name,
+		Description: c.description,
+		Notes:       c.notes,
+		NPC:         c.npc,
+		Abilities: ExportAbilities{
+			Strength:     c.abilities.Get(AbilityStrength),
+			Dexterity:    c.abilities.Get(AbilityDexterity),
+			Constitution: c.abilities.Get(AbilityConstitution),
+			Intelligence: c.abilities.Get(AbilityIntelligence),
+			Wisdom:       c.abilities.Get(AbilityWisdom),
+			Charisma:     c.abilities.Get(AbilityCharisma),
+		},
+		HitPoints: c.hitPoints,
+		Attacks:   attacks,
+		Inventory: items,
+	}
+}
+
+// fromExportDocument recreates a not persisted character from the document.
+// The version must match ExportVersion, older documents are not migrated.
+func fromExportDocument(doc ExportDocument) (*Character, error) {
+	if err := checkExportVersion(doc.Version); err != nil {
+		return nil, err
+	}
+	return fromSnapshot(doc.Character)
+}
+
+// checkExportVersion accepts only ExportVersion, a missing version is unsupported too
+func checkExportVersion(version int) error {
+	if version != ExportVersion {
+		return ErrUnsupportedExportVersion
+	}
+	return nil
+}
+
+// fromSnapshot recreates a not persisted character from the sheet
+func fromSnapshot(snap ExportCharacter) (*Character, error) {
+	items := make([]Item, len(snap.Inventory))
+	for i, it := range snap.Inventory {
+		item, err := NewItem(it.Name, it.Description, it.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	inventory, err := NewInventory(items)
+	if err != nil {
+		return nil, err
+	}
+
+	attacks := make([]Attack, len(snap.Attacks))
+	for i, at := range snap.Attacks {
+		attack, err := NewAttack(at.Name, at.Bonus, at.Damage)
+		if err != nil {
+			return nil, err
+		}
+		attacks[i] = attack
+	}
+
+	if snap.HitPoints < 0 {
+		return nil, ErrNegativeHitPoints
+	}
+
+	a := snap.Abilities
+	abilities := NewAbilities(a.Strength, a.Dexterity, a.Constitution, a.Intelligence, a.Wisdom, a.Charisma)
+	if err := abilities.Validate(); err != nil {
+		return nil, err
+	}
+
+	return New(snap.Name, snap.Description,
+		WithAbilities(abilities),
+		WithInventory(inventory),
+		WithHitPoints(snap.HitPoints),
+		WithAttacks(attacks),
+		WithNotes(snap.Notes),
+	), nil
+}
@@ -0,0 +1,91 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportDocument_RoundTrip(t *testing.T) {
+	sword, err := NewItem("sword", "a rusty sword", 1)
+	require.NoError(t, err)
+	inventory, err := NewInventory([]Item{sword})
+	require.NoError(t, err)
+
+	anduril, err := NewAttack("Andúril", 5, "1d8+3")
+	require.NoError(t, err)
+
+	original := New("Aragorn", "a ranger from the north",
+		WithAbilities(NewAbilities(16, 14, 15, 10, 12, 13)),
+		WithInventory(inventory),
+		WithHitPoints(42),
+		WithAttacks([]Attack{anduril}),
+		WithNotes("owes 10 gold to the innkeeper"),
+	)
+
+	doc := newExportDocument(original)
+	assert.Equal(t, ExportVersion, doc.Version)
+	assert.Equal(t, ExportSchemaId, doc.Schema)
+
+	imported, err := fromExportDocument(doc)
+	require.NoError(t, err)
+
+	assert.Equal(t, original.name, imported.name)
+	assert.Equal(t, original.description, imported.description)
+	assert.Equal(t, original.notes, imported.notes)
+	assert.Equal(t, original.abilities, imported.abilities)
+	assert.Equal(t, original.inventory.Items(), imported.inventory.Items())
+	assert.Equal(t, original.hitPoints, imported.hitPoints)
+	assert.Equal(t, original.attacks, imported.attacks)
+}
+
+func TestFromExportDocument_Failure(t *testing.T) {
+	valid := func() ExportDocument {
+		return newExportDocument(New("Legolas", "an elf"))
+	}
+
+	t.Run("version mismatch", func(t *testing.T) {
+		doc := valid()
+		doc.Version = ExportVersion + 1
+		_, err := fromExportDocument(doc)
+		assert.ErrorIs(t, err, ErrUnsupportedExportVersion)
+	})
+
+	t.Run("missing version", func(t *testing.T) {
+		doc := valid()
+		doc.Version = 0
+		_, err := fromExportDocument(doc)
+		assert.ErrorIs(t, err, ErrUnsupportedExportVersion)
+	})
+
+	t.Run("negative ability", func(t *testing.T) {
+		doc := valid()
+		doc.Character.Abilities.Strength = -1
+		_, err := fromExportDocument(doc)
+		assert.ErrorIs(t, err, ErrNegativeAbility)
+	})
+
+	t.Run("too many items", func(t *testing.T) {
+		doc := valid()
+		for i := 0; i <= ItemDefaultCapacity; i++ {
+			doc.Character.Inventory = append(doc.Character.Inventory, ExportItem{Name: "arrow", Quantity: 1})
+		}
+		_, err := fromExportDocument(doc)
+		assert.ErrorIs(t, err, ErrInventoryFull)
+	})
+
+	t.Run("invalid attack damage", func(t *testing.T) {
+		doc := valid()
+		doc.Character.Attacks = []ExportAttack{{Name: "bite", Damage: "a lot"}}
+		_, err := fromExportDocument(doc)
+		assert.ErrorIs(t, err, ErrInvalidAttack)
+	})
+
+	t.Run("invalid item quantity", func(t *testing.T) {
+		doc := valid()
+		doc.Character.Inventory = []ExportItem{{Name: "arrow", Quantity: 0}}
+		_, err := fromExportDocument(doc)
+		assert.ErrorIs(t, err, ErrInvalidItemQuantity)
+	})
+}
@@ -0,0 +1,95 @@
+package character
+
+import (
+	"beldur/pkg/dice"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+//go:embed generator_tables.json
+var generatorTablesJSON []byte
+
+type generatorTables struct {
+	Cultures []struct {
+		Name   string   `json:"name"`
+		Given  []string `json:"given"`
+		Family []string `json:"family"`
+	} `json:"cultures"`
+	Occupations []string `json:"occupations"`
+	Quirks      []string `json:"quirks"`
+}
+
+// GeneratedNPC is a random NPC that has not been saved yet
+type GeneratedNPC struct {
+	Culture    string
+	Name       string
+	Occupation string
+	Quirk      string
+	Abilities  Abilities
+}
+
+// NPCGenerator builds random NPCs from the embedded name, occupation and quirk tables.
+// Given the same roller state it always generates the same NPC.
+type NPCGenerator struct {
+	tables generatorTables
+}
+
+func NewNPCGenerator() *NPCGenerator {
+	var tables generatorTables
+	if err := json.Unmarshal(generatorTablesJSON, &tables); err != nil {
+		panic(fmt.Sprintf("invalid embedded generator tables: %v", err))
+	}
+	return &NPCGenerator{tables: tables}
+}
+
+// Cultures gives back the names of the available cultures
+func (g *NPCGenerator) Cultures() []string {
+	cultures := make([]string, len(g.tables.Cultures))
+	for i, c := range g.tables.Cultures {
+		cultures[i] = c.Name
+	}
+	return cultures
+}
+
+// Generate creates a NPC of the culture, or of a random culture if culture is empty.
+// Abilities are rolled with 4d6, dropping the lowest die.
+func (g *NPCGenerator) Generate(culture string, roller dice.Roller) (GeneratedNPC, error) {
+	idx := slices.Index(g.Cultures(), culture)
+	if culture == "" {
+		idx = roller.IntN(len(g.tables.Cultures))
+	}
+	if idx < 0 {
+		return GeneratedNPC{}, ErrUnknownCulture
+	}
+	c := g.tables.Cultures[idx]
+
+	name := fmt.Sprintf("%s %s", pick(roller, c.Given), pick(roller, c.Family))
+	occupation := pick(roller, g.tables.Occupations)
+	quirk := pick(roller, g.tables.Quirks)
+
+	abilities := NewDefaultAbilities()
+	for _, ability := range AllAbilityStats {
+		abilities.Set(ability, rollAbilityScore(roller))
+	}
+
+	return GeneratedNPC{
+		Culture:    c.Name,
+		Name:       name,
+		Occupation: occupation,
+		Quirk:      quirk,
+		Abilities:  abilities,
+	}, nil
+}
+
+func pick(roller dice.Roller, values []string) string {
+	return values[roller.IntN(len(values))]
+}
+
+// rollAbilityScore rolls 4d6 and sums the three highest
+func rollAbilityScore(roller dice.Roller) int {
+	rolls := dice.Expr{Count: 4, Sides: 6}.Roll(roller).Rolls
+	slices.Sort(rolls)
+	return rolls[1] + rolls[2] + rolls[3]
+}
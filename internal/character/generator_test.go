@@ -0,0 +1,44 @@
+package character
+
+import (
+	"beldur/pkg/dice"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNPCGenerator_Generate(t *testing.T) {
+	g := NewNPCGenerator()
+
+	t.Run("same seed same npc", func(t *testing.T) {
+		first, err := g.Generate("dwarf", dice.NewSeededRoller(1234))
+		require.NoError(t, err)
+		second, err := g.Generate("dwarf", dice.NewSeededRoller(1234))
+		require.NoError(t, err)
+
+		assert.Equal(t, first, second)
+		assert.Equal(t, "dwarf", first.Culture)
+		assert.NotEmpty(t, first.Name)
+		assert.NotEmpty(t, first.Occupation)
+		assert.NotEmpty(t, first.Quirk)
+	})
+
+	t.Run("abilities between 3 and 18", func(t *testing.T) {
+		roller := dice.NewSeededRoller(99)
+		for i := 0; i < 50; i++ {
+			npc, err := g.Generate("", roller)
+			require.NoError(t, err)
+			assert.Contains(t, g.Cultures(), npc.Culture)
+			for _, ability := range AllAbilityStats {
+				assert.GreaterOrEqual(t, npc.Abilities.Get(ability), 3)
+				assert.LessOrEqual(t, npc.Abilities.Get(ability), 18)
+			}
+		}
+	})
+
+	t.Run("unknown culture", func(t *testing.T) {
+		_, err := g.Generate("gnoll", dice.NewSeededRoller(1))
+		assert.ErrorIs(t, err, ErrUnknownCulture)
+	})
+}
@@ -4,20 +4,45 @@ import (
 	"beldur/internal/id"
 	"beldur/pkg/httperr"
 	"beldur/pkg/middleware"
+	"context"
+	"fmt"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type HttpHandler struct {
-	createUC   *CreateUseCase
-	errManager *httperr.Manager
+	createUC      *CreateUseCase
+	portabilityUC *PortabilityUseCase
+	sheetUC       *SheetUseCase
+	generateUC    *GenerateUseCase
+	editUC        *EditUseCase
+	historyUC     *HistoryUseCase
+	lifecycleUC   *LifecycleUseCase
+	transferUC    *TransferUseCase
+	errManager    *httperr.Manager
 }
 
-func NewHttpHandler(createUC *CreateUseCase) *HttpHandler {
+func NewHttpHandler(
+	createUC *CreateUseCase,
+	portabilityUC *PortabilityUseCase,
+	sheetUC *SheetUseCase,
+	generateUC *GenerateUseCase,
+	editUC *EditUseCase,
+	historyUC *HistoryUseCase,
+	lifecycleUC *LifecycleUseCase,
+	transferUC *TransferUseCase,
+) *HttpHandler {
 	return &HttpHandler{
-		createUC:   createUC,
-		errManager: NewCharacterApiErrorManager(),
+		createUC:      createUC,
+		portabilityUC: portabilityUC,
+		sheetUC:       sheetUC,
+		generateUC:    generateUC,
+		editUC:        editUC,
+		historyUC:     historyUC,
+		lifecycleUC:   lifecycleUC,
+		transferUC:    transferUC,
+		errManager:    NewCharacterApiErrorManager(),
 	}
 }
 
@@ -45,3 +70,375 @@ func (h *HttpHandler) HandleNpcCreation(c *fiber.Ctx) error {
 	}
 	return c.Status(fiber.StatusCreated).JSON(resp)
 }
+
+// HandleNpcGeneration gives back a random NPC preview, that is not saved
+func (h *HttpHandler) HandleNpcGeneration(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(GenerateNPCRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.generateUC.GenerateNPC(c.Context(), req, id.CampaignId(campId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// HandleExport sends the character sheet as a downloadable versioned JSON document
+func (h *HttpHandler) HandleExport(c *fiber.Ctx) error {
+	charInstr := c.Params("id")
+	if charInstr == "" {
+		panic("wrong parameter naming")
+	}
+	charId, err := strconv.Atoi(charInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	doc, err := h.portabilityUC.Export(c.Context(), id.CharacterId(charId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+
+	c.Attachment(fmt.Sprintf("character-%d.json", charId))
+	return c.Status(fiber.StatusOK).JSON(doc)
+}
+
+// CheckImportVersion rejects the documents of another export version before the body is
+// validated, their fields may not match the ones of ExportDocument.
+func (h *HttpHandler) CheckImportVersion(c *fiber.Ctx) error {
+	var head struct {
+		Version int `json:"version"`
+	}
+	if err := c.BodyParser(&head); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	if err := checkExportVersion(head.Version); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Next()
+}
+
+func (h *HttpHandler) HandleImport(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	doc := c.Locals("body").(ExportDocument)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.portabilityUC.Import(c.Context(), doc, id.CampaignId(campId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// HandleExportSchema require no authentication
+func (h *HttpHandler) HandleExportSchema(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, "application/schema+json")
+	return c.Status(fiber.StatusOK).Send(ExportSchema)
+}
+
+// HandleSheetPDF sends the printable sheet of the character
+func (h *HttpHandler) HandleSheetPDF(c *fiber.Ctx) error {
+	charInstr := c.Params("id")
+	if charInstr == "" {
+		panic("wrong parameter naming")
+	}
+	charId, err := strconv.Atoi(charInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	pdf, err := h.sheetUC.RenderPDF(c.Context(), id.CharacterId(charId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="character-%d.pdf"`, charId))
+	return c.Status(fiber.StatusOK).Send(pdf)
+}
+
+func (h *HttpHandler) HandleUpdate(c *fiber.Ctx) error {
+	charInstr := c.Params("id")
+	if charInstr == "" {
+		panic("wrong parameter naming")
+	}
+	charId, err := strconv.Atoi(charInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(UpdateCharacterRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.editUC.Update(c.Context(), req, id.CharacterId(charId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleHistory(c *fiber.Ctx) error {
+	charInstr := c.Params("id")
+	if charInstr == "" {
+		panic("wrong parameter naming")
+	}
+	charId, err := strconv.Atoi(charInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.historyUC.History(c.Context(), id.CharacterId(charId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleRestore(c *fiber.Ctx) error {
+	charInstr := c.Params("id")
+	versionInstr := c.Params("version")
+	if charInstr == "" || versionInstr == "" {
+		panic("wrong parameter naming")
+	}
+	charId, err := strconv.Atoi(charInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	version, err := strconv.Atoi(versionInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.historyUC.Restore(c.Context(), id.CharacterId(charId), version, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleDeath(c *fiber.Ctx) error {
+	return h.handleStatusChange(c, h.lifecycleUC.Kill)
+}
+
+func (h *HttpHandler) HandleRetirement(c *fiber.Ctx) error {
+	return h.handleStatusChange(c, h.lifecycleUC.Retire)
+}
+
+func (h *HttpHandler) HandleResurrection(c *fiber.Ctx) error {
+	return h.handleStatusChange(c, h.lifecycleUC.Resurrect)
+}
+
+func (h *HttpHandler) handleStatusChange(
+	c *fiber.Ctx,
+	change func(context.Context, id.CharacterId, id.PlayerId) (LifecycleResponse, error),
+) error {
+	charInstr := c.Params("id")
+	if charInstr == "" {
+		panic("wrong parameter naming")
+	}
+	charId, err := strconv.Atoi(charInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := change(c.Context(), id.CharacterId(charId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleDelete(c *fiber.Ctx) error {
+	charInstr := c.Params("id")
+	if charInstr == "" {
+		panic("wrong parameter naming")
+	}
+	charId, err := strconv.Atoi(charInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.lifecycleUC.DeleteNPC(c.Context(), id.CharacterId(charId), p.PlayerID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *HttpHandler) HandleGraveyard(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.lifecycleUC.Graveyard(c.Context(), id.CampaignId(campId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleTransferRequest(c *fiber.Ctx) error {
+	charInstr := c.Params("id")
+	if charInstr == "" {
+		panic("wrong parameter naming")
+	}
+	charId, err := strconv.Atoi(charInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(TransferRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.transferUC.RequestTransfer(c.Context(), req, id.CharacterId(charId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *HttpHandler) HandlePendingTransfers(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.transferUC.PendingTransfers(c.Context(), id.CampaignId(campId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleTransferApproval(c *fiber.Ctx) error {
+	return h.handleTransferDecision(c, h.transferUC.Approve)
+}
+
+func (h *HttpHandler) HandleTransferRejection(c *fiber.Ctx) error {
+	return h.handleTransferDecision(c, h.transferUC.Reject)
+}
+
+func (h *HttpHandler) handleTransferDecision(
+	c *fiber.Ctx,
+	decide func(context.Context, id.CampaignId, id.TransferId, id.PlayerId) (TransferResponse, error),
+) error {
+	campaignInstr := c.Params("campaignId")
+	transferInstr := c.Params("transferId")
+	if campaignInstr == "" || transferInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	transferId, err := strconv.Atoi(transferInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := decide(c.Context(), id.CampaignId(campId), id.TransferId(transferId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
@@ -0,0 +1,71 @@
+package character
+
+import (
+	"beldur/internal/id"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Version is a snapshot of the character taken after every change of its sheet.
+// Version numbers start from 1, the creation of the character.
+type Version struct {
+	characterId id.CharacterId
+	number      int
+	authorId    id.PlayerId
+	createdAt   time.Time
+	snapshot    ExportCharacter
+	// changes from the previous version, empty for the first one
+	changes []Change
+	// set if the version is the restore of an older one
+	restoredFrom *int
+}
+
+// Change of a single field of the sheet between two versions
+type Change struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+func (v *Version) Number() int { return v.number }
+
+// diffSnapshots lists the fields that changed from prev to next.
+// Attacks and inventory are compared as a whole.
+func diffSnapshots(prev, next ExportCharacter) []Change {
+	changes := make([]Change, 0)
+	add := func(field string, from, to any) {
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, Change{Field: field, From: from, To: to})
+		}
+	}
+
+	add("name", prev.Name, next.Name)
+	add("description", prev.Description, next.Description)
+	add("notes", prev.Notes, next.Notes)
+	add("hit_points", prev.HitPoints, next.HitPoints)
+
+	pa, na := prev.Abilities, next.Abilities
+	add(abilityField(AbilityStrength), pa.Strength, na.Strength)
+	add(abilityField(AbilityDexterity), pa.Dexterity, na.Dexterity)
+	add(abilityField(AbilityConstitution), pa.Constitution, na.Constitution)
+	add(abilityField(AbilityIntelligence), pa.Intelligence, na.Intelligence)
+	add(abilityField(AbilityWisdom), pa.Wisdom, na.Wisdom)
+	add(abilityField(AbilityCharisma), pa.Charisma, na.Charisma)
+
+	add("attacks", nonNil(prev.Attacks), nonNil(next.Attacks))
+	add("inventory", nonNil(prev.Inventory), nonNil(next.Inventory))
+	return changes
+}
+
+func abilityField(ability AbilityStat) string {
+	return fmt.Sprintf("abilities.%s", ability)
+}
+
+// nonNil avoids reporting a change between a nil and an empty slice
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package character
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	c := New("Boromir", "son of Denethor", WithAbilities(NewAbilities(16, 12, 14, 10, 10, 13)))
+	before := newSnapshot(c)
+
+	t.Run("no changes", func(t *testing.T) {
+		assert.Empty(t, diffSnapshots(before, newSnapshot(c)))
+	})
+
+	t.Run("changed fields", func(t *testing.T) {
+		edited := New("Boromir", "captain of Gondor", WithAbilities(NewAbilities(18, 12, 14, 10, 10, 13)))
+		horn, err := NewItem("horn of Gondor", "", 1)
+		require.NoError(t, err)
+		edited.inventory.AddItem(horn)
+
+		changes := diffSnapshots(before, newSnapshot(edited))
+		require.Len(t, changes, 3)
+		assert.Equal(t, Change{Field: "description", From: "son of Denethor", To: "captain of Gondor"}, changes[0])
+		assert.Equal(t, Change{Field: "abilities.strength", From: 16, To: 18}, changes[1])
+		assert.Equal(t, "inventory", changes[2].Field)
+	})
+}
+
+func TestApplyUpdate(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	intPtr := func(i int) *int { return &i }
+
+	t.Run("only set fields change", func(t *testing.T) {
+		c := New("Sam", "a gardener", WithNotes("likes potatoes"))
+		err := applyUpdate(c, UpdateCharacterRequest{
+			Description: strPtr("a loyal gardener"),
+			HitPoints:   intPtr(12),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Sam", c.name)
+		assert.Equal(t, "a loyal gardener", c.description)
+		assert.Equal(t, "likes potatoes", c.notes)
+		assert.Equal(t, 12, c.hitPoints)
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		c := New("Sam", "a gardener")
+		err := applyUpdate(c, UpdateCharacterRequest{Name: strPtr("")})
+		assert.ErrorIs(t, err, ErrInvalidCharacterName)
+	})
+
+	t.Run("negative ability", func(t *testing.T) {
+		c := New("Sam", "a gardener")
+		err := applyUpdate(c, UpdateCharacterRequest{Abilities: &ExportAbilities{Strength: -2}})
+		assert.ErrorIs(t, err, ErrNegativeAbility)
+	})
+}
@@ -1,8 +1,35 @@
 package character
 
+import "beldur/internal/id"
+
 const ItemDefaultCapacity = 10
 
-type Item struct{}
+type Item struct {
+	id          id.ItemId
+	name        string
+	description string
+	quantity    int
+}
+
+func NewItem(name, description string, quantity int) (Item, error) {
+	if name == "" {
+		return Item{}, ErrInvalidItem
+	}
+	if quantity < 1 {
+		return Item{}, ErrInvalidItemQuantity
+	}
+	return Item{
+		name:        name,
+		description: description,
+		quantity:    quantity,
+	}, nil
+}
+
+func (i Item) Name() string { return i.name }
+
+func (i Item) Description() string { return i.description }
+
+func (i Item) Quantity() int { return i.quantity }
 
 type Inventory struct {
 	capacity int
@@ -16,6 +43,20 @@ func NewEmptyInventory() Inventory {
 	}
 }
 
+// NewInventory builds an inventory with the default capacity already filled with items.
+func NewInventory(items []Item) (Inventory, error) {
+	inv := NewEmptyInventory()
+	if len(items) > inv.capacity {
+		return Inventory{}, ErrInventoryFull
+	}
+	inv.items = append(inv.items, items...)
+	return inv, nil
+}
+
 func (i *Inventory) AddItem(item Item) {
 	i.items = append(i.items, item)
 }
+
+func (i *Inventory) Items() []Item {
+	return i.items
+}
@@ -4,6 +4,12 @@ import (
 	"beldur/internal/id"
 	"beldur/pkg/db/postgres"
 	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type PostgresRepository struct {
@@ -32,6 +38,8 @@ func (p *PostgresRepository) SaveNPC(
 	return p.save(ctx, c, campaignId, masterId, true)
 }
 
+// save inserts the character along with its inventory and its first version.
+// It should be called inside a transaction.
 func (p *PostgresRepository) save(
 	ctx context.Context,
 	c *Character,
@@ -41,10 +49,11 @@ func (p *PostgresRepository) save(
 ) error {
 	const query = `
 		INSERT INTO characters 
-		    (campaign_id, player_id, name, description, 
+		    (campaign_id, player_id, name, description, notes,
 		     base_strength, base_dexterity, base_constitution, 
-		     base_intelligence, base_wisdom, base_charisma, is_npc)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		     base_intelligence, base_wisdom, base_charisma, is_npc, hit_points,
+		     status, died_at, retired_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 		RETURNING character_id
 	`
 
@@ -53,6 +62,7 @@ func (p *PostgresRepository) save(
 		int(masterId),
 		c.name,
 		c.description,
+		c.notes,
 		c.abilities.Get(AbilityStrength),
 		c.abilities.Get(AbilityDexterity),
 		c.abilities.Get(AbilityConstitution),
@@ -60,11 +70,764 @@ func (p *PostgresRepository) save(
 		c.abilities.Get(AbilityWisdom),
 		c.abilities.Get(AbilityCharisma),
 		isNPC,
+		c.hitPoints,
+		string(c.status),
+		c.diedAt,
+		c.retiredAt,
 	)
 	var characterID int
 	if err := row.Scan(&characterID); err != nil {
 		return err
 	}
 	c.id = id.CharacterId(characterID)
+	c.campaignId = campaignId
+	c.playerId = masterId
+	c.npc = isNPC
+
+	if err := p.saveItems(ctx, c); err != nil {
+		return err
+	}
+	if err := p.saveAttacks(ctx, c); err != nil {
+		return err
+	}
+	return p.saveVersion(ctx, c, masterId, nil)
+}
+
+// Update persists the sheet of the character and records a new version authored by the editor.
+// It should be called inside a transaction.
+func (p *PostgresRepository) Update(ctx context.Context, c *Character, editorId id.PlayerId) error {
+	return p.update(ctx, c, editorId, nil)
+}
+
+// Restore persists the sheet of the character, previously rebuilt from an older version,
+// and records a new version that references it. It should be called inside a transaction.
+func (p *PostgresRepository) Restore(ctx context.Context, c *Character, editorId id.PlayerId, version int) error {
+	return p.update(ctx, c, editorId, &version)
+}
+
+func (p *PostgresRepository) update(ctx context.Context, c *Character, editorId id.PlayerId, restoredFrom *int) error {
+	const query = `
+		UPDATE characters
+		SET name = $1,
+		    description = $2,
+		    notes = $3,
+		    base_strength = $4,
+		    base_dexterity = $5,
+		    base_constitution = $6,
+		    base_intelligence = $7,
+		    base_wisdom = $8,
+		    base_charisma = $9,
+		    hit_points = $10
+		WHERE character_id = $11
+	`
+
+	cmd, err := p.q(ctx).Exec(ctx, query,
+		c.name,
+		c.description,
+		c.notes,
+		c.abilities.Get(AbilityStrength),
+		c.abilities.Get(AbilityDexterity),
+		c.abilities.Get(AbilityConstitution),
+		c.abilities.Get(AbilityIntelligence),
+		c.abilities.Get(AbilityWisdom),
+		c.abilities.Get(AbilityCharisma),
+		c.hitPoints,
+		int(c.id),
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+
+	// items and attacks are replaced as a whole
+	if _, err := p.q(ctx).Exec(ctx, `DELETE FROM character_items WHERE character_id = $1`, int(c.id)); err != nil {
+		return err
+	}
+	if _, err := p.q(ctx).Exec(ctx, `DELETE FROM character_attacks WHERE character_id = $1`, int(c.id)); err != nil {
+		return err
+	}
+	if err := p.saveItems(ctx, c); err != nil {
+		return err
+	}
+	if err := p.saveAttacks(ctx, c); err != nil {
+		return err
+	}
+	return p.saveVersion(ctx, c, editorId, restoredFrom)
+}
+
+// saveVersion records the current sheet as the next version of the character.
+// Nothing is recorded if the sheet did not change, unless it is a restore.
+func (p *PostgresRepository) saveVersion(ctx context.Context, c *Character, authorId id.PlayerId, restoredFrom *int) error {
+	const sqlLast = `
+		SELECT snapshot
+		FROM character_versions
+		WHERE character_id = $1
+		ORDER BY version DESC
+		LIMIT 1
+	`
+
+	const sqlInsert = `
+		INSERT INTO character_versions (character_id, version, author_id, snapshot, changes, restored_from)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4, $5
+		FROM character_versions
+		WHERE character_id = $1
+	`
+
+	next := newSnapshot(c)
+	changes := make([]Change, 0)
+
+	var lastJSON []byte
+	err := p.q(ctx).QueryRow(ctx, sqlLast, int(c.id)).Scan(&lastJSON)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// first version
+	case err != nil:
+		return err
+	default:
+		var last ExportCharacter
+		if err := json.Unmarshal(lastJSON, &last); err != nil {
+			return err
+		}
+		changes = diffSnapshots(last, next)
+		if len(changes) == 0 && restoredFrom == nil {
+			return nil
+		}
+	}
+
+	snapshotJSON, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.q(ctx).Exec(ctx, sqlInsert, int(c.id), int(authorId), snapshotJSON, changesJSON, restoredFrom)
+	return err
+}
+
+func (p *PostgresRepository) FindVersions(ctx context.Context, characterId id.CharacterId) ([]*Version, error) {
+	const query = `
+		SELECT character_id, version, author_id, created_at, snapshot, changes, restored_from
+		FROM character_versions
+		WHERE character_id = $1
+		ORDER BY version DESC
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(characterId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]*Version, 0)
+	for rows.Next() {
+		v, err := p.scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (p *PostgresRepository) FindVersion(ctx context.Context, characterId id.CharacterId, number int) (*Version, error) {
+	const query = `
+		SELECT character_id, version, author_id, created_at, snapshot, changes, restored_from
+		FROM character_versions
+		WHERE character_id = $1 AND version = $2
+	`
+
+	v, err := p.scanVersion(p.q(ctx).QueryRow(ctx, query, int(characterId), number))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+	return v, nil
+}
+
+func (p *PostgresRepository) scanVersion(row pgx.Row) (*Version, error) {
+	var (
+		characterID  int
+		number       int
+		authorID     int
+		createdAt    time.Time
+		snapshotJSON []byte
+		changesJSON  []byte
+		restoredFrom *int
+	)
+
+	if err := row.Scan(&characterID, &number, &authorID, &createdAt, &snapshotJSON, &changesJSON, &restoredFrom); err != nil {
+		return nil, err
+	}
+
+	v := &Version{
+		characterId:  id.CharacterId(characterID),
+		number:       number,
+		authorId:     id.PlayerId(authorID),
+		createdAt:    createdAt,
+		restoredFrom: restoredFrom,
+	}
+	if err := json.Unmarshal(snapshotJSON, &v.snapshot); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(changesJSON, &v.changes); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (p *PostgresRepository) saveItems(ctx context.Context, c *Character) error {
+	const query = `
+		INSERT INTO character_items (character_id, name, description, quantity)
+		VALUES ($1, $2, $3, $4)
+		RETURNING item_id
+	`
+
+	for i := range c.inventory.items {
+		item := &c.inventory.items[i]
+		var itemID int
+		if err := p.q(ctx).QueryRow(ctx, query,
+			int(c.id),
+			item.name,
+			item.description,
+			item.quantity,
+		).Scan(&itemID); err != nil {
+			return err
+		}
+		item.id = id.ItemId(itemID)
+	}
 	return nil
 }
+
+func (p *PostgresRepository) saveAttacks(ctx context.Context, c *Character) error {
+	const query = `
+		INSERT INTO character_attacks (character_id, position, name, bonus, damage)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	for i, a := range c.attacks {
+		if _, err := p.q(ctx).Exec(ctx, query,
+			int(c.id),
+			i,
+			a.name,
+			a.bonus,
+			a.damage.String(),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const selectCharacter = `
+	SELECT
+	    character_id,
+	    campaign_id,
+	    player_id,
+	    name,
+	    COALESCE(description, ''),
+	    notes,
+	    COALESCE(is_npc, FALSE),
+	    base_strength,
+	    base_dexterity,
+	    base_constitution,
+	    base_intelligence,
+	    base_wisdom,
+	    base_charisma,
+	    hit_points,
+	    status,
+	    died_at,
+	    retired_at
+	FROM characters
+`
+
+func (p *PostgresRepository) FindById(ctx context.Context, characterId id.CharacterId) (*Character, error) {
+	const query = selectCharacter + `WHERE character_id = $1`
+
+	c, err := p.scanCharacter(p.q(ctx).QueryRow(ctx, query, int(characterId)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+
+	items, err := p.findItems(ctx, characterId)
+	if err != nil {
+		return nil, err
+	}
+
+	attacks, err := p.findAttacks(ctx, characterId)
+	if err != nil {
+		return nil, err
+	}
+
+	c.attacks = attacks
+	c.inventory.items = append(c.inventory.items, items...)
+	return c, nil
+}
+
+// FindGraveyard gives back the dead and retired characters of the campaign,
+// the most recent first. Inventory and attacks are not loaded.
+func (p *PostgresRepository) FindGraveyard(ctx context.Context, campaignId id.CampaignId) ([]*Character, error) {
+	const query = selectCharacter + `
+		WHERE campaign_id = $1 AND status <> $2
+		ORDER BY COALESCE(died_at, retired_at) DESC, character_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(campaignId), string(StatusActive))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	characters := make([]*Character, 0)
+	for rows.Next() {
+		c, err := p.scanCharacter(rows)
+		if err != nil {
+			return nil, err
+		}
+		characters = append(characters, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return characters, nil
+}
+
+func (p *PostgresRepository) HasActivePlayerCharacter(ctx context.Context, campaignId id.CampaignId, playerId id.PlayerId) (bool, error) {
+	const query = `
+		SELECT EXISTS (
+			SELECT 1
+			FROM characters
+			WHERE campaign_id = $1
+			  AND player_id = $2
+			  AND NOT COALESCE(is_npc, FALSE)
+			  AND status = $3
+		)
+	`
+
+	var exists bool
+	if err := p.q(ctx).QueryRow(ctx, query, int(campaignId), int(playerId), string(StatusActive)).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// scanCharacter translates DB row -> domain model, without inventory and attacks.
+func (p *PostgresRepository) scanCharacter(row pgx.Row) (*Character, error) {
+	var (
+		characterID  int
+		campaignID   int
+		playerID     int
+		name         string
+		description  string
+		notes        string
+		isNPC        bool
+		strength     int
+		dexterity    int
+		constitution int
+		intelligence int
+		wisdom       int
+		charisma     int
+		hitPoints    int
+		status       StatusCharacter
+		diedAt       *time.Time
+		retiredAt    *time.Time
+	)
+
+	if err := row.Scan(
+		&characterID,
+		&campaignID,
+		&playerID,
+		&name,
+		&description,
+		&notes,
+		&isNPC,
+		&strength,
+		&dexterity,
+		&constitution,
+		&intelligence,
+		&wisdom,
+		&charisma,
+		&hitPoints,
+		&status,
+		&diedAt,
+		&retiredAt,
+	); err != nil {
+		return nil, err
+	}
+
+	c := New(name, description,
+		WithNotes(notes),
+		WithAbilities(NewAbilities(strength, dexterity, constitution, intelligence, wisdom, charisma)),
+		WithHitPoints(hitPoints),
+	)
+	c.id = id.CharacterId(characterID)
+	c.campaignId = id.CampaignId(campaignID)
+	c.playerId = id.PlayerId(playerID)
+	c.npc = isNPC
+	c.status = status
+	c.diedAt = diedAt
+	c.retiredAt = retiredAt
+	return c, nil
+}
+
+// UpdateStatus persists the lifecycle status of the character.
+// The status is not part of the sheet, so no version is recorded.
+func (p *PostgresRepository) UpdateStatus(ctx context.Context, c *Character) error {
+	const query = `
+		UPDATE characters
+		SET status = $1,
+		    died_at = $2,
+		    retired_at = $3
+		WHERE character_id = $4
+	`
+
+	cmd, err := p.q(ctx).Exec(ctx, query, string(c.status), c.diedAt, c.retiredAt, int(c.id))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+// Delete removes the character. Items, attacks and versions are deleted with it.
+func (p *PostgresRepository) Delete(ctx context.Context, characterId id.CharacterId) error {
+	const query = `DELETE FROM characters WHERE character_id = $1`
+
+	cmd, err := p.q(ctx).Exec(ctx, query, int(characterId))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowFound
+	}
+	return nil
+}
+
+func (p *PostgresRepository) findItems(ctx context.Context, characterId id.CharacterId) ([]Item, error) {
+	const query = `
+		SELECT item_id, name, description, quantity
+		FROM character_items
+		WHERE character_id = $1
+		ORDER BY item_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(characterId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]Item, 0)
+	for rows.Next() {
+		var (
+			itemID int
+			item   Item
+		)
+		if err := rows.Scan(&itemID, &item.name, &item.description, &item.quantity); err != nil {
+			return nil, err
+		}
+		item.id = id.ItemId(itemID)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (p *PostgresRepository) findAttacks(ctx context.Context, characterId id.CharacterId) ([]Attack, error) {
+	const query = `
+		SELECT name, bonus, damage
+		FROM character_attacks
+		WHERE character_id = $1
+		ORDER BY position
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(characterId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attacks := make([]Attack, 0)
+	for rows.Next() {
+		var (
+			name   string
+			bonus  int
+			damage string
+		)
+		if err := rows.Scan(&name, &bonus, &damage); err != nil {
+			return nil, err
+		}
+		// stored attacks are always valid
+		a, err := NewAttack(name, bonus, damage)
+		if err != nil {
+			return nil, err
+		}
+		attacks = append(attacks, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return attacks, nil
+}
+
+// LockNPCNames holds a lock on the NPC names of the campaign until the end of the transaction,
+// so two spawns can't number their NPCs after the same names.
+func (p *PostgresRepository) LockNPCNames(ctx context.Context, campaignId id.CampaignId) error {
+	_, err := p.q(ctx).Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('npc_names'), $1)`, int(campaignId))
+	return err
+}
+
+// FindNPCNames gives back the names of the NPCs of the campaign that start with "prefix ".
+func (p *PostgresRepository) FindNPCNames(ctx context.Context, campaignId id.CampaignId, prefix string) ([]string, error) {
+	const query = `
+		SELECT name
+		FROM characters
+		WHERE campaign_id = $1
+		  AND is_npc
+		  AND left(name, char_length($2) + 1) = $2 || ' '
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(campaignId), prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+const selectTransfer = `
+	SELECT
+	    transfer_id,
+	    character_id,
+	    player_id,
+	    source_campaign_id,
+	    target_campaign_id,
+	    mode,
+	    status,
+	    created_at,
+	    decided_at,
+	    result_character_id
+	FROM character_transfers
+`
+
+// SaveTransfer inserts a pending transfer. Only one transfer of the character
+// to the same campaign can be pending, otherwise postgres.ErrUniqueValueViolation is returned.
+func (p *PostgresRepository) SaveTransfer(ctx context.Context, t *Transfer) error {
+	const query = `
+		INSERT INTO character_transfers
+		    (character_id, player_id, source_campaign_id, target_campaign_id, mode, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING transfer_id
+	`
+
+	var transferID int
+	if err := p.q(ctx).QueryRow(ctx, query,
+		int(t.characterId),
+		int(t.playerId),
+		int(t.sourceCampaignId),
+		int(t.targetCampaignId),
+		string(t.mode),
+		string(t.status),
+		t.createdAt,
+	).Scan(&transferID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return postgres.ErrUniqueValueViolation
+		}
+		return err
+	}
+	t.id = id.TransferId(transferID)
+	return nil
+}
+
+func (p *PostgresRepository) UpdateTransfer(ctx context.Context, t *Transfer) error {
+	const query = `
+		UPDATE character_transfers
+		SET status = $1,
+		    decided_at = $2,
+		    result_character_id = $3
+		WHERE transfer_id = $4
+	`
+
+	var resultID *int
+	if t.resultCharacterId != nil {
+		rid := int(*t.resultCharacterId)
+		resultID = &rid
+	}
+
+	cmd, err := p.q(ctx).Exec(ctx, query, string(t.status), t.decidedAt, resultID, int(t.id))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (p *PostgresRepository) FindTransferById(ctx context.Context, transferId id.TransferId) (*Transfer, error) {
+	const query = selectTransfer + `WHERE transfer_id = $1`
+
+	t, err := p.scanTransfer(p.q(ctx).QueryRow(ctx, query, int(transferId)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// FindPendingTransfers gives back the transfers waiting for the approval of the target campaign, oldest first.
+func (p *PostgresRepository) FindPendingTransfers(ctx context.Context, targetCampaignId id.CampaignId) ([]*Transfer, error) {
+	const query = selectTransfer + `
+		WHERE target_campaign_id = $1 AND status = $2
+		ORDER BY created_at, transfer_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(targetCampaignId), string(TransferPending))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := make([]*Transfer, 0)
+	for rows.Next() {
+		t, err := p.scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+func (p *PostgresRepository) scanTransfer(row pgx.Row) (*Transfer, error) {
+	var (
+		transferID  int
+		characterID int
+		playerID    int
+		sourceID    int
+		targetID    int
+		mode        TransferMode
+		status      TransferStatus
+		createdAt   time.Time
+		decidedAt   *time.Time
+		resultID    *int
+	)
+
+	if err := row.Scan(
+		&transferID,
+		&characterID,
+		&playerID,
+		&sourceID,
+		&targetID,
+		&mode,
+		&status,
+		&createdAt,
+		&decidedAt,
+		&resultID,
+	); err != nil {
+		return nil, err
+	}
+
+	t := &Transfer{
+		id:               id.TransferId(transferID),
+		characterId:      id.CharacterId(characterID),
+		playerId:         id.PlayerId(playerID),
+		sourceCampaignId: id.CampaignId(sourceID),
+		targetCampaignId: id.CampaignId(targetID),
+		mode:             mode,
+		status:           status,
+		createdAt:        createdAt,
+		decidedAt:        decidedAt,
+	}
+	if resultID != nil {
+		rid := id.CharacterId(*resultID)
+		t.resultCharacterId = &rid
+	}
+	return t, nil
+}
+
+func (p *PostgresRepository) FindExportCharacters(ctx context.Context, playerId id.PlayerId) ([]ExportedCharacter, error) {
+	const query = `
+		SELECT character_id, campaign_id, name, description, notes, status
+		FROM characters
+		WHERE player_id = $1
+		ORDER BY character_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	characters := make([]ExportedCharacter, 0)
+	for rows.Next() {
+		var c ExportedCharacter
+		if err := rows.Scan(&c.CharacterId, &c.CampaignId, &c.Name, &c.Description, &c.Notes, &c.Status); err != nil {
+			return nil, err
+		}
+		characters = append(characters, c)
+	}
+	return characters, rows.Err()
+}
+
+func (p *PostgresRepository) FindExportVersions(ctx context.Context, playerId id.PlayerId) ([]ExportedVersion, error) {
+	const query = `
+		SELECT v.character_id, v.version, v.author_id, v.snapshot, v.changes, v.restored_from, v.created_at
+		FROM character_versions v
+		JOIN characters c ON c.character_id = v.character_id
+		WHERE c.player_id = $1 OR v.author_id = $1
+		ORDER BY v.character_id, v.version
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]ExportedVersion, 0)
+	for rows.Next() {
+		var v ExportedVersion
+		err := rows.Scan(&v.CharacterId, &v.Version, &v.AuthorId, &v.Snapshot, &v.Changes, &v.RestoredFrom, &v.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
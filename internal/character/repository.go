@@ -14,3 +14,49 @@ type Saver interface {
 	SavePlayerCharacter(ctx context.Context, character *Character, campaignId id.CampaignId, playerId id.PlayerId) error
 	SaveNPC(ctx context.Context, character *Character, campaignId id.CampaignId, masterId id.PlayerId) error
 }
+
+type Finder interface {
+	FindById(ctx context.Context, characterId id.CharacterId) (*Character, error)
+}
+
+type PlayerCharacterFinder interface {
+	// HasActivePlayerCharacter tells if the player plays an active character in the campaign
+	HasActivePlayerCharacter(ctx context.Context, campaignId id.CampaignId, playerId id.PlayerId) (bool, error)
+}
+
+type Updater interface {
+	Update(ctx context.Context, character *Character, editorId id.PlayerId) error
+	Restore(ctx context.Context, character *Character, editorId id.PlayerId, version int) error
+	UpdateStatus(ctx context.Context, character *Character) error
+}
+
+type Deleter interface {
+	Delete(ctx context.Context, characterId id.CharacterId) error
+}
+
+type GraveyardFinder interface {
+	FindGraveyard(ctx context.Context, campaignId id.CampaignId) ([]*Character, error)
+}
+
+type VersionFinder interface {
+	FindVersions(ctx context.Context, characterId id.CharacterId) ([]*Version, error)
+	FindVersion(ctx context.Context, characterId id.CharacterId, number int) (*Version, error)
+}
+
+type TransferSaver interface {
+	SaveTransfer(ctx context.Context, transfer *Transfer) error
+	UpdateTransfer(ctx context.Context, transfer *Transfer) error
+}
+
+type TransferFinder interface {
+	FindTransferById(ctx context.Context, transferId id.TransferId) (*Transfer, error)
+	FindPendingTransfers(ctx context.Context, targetCampaignId id.CampaignId) ([]*Transfer, error)
+}
+
+// Exporter reads the characters of a player for the export of its account
+type Exporter interface {
+	FindExportCharacters(ctx context.Context, playerId id.PlayerId) ([]ExportedCharacter, error)
+	// FindExportVersions gives back the versions of the characters of the player, and the
+	// versions the player wrote of other characters
+	FindExportVersions(ctx context.Context, playerId id.PlayerId) ([]ExportedVersion, error)
+}
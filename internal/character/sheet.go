@@ -0,0 +1,110 @@
+package character
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// sheetMaxNotesRunes keeps the notes short enough for the sheet to fit in two pages.
+const sheetMaxNotesRunes = 2500
+
+// RenderSheetPDF writes a printable A4 character sheet of the character to w.
+// Only the core PDF fonts are used, so the rendering does not need any external resource.
+func RenderSheetPDF(c *Character, w io.Writer) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(c.name, true)
+	pdf.SetCreator("Beldur", false)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("{nb}")
+
+	// core fonts are cp1252 encoded
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("%s - page %d/{nb}", tr(c.name), pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	pdf.AddPage()
+
+	// header
+	pdf.SetFont("Helvetica", "B", 22)
+	pdf.CellFormat(0, 12, tr(c.name), "B", 1, "L", false, 0, "")
+	pdf.Ln(3)
+	if c.description != "" {
+		pdf.SetFont("Helvetica", "I", 11)
+		pdf.MultiCell(0, 5.5, tr(c.description), "", "L", false)
+		pdf.Ln(4)
+	}
+
+	// abilities, one box for each one
+	sheetSection(pdf, "Abilities")
+	const boxWidth, boxGap = 28.0, 2.4
+	pdf.SetFillColor(235, 235, 235)
+	y := pdf.GetY()
+	for i, ability := range AllAbilityStats {
+		x := 15 + float64(i)*(boxWidth+boxGap)
+		pdf.SetXY(x, y)
+		pdf.SetFont("Helvetica", "B", 8)
+		pdf.CellFormat(boxWidth, 6, strings.ToUpper(string(ability)), "LTR", 2, "C", true, 0, "")
+		pdf.SetFont("Helvetica", "B", 18)
+		pdf.CellFormat(boxWidth, 10, formatModifier(c.abilities.Modifier(ability)), "LR", 2, "C", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(boxWidth, 6, fmt.Sprintf("%d", c.abilities.Get(ability)), "LBR", 0, "C", false, 0, "")
+	}
+	pdf.SetXY(15, y+26)
+
+	// inventory
+	sheetSection(pdf, "Inventory")
+	items := c.inventory.Items()
+	if len(items) == 0 {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.CellFormat(0, 6, "Empty", "", 1, "L", false, 0, "")
+	} else {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(15, 7, "Qty", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(55, 7, "Item", "1", 0, "L", true, 0, "")
+		pdf.CellFormat(0, 7, "Description", "1", 1, "L", true, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		for _, item := range items {
+			pdf.CellFormat(15, 7, fmt.Sprintf("%d", item.quantity), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(55, 7, tr(truncateRunes(item.name, 30)), "1", 0, "L", false, 0, "")
+			pdf.CellFormat(0, 7, tr(truncateRunes(item.description, 60)), "1", 1, "L", false, 0, "")
+		}
+	}
+	pdf.Ln(4)
+
+	if c.notes != "" {
+		sheetSection(pdf, "Notes")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 5, tr(truncateRunes(c.notes, sheetMaxNotesRunes)), "", "L", false)
+	}
+
+	return pdf.Output(w)
+}
+
+func sheetSection(pdf *fpdf.Fpdf, title string) {
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 8, title, "B", 1, "L", false, 0, "")
+	pdf.Ln(2)
+}
+
+func formatModifier(mod int) string {
+	if mod >= 0 {
+		return fmt.Sprintf("+%d", mod)
+	}
+	return fmt.Sprintf("%d", mod)
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max-3]) + "..."
+}
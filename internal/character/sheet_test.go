@@ -0,0 +1,43 @@
+package character
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModifier(t *testing.T) {
+	tests := map[int]int{
+		1:  -5,
+		8:  -1,
+		9:  -1,
+		10: 0,
+		11: 0,
+		12: 1,
+		15: 2,
+		20: 5,
+	}
+	for score, want := range tests {
+		assert.Equal(t, want, Modifier(score), "score %d", score)
+	}
+}
+
+func TestRenderSheetPDF(t *testing.T) {
+	potion, err := NewItem("healing potion", "heals 2d4+2", 3)
+	require.NoError(t, err)
+	inventory, err := NewInventory([]Item{potion})
+	require.NoError(t, err)
+
+	c := New("Gimli", "son of Glóin",
+		WithAbilities(NewAbilities(17, 10, 16, 9, 11, 8)),
+		WithInventory(inventory),
+		WithNotes(strings.Repeat("a very long note. ", 200)),
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderSheetPDF(c, &buf))
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+}
@@ -0,0 +1,10 @@
+package character
+
+type StatusCharacter string
+
+const (
+	// StatusActive character is playing in the campaign
+	StatusActive  StatusCharacter = "ACTIVE"
+	StatusDead    StatusCharacter = "DEAD"
+	StatusRetired StatusCharacter = "RETIRED"
+)
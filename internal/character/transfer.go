@@ -0,0 +1,111 @@
+package character
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"time"
+)
+
+type TransferMode string
+
+const (
+	// TransferClone copies the character, the original stays in its campaign
+	TransferClone TransferMode = "CLONE"
+	// TransferMove copies the character and retires the original
+	TransferMove TransferMode = "MOVE"
+)
+
+type TransferStatus string
+
+const (
+	TransferPending  TransferStatus = "PENDING"
+	TransferApproved TransferStatus = "APPROVED"
+	TransferRejected TransferStatus = "REJECTED"
+)
+
+// Transfer is the request of a player to bring their character into another campaign.
+// It waits for the approval of the master of the target campaign.
+type Transfer struct {
+	id               id.TransferId
+	characterId      id.CharacterId
+	playerId         id.PlayerId
+	sourceCampaignId id.CampaignId
+	targetCampaignId id.CampaignId
+	mode             TransferMode
+	status           TransferStatus
+	createdAt        time.Time
+	// nil while pending
+	decidedAt *time.Time
+	// the character created in the target campaign, nil until approved
+	resultCharacterId *id.CharacterId
+}
+
+// NewTransfer creates a pending transfer of the character of the player into the target campaign.
+// Only active player characters can be transferred, by their owner.
+func NewTransfer(ch *Character, playerId id.PlayerId, targetCampaignId id.CampaignId, mode TransferMode) (*Transfer, error) {
+	if mode != TransferClone && mode != TransferMove {
+		return nil, ErrInvalidTransferMode
+	}
+	if ch.npc || ch.playerId != playerId {
+		return nil, ErrCharacterAccessDenied
+	}
+	if err := ch.checkActive(); err != nil {
+		return nil, err
+	}
+	if ch.campaignId == targetCampaignId {
+		return nil, ErrTransferToSameCampaign
+	}
+
+	return &Transfer{
+		characterId:      ch.id,
+		playerId:         playerId,
+		sourceCampaignId: ch.campaignId,
+		targetCampaignId: targetCampaignId,
+		mode:             mode,
+		status:           TransferPending,
+		createdAt:        time.Now(),
+	}, nil
+}
+
+// Approve records the character created in the target campaign
+func (t *Transfer) Approve(resultCharacterId id.CharacterId) error {
+	if t.status != TransferPending {
+		return ErrTransferAlreadyDecided
+	}
+	now := time.Now()
+	t.status = TransferApproved
+	t.decidedAt = &now
+	t.resultCharacterId = &resultCharacterId
+	return nil
+}
+
+func (t *Transfer) Reject() error {
+	if t.status != TransferPending {
+		return ErrTransferAlreadyDecided
+	}
+	now := time.Now()
+	t.status = TransferRejected
+	t.decidedAt = &now
+	return nil
+}
+
+func (t *Transfer) Id() id.TransferId { return t.id }
+
+// validateForCampaign checks that the character can join the target campaign: the campaign must
+// still be playable and the owner must not play another active character there (hasCharacter).
+// Campaigns have no custom rules yet, so the sheet is checked against the default ones.
+func validateForCampaign(ch *Character, target *campaign.Campaign, hasCharacter bool) error {
+	if !target.IsOpen() {
+		return ErrCampaignNotOpen
+	}
+	if hasCharacter {
+		return ErrPlayerAlreadyHasCharacter
+	}
+	if err := ch.abilities.Validate(); err != nil {
+		return err
+	}
+	if ch.hitPoints < 0 {
+		return ErrNegativeHitPoints
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package character
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransfer(t *testing.T) {
+	owner := id.PlayerId(1)
+	source, target := id.CampaignId(10), id.CampaignId(20)
+
+	newCharacter := func() *Character {
+		c := New("Aragorn", "ranger of the north")
+		c.playerId = owner
+		c.campaignId = source
+		return c
+	}
+
+	npc := newCharacter()
+	npc.npc = true
+	dead := newCharacter()
+	require.NoError(t, dead.Kill())
+
+	tests := []struct {
+		name     string
+		ch       *Character
+		playerId id.PlayerId
+		target   id.CampaignId
+		mode     TransferMode
+		wantErr  error
+	}{
+		{"clone", newCharacter(), owner, target, TransferClone, nil},
+		{"move", newCharacter(), owner, target, TransferMove, nil},
+		{"invalid mode", newCharacter(), owner, target, "COPY", ErrInvalidTransferMode},
+		{"not the owner", newCharacter(), id.PlayerId(2), target, TransferClone, ErrCharacterAccessDenied},
+		{"npc", npc, owner, target, TransferClone, ErrCharacterAccessDenied},
+		{"dead", dead, owner, target, TransferClone, ErrCharacterDead},
+		{"same campaign", newCharacter(), owner, source, TransferClone, ErrTransferToSameCampaign},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr, err := NewTransfer(tt.ch, tt.playerId, tt.target, tt.mode)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, TransferPending, tr.status)
+			assert.Equal(t, source, tr.sourceCampaignId)
+		})
+	}
+}
+
+func TestTransferDecision(t *testing.T) {
+	c := New("Legolas", "prince of the woodland realm")
+	c.playerId = 1
+	c.campaignId = 10
+
+	tr, err := NewTransfer(c, 1, 20, TransferClone)
+	require.NoError(t, err)
+
+	require.NoError(t, tr.Approve(42))
+	assert.Equal(t, TransferApproved, tr.status)
+	require.NotNil(t, tr.resultCharacterId)
+	assert.Equal(t, id.CharacterId(42), *tr.resultCharacterId)
+	assert.NotNil(t, tr.decidedAt)
+
+	assert.ErrorIs(t, tr.Reject(), ErrTransferAlreadyDecided)
+	assert.ErrorIs(t, tr.Approve(43), ErrTransferAlreadyDecided)
+}
+
+func TestValidateForCampaign(t *testing.T) {
+	newTarget := func(t *testing.T) *campaign.Campaign {
+		c, err := campaign.New("target", "the campaign receiving the character", 2)
+		require.NoError(t, err)
+		require.NoError(t, c.AddPlayer(1))
+		return c
+	}
+	newCharacter := func() *Character {
+		c := New("Gimli", "son of Gloin")
+		c.playerId = 1
+		return c
+	}
+
+	assert.NoError(t, validateForCampaign(newCharacter(), newTarget(t), false))
+
+	t.Run("player already has a character", func(t *testing.T) {
+		assert.ErrorIs(t, validateForCampaign(newCharacter(), newTarget(t), true), ErrPlayerAlreadyHasCharacter)
+	})
+
+	t.Run("campaign not open", func(t *testing.T) {
+		target := newTarget(t)
+		require.NoError(t, target.Archive())
+		assert.ErrorIs(t, validateForCampaign(newCharacter(), target, false), ErrCampaignNotOpen)
+	})
+
+	t.Run("invalid sheet", func(t *testing.T) {
+		ch := newCharacter()
+		ch.hitPoints = -1
+		assert.ErrorIs(t, validateForCampaign(ch, newTarget(t), false), ErrNegativeHitPoints)
+	})
+}
@@ -1,20 +1,31 @@
 package character
 
 import (
+	"beldur/internal/campaign"
 	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dice"
+	"beldur/pkg/dto"
+	"beldur/pkg/logger"
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"math/rand/v2"
 )
 
 type CreateUseCase struct {
 	campaignFinder CampaignFinder
 	characterSaver Saver
+	tx             tx.Transactor
 }
 
-func NewCreateUseCase(campaignFinder CampaignFinder, characterSaver Saver) *CreateUseCase {
+func NewCreateUseCase(campaignFinder CampaignFinder, characterSaver Saver, tx tx.Transactor) *CreateUseCase {
 	return &CreateUseCase{
 		campaignFinder: campaignFinder,
 		characterSaver: characterSaver,
+		tx:             tx,
 	}
 }
 
@@ -43,8 +54,11 @@ func (uc *CreateUseCase) CreateNPC(
 	}
 
 	// Nothing to do here... NPC is created, now I have to save him in the repository
-	// maybe I will need for a Transactor if multiple queries are needed
-	if err := uc.characterSaver.SaveNPC(ctx, ch, camp.Id(), masterId); err != nil {
+	// along with its first version, so multiple queries are needed
+	err = uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		return uc.characterSaver.SaveNPC(ctx, ch, camp.Id(), masterId)
+	})
+	if err != nil {
 		return CreateCharacterResponse{}, errors.New("failed to save character")
 	}
 
@@ -67,3 +81,786 @@ func (uc *CreateUseCase) CreatePlayerCharacter(req CreateCharacterRequest, campa
 func (uc *CreateUseCase) getAbilities(req CreateCharacterRequest) Abilities {
 	return NewAbilities(req.Abilities.Strength, req.Abilities.Dexterity, req.Abilities.Constitution, req.Abilities.Intelligence, req.Abilities.Wisdom, req.Abilities.Charisma)
 }
+
+// GenerateUseCase gives the master random NPCs to use when the players go off-script
+type GenerateUseCase struct {
+	campaignFinder CampaignFinder
+	generator      *NPCGenerator
+}
+
+func NewGenerateUseCase(campaignFinder CampaignFinder, generator *NPCGenerator) *GenerateUseCase {
+	return &GenerateUseCase{
+		campaignFinder: campaignFinder,
+		generator:      generator,
+	}
+}
+
+// GenerateNPC builds a random NPC preview without saving it. The returned seed
+// generates the same NPC again. Only a master of the campaign can generate NPCs.
+func (uc *GenerateUseCase) GenerateNPC(
+	ctx context.Context,
+	req GenerateNPCRequest,
+	campaignId id.CampaignId,
+	masterId id.PlayerId) (GenerateNPCResponse, error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		return GenerateNPCResponse{}, errors.Join(ErrCampaignNotFound, err)
+	}
+	if !camp.IsMaster(masterId) {
+		return GenerateNPCResponse{}, ErrCampaignHasAnotherMaster
+	}
+
+	seed := rand.Uint64()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	npc, err := uc.generator.Generate(req.Culture, dice.NewSeededRoller(seed))
+	if err != nil {
+		return GenerateNPCResponse{}, err
+	}
+
+	return GenerateNPCResponse{
+		Seed:       seed,
+		Culture:    npc.Culture,
+		Occupation: npc.Occupation,
+		Quirk:      npc.Quirk,
+		NPC: CreateCharacterRequest{
+			Name:        npc.Name,
+			Description: fmt.Sprintf("%s. %s.", npc.Occupation, npc.Quirk),
+			Abilities: AbilityDto{
+				Strength:     npc.Abilities.Get(AbilityStrength),
+				Dexterity:    npc.Abilities.Get(AbilityDexterity),
+				Constitution: npc.Abilities.Get(AbilityConstitution),
+				Intelligence: npc.Abilities.Get(AbilityIntelligence),
+				Wisdom:       npc.Abilities.Get(AbilityWisdom),
+				Charisma:     npc.Abilities.Get(AbilityCharisma),
+			},
+		},
+	}, nil
+}
+
+// PortabilityUseCase moves characters between tables and tools through the
+// versioned ExportDocument format.
+type PortabilityUseCase struct {
+	campaignFinder  CampaignFinder
+	characterFinder Finder
+	characterSaver  Saver
+	tx              tx.Transactor
+}
+
+func NewPortabilityUseCase(campaignFinder CampaignFinder, characterFinder Finder, characterSaver Saver, tx tx.Transactor) *PortabilityUseCase {
+	return &PortabilityUseCase{
+		campaignFinder:  campaignFinder,
+		characterFinder: characterFinder,
+		characterSaver:  characterSaver,
+		tx:              tx,
+	}
+}
+
+// Export gives back the full sheet of the character in the portable format.
+// Only the owner of the character and the master of its campaign can export it.
+func (uc *PortabilityUseCase) Export(ctx context.Context, characterId id.CharacterId, playerId id.PlayerId) (ExportDocument, error) {
+	ch, err := findViewableCharacter(ctx, uc.characterFinder, uc.campaignFinder, characterId, playerId)
+	if err != nil {
+		return ExportDocument{}, err
+	}
+	return newExportDocument(ch), nil
+}
+
+// Import recreates the character of the document inside the campaign.
+// A player character becomes owned by the importing player, that must be in the campaign.
+// NPCs can be imported only by the master of the campaign.
+func (uc *PortabilityUseCase) Import(ctx context.Context, doc ExportDocument, campaignId id.CampaignId, playerId id.PlayerId) (ImportResponse, error) {
+	ch, err := fromExportDocument(doc)
+	if err != nil {
+		logger.Debug("failed to import character", "error", err)
+		return ImportResponse{}, err
+	}
+
+	err = uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+		if err != nil {
+			return errors.Join(ErrCampaignNotFound, err)
+		}
+
+		if doc.Character.NPC {
+			if !camp.IsMaster(playerId) {
+				return ErrCampaignHasAnotherMaster
+			}
+			return uc.characterSaver.SaveNPC(ctx, ch, camp.Id(), playerId)
+		}
+
+		if !camp.HasPlayer(playerId) {
+			return ErrPlayerNotInCampaign
+		}
+		return uc.characterSaver.SavePlayerCharacter(ctx, ch, camp.Id(), playerId)
+	})
+	if err != nil {
+		logger.Debug("failed to save imported character", "error", err)
+		return ImportResponse{}, err
+	}
+
+	return ImportResponse{
+		Id:         int(ch.id),
+		CampaignId: int(ch.campaignId),
+		Name:       ch.name,
+		NPC:        ch.npc,
+	}, nil
+}
+
+// SheetUseCase renders the printable character sheet
+type SheetUseCase struct {
+	campaignFinder  CampaignFinder
+	characterFinder Finder
+}
+
+func NewSheetUseCase(campaignFinder CampaignFinder, characterFinder Finder) *SheetUseCase {
+	return &SheetUseCase{
+		campaignFinder:  campaignFinder,
+		characterFinder: characterFinder,
+	}
+}
+
+// RenderPDF gives back the PDF sheet of the character.
+// Only the owner of the character and the master of its campaign can print it.
+func (uc *SheetUseCase) RenderPDF(ctx context.Context, characterId id.CharacterId, playerId id.PlayerId) ([]byte, error) {
+	ch, err := findViewableCharacter(ctx, uc.characterFinder, uc.campaignFinder, characterId, playerId)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := RenderSheetPDF(ch, &buf); err != nil {
+		logger.Error("failed to render character sheet", err, "character_id", characterId)
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// findViewableCharacter loads the character and checks that the player can see the full sheet.
+func findViewableCharacter(
+	ctx context.Context,
+	characterFinder Finder,
+	campaignFinder CampaignFinder,
+	characterId id.CharacterId,
+	playerId id.PlayerId,
+) (*Character, error) {
+	ch, camp, err := findCharacterAndCampaign(ctx, characterFinder, campaignFinder, characterId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ch.CanBeViewedBy(playerId, camp.IsMaster(playerId)) {
+		return nil, ErrCharacterAccessDenied
+	}
+	return ch, nil
+}
+
+// findCharacterAndCampaign loads the character along with the campaign it belongs to.
+func findCharacterAndCampaign(
+	ctx context.Context,
+	characterFinder Finder,
+	campaignFinder CampaignFinder,
+	characterId id.CharacterId,
+) (*Character, *campaign.Campaign, error) {
+	ch, err := characterFinder.FindById(ctx, characterId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, nil, ErrCharacterNotFound
+		}
+		logger.Debug("failed to find character", "character_id", characterId, "error", err)
+		return nil, nil, err
+	}
+
+	camp, err := campaignFinder.FindById(ctx, ch.campaignId)
+	if err != nil {
+		return nil, nil, errors.Join(ErrCampaignNotFound, err)
+	}
+	return ch, camp, nil
+}
+
+// EditUseCase changes the sheet of existing characters. Every change is recorded
+// as a new version of the character by the repository.
+type EditUseCase struct {
+	campaignFinder   CampaignFinder
+	characterFinder  Finder
+	characterUpdater Updater
+	tx               tx.Transactor
+}
+
+func NewEditUseCase(campaignFinder CampaignFinder, characterFinder Finder, characterUpdater Updater, tx tx.Transactor) *EditUseCase {
+	return &EditUseCase{
+		campaignFinder:   campaignFinder,
+		characterFinder:  characterFinder,
+		characterUpdater: characterUpdater,
+		tx:               tx,
+	}
+}
+
+// Update changes the fields of the request that are set.
+// Only the owner of the character and the master of its campaign can do it.
+func (uc *EditUseCase) Update(ctx context.Context, req UpdateCharacterRequest, characterId id.CharacterId, playerId id.PlayerId) (CharacterResponse, error) {
+	var resp CharacterResponse
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		ch, camp, err := findCharacterAndCampaign(ctx, uc.characterFinder, uc.campaignFinder, characterId)
+		if err != nil {
+			return err
+		}
+		if !ch.CanBeEditedBy(playerId, camp.IsMaster(playerId)) {
+			return ErrCharacterAccessDenied
+		}
+
+		if err := applyUpdate(ch, req); err != nil {
+			return err
+		}
+
+		if err := uc.characterUpdater.Update(ctx, ch, playerId); err != nil {
+			logger.Debug("failed to update character", "character_id", characterId, "error", err)
+			return err
+		}
+		resp = toCharacterResponse(ch)
+		return nil
+	})
+	if err != nil {
+		return CharacterResponse{}, err
+	}
+	return resp, nil
+}
+
+func applyUpdate(ch *Character, req UpdateCharacterRequest) error {
+	if req.Name != nil {
+		if err := ch.Rename(*req.Name); err != nil {
+			return err
+		}
+	}
+	if req.Description != nil {
+		if err := ch.UpdateDescription(*req.Description); err != nil {
+			return err
+		}
+	}
+	if req.Notes != nil {
+		ch.UpdateNotes(*req.Notes)
+	}
+	if req.HitPoints != nil {
+		if err := ch.UpdateHitPoints(*req.HitPoints); err != nil {
+			return err
+		}
+	}
+	if a := req.Abilities; a != nil {
+		abilities := NewAbilities(a.Strength, a.Dexterity, a.Constitution, a.Intelligence, a.Wisdom, a.Charisma)
+		if err := ch.UpdateAbilities(abilities); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HistoryUseCase reads and restores the versions of a character
+type HistoryUseCase struct {
+	campaignFinder   CampaignFinder
+	characterFinder  Finder
+	characterUpdater Updater
+	versionFinder    VersionFinder
+	tx               tx.Transactor
+}
+
+func NewHistoryUseCase(
+	campaignFinder CampaignFinder,
+	characterFinder Finder,
+	characterUpdater Updater,
+	versionFinder VersionFinder,
+	tx tx.Transactor,
+) *HistoryUseCase {
+	return &HistoryUseCase{
+		campaignFinder:   campaignFinder,
+		characterFinder:  characterFinder,
+		characterUpdater: characterUpdater,
+		versionFinder:    versionFinder,
+		tx:               tx,
+	}
+}
+
+// History gives back the versions of the character, the most recent first.
+// Only the owner of the character and the master of its campaign can read it.
+func (uc *HistoryUseCase) History(ctx context.Context, characterId id.CharacterId, playerId id.PlayerId) (dto.ListResponse[VersionResponse], error) {
+	if _, err := findViewableCharacter(ctx, uc.characterFinder, uc.campaignFinder, characterId, playerId); err != nil {
+		return dto.ListResponse[VersionResponse]{}, err
+	}
+
+	versions, err := uc.versionFinder.FindVersions(ctx, characterId)
+	if err != nil {
+		logger.Debug("failed to find character versions", "character_id", characterId, "error", err)
+		return dto.ListResponse[VersionResponse]{}, err
+	}
+
+	list := make([]VersionResponse, len(versions))
+	for i, v := range versions {
+		list[i] = VersionResponse{
+			Version:      v.number,
+			AuthorId:     int(v.authorId),
+			CreatedAt:    v.createdAt,
+			RestoredFrom: v.restoredFrom,
+			Changes:      nonNil(v.changes),
+		}
+	}
+	return dto.ListResponse[VersionResponse]{Data: list}, nil
+}
+
+// Restore brings the sheet of the character back to an older version.
+// The restore is itself recorded as a new version. Only the master of the campaign can do it.
+func (uc *HistoryUseCase) Restore(ctx context.Context, characterId id.CharacterId, version int, masterId id.PlayerId) (CharacterResponse, error) {
+	var resp CharacterResponse
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		ch, camp, err := findCharacterAndCampaign(ctx, uc.characterFinder, uc.campaignFinder, characterId)
+		if err != nil {
+			return err
+		}
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+
+		v, err := uc.versionFinder.FindVersion(ctx, characterId, version)
+		if err != nil {
+			if errors.Is(err, postgres.ErrNoRowFound) {
+				return ErrVersionNotFound
+			}
+			return err
+		}
+
+		restored, err := fromSnapshot(v.snapshot)
+		if err != nil {
+			return err
+		}
+		restored.id = ch.id
+		restored.campaignId = ch.campaignId
+		restored.playerId = ch.playerId
+		restored.npc = ch.npc
+		restored.status = ch.status
+		restored.diedAt = ch.diedAt
+		restored.retiredAt = ch.retiredAt
+
+		if err := uc.characterUpdater.Restore(ctx, restored, masterId, v.number); err != nil {
+			logger.Debug("failed to restore character", "character_id", characterId, "version", version, "error", err)
+			return err
+		}
+		resp = toCharacterResponse(restored)
+		return nil
+	})
+	if err != nil {
+		return CharacterResponse{}, err
+	}
+	return resp, nil
+}
+
+func toCharacterResponse(ch *Character) CharacterResponse {
+	return CharacterResponse{
+		Id:         int(ch.id),
+		CampaignId: int(ch.campaignId),
+		PlayerId:   int(ch.playerId),
+		Status:     ch.status,
+		Character:  newSnapshot(ch),
+	}
+}
+
+// LifecycleUseCase moves characters between active, dead and retired,
+// and removes NPCs that are not needed anymore.
+type LifecycleUseCase struct {
+	campaignFinder   CampaignFinder
+	characterFinder  Finder
+	characterUpdater Updater
+	characterDeleter Deleter
+	graveyardFinder  GraveyardFinder
+	tx               tx.Transactor
+}
+
+func NewLifecycleUseCase(
+	campaignFinder CampaignFinder,
+	characterFinder Finder,
+	characterUpdater Updater,
+	characterDeleter Deleter,
+	graveyardFinder GraveyardFinder,
+	tx tx.Transactor,
+) *LifecycleUseCase {
+	return &LifecycleUseCase{
+		campaignFinder:   campaignFinder,
+		characterFinder:  characterFinder,
+		characterUpdater: characterUpdater,
+		characterDeleter: characterDeleter,
+		graveyardFinder:  graveyardFinder,
+		tx:               tx,
+	}
+}
+
+// Kill marks the character as dead. The owner and the master of the campaign can do it.
+func (uc *LifecycleUseCase) Kill(ctx context.Context, characterId id.CharacterId, playerId id.PlayerId) (LifecycleResponse, error) {
+	return uc.changeStatus(ctx, characterId, playerId, false, (*Character).Kill)
+}
+
+// Retire takes the character out of play. The owner and the master of the campaign can do it.
+func (uc *LifecycleUseCase) Retire(ctx context.Context, characterId id.CharacterId, playerId id.PlayerId) (LifecycleResponse, error) {
+	return uc.changeStatus(ctx, characterId, playerId, false, (*Character).Retire)
+}
+
+// Resurrect brings a dead character back to play. Only the master of the campaign can do it.
+func (uc *LifecycleUseCase) Resurrect(ctx context.Context, characterId id.CharacterId, masterId id.PlayerId) (LifecycleResponse, error) {
+	return uc.changeStatus(ctx, characterId, masterId, true, (*Character).Resurrect)
+}
+
+func (uc *LifecycleUseCase) changeStatus(
+	ctx context.Context,
+	characterId id.CharacterId,
+	playerId id.PlayerId,
+	masterOnly bool,
+	change func(*Character) error,
+) (LifecycleResponse, error) {
+	var resp LifecycleResponse
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		ch, camp, err := findCharacterAndCampaign(ctx, uc.characterFinder, uc.campaignFinder, characterId)
+		if err != nil {
+			return err
+		}
+		isMaster := camp.IsMaster(playerId)
+		if masterOnly && !isMaster {
+			return ErrCampaignHasAnotherMaster
+		}
+		// the status is checked by the change, not by the access
+		if !isMaster && (ch.npc || ch.playerId != playerId) {
+			return ErrCharacterAccessDenied
+		}
+
+		if err := change(ch); err != nil {
+			return err
+		}
+
+		if err := uc.characterUpdater.UpdateStatus(ctx, ch); err != nil {
+			logger.Debug("failed to update character status", "character_id", characterId, "error", err)
+			return err
+		}
+		resp = toLifecycleResponse(ch)
+		return nil
+	})
+	if err != nil {
+		return LifecycleResponse{}, err
+	}
+	return resp, nil
+}
+
+// DeleteNPC removes the NPC with all of its history. Player characters cannot be deleted,
+// they are retired instead. Only the master of the campaign can do it.
+func (uc *LifecycleUseCase) DeleteNPC(ctx context.Context, characterId id.CharacterId, masterId id.PlayerId) error {
+	return uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		ch, camp, err := findCharacterAndCampaign(ctx, uc.characterFinder, uc.campaignFinder, characterId)
+		if err != nil {
+			return err
+		}
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		if !ch.npc {
+			return ErrCharacterNotNPC
+		}
+
+		if err := uc.characterDeleter.Delete(ctx, characterId); err != nil {
+			logger.Debug("failed to delete npc", "character_id", characterId, "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// Graveyard gives back the dead and retired characters of the campaign.
+// Every player of the campaign can read it.
+func (uc *LifecycleUseCase) Graveyard(ctx context.Context, campaignId id.CampaignId, playerId id.PlayerId) (dto.ListResponse[LifecycleResponse], error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		logger.Debug("no campaign found", "campaign_id", campaignId)
+		return dto.ListResponse[LifecycleResponse]{}, errors.Join(ErrCampaignNotFound, err)
+	}
+	if !camp.HasPlayer(playerId) {
+		return dto.ListResponse[LifecycleResponse]{}, ErrPlayerNotInCampaign
+	}
+
+	characters, err := uc.graveyardFinder.FindGraveyard(ctx, campaignId)
+	if err != nil {
+		logger.Debug("failed to find graveyard", "campaign_id", campaignId, "error", err)
+		return dto.ListResponse[LifecycleResponse]{}, err
+	}
+
+	list := make([]LifecycleResponse, len(characters))
+	for i, ch := range characters {
+		list[i] = toLifecycleResponse(ch)
+	}
+	return dto.ListResponse[LifecycleResponse]{Data: list}, nil
+}
+
+func toLifecycleResponse(ch *Character) LifecycleResponse {
+	return LifecycleResponse{
+		Id:         int(ch.id),
+		CampaignId: int(ch.campaignId),
+		PlayerId:   int(ch.playerId),
+		Name:       ch.name,
+		NPC:        ch.npc,
+		Status:     ch.status,
+		DiedAt:     ch.diedAt,
+		RetiredAt:  ch.retiredAt,
+	}
+}
+
+// TransferUseCase brings player characters from a campaign to another,
+// after the approval of the master of the target campaign.
+type TransferUseCase struct {
+	campaignFinder   CampaignFinder
+	characterFinder  Finder
+	playerCharacters PlayerCharacterFinder
+	characterSaver   Saver
+	characterUpdater Updater
+	transferSaver    TransferSaver
+	transferFinder   TransferFinder
+	tx               tx.Transactor
+}
+
+func NewTransferUseCase(
+	campaignFinder CampaignFinder,
+	characterFinder Finder,
+	playerCharacters PlayerCharacterFinder,
+	characterSaver Saver,
+	characterUpdater Updater,
+	transferSaver TransferSaver,
+	transferFinder TransferFinder,
+	tx tx.Transactor,
+) *TransferUseCase {
+	return &TransferUseCase{
+		campaignFinder:   campaignFinder,
+		characterFinder:  characterFinder,
+		playerCharacters: playerCharacters,
+		characterSaver:   characterSaver,
+		characterUpdater: characterUpdater,
+		transferSaver:    transferSaver,
+		transferFinder:   transferFinder,
+		tx:               tx,
+	}
+}
+
+// RequestTransfer asks the master of the target campaign to accept the character.
+// The owner of the character must already be a player of the target campaign, without
+// another active character there.
+func (uc *TransferUseCase) RequestTransfer(ctx context.Context, req TransferRequest, characterId id.CharacterId, playerId id.PlayerId) (TransferResponse, error) {
+	var resp TransferResponse
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		ch, _, err := findCharacterAndCampaign(ctx, uc.characterFinder, uc.campaignFinder, characterId)
+		if err != nil {
+			return err
+		}
+
+		target, err := uc.campaignFinder.FindById(ctx, id.CampaignId(req.TargetCampaignId))
+		if err != nil {
+			return errors.Join(ErrCampaignNotFound, err)
+		}
+		if !target.HasPlayer(playerId) {
+			return ErrPlayerNotInCampaign
+		}
+
+		t, err := NewTransfer(ch, playerId, target.Id(), req.Mode)
+		if err != nil {
+			return err
+		}
+		if err := uc.validateForTarget(ctx, ch, target, playerId); err != nil {
+			return err
+		}
+
+		if err := uc.transferSaver.SaveTransfer(ctx, t); err != nil {
+			if errors.Is(err, postgres.ErrUniqueValueViolation) {
+				return ErrTransferAlreadyPending
+			}
+			logger.Debug("failed to save transfer", "character_id", characterId, "error", err)
+			return err
+		}
+		resp = toTransferResponse(t)
+		return nil
+	})
+	if err != nil {
+		return TransferResponse{}, err
+	}
+	return resp, nil
+}
+
+// PendingTransfers gives back the transfers waiting for approval into the campaign.
+// Only the master of the campaign can read them.
+func (uc *TransferUseCase) PendingTransfers(ctx context.Context, campaignId id.CampaignId, masterId id.PlayerId) (dto.ListResponse[TransferResponse], error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		logger.Debug("no campaign found", "campaign_id", campaignId)
+		return dto.ListResponse[TransferResponse]{}, errors.Join(ErrCampaignNotFound, err)
+	}
+	if !camp.IsMaster(masterId) {
+		return dto.ListResponse[TransferResponse]{}, ErrCampaignHasAnotherMaster
+	}
+
+	transfers, err := uc.transferFinder.FindPendingTransfers(ctx, campaignId)
+	if err != nil {
+		logger.Debug("failed to find pending transfers", "campaign_id", campaignId, "error", err)
+		return dto.ListResponse[TransferResponse]{}, err
+	}
+
+	list := make([]TransferResponse, len(transfers))
+	for i, t := range transfers {
+		list[i] = toTransferResponse(t)
+	}
+	return dto.ListResponse[TransferResponse]{Data: list}, nil
+}
+
+// Approve copies the character into the target campaign, as it is at the moment of the approval.
+// The sheet is validated again against the rules of the target campaign. A moved character
+// is retired from its original campaign. Only the master of the target campaign can do it.
+func (uc *TransferUseCase) Approve(ctx context.Context, campaignId id.CampaignId, transferId id.TransferId, masterId id.PlayerId) (TransferResponse, error) {
+	var resp TransferResponse
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		t, camp, err := uc.findPendingTransfer(ctx, campaignId, transferId, masterId)
+		if err != nil {
+			return err
+		}
+		if !camp.HasPlayer(t.playerId) {
+			return ErrPlayerNotInCampaign
+		}
+
+		ch, err := uc.characterFinder.FindById(ctx, t.characterId)
+		if err != nil {
+			if errors.Is(err, postgres.ErrNoRowFound) {
+				return ErrCharacterNotFound
+			}
+			return err
+		}
+		// the character could have changed since the request
+		if _, err := NewTransfer(ch, t.playerId, camp.Id(), t.mode); err != nil {
+			return err
+		}
+
+		clone, err := fromSnapshot(newSnapshot(ch))
+		if err != nil {
+			return err
+		}
+		if err := uc.validateForTarget(ctx, clone, camp, t.playerId); err != nil {
+			return err
+		}
+		if err := uc.characterSaver.SavePlayerCharacter(ctx, clone, camp.Id(), t.playerId); err != nil {
+			logger.Debug("failed to save transferred character", "transfer_id", transferId, "error", err)
+			return err
+		}
+
+		if t.mode == TransferMove {
+			if err := ch.Retire(); err != nil {
+				return err
+			}
+			if err := uc.characterUpdater.UpdateStatus(ctx, ch); err != nil {
+				return err
+			}
+		}
+
+		if err := t.Approve(clone.id); err != nil {
+			return err
+		}
+		if err := uc.transferSaver.UpdateTransfer(ctx, t); err != nil {
+			logger.Debug("failed to update transfer", "transfer_id", transferId, "error", err)
+			return err
+		}
+		resp = toTransferResponse(t)
+		return nil
+	})
+	if err != nil {
+		return TransferResponse{}, err
+	}
+	return resp, nil
+}
+
+// Reject refuses the transfer. Only the master of the target campaign can do it.
+func (uc *TransferUseCase) Reject(ctx context.Context, campaignId id.CampaignId, transferId id.TransferId, masterId id.PlayerId) (TransferResponse, error) {
+	var resp TransferResponse
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		t, _, err := uc.findPendingTransfer(ctx, campaignId, transferId, masterId)
+		if err != nil {
+			return err
+		}
+		if err := t.Reject(); err != nil {
+			return err
+		}
+		if err := uc.transferSaver.UpdateTransfer(ctx, t); err != nil {
+			logger.Debug("failed to update transfer", "transfer_id", transferId, "error", err)
+			return err
+		}
+		resp = toTransferResponse(t)
+		return nil
+	})
+	if err != nil {
+		return TransferResponse{}, err
+	}
+	return resp, nil
+}
+
+// validateForTarget checks the character against the target campaign, where the player
+// can play a single active character.
+func (uc *TransferUseCase) validateForTarget(ctx context.Context, ch *Character, target *campaign.Campaign, playerId id.PlayerId) error {
+	hasCharacter, err := uc.playerCharacters.HasActivePlayerCharacter(ctx, target.Id(), playerId)
+	if err != nil {
+		logger.Debug("failed to find player characters", "campaign_id", target.Id(), "player_id", playerId, "error", err)
+		return err
+	}
+	return validateForCampaign(ch, target, hasCharacter)
+}
+
+// findPendingTransfer loads the transfer into the campaign and checks that the player is its master.
+func (uc *TransferUseCase) findPendingTransfer(
+	ctx context.Context,
+	campaignId id.CampaignId,
+	transferId id.TransferId,
+	masterId id.PlayerId,
+) (*Transfer, *campaign.Campaign, error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		return nil, nil, errors.Join(ErrCampaignNotFound, err)
+	}
+	if !camp.IsMaster(masterId) {
+		return nil, nil, ErrCampaignHasAnotherMaster
+	}
+
+	t, err := uc.transferFinder.FindTransferById(ctx, transferId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, nil, ErrTransferNotFound
+		}
+		return nil, nil, err
+	}
+	if t.targetCampaignId != campaignId {
+		return nil, nil, ErrTransferNotFound
+	}
+	if t.status != TransferPending {
+		return nil, nil, ErrTransferAlreadyDecided
+	}
+	return t, camp, nil
+}
+
+func toTransferResponse(t *Transfer) TransferResponse {
+	var resultID *int
+	if t.resultCharacterId != nil {
+		rid := int(*t.resultCharacterId)
+		resultID = &rid
+	}
+	return TransferResponse{
+		Id:                int(t.id),
+		CharacterId:       int(t.characterId),
+		PlayerId:          int(t.playerId),
+		SourceCampaignId:  int(t.sourceCampaignId),
+		TargetCampaignId:  int(t.targetCampaignId),
+		Mode:              t.mode,
+		Status:            t.status,
+		CreatedAt:         t.createdAt,
+		DecidedAt:         t.decidedAt,
+		ResultCharacterId: resultID,
+	}
+}
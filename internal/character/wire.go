@@ -3,10 +3,12 @@ package character
 import (
 	"beldur/internal/campaign"
 	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
 )
 
 type Deps struct {
-	QProvider postgres.QuerierProvider
+	QProvider  postgres.QuerierProvider
+	Transactor tx.Transactor
 }
 
 func NewHandlerFromDeps(deps Deps) *HttpHandler {
@@ -18,6 +20,22 @@ func NewHandlerFromDeps(deps Deps) *HttpHandler {
 	// if We put the repository interface as dependency then its better
 	// but then I have to change also other handlers deps (easy)
 	campaignRepo := campaign.NewPostgresRepository(deps.QProvider)
-	creationUseCase := NewCreateUseCase(campaignRepo, charRepo)
-	return NewHttpHandler(creationUseCase)
+	creationUseCase := NewCreateUseCase(campaignRepo, charRepo, deps.Transactor)
+	portabilityUseCase := NewPortabilityUseCase(campaignRepo, charRepo, charRepo, deps.Transactor)
+	sheetUseCase := NewSheetUseCase(campaignRepo, charRepo)
+	generateUseCase := NewGenerateUseCase(campaignRepo, NewNPCGenerator())
+	editUseCase := NewEditUseCase(campaignRepo, charRepo, charRepo, deps.Transactor)
+	historyUseCase := NewHistoryUseCase(campaignRepo, charRepo, charRepo, charRepo, deps.Transactor)
+	lifecycleUseCase := NewLifecycleUseCase(campaignRepo, charRepo, charRepo, charRepo, charRepo, deps.Transactor)
+	transferUseCase := NewTransferUseCase(campaignRepo, charRepo, charRepo, charRepo, charRepo, charRepo, charRepo, deps.Transactor)
+	return NewHttpHandler(
+		creationUseCase,
+		portabilityUseCase,
+		sheetUseCase,
+		generateUseCase,
+		editUseCase,
+		historyUseCase,
+		lifecycleUseCase,
+		transferUseCase,
+	)
 }
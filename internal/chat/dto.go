@@ -0,0 +1,77 @@
+package chat
+
+import "time"
+
+// SendMessageRequest sends a message to everyone, unless WhisperTo or ToMaster is given
+type SendMessageRequest struct {
+	Body string `json:"body" validate:"max=2000"`
+	// speak as the character instead of as the player
+	CharacterId *int `json:"character_id"`
+	// player receiving the whisper
+	WhisperTo *int `json:"whisper_to"`
+	ToMaster  bool `json:"to_master"`
+	// dice expression rolled by the server, like 1d20+5
+	Roll string `json:"roll" validate:"max=20"`
+}
+
+type EditMessageRequest struct {
+	Body string `json:"body" validate:"max=2000"`
+}
+
+type MessageResponse struct {
+	Id            int           `json:"message_id"`
+	CampaignId    int           `json:"campaign_id"`
+	AuthorId      int           `json:"author_id"`
+	CharacterId   *int          `json:"character_id"`
+	CharacterName string        `json:"character_name,omitempty"`
+	Audience      Audience      `json:"audience"`
+	WhisperTo     *int          `json:"whisper_to"`
+	Body          string        `json:"body"`
+	Roll          *RollResponse `json:"roll"`
+	CreatedAt     time.Time     `json:"created_at"`
+	EditedAt      *time.Time    `json:"edited_at"`
+	Deleted       bool          `json:"deleted"`
+}
+
+type RollResponse struct {
+	Expression string `json:"expression"`
+	Rolls      []int  `json:"rolls"`
+	Total      int    `json:"total"`
+}
+
+// HistoryResponse holds messages from the oldest to the newest.
+// HasMore tells if there are other messages in the direction of the cursor.
+type HistoryResponse struct {
+	Data    []MessageResponse `json:"data"`
+	HasMore bool              `json:"has_more"`
+}
+
+type AuditResponse struct {
+	Action       AuditAction `json:"action"`
+	PreviousBody string      `json:"previous_body"`
+	ActorId      int         `json:"actor_id"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// ExportedMessage is a chat message written or received by the player, for the export of its account
+type ExportedMessage struct {
+	MessageId     int        `json:"message_id"`
+	CampaignId    int        `json:"campaign_id"`
+	AuthorId      int        `json:"author_id"`
+	CharacterName string     `json:"character_name"`
+	Audience      string     `json:"audience"`
+	Body          string     `json:"body"`
+	RollTotal     *int       `json:"roll_total"`
+	CreatedAt     time.Time  `json:"created_at"`
+	EditedAt      *time.Time `json:"edited_at"`
+	DeletedAt     *time.Time `json:"deleted_at"`
+}
+
+// ExportedEdit is a previous body of a chat message of the player
+type ExportedEdit struct {
+	MessageId    int       `json:"message_id"`
+	Action       string    `json:"action"`
+	PreviousBody string    `json:"previous_body"`
+	ActorId      int       `json:"actor_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
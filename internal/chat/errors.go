@@ -0,0 +1,103 @@
+package chat
+
+import (
+	"beldur/pkg/httperr"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidBody            = errors.New("message must have a body of at most 2000 characters or a dice roll")
+	ErrInvalidRoll            = errors.New("invalid dice expression")
+	ErrInvalidRecipient       = errors.New("invalid whisper recipient")
+	ErrInvalidCursor          = errors.New("before and after cannot be used together")
+	ErrMessageDeleted         = errors.New("message is deleted")
+	ErrMessageOfAnotherAuthor = errors.New("message belongs to another author")
+)
+
+var (
+	ErrMessageNotFound          = errors.New("message not found")
+	ErrCampaignNotFound         = errors.New("campaign not found")
+	ErrCharacterNotFound        = errors.New("character not found")
+	ErrCharacterNotPlayed       = errors.New("player cannot speak as the character")
+	ErrPlayerNotInCampaign      = errors.New("player is not in the campaign")
+	ErrCampaignHasAnotherMaster = errors.New("campaign has another master")
+)
+
+func NewChatApiErrorManager() *httperr.Manager {
+	mng := httperr.NewManager()
+
+	mng.Add(ErrInvalidBody, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_body",
+		Message: ErrInvalidBody.Error(),
+	})
+
+	mng.Add(ErrInvalidRoll, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_roll",
+		Message: ErrInvalidRoll.Error(),
+	})
+
+	mng.Add(ErrInvalidRecipient, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_recipient",
+		Message: ErrInvalidRecipient.Error(),
+	})
+
+	mng.Add(ErrInvalidCursor, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_cursor",
+		Message: ErrInvalidCursor.Error(),
+	})
+
+	mng.Add(ErrMessageDeleted, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "message_deleted",
+		Message: ErrMessageDeleted.Error(),
+	})
+
+	mng.Add(ErrMessageOfAnotherAuthor, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "message_of_another_author",
+		Message: ErrMessageOfAnotherAuthor.Error(),
+	})
+
+	mng.Add(ErrMessageNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "message_not_found",
+		Message: ErrMessageNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "campaign_not_found",
+		Message: ErrCampaignNotFound.Error(),
+	})
+
+	mng.Add(ErrCharacterNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "character_not_found",
+		Message: ErrCharacterNotFound.Error(),
+	})
+
+	mng.Add(ErrCharacterNotPlayed, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "character_not_played",
+		Message: ErrCharacterNotPlayed.Error(),
+	})
+
+	mng.Add(ErrPlayerNotInCampaign, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "player_not_in_campaign",
+		Message: ErrPlayerNotInCampaign.Error(),
+	})
+
+	mng.Add(ErrCampaignHasAnotherMaster, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "campaign_has_another_master",
+		Message: ErrCampaignHasAnotherMaster.Error(),
+	})
+
+	return mng
+}
@@ -0,0 +1,193 @@
+package chat
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/auth"
+	"beldur/pkg/httperr"
+	"beldur/pkg/live"
+	"beldur/pkg/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpHandler struct {
+	chatUC     *UseCase
+	errManager *httperr.Manager
+}
+
+func NewHttpHandler(chatUC *UseCase) *HttpHandler {
+	return &HttpHandler{
+		chatUC:     chatUC,
+		errManager: NewChatApiErrorManager(),
+	}
+}
+
+// HandleSendMessage needs the campaign:write scope, or rolls:write for a message carrying a roll
+func (h *HttpHandler) HandleSendMessage(c *fiber.Ctx) error {
+	campaignId, err := campaignIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(SendMessageRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+	if !p.HasScope(auth.ScopeCampaignWrite) && (req.Roll == "" || !p.HasScope(auth.ScopeRollsWrite)) {
+		return c.SendStatus(fiber.StatusForbidden)
+	}
+
+	resp, err := h.chatUC.Send(c.Context(), req, campaignId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// HandleGetHistory accepts the query parameters before, after and limit
+func (h *HttpHandler) HandleGetHistory(c *fiber.Ctx) error {
+	campaignId, err := campaignIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var cursor Cursor
+	if cursor.Before, err = messageIdQuery(c, "before"); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	if cursor.After, err = messageIdQuery(c, "after"); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.chatUC.History(c.Context(), campaignId, cursor, c.QueryInt("limit"), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// HandleStream delivers the new, edited and deleted messages as Server-Sent Events
+func (h *HttpHandler) HandleStream(c *fiber.Ctx) error {
+	campaignId, err := campaignIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	events, unsubscribe, err := h.chatUC.Subscribe(c.Context(), campaignId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return live.Stream(c, events, unsubscribe)
+}
+
+func (h *HttpHandler) HandleEditMessage(c *fiber.Ctx) error {
+	messageId, err := messageIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(EditMessageRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.chatUC.Edit(c.Context(), req, messageId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleDeleteMessage(c *fiber.Ctx) error {
+	messageId, err := messageIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.chatUC.Delete(c.Context(), messageId, p.PlayerID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *HttpHandler) HandleGetAudit(c *fiber.Ctx) error {
+	messageId, err := messageIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.chatUC.Audit(c.Context(), messageId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func campaignIdParam(c *fiber.Ctx) (id.CampaignId, error) {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.CampaignId(campaignId), nil
+}
+
+func messageIdParam(c *fiber.Ctx) (id.MessageId, error) {
+	messageInstr := c.Params("messageId")
+	if messageInstr == "" {
+		panic("wrong parameter naming")
+	}
+	messageId, err := strconv.Atoi(messageInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.MessageId(messageId), nil
+}
+
+// messageIdQuery reads an optional message id from the query string
+func messageIdQuery(c *fiber.Ctx, key string) (*id.MessageId, error) {
+	instr := c.Query(key)
+	if instr == "" {
+		return nil, nil
+	}
+	messageId, err := strconv.Atoi(instr)
+	if err != nil {
+		return nil, err
+	}
+	mid := id.MessageId(messageId)
+	return &mid, nil
+}
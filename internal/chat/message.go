@@ -0,0 +1,216 @@
+package chat
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/dice"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const MaxBodyCharacters = 2000
+
+type Audience string
+
+const (
+	AudienceEveryone Audience = "EVERYONE"
+	// AudiencePlayer private whisper to a player
+	AudiencePlayer Audience = "PLAYER"
+	// AudienceMaster private whisper to the master
+	AudienceMaster Audience = "MASTER"
+)
+
+type AuditAction string
+
+const (
+	AuditEdit   AuditAction = "EDIT"
+	AuditDelete AuditAction = "DELETE"
+)
+
+// Roll is the result of a dice roll embedded in a message. Dice are rolled
+// by the server, so the result cannot be forged by the clients.
+type Roll struct {
+	expression string
+	rolls      []int
+	total      int
+}
+
+// AuditRecord keeps the body of a message before an edit or a deletion
+type AuditRecord struct {
+	action       AuditAction
+	previousBody string
+	actorId      id.PlayerId
+	createdAt    time.Time
+}
+
+type Option func(*Message) error
+
+// AsCharacter makes the author speak as one of the characters of the campaign.
+// The caller checks the author can play the character.
+func AsCharacter(characterId id.CharacterId, name string) Option {
+	return func(m *Message) error {
+		m.characterId = &characterId
+		m.characterName = name
+		return nil
+	}
+}
+
+// WhisperTo sends the message to the player only. The caller checks the player is in the campaign.
+func WhisperTo(playerId id.PlayerId) Option {
+	return func(m *Message) error {
+		if playerId == m.authorId {
+			return ErrInvalidRecipient
+		}
+		m.audience = AudiencePlayer
+		m.whisperTo = &playerId
+		return nil
+	}
+}
+
+// WhisperToMaster sends the message to the master only
+func WhisperToMaster() Option {
+	return func(m *Message) error {
+		m.audience = AudienceMaster
+		return nil
+	}
+}
+
+// WithRoll rolls the dice expression and embeds the result in the message
+func WithRoll(expression string, roller dice.Roller) Option {
+	return func(m *Message) error {
+		expr, err := dice.Parse(expression)
+		if err != nil {
+			return ErrInvalidRoll
+		}
+		res := expr.Roll(roller)
+		m.roll = &Roll{expression: expr.String(), rolls: res.Rolls, total: res.Total}
+		return nil
+	}
+}
+
+// Message is a message of the chat of a campaign
+type Message struct {
+	id         id.MessageId
+	campaignId id.CampaignId
+	authorId   id.PlayerId
+	// nil if the author speaks as a player
+	characterId   *id.CharacterId
+	characterName string
+	audience      Audience
+	// nil unless the audience is a player
+	whisperTo *id.PlayerId
+	body      string
+	// nil if no dice were rolled
+	roll      *Roll
+	createdAt time.Time
+	// nil if never edited
+	editedAt *time.Time
+	// nil if not deleted
+	deletedAt *time.Time
+
+	// audit records since the message was created or loaded, the repository persists them
+	audit []AuditRecord
+}
+
+// New writes a message in the chat of the campaign. The body can be empty only when dice are rolled.
+func New(campaignId id.CampaignId, authorId id.PlayerId, body string, opt ...Option) (*Message, error) {
+	m := &Message{
+		campaignId: campaignId,
+		authorId:   authorId,
+		audience:   AudienceEveryone,
+		createdAt:  time.Now(),
+	}
+	for _, o := range opt {
+		if err := o(m); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.setBody(body); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Edit replaces the body of the message. Only the author can do it, the rolled dice stay the same.
+func (m *Message) Edit(body string, playerId id.PlayerId) error {
+	if m.IsDeleted() {
+		return ErrMessageDeleted
+	}
+	if m.authorId != playerId {
+		return ErrMessageOfAnotherAuthor
+	}
+
+	previous := m.body
+	if err := m.setBody(body); err != nil {
+		return err
+	}
+	if m.body == previous {
+		return nil
+	}
+
+	now := time.Now()
+	m.editedAt = &now
+	m.record(AuditEdit, previous, playerId, now)
+	return nil
+}
+
+// Delete hides the body of the message, the message stays in the history.
+// The author and the master can do it.
+func (m *Message) Delete(playerId id.PlayerId, isMaster bool) error {
+	if m.IsDeleted() {
+		return ErrMessageDeleted
+	}
+	if m.authorId != playerId && !isMaster {
+		return ErrMessageOfAnotherAuthor
+	}
+
+	now := time.Now()
+	m.record(AuditDelete, m.body, playerId, now)
+	m.body = ""
+	m.roll = nil
+	m.deletedAt = &now
+	return nil
+}
+
+// IsVisibleTo tells if a player of the campaign can read the message.
+// Whispers are private, the master does not read the ones between players.
+func (m *Message) IsVisibleTo(playerId id.PlayerId, isMaster bool) bool {
+	if m.authorId == playerId {
+		return true
+	}
+	switch m.audience {
+	case AudiencePlayer:
+		return m.whisperTo != nil && *m.whisperTo == playerId
+	case AudienceMaster:
+		return isMaster
+	default:
+		return true
+	}
+}
+
+func (m *Message) Id() id.MessageId { return m.id }
+
+func (m *Message) CampaignId() id.CampaignId { return m.campaignId }
+
+func (m *Message) IsDeleted() bool { return m.deletedAt != nil }
+
+func (m *Message) setBody(body string) error {
+	body = strings.TrimSpace(body)
+	if body == "" && m.roll == nil {
+		return ErrInvalidBody
+	}
+	if utf8.RuneCountInString(body) > MaxBodyCharacters {
+		return ErrInvalidBody
+	}
+	m.body = body
+	return nil
+}
+
+func (m *Message) record(action AuditAction, previousBody string, actorId id.PlayerId, at time.Time) {
+	m.audit = append(m.audit, AuditRecord{
+		action:       action,
+		previousBody: previousBody,
+		actorId:      actorId,
+		createdAt:    at,
+	})
+}
@@ -0,0 +1,129 @@
+package chat
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/dice"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	author = id.PlayerId(2)
+	other  = id.PlayerId(3)
+	third  = id.PlayerId(4)
+)
+
+func TestNew(t *testing.T) {
+	roller := dice.NewSeededRoller(1)
+
+	tests := []struct {
+		name    string
+		body    string
+		opts    []Option
+		wantErr error
+	}{
+		{"message", "Hello", nil, nil},
+		{"as character", "I attack", []Option{AsCharacter(1, "Aragorn")}, nil},
+		{"whisper", "Psst", []Option{WhisperTo(other)}, nil},
+		{"to master", "I steal the ring", []Option{WhisperToMaster()}, nil},
+		{"only roll", "", []Option{WithRoll("1d20+5", roller)}, nil},
+		{"empty", "   ", nil, ErrInvalidBody},
+		{"too long", strings.Repeat("a", MaxBodyCharacters+1), nil, ErrInvalidBody},
+		{"invalid roll", "Attack", []Option{WithRoll("d", roller)}, ErrInvalidRoll},
+		{"whisper to self", "Psst", []Option{WhisperTo(author)}, ErrInvalidRecipient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(1, author, tt.body, tt.opts...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWithRoll(t *testing.T) {
+	m, err := New(1, author, "Attack", WithRoll("2d6 + 3", dice.NewSeededRoller(1)))
+	require.NoError(t, err)
+	require.NotNil(t, m.roll)
+
+	assert.Equal(t, "2d6+3", m.roll.expression)
+	require.Len(t, m.roll.rolls, 2)
+	assert.Equal(t, m.roll.rolls[0]+m.roll.rolls[1]+3, m.roll.total)
+}
+
+func TestIsVisibleTo(t *testing.T) {
+	everyone, err := New(1, author, "Hello")
+	require.NoError(t, err)
+	whisper, err := New(1, author, "Psst", WhisperTo(other))
+	require.NoError(t, err)
+	toMaster, err := New(1, author, "Secret", WhisperToMaster())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		message  *Message
+		playerId id.PlayerId
+		isMaster bool
+		want     bool
+	}{
+		{"everyone", everyone, third, false, true},
+		{"whisper author", whisper, author, false, true},
+		{"whisper recipient", whisper, other, false, true},
+		{"whisper other player", whisper, third, false, false},
+		{"whisper master", whisper, third, true, false},
+		{"to master author", toMaster, author, false, true},
+		{"to master master", toMaster, third, true, true},
+		{"to master player", toMaster, other, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.message.IsVisibleTo(tt.playerId, tt.isMaster))
+		})
+	}
+}
+
+func TestEdit(t *testing.T) {
+	m, err := New(1, author, "Helo")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, m.Edit("Hi", other), ErrMessageOfAnotherAuthor)
+
+	require.NoError(t, m.Edit("Hello", author))
+	assert.Equal(t, "Hello", m.body)
+	assert.NotNil(t, m.editedAt)
+	require.Len(t, m.audit, 1)
+	assert.Equal(t, AuditEdit, m.audit[0].action)
+	assert.Equal(t, "Helo", m.audit[0].previousBody)
+
+	// same body, nothing to audit
+	require.NoError(t, m.Edit("Hello ", author))
+	assert.Len(t, m.audit, 1)
+
+	assert.ErrorIs(t, m.Edit("", author), ErrInvalidBody)
+}
+
+func TestDelete(t *testing.T) {
+	m, err := New(1, author, "Rude words", WithRoll("1d20", dice.NewSeededRoller(1)))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, m.Delete(other, false), ErrMessageOfAnotherAuthor)
+
+	// the master moderates the chat
+	require.NoError(t, m.Delete(other, true))
+	assert.True(t, m.IsDeleted())
+	assert.Empty(t, m.body)
+	assert.Nil(t, m.roll)
+	require.Len(t, m.audit, 1)
+	assert.Equal(t, AuditDelete, m.audit[0].action)
+	assert.Equal(t, "Rude words", m.audit[0].previousBody)
+	assert.Equal(t, other, m.audit[0].actorId)
+
+	assert.ErrorIs(t, m.Delete(author, false), ErrMessageDeleted)
+	assert.ErrorIs(t, m.Edit("Sorry", author), ErrMessageDeleted)
+}
@@ -0,0 +1,370 @@
+package chat
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresRepository struct {
+	q postgres.QuerierProvider
+}
+
+func NewPostgresRepository(q postgres.QuerierProvider) *PostgresRepository {
+	return &PostgresRepository{q: q}
+}
+
+const selectMessage = `
+	SELECT
+	    message_id,
+	    campaign_id,
+	    author_id,
+	    character_id,
+	    character_name,
+	    audience,
+	    whisper_to,
+	    body,
+	    roll_expression,
+	    roll_results,
+	    roll_total,
+	    created_at,
+	    edited_at,
+	    deleted_at
+	FROM chat_messages
+`
+
+// visibleTo filters the messages a player can read, $2 is the player and $3 tells if they are the master
+const visibleTo = `
+	(audience = 'EVERYONE' OR author_id = $2 OR whisper_to = $2 OR (audience = 'MASTER' AND $3))
+`
+
+func (p *PostgresRepository) Save(ctx context.Context, m *Message) error {
+	const query = `
+		INSERT INTO chat_messages (
+			campaign_id, author_id, character_id, character_name, audience, whisper_to, body,
+			roll_expression, roll_results, roll_total, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING message_id
+	`
+
+	var (
+		characterID *int
+		whisperTo   *int
+		rollExpr    *string
+		rollResults []int
+		rollTotal   *int
+	)
+	if m.characterId != nil {
+		cid := int(*m.characterId)
+		characterID = &cid
+	}
+	if m.whisperTo != nil {
+		pid := int(*m.whisperTo)
+		whisperTo = &pid
+	}
+	if m.roll != nil {
+		rollExpr = &m.roll.expression
+		rollResults = m.roll.rolls
+		rollTotal = &m.roll.total
+	}
+
+	var messageID int
+	if err := p.q(ctx).QueryRow(ctx, query,
+		int(m.campaignId),
+		int(m.authorId),
+		characterID,
+		m.characterName,
+		string(m.audience),
+		whisperTo,
+		m.body,
+		rollExpr,
+		rollResults,
+		rollTotal,
+		m.createdAt,
+	).Scan(&messageID); err != nil {
+		return err
+	}
+	m.id = id.MessageId(messageID)
+	return nil
+}
+
+// Update should be called inside a transaction
+func (p *PostgresRepository) Update(ctx context.Context, m *Message) error {
+	const query = `
+		UPDATE chat_messages
+		SET body = $1,
+		    roll_expression = $2,
+		    roll_results = $3,
+		    roll_total = $4,
+		    edited_at = $5,
+		    deleted_at = $6
+		WHERE message_id = $7
+	`
+	const sqlInsertAudit = `
+		INSERT INTO chat_message_audit (message_id, action, previous_body, actor_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	var (
+		rollExpr    *string
+		rollResults []int
+		rollTotal   *int
+	)
+	if m.roll != nil {
+		rollExpr = &m.roll.expression
+		rollResults = m.roll.rolls
+		rollTotal = &m.roll.total
+	}
+
+	cmd, err := p.q(ctx).Exec(ctx, query, m.body, rollExpr, rollResults, rollTotal, m.editedAt, m.deletedAt, int(m.id))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+
+	for _, a := range m.audit {
+		if _, err := p.q(ctx).Exec(ctx, sqlInsertAudit, int(m.id), string(a.action), a.previousBody, int(a.actorId), a.createdAt); err != nil {
+			return err
+		}
+	}
+	m.audit = nil
+	return nil
+}
+
+func (p *PostgresRepository) FindById(ctx context.Context, messageId id.MessageId) (*Message, error) {
+	const query = selectMessage + `WHERE message_id = $1`
+
+	m, err := p.scanMessage(p.q(ctx).QueryRow(ctx, query, int(messageId)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+func (p *PostgresRepository) FindHistory(
+	ctx context.Context,
+	campaignId id.CampaignId,
+	viewer Viewer,
+	cursor Cursor,
+	limit int,
+) ([]*Message, error) {
+	// the newest messages, or the ones before the cursor, are read backwards
+	const sqlBackward = selectMessage + `
+		WHERE campaign_id = $1
+		  AND ($4::INT IS NULL OR message_id < $4)
+		  AND ` + visibleTo + `
+		ORDER BY message_id DESC
+		LIMIT $5
+	`
+	const sqlForward = selectMessage + `
+		WHERE campaign_id = $1
+		  AND message_id > $4
+		  AND ` + visibleTo + `
+		ORDER BY message_id
+		LIMIT $5
+	`
+
+	query, from := sqlBackward, cursor.Before
+	if cursor.After != nil {
+		query, from = sqlForward, cursor.After
+	}
+	var fromID *int
+	if from != nil {
+		fid := int(*from)
+		fromID = &fid
+	}
+
+	rows, err := p.q(ctx).Query(ctx, query, int(campaignId), int(viewer.PlayerId), viewer.IsMaster, fromID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]*Message, 0)
+	for rows.Next() {
+		m, err := p.scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if cursor.After == nil {
+		slices.Reverse(messages)
+	}
+	return messages, nil
+}
+
+func (p *PostgresRepository) FindAudit(ctx context.Context, messageId id.MessageId) ([]AuditRecord, error) {
+	const query = `
+		SELECT action, previous_body, actor_id, created_at
+		FROM chat_message_audit
+		WHERE message_id = $1
+		ORDER BY audit_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(messageId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := make([]AuditRecord, 0)
+	for rows.Next() {
+		var (
+			a       AuditRecord
+			actorID int
+		)
+		if err := rows.Scan(&a.action, &a.previousBody, &actorID, &a.createdAt); err != nil {
+			return nil, err
+		}
+		a.actorId = id.PlayerId(actorID)
+		records = append(records, a)
+	}
+	return records, rows.Err()
+}
+
+// scanMessage translates DB row -> domain model
+func (p *PostgresRepository) scanMessage(row pgx.Row) (*Message, error) {
+	var (
+		messageID     int
+		campaignID    int
+		authorID      int
+		characterID   *int
+		characterName string
+		audience      Audience
+		whisperTo     *int
+		body          string
+		rollExpr      *string
+		rollResults   []int
+		rollTotal     *int
+		createdAt     time.Time
+		editedAt      *time.Time
+		deletedAt     *time.Time
+	)
+
+	if err := row.Scan(
+		&messageID,
+		&campaignID,
+		&authorID,
+		&characterID,
+		&characterName,
+		&audience,
+		&whisperTo,
+		&body,
+		&rollExpr,
+		&rollResults,
+		&rollTotal,
+		&createdAt,
+		&editedAt,
+		&deletedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	m := &Message{
+		id:            id.MessageId(messageID),
+		campaignId:    id.CampaignId(campaignID),
+		authorId:      id.PlayerId(authorID),
+		characterName: characterName,
+		audience:      audience,
+		body:          body,
+		createdAt:     createdAt,
+		editedAt:      editedAt,
+		deletedAt:     deletedAt,
+	}
+	if characterID != nil {
+		cid := id.CharacterId(*characterID)
+		m.characterId = &cid
+	}
+	if whisperTo != nil {
+		pid := id.PlayerId(*whisperTo)
+		m.whisperTo = &pid
+	}
+	if rollExpr != nil && rollTotal != nil {
+		m.roll = &Roll{expression: *rollExpr, rolls: rollResults, total: *rollTotal}
+	}
+	return m, nil
+}
+
+func (p *PostgresRepository) FindExportMessages(ctx context.Context, playerId id.PlayerId) ([]ExportedMessage, error) {
+	const query = `
+		SELECT message_id, campaign_id, author_id, character_name, audience, body, roll_total,
+		       created_at, edited_at, deleted_at
+		FROM chat_messages
+		WHERE author_id = $1
+		ORDER BY message_id
+	`
+	return p.findExportMessages(ctx, query, playerId)
+}
+
+func (p *PostgresRepository) FindExportReceivedWhispers(ctx context.Context, playerId id.PlayerId) ([]ExportedMessage, error) {
+	const query = `
+		SELECT message_id, campaign_id, author_id, character_name, audience, body, roll_total,
+		       created_at, edited_at, deleted_at
+		FROM chat_messages
+		WHERE whisper_to = $1
+		ORDER BY message_id
+	`
+	return p.findExportMessages(ctx, query, playerId)
+}
+
+func (p *PostgresRepository) findExportMessages(ctx context.Context, query string, playerId id.PlayerId) ([]ExportedMessage, error) {
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]ExportedMessage, 0)
+	for rows.Next() {
+		var m ExportedMessage
+		err := rows.Scan(&m.MessageId, &m.CampaignId, &m.AuthorId, &m.CharacterName, &m.Audience, &m.Body, &m.RollTotal,
+			&m.CreatedAt, &m.EditedAt, &m.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+func (p *PostgresRepository) FindExportEdits(ctx context.Context, playerId id.PlayerId) ([]ExportedEdit, error) {
+	const query = `
+		SELECT a.message_id, a.action, a.previous_body, a.actor_id, a.created_at
+		FROM chat_message_audit a
+		JOIN chat_messages m ON m.message_id = a.message_id
+		WHERE m.author_id = $1
+		ORDER BY a.audit_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edits := make([]ExportedEdit, 0)
+	for rows.Next() {
+		var e ExportedEdit
+		if err := rows.Scan(&e.MessageId, &e.Action, &e.PreviousBody, &e.ActorId, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		edits = append(edits, e)
+	}
+	return edits, rows.Err()
+}
@@ -0,0 +1,49 @@
+package chat
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"context"
+)
+
+// Cursor selects the messages before or after a message, the newest ones when both are nil
+type Cursor struct {
+	Before *id.MessageId
+	After  *id.MessageId
+}
+
+// Viewer is the player reading the history, whispers not meant for them are skipped
+type Viewer struct {
+	PlayerId id.PlayerId
+	IsMaster bool
+}
+
+type Saver interface {
+	Save(ctx context.Context, m *Message) error
+	// Update persists the body and the state of the message along with its new audit records
+	Update(ctx context.Context, m *Message) error
+}
+
+type Finder interface {
+	FindById(ctx context.Context, messageId id.MessageId) (*Message, error)
+	// FindHistory gives back at most limit messages next to the cursor, from the oldest to the newest
+	FindHistory(ctx context.Context, campaignId id.CampaignId, viewer Viewer, cursor Cursor, limit int) ([]*Message, error)
+	FindAudit(ctx context.Context, messageId id.MessageId) ([]AuditRecord, error)
+}
+
+type CampaignFinder interface {
+	FindById(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error)
+}
+
+type CharacterFinder interface {
+	FindById(ctx context.Context, characterId id.CharacterId) (*character.Character, error)
+}
+
+// Exporter reads the chat messages of a player for the export of its account
+type Exporter interface {
+	FindExportMessages(ctx context.Context, playerId id.PlayerId) ([]ExportedMessage, error)
+	FindExportReceivedWhispers(ctx context.Context, playerId id.PlayerId) ([]ExportedMessage, error)
+	// FindExportEdits gives back the previous bodies of the messages of the player
+	FindExportEdits(ctx context.Context, playerId id.PlayerId) ([]ExportedEdit, error)
+}
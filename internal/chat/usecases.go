@@ -0,0 +1,336 @@
+package chat
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dice"
+	"beldur/pkg/dto"
+	"beldur/pkg/live"
+	"beldur/pkg/logger"
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	EventMessage        = "message"
+	EventMessageEdited  = "message_edited"
+	EventMessageDeleted = "message_deleted"
+)
+
+// Publisher delivers the events live to the connected players
+type Publisher interface {
+	Publish(topic string, event live.Event, to func(playerId id.PlayerId) bool)
+}
+
+// Subscriber connects a player to the live events
+type Subscriber interface {
+	Subscribe(topic string, playerId id.PlayerId) (<-chan live.Event, func())
+}
+
+type UseCase struct {
+	messageSaver    Saver
+	messageFinder   Finder
+	campaignFinder  CampaignFinder
+	characterFinder CharacterFinder
+	publisher       Publisher
+	subscriber      Subscriber
+	roller          dice.Roller
+	tx              tx.Transactor
+}
+
+func NewUseCase(
+	messageSaver Saver,
+	messageFinder Finder,
+	campaignFinder CampaignFinder,
+	characterFinder CharacterFinder,
+	publisher Publisher,
+	subscriber Subscriber,
+	roller dice.Roller,
+	tx tx.Transactor,
+) *UseCase {
+	return &UseCase{
+		messageSaver:    messageSaver,
+		messageFinder:   messageFinder,
+		campaignFinder:  campaignFinder,
+		characterFinder: characterFinder,
+		publisher:       publisher,
+		subscriber:      subscriber,
+		roller:          roller,
+		tx:              tx,
+	}
+}
+
+// Send writes a message in the chat of the campaign and delivers it to the connected players who can read it.
+// Every player of the campaign can do it, speaking as one of the characters they play.
+func (uc *UseCase) Send(ctx context.Context, req SendMessageRequest, campaignId id.CampaignId, playerId id.PlayerId) (MessageResponse, error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return MessageResponse{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return MessageResponse{}, ErrPlayerNotInCampaign
+	}
+
+	var opts []Option
+	if req.CharacterId != nil {
+		ch, err := uc.characterFinder.FindById(ctx, id.CharacterId(*req.CharacterId))
+		if err != nil {
+			if errors.Is(err, postgres.ErrNoRowFound) {
+				return MessageResponse{}, ErrCharacterNotFound
+			}
+			return MessageResponse{}, err
+		}
+		if ch.CampaignId() != campaignId || !ch.IsActive() || !ch.CanBeEditedBy(playerId, camp.IsMaster(playerId)) {
+			return MessageResponse{}, ErrCharacterNotPlayed
+		}
+		opts = append(opts, AsCharacter(ch.Id(), ch.Name()))
+	}
+	switch {
+	case req.WhisperTo != nil && req.ToMaster:
+		return MessageResponse{}, ErrInvalidRecipient
+	case req.WhisperTo != nil:
+		recipient := id.PlayerId(*req.WhisperTo)
+		if !camp.HasPlayer(recipient) {
+			return MessageResponse{}, ErrPlayerNotInCampaign
+		}
+		opts = append(opts, WhisperTo(recipient))
+	case req.ToMaster:
+		if camp.IsMaster(playerId) {
+			return MessageResponse{}, ErrInvalidRecipient
+		}
+		opts = append(opts, WhisperToMaster())
+	}
+	if req.Roll != "" {
+		opts = append(opts, WithRoll(req.Roll, uc.roller))
+	}
+
+	m, err := New(campaignId, playerId, req.Body, opts...)
+	if err != nil {
+		return MessageResponse{}, err
+	}
+
+	err = uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		return uc.messageSaver.Save(ctx, m)
+	})
+	if err != nil {
+		logger.Debug("failed to save message", "campaign_id", campaignId, "error", err)
+		return MessageResponse{}, err
+	}
+
+	uc.publish(EventMessage, m, camp)
+	return toMessageResponse(m), nil
+}
+
+// History gives back the messages of the campaign the player can read, next to the cursor.
+// Without a cursor it gives back the newest ones.
+func (uc *UseCase) History(ctx context.Context, campaignId id.CampaignId, cursor Cursor, limit int, playerId id.PlayerId) (HistoryResponse, error) {
+	if cursor.Before != nil && cursor.After != nil {
+		return HistoryResponse{}, ErrInvalidCursor
+	}
+
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return HistoryResponse{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return HistoryResponse{}, ErrPlayerNotInCampaign
+	}
+
+	limit = dto.NewPage(limit, 0).Limit
+	viewer := Viewer{PlayerId: playerId, IsMaster: camp.IsMaster(playerId)}
+
+	// one more message tells if there are others
+	messages, err := uc.messageFinder.FindHistory(ctx, campaignId, viewer, cursor, limit+1)
+	if err != nil {
+		logger.Debug("failed to find messages", "campaign_id", campaignId, "error", err)
+		return HistoryResponse{}, err
+	}
+
+	hasMore := len(messages) > limit
+	if hasMore {
+		// the extra message is the farthest from the cursor
+		if cursor.After != nil {
+			messages = messages[:limit]
+		} else {
+			messages = messages[1:]
+		}
+	}
+
+	list := make([]MessageResponse, len(messages))
+	for i, m := range messages {
+		list[i] = toMessageResponse(m)
+	}
+	return HistoryResponse{Data: list, HasMore: hasMore}, nil
+}
+
+// Edit replaces the body of the message. Only its author can do it, the previous body is kept for audit.
+func (uc *UseCase) Edit(ctx context.Context, req EditMessageRequest, messageId id.MessageId, playerId id.PlayerId) (MessageResponse, error) {
+	return uc.modify(ctx, messageId, EventMessageEdited, func(m *Message, camp *campaign.Campaign) error {
+		return m.Edit(req.Body, playerId)
+	}, playerId)
+}
+
+// Delete hides the message. Its author and the master can do it, the previous body is kept for audit.
+func (uc *UseCase) Delete(ctx context.Context, messageId id.MessageId, playerId id.PlayerId) error {
+	_, err := uc.modify(ctx, messageId, EventMessageDeleted, func(m *Message, camp *campaign.Campaign) error {
+		return m.Delete(playerId, camp.IsMaster(playerId))
+	}, playerId)
+	return err
+}
+
+// Audit gives back the edits and the deletion of the message. Only the master of the campaign can read them,
+// and only for the messages they can see: the whispers between players stay private.
+func (uc *UseCase) Audit(ctx context.Context, messageId id.MessageId, masterId id.PlayerId) (dto.ListResponse[AuditResponse], error) {
+	m, camp, err := uc.findMessage(ctx, messageId)
+	if err != nil {
+		return dto.ListResponse[AuditResponse]{}, err
+	}
+	if !camp.IsMaster(masterId) {
+		return dto.ListResponse[AuditResponse]{}, ErrCampaignHasAnotherMaster
+	}
+	if !m.IsVisibleTo(masterId, true) {
+		return dto.ListResponse[AuditResponse]{}, ErrMessageNotFound
+	}
+
+	records, err := uc.messageFinder.FindAudit(ctx, messageId)
+	if err != nil {
+		logger.Debug("failed to find message audit", "message_id", messageId, "error", err)
+		return dto.ListResponse[AuditResponse]{}, err
+	}
+
+	list := make([]AuditResponse, len(records))
+	for i, a := range records {
+		list[i] = AuditResponse{
+			Action:       a.action,
+			PreviousBody: a.previousBody,
+			ActorId:      int(a.actorId),
+			CreatedAt:    a.createdAt,
+		}
+	}
+	return dto.ListResponse[AuditResponse]{Data: list}, nil
+}
+
+// Subscribe connects the player to the live messages of the campaign.
+// The returned function must be called on disconnection.
+func (uc *UseCase) Subscribe(ctx context.Context, campaignId id.CampaignId, playerId id.PlayerId) (<-chan live.Event, func(), error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return nil, nil, ErrPlayerNotInCampaign
+	}
+
+	events, unsubscribe := uc.subscriber.Subscribe(topic(campaignId), playerId)
+	return events, unsubscribe, nil
+}
+
+// modify loads the message with its campaign, applies the change, persists it and delivers it live
+func (uc *UseCase) modify(
+	ctx context.Context,
+	messageId id.MessageId,
+	event string,
+	change func(*Message, *campaign.Campaign) error,
+	playerId id.PlayerId,
+) (MessageResponse, error) {
+	var (
+		m    *Message
+		camp *campaign.Campaign
+	)
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		m, camp, err = uc.findMessage(ctx, messageId)
+		if err != nil {
+			return err
+		}
+		// messages not meant for the player do not exist for them
+		if !camp.HasPlayer(playerId) || !m.IsVisibleTo(playerId, camp.IsMaster(playerId)) {
+			return ErrMessageNotFound
+		}
+		if err := change(m, camp); err != nil {
+			return err
+		}
+		if err := uc.messageSaver.Update(ctx, m); err != nil {
+			logger.Debug("failed to update message", "message_id", messageId, "error", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return MessageResponse{}, err
+	}
+
+	uc.publish(event, m, camp)
+	return toMessageResponse(m), nil
+}
+
+// publish delivers the message to the connected players who can read it
+func (uc *UseCase) publish(event string, m *Message, camp *campaign.Campaign) {
+	uc.publisher.Publish(topic(m.campaignId), live.Event{Type: event, Data: toMessageResponse(m)}, func(playerId id.PlayerId) bool {
+		return m.IsVisibleTo(playerId, camp.IsMaster(playerId))
+	})
+}
+
+func (uc *UseCase) findMessage(ctx context.Context, messageId id.MessageId) (*Message, *campaign.Campaign, error) {
+	m, err := uc.messageFinder.FindById(ctx, messageId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, nil, ErrMessageNotFound
+		}
+		logger.Debug("failed to find message", "message_id", messageId, "error", err)
+		return nil, nil, err
+	}
+
+	camp, err := uc.findCampaign(ctx, m.campaignId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, camp, nil
+}
+
+func (uc *UseCase) findCampaign(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		logger.Debug("no campaign found", "campaign_id", campaignId)
+		return nil, errors.Join(ErrCampaignNotFound, err)
+	}
+	return camp, nil
+}
+
+func topic(campaignId id.CampaignId) string {
+	return fmt.Sprintf("campaign/%d/chat", campaignId)
+}
+
+func toMessageResponse(m *Message) MessageResponse {
+	resp := MessageResponse{
+		Id:            int(m.id),
+		CampaignId:    int(m.campaignId),
+		AuthorId:      int(m.authorId),
+		CharacterName: m.characterName,
+		Audience:      m.audience,
+		Body:          m.body,
+		CreatedAt:     m.createdAt,
+		EditedAt:      m.editedAt,
+		Deleted:       m.IsDeleted(),
+	}
+	if m.characterId != nil {
+		cid := int(*m.characterId)
+		resp.CharacterId = &cid
+	}
+	if m.whisperTo != nil {
+		pid := int(*m.whisperTo)
+		resp.WhisperTo = &pid
+	}
+	if m.roll != nil {
+		resp.Roll = &RollResponse{
+			Expression: m.roll.expression,
+			Rolls:      m.roll.rolls,
+			Total:      m.roll.total,
+		}
+	}
+	return resp
+}
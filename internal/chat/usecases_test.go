@@ -0,0 +1,72 @@
+package chat
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseCase_Audit(t *testing.T) {
+	ctx := context.Background()
+	master := id.PlayerId(1)
+
+	camp, err := campaign.New("campaign", "description", master)
+	require.NoError(t, err)
+	require.NoError(t, camp.AddPlayer(author))
+	require.NoError(t, camp.AddPlayer(other))
+
+	public, err := New(0, author, "Helo")
+	require.NoError(t, err)
+	require.NoError(t, public.Edit("Hello", author))
+	whisper, err := New(0, author, "Psst", WhisperTo(other))
+	require.NoError(t, err)
+	require.NoError(t, whisper.Edit("Psst!", author))
+
+	messages, campaigns := new(MockFinder), new(MockCampaignFinder)
+	messages.On("FindById", mock.Anything, id.MessageId(1)).Return(public, nil)
+	messages.On("FindById", mock.Anything, id.MessageId(2)).Return(whisper, nil)
+	messages.On("FindAudit", mock.Anything, id.MessageId(1)).Return(public.audit, nil)
+	campaigns.On("FindById", mock.Anything, id.CampaignId(0)).Return(camp, nil)
+	uc := NewUseCase(nil, messages, campaigns, nil, nil, nil, nil, nil)
+
+	audit, err := uc.Audit(ctx, 1, master)
+	require.NoError(t, err)
+	require.Len(t, audit.Data, 1)
+	assert.Equal(t, "Helo", audit.Data[0].PreviousBody)
+
+	_, err = uc.Audit(ctx, 1, author)
+	assert.ErrorIs(t, err, ErrCampaignHasAnotherMaster)
+
+	// the whispers between players are not shown to the master, nor their edits
+	_, err = uc.Audit(ctx, 2, master)
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+	messages.AssertNotCalled(t, "FindAudit", mock.Anything, id.MessageId(2))
+}
+
+type MockFinder struct{ mock.Mock }
+type MockCampaignFinder struct{ mock.Mock }
+
+func (m *MockFinder) FindById(ctx context.Context, messageId id.MessageId) (*Message, error) {
+	args := m.Called(ctx, messageId)
+	return args.Get(0).(*Message), args.Error(1)
+}
+
+func (m *MockFinder) FindHistory(ctx context.Context, campaignId id.CampaignId, viewer Viewer, cursor Cursor, limit int) ([]*Message, error) {
+	args := m.Called(ctx, campaignId, viewer, cursor, limit)
+	return args.Get(0).([]*Message), args.Error(1)
+}
+
+func (m *MockFinder) FindAudit(ctx context.Context, messageId id.MessageId) ([]AuditRecord, error) {
+	args := m.Called(ctx, messageId)
+	return args.Get(0).([]AuditRecord), args.Error(1)
+}
+
+func (m *MockCampaignFinder) FindById(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error) {
+	args := m.Called(ctx, campaignId)
+	return args.Get(0).(*campaign.Campaign), args.Error(1)
+}
@@ -0,0 +1,34 @@
+package chat
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dice"
+	"beldur/pkg/live"
+)
+
+type Deps struct {
+	QProvider  postgres.QuerierProvider
+	Transactor tx.Transactor
+	Broker     *live.Broker
+}
+
+func NewHandlerFromDeps(deps Deps) *HttpHandler {
+	messageRepo := NewPostgresRepository(deps.QProvider)
+	campaignRepo := campaign.NewPostgresRepository(deps.QProvider)
+	characterRepo := character.NewPostgresRepository(deps.QProvider)
+
+	chatUC := NewUseCase(
+		messageRepo,
+		messageRepo,
+		campaignRepo,
+		characterRepo,
+		deps.Broker,
+		deps.Broker,
+		dice.NewRoller(),
+		deps.Transactor,
+	)
+	return NewHttpHandler(chatUC)
+}
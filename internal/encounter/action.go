@@ -0,0 +1,32 @@
+package encounter
+
+import (
+	"beldur/internal/id"
+	"time"
+)
+
+type ActionKind string
+
+const (
+	ActionStart            ActionKind = "start"
+	ActionNextTurn         ActionKind = "next_turn"
+	ActionDelay            ActionKind = "delay"
+	ActionRemove           ActionKind = "remove"
+	ActionHitPoints        ActionKind = "hit_points"
+	ActionConditionAdded   ActionKind = "condition_added"
+	ActionConditionRemoved ActionKind = "condition_removed"
+	ActionEnd              ActionKind = "end"
+)
+
+// Action is an entry of the history of the encounter
+type Action struct {
+	// set by the repository once the action is persisted
+	id    int
+	round int
+	kind  ActionKind
+	// the combatant the action is about, nil if it is about the whole encounter
+	characterId *id.CharacterId
+	detail      map[string]any
+	authorId    id.PlayerId
+	createdAt   time.Time
+}
@@ -0,0 +1,46 @@
+package encounter
+
+import "slices"
+
+type Condition string
+
+const (
+	ConditionBlinded       Condition = "blinded"
+	ConditionCharmed       Condition = "charmed"
+	ConditionDeafened      Condition = "deafened"
+	ConditionFrightened    Condition = "frightened"
+	ConditionGrappled      Condition = "grappled"
+	ConditionIncapacitated Condition = "incapacitated"
+	ConditionInvisible     Condition = "invisible"
+	ConditionParalyzed     Condition = "paralyzed"
+	ConditionPetrified     Condition = "petrified"
+	ConditionPoisoned      Condition = "poisoned"
+	ConditionProne         Condition = "prone"
+	ConditionRestrained    Condition = "restrained"
+	ConditionStunned       Condition = "stunned"
+	ConditionUnconscious   Condition = "unconscious"
+)
+
+var AllConditions = []Condition{
+	ConditionBlinded,
+	ConditionCharmed,
+	ConditionDeafened,
+	ConditionFrightened,
+	ConditionGrappled,
+	ConditionIncapacitated,
+	ConditionInvisible,
+	ConditionParalyzed,
+	ConditionPetrified,
+	ConditionPoisoned,
+	ConditionProne,
+	ConditionRestrained,
+	ConditionStunned,
+	ConditionUnconscious,
+}
+
+func (c Condition) Validate() error {
+	if !slices.Contains(AllConditions, c) {
+		return ErrUnknownCondition
+	}
+	return nil
+}
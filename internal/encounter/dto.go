@@ -0,0 +1,77 @@
+package encounter
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type CreateEncounterRequest struct {
+	Name         string `json:"name" validate:"required,max=100"`
+	CharacterIds []int  `json:"character_ids" validate:"required,min=1,max=50"`
+}
+
+type DelayRequest struct {
+	// the combatant to act after, the next one if nil
+	AfterCharacterId *int `json:"after_character_id"`
+}
+
+// UpdateCombatantRequest changes the hit points by the delta, if set, then adds and removes conditions
+type UpdateCombatantRequest struct {
+	HitPointsDelta   *int        `json:"hit_points_delta"`
+	AddConditions    []Condition `json:"add_conditions"`
+	RemoveConditions []Condition `json:"remove_conditions"`
+}
+
+type EncounterResponse struct {
+	Id                int                 `json:"encounter_id"`
+	CampaignId        int                 `json:"campaign_id"`
+	Name              string              `json:"name"`
+	Status            Status              `json:"status"`
+	Round             int                 `json:"round"`
+	ActiveCharacterId *int                `json:"active_character_id"`
+	Combatants        []CombatantResponse `json:"combatants"`
+	CreatedAt         time.Time           `json:"created_at"`
+	EndedAt           *time.Time          `json:"ended_at"`
+}
+
+// CombatantResponse hides the hit points of NPCs to the players
+type CombatantResponse struct {
+	CharacterId  int         `json:"character_id"`
+	Name         string      `json:"name"`
+	NPC          bool        `json:"npc"`
+	Initiative   int         `json:"initiative"`
+	HitPoints    *int        `json:"hit_points,omitempty"`
+	MaxHitPoints *int        `json:"max_hit_points,omitempty"`
+	Conditions   []Condition `json:"conditions"`
+}
+
+type EncounterSummaryResponse struct {
+	Id        int        `json:"encounter_id"`
+	Name      string     `json:"name"`
+	Status    Status     `json:"status"`
+	Round     int        `json:"round"`
+	CreatedAt time.Time  `json:"created_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+}
+
+type ActionResponse struct {
+	Id          int            `json:"action_id"`
+	Round       int            `json:"round"`
+	Kind        ActionKind     `json:"kind"`
+	CharacterId *int           `json:"character_id"`
+	Detail      map[string]any `json:"detail"`
+	AuthorId    int            `json:"author_id"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// ExportedAction is an encounter action logged by the player, for the export of its account
+type ExportedAction struct {
+	ActionId    int             `json:"action_id"`
+	EncounterId int             `json:"encounter_id"`
+	CampaignId  int             `json:"campaign_id"`
+	Round       int             `json:"round"`
+	Kind        string          `json:"kind"`
+	CharacterId *int            `json:"character_id"`
+	Detail      json.RawMessage `json:"detail"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
@@ -0,0 +1,369 @@
+package encounter
+
+import (
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"beldur/pkg/dice"
+	"cmp"
+	"slices"
+	"time"
+)
+
+const (
+	MaxNameCharacters = 100
+	MaxCombatants     = 50
+)
+
+type Status string
+
+const (
+	StatusRunning Status = "RUNNING"
+	StatusEnded   Status = "ENDED"
+)
+
+// Combatant is a character taking part in the encounter
+type Combatant struct {
+	characterId id.CharacterId
+	name        string
+	npc         bool
+	initiative  int
+	// dexterity modifier, it breaks the ties of initiative
+	dexModifier int
+	hitPoints   int
+	// 0 when hit points are not tracked by the character
+	maxHitPoints int
+	conditions   []Condition
+}
+
+func (c *Combatant) CharacterId() id.CharacterId { return c.characterId }
+
+func (c *Combatant) Initiative() int { return c.initiative }
+
+func (c *Combatant) HitPoints() int { return c.hitPoints }
+
+func (c *Combatant) Conditions() []Condition { return c.conditions }
+
+// Encounter is a fight of a campaign. Combatants act in order of initiative,
+// one turn each, and a round ends when everyone has acted.
+type Encounter struct {
+	id         id.EncounterId
+	campaignId id.CampaignId
+	name       string
+	status     Status
+	round      int
+	// index in combatants of the active combatant
+	turn int
+	// in order of turn
+	combatants []*Combatant
+	createdAt  time.Time
+	// nil if not ended
+	endedAt *time.Time
+
+	// actions recorded since the encounter was created or loaded, the repository persists them
+	actions []Action
+}
+
+// New starts an encounter of the campaign, rolling the initiative of every participant
+// with a d20 plus the dexterity modifier. Participants must be active characters of the campaign.
+func New(
+	name string,
+	campaignId id.CampaignId,
+	participants []*character.Character,
+	roller dice.Roller,
+	masterId id.PlayerId,
+) (*Encounter, error) {
+	if name == "" || len(name) > MaxNameCharacters {
+		return nil, ErrInvalidEncounterName
+	}
+	if len(participants) == 0 {
+		return nil, ErrNoCombatants
+	}
+	if len(participants) > MaxCombatants {
+		return nil, ErrTooManyCombatants
+	}
+
+	combatants := make([]*Combatant, 0, len(participants))
+	seen := make(map[id.CharacterId]struct{}, len(participants))
+	for _, ch := range participants {
+		if _, ok := seen[ch.Id()]; ok {
+			return nil, ErrDuplicateCombatant
+		}
+		seen[ch.Id()] = struct{}{}
+
+		if ch.CampaignId() != campaignId {
+			return nil, ErrCharacterNotInCampaign
+		}
+		if !ch.IsActive() {
+			return nil, ErrCharacterNotActive
+		}
+
+		dex := character.Modifier(ch.AbilityPoint(character.AbilityDexterity))
+		combatants = append(combatants, &Combatant{
+			characterId:  ch.Id(),
+			name:         ch.Name(),
+			npc:          ch.IsNPC(),
+			initiative:   dice.D(roller, 20) + dex,
+			dexModifier:  dex,
+			hitPoints:    ch.HitPoints(),
+			maxHitPoints: ch.HitPoints(),
+			conditions:   make([]Condition, 0),
+		})
+	}
+
+	slices.SortStableFunc(combatants, func(a, b *Combatant) int {
+		if c := cmp.Compare(b.initiative, a.initiative); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.dexModifier, a.dexModifier); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.characterId, b.characterId)
+	})
+
+	e := &Encounter{
+		campaignId: campaignId,
+		name:       name,
+		status:     StatusRunning,
+		round:      1,
+		turn:       0,
+		combatants: combatants,
+		createdAt:  time.Now(),
+	}
+
+	order := make([]map[string]any, len(combatants))
+	for i, c := range combatants {
+		order[i] = map[string]any{"character_id": int(c.characterId), "name": c.name, "initiative": c.initiative}
+	}
+	e.record(ActionStart, nil, map[string]any{"order": order}, masterId)
+	return e, nil
+}
+
+// Active gives back the combatant whose turn it is, nil if the encounter has no combatants left
+func (e *Encounter) Active() *Combatant {
+	if len(e.combatants) == 0 {
+		return nil
+	}
+	return e.combatants[e.turn]
+}
+
+// NextTurn ends the turn of the active combatant. After the last one a new round starts.
+func (e *Encounter) NextTurn(masterId id.PlayerId) error {
+	if err := e.checkRunning(); err != nil {
+		return err
+	}
+	// the characters of all the combatants may have been deleted
+	if len(e.combatants) == 0 {
+		return ErrNoCombatants
+	}
+
+	e.turn++
+	if e.turn >= len(e.combatants) {
+		e.turn = 0
+		e.round++
+	}
+
+	active := e.Active()
+	e.record(ActionNextTurn, &active.characterId, map[string]any{"name": active.name}, masterId)
+	return nil
+}
+
+// Delay moves the active combatant right after another one that still has to act
+// in this round, by default the next one. The active combatant takes its initiative.
+func (e *Encounter) Delay(after *id.CharacterId, masterId id.PlayerId) error {
+	if err := e.checkRunning(); err != nil {
+		return err
+	}
+
+	target := e.turn + 1
+	if after != nil {
+		target = e.indexOf(*after)
+		if target < 0 {
+			return ErrCombatantNotFound
+		}
+	}
+	if target <= e.turn || target >= len(e.combatants) {
+		return ErrCannotDelay
+	}
+
+	delayed := e.combatants[e.turn]
+	targetCombatant := e.combatants[target]
+	delayed.initiative = targetCombatant.initiative
+
+	e.combatants = slices.Delete(e.combatants, e.turn, e.turn+1)
+	// the target moved back by one with the deletion
+	e.combatants = slices.Insert(e.combatants, target, delayed)
+
+	e.record(ActionDelay, &delayed.characterId, map[string]any{
+		"name":               delayed.name,
+		"after_character_id": int(targetCombatant.characterId),
+	}, masterId)
+	return nil
+}
+
+// Remove takes the combatant out of the encounter. If it was its turn, the next combatant acts.
+// The encounter ends when there are no combatants left.
+func (e *Encounter) Remove(characterId id.CharacterId, masterId id.PlayerId) error {
+	if err := e.checkRunning(); err != nil {
+		return err
+	}
+
+	idx := e.indexOf(characterId)
+	if idx < 0 {
+		return ErrCombatantNotFound
+	}
+	removed := e.combatants[idx]
+	e.combatants = slices.Delete(e.combatants, idx, idx+1)
+
+	e.record(ActionRemove, &removed.characterId, map[string]any{"name": removed.name}, masterId)
+
+	if len(e.combatants) == 0 {
+		e.turn = 0
+		return e.End(masterId)
+	}
+	if idx < e.turn {
+		e.turn--
+	} else if e.turn >= len(e.combatants) {
+		e.turn = 0
+		e.round++
+	}
+	return nil
+}
+
+// ChangeHitPoints applies damage (negative delta) or healing (positive delta) to the combatant.
+// Hit points never go below 0, nor above the maximum of the character when it is tracked.
+func (e *Encounter) ChangeHitPoints(characterId id.CharacterId, delta int, masterId id.PlayerId) error {
+	c, err := e.runningCombatant(characterId)
+	if err != nil {
+		return err
+	}
+
+	from := c.hitPoints
+	c.hitPoints = max(0, c.hitPoints+delta)
+	if c.maxHitPoints > 0 {
+		c.hitPoints = min(c.hitPoints, c.maxHitPoints)
+	}
+	if c.hitPoints == from {
+		return nil
+	}
+
+	e.record(ActionHitPoints, &c.characterId, map[string]any{
+		"name": c.name,
+		"npc":  c.npc,
+		"from": from,
+		"to":   c.hitPoints,
+	}, masterId)
+	return nil
+}
+
+// AddCondition gives the condition to the combatant, nothing happens if it already has it
+func (e *Encounter) AddCondition(characterId id.CharacterId, condition Condition, masterId id.PlayerId) error {
+	if err := condition.Validate(); err != nil {
+		return err
+	}
+	c, err := e.runningCombatant(characterId)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(c.conditions, condition) {
+		return nil
+	}
+
+	c.conditions = append(c.conditions, condition)
+	e.record(ActionConditionAdded, &c.characterId, map[string]any{"name": c.name, "condition": condition}, masterId)
+	return nil
+}
+
+// RemoveCondition takes the condition away from the combatant, nothing happens if it does not have it
+func (e *Encounter) RemoveCondition(characterId id.CharacterId, condition Condition, masterId id.PlayerId) error {
+	if err := condition.Validate(); err != nil {
+		return err
+	}
+	c, err := e.runningCombatant(characterId)
+	if err != nil {
+		return err
+	}
+	idx := slices.Index(c.conditions, condition)
+	if idx < 0 {
+		return nil
+	}
+
+	c.conditions = slices.Delete(c.conditions, idx, idx+1)
+	e.record(ActionConditionRemoved, &c.characterId, map[string]any{"name": c.name, "condition": condition}, masterId)
+	return nil
+}
+
+func (e *Encounter) End(masterId id.PlayerId) error {
+	if err := e.checkRunning(); err != nil {
+		return err
+	}
+	now := time.Now()
+	e.status = StatusEnded
+	e.endedAt = &now
+	e.record(ActionEnd, nil, map[string]any{}, masterId)
+	return nil
+}
+
+// resumeTurn finds the active combatant of a loaded encounter from the saved positions of its
+// combatants, in order. Deleting a character deletes its combatant without the encounter knowing,
+// leaving gaps in the positions: the turn stays with the saved one, or goes to the next one, or
+// to the first of a new round.
+func (e *Encounter) resumeTurn(positions []int) {
+	idx := slices.IndexFunc(positions, func(p int) bool { return p >= e.turn })
+	switch {
+	case len(e.combatants) == 0:
+		e.turn = 0
+	case idx < 0:
+		e.turn = 0
+		e.round++
+	default:
+		e.turn = idx
+	}
+}
+
+func (e *Encounter) Id() id.EncounterId { return e.id }
+
+func (e *Encounter) CampaignId() id.CampaignId { return e.campaignId }
+
+func (e *Encounter) Round() int { return e.round }
+
+func (e *Encounter) Combatants() []*Combatant { return e.combatants }
+
+func (e *Encounter) checkRunning() error {
+	if e.status != StatusRunning {
+		return ErrEncounterEnded
+	}
+	return nil
+}
+
+func (e *Encounter) runningCombatant(characterId id.CharacterId) (*Combatant, error) {
+	if err := e.checkRunning(); err != nil {
+		return nil, err
+	}
+	idx := e.indexOf(characterId)
+	if idx < 0 {
+		return nil, ErrCombatantNotFound
+	}
+	return e.combatants[idx], nil
+}
+
+func (e *Encounter) indexOf(characterId id.CharacterId) int {
+	return slices.IndexFunc(e.combatants, func(c *Combatant) bool {
+		return c.characterId == characterId
+	})
+}
+
+func (e *Encounter) record(kind ActionKind, characterId *id.CharacterId, detail map[string]any, authorId id.PlayerId) {
+	var charId *id.CharacterId
+	if characterId != nil {
+		cid := *characterId
+		charId = &cid
+	}
+	e.actions = append(e.actions, Action{
+		round:       e.round,
+		kind:        kind,
+		characterId: charId,
+		detail:      detail,
+		authorId:    authorId,
+		createdAt:   time.Now(),
+	})
+}
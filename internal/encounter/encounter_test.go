@@ -0,0 +1,197 @@
+package encounter
+
+import (
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"beldur/pkg/dice"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const masterId = id.PlayerId(1)
+
+// newTestEncounter builds a running encounter with the combatants already in order of turn
+func newTestEncounter(characterIds ...id.CharacterId) *Encounter {
+	combatants := make([]*Combatant, len(characterIds))
+	for i, cid := range characterIds {
+		combatants[i] = &Combatant{
+			characterId:  cid,
+			initiative:   20 - i,
+			hitPoints:    10,
+			maxHitPoints: 10,
+			conditions:   make([]Condition, 0),
+		}
+	}
+	return &Encounter{status: StatusRunning, round: 1, combatants: combatants}
+}
+
+func order(e *Encounter) []id.CharacterId {
+	ids := make([]id.CharacterId, len(e.combatants))
+	for i, c := range e.combatants {
+		ids[i] = c.characterId
+	}
+	return ids
+}
+
+func TestNew(t *testing.T) {
+	goblin := character.New("Goblin", "", character.WithAbilities(character.NewAbilities(8, 14, 10, 10, 8, 8)), character.WithHitPoints(7))
+
+	e, err := New("Ambush", 0, []*character.Character{goblin}, dice.NewSeededRoller(1), masterId)
+	require.NoError(t, err)
+	require.Len(t, e.combatants, 1)
+
+	c := e.combatants[0]
+	assert.Equal(t, 2, c.dexModifier)
+	assert.GreaterOrEqual(t, c.initiative, 3)
+	assert.LessOrEqual(t, c.initiative, 22)
+	assert.Equal(t, 7, c.hitPoints)
+	assert.Equal(t, 1, e.round)
+	require.Len(t, e.actions, 1)
+	assert.Equal(t, ActionStart, e.actions[0].kind)
+
+	_, err = New("Ambush", 0, nil, dice.NewSeededRoller(1), masterId)
+	assert.ErrorIs(t, err, ErrNoCombatants)
+
+	_, err = New("Ambush", 0, []*character.Character{goblin, goblin}, dice.NewSeededRoller(1), masterId)
+	assert.ErrorIs(t, err, ErrDuplicateCombatant)
+
+	_, err = New("Ambush", 5, []*character.Character{goblin}, dice.NewSeededRoller(1), masterId)
+	assert.ErrorIs(t, err, ErrCharacterNotInCampaign)
+
+	dead := character.New("Skeleton", "")
+	require.NoError(t, dead.Kill())
+	_, err = New("Ambush", 0, []*character.Character{dead}, dice.NewSeededRoller(1), masterId)
+	assert.ErrorIs(t, err, ErrCharacterNotActive)
+}
+
+func TestNextTurn(t *testing.T) {
+	e := newTestEncounter(1, 2, 3)
+
+	require.NoError(t, e.NextTurn(masterId))
+	assert.Equal(t, id.CharacterId(2), e.Active().characterId)
+	require.NoError(t, e.NextTurn(masterId))
+	require.NoError(t, e.NextTurn(masterId))
+	assert.Equal(t, id.CharacterId(1), e.Active().characterId)
+	assert.Equal(t, 2, e.round)
+	assert.Len(t, e.actions, 3)
+
+	// the characters of all the combatants were deleted
+	empty := newTestEncounter()
+	assert.ErrorIs(t, empty.NextTurn(masterId), ErrNoCombatants)
+}
+
+func TestResumeTurn(t *testing.T) {
+	tests := []struct {
+		name      string
+		positions []int
+		turn      int
+		wantTurn  int
+		wantRound int
+	}{
+		{"nothing deleted", []int{0, 1, 2}, 1, 1, 1},
+		{"one before the active one deleted", []int{1, 2}, 1, 0, 1},
+		{"active one deleted", []int{0, 2}, 1, 1, 1},
+		{"last and active one deleted", []int{0, 1}, 2, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := make([]id.CharacterId, len(tt.positions))
+			for i, p := range tt.positions {
+				ids[i] = id.CharacterId(p + 1)
+			}
+			e := newTestEncounter(ids...)
+			e.turn = tt.turn
+
+			e.resumeTurn(tt.positions)
+			assert.Equal(t, tt.wantTurn, e.turn)
+			assert.Equal(t, tt.wantRound, e.round)
+		})
+	}
+}
+
+func TestDelay(t *testing.T) {
+	t.Run("after the next one", func(t *testing.T) {
+		e := newTestEncounter(1, 2, 3)
+		require.NoError(t, e.Delay(nil, masterId))
+		assert.Equal(t, []id.CharacterId{2, 1, 3}, order(e))
+		assert.Equal(t, id.CharacterId(2), e.Active().characterId)
+		assert.Equal(t, 19, e.combatants[1].initiative)
+	})
+
+	t.Run("after a chosen one", func(t *testing.T) {
+		e := newTestEncounter(1, 2, 3, 4)
+		after := id.CharacterId(3)
+		require.NoError(t, e.Delay(&after, masterId))
+		assert.Equal(t, []id.CharacterId{2, 3, 1, 4}, order(e))
+	})
+
+	t.Run("after one that already acted", func(t *testing.T) {
+		e := newTestEncounter(1, 2, 3)
+		require.NoError(t, e.NextTurn(masterId))
+		after := id.CharacterId(1)
+		assert.ErrorIs(t, e.Delay(&after, masterId), ErrCannotDelay)
+	})
+
+	t.Run("last of the round", func(t *testing.T) {
+		e := newTestEncounter(1, 2)
+		require.NoError(t, e.NextTurn(masterId))
+		assert.ErrorIs(t, e.Delay(nil, masterId), ErrCannotDelay)
+	})
+}
+
+func TestRemove(t *testing.T) {
+	t.Run("before the active one", func(t *testing.T) {
+		e := newTestEncounter(1, 2, 3)
+		require.NoError(t, e.NextTurn(masterId))
+		require.NoError(t, e.Remove(1, masterId))
+		assert.Equal(t, id.CharacterId(2), e.Active().characterId)
+	})
+
+	t.Run("the active one", func(t *testing.T) {
+		e := newTestEncounter(1, 2, 3)
+		require.NoError(t, e.Remove(1, masterId))
+		assert.Equal(t, id.CharacterId(2), e.Active().characterId)
+		assert.Equal(t, 1, e.round)
+	})
+
+	t.Run("the active one, last of the round", func(t *testing.T) {
+		e := newTestEncounter(1, 2)
+		require.NoError(t, e.NextTurn(masterId))
+		require.NoError(t, e.Remove(2, masterId))
+		assert.Equal(t, id.CharacterId(1), e.Active().characterId)
+		assert.Equal(t, 2, e.round)
+	})
+
+	t.Run("the last combatant ends the encounter", func(t *testing.T) {
+		e := newTestEncounter(1)
+		require.NoError(t, e.Remove(1, masterId))
+		assert.Equal(t, StatusEnded, e.status)
+		assert.ErrorIs(t, e.NextTurn(masterId), ErrEncounterEnded)
+	})
+
+	t.Run("unknown combatant", func(t *testing.T) {
+		e := newTestEncounter(1)
+		assert.ErrorIs(t, e.Remove(9, masterId), ErrCombatantNotFound)
+	})
+}
+
+func TestChangeHitPointsAndConditions(t *testing.T) {
+	e := newTestEncounter(1)
+
+	require.NoError(t, e.ChangeHitPoints(1, -15, masterId))
+	assert.Equal(t, 0, e.combatants[0].hitPoints)
+	require.NoError(t, e.ChangeHitPoints(1, 25, masterId))
+	assert.Equal(t, 10, e.combatants[0].hitPoints)
+
+	require.NoError(t, e.AddCondition(1, ConditionProne, masterId))
+	require.NoError(t, e.AddCondition(1, ConditionProne, masterId))
+	assert.Equal(t, []Condition{ConditionProne}, e.combatants[0].conditions)
+	require.NoError(t, e.RemoveCondition(1, ConditionProne, masterId))
+	assert.Empty(t, e.combatants[0].conditions)
+	assert.ErrorIs(t, e.AddCondition(1, "sleepy", masterId), ErrUnknownCondition)
+
+	// two hit point changes, one condition added and removed
+	assert.Len(t, e.actions, 4)
+}
@@ -0,0 +1,124 @@
+package encounter
+
+import (
+	"beldur/pkg/httperr"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidEncounterName   = errors.New("invalid encounter name")
+	ErrNoCombatants           = errors.New("encounter has no combatants")
+	ErrTooManyCombatants      = errors.New("too many combatants")
+	ErrDuplicateCombatant     = errors.New("character is already a combatant")
+	ErrCharacterNotInCampaign = errors.New("character is not in the campaign of the encounter")
+	ErrCharacterNotActive     = errors.New("dead or retired characters cannot fight")
+	ErrCombatantNotFound      = errors.New("combatant not found")
+	ErrCannotDelay            = errors.New("active combatant cannot delay after the chosen combatant")
+	ErrUnknownCondition       = errors.New("unknown condition")
+	ErrEncounterEnded         = errors.New("encounter is ended")
+)
+
+var (
+	ErrEncounterNotFound        = errors.New("encounter not found")
+	ErrCharacterNotFound        = errors.New("character not found")
+	ErrCampaignNotFound         = errors.New("campaign not found")
+	ErrCampaignHasAnotherMaster = errors.New("campaign has another master")
+	ErrPlayerNotInCampaign      = errors.New("player is not in the campaign")
+)
+
+func NewEncounterApiErrorManager() *httperr.Manager {
+	mng := httperr.NewManager()
+
+	mng.Add(ErrInvalidEncounterName, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_encounter_name",
+		Message: ErrInvalidEncounterName.Error(),
+	})
+
+	mng.Add(ErrNoCombatants, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "no_combatants",
+		Message: ErrNoCombatants.Error(),
+	})
+
+	mng.Add(ErrTooManyCombatants, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "too_many_combatants",
+		Message: ErrTooManyCombatants.Error(),
+	})
+
+	mng.Add(ErrDuplicateCombatant, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "duplicate_combatant",
+		Message: ErrDuplicateCombatant.Error(),
+	})
+
+	mng.Add(ErrCharacterNotInCampaign, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "character_not_in_campaign",
+		Message: ErrCharacterNotInCampaign.Error(),
+	})
+
+	mng.Add(ErrCharacterNotActive, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "character_not_active",
+		Message: ErrCharacterNotActive.Error(),
+	})
+
+	mng.Add(ErrCombatantNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "combatant_not_found",
+		Message: ErrCombatantNotFound.Error(),
+	})
+
+	mng.Add(ErrCannotDelay, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "cannot_delay",
+		Message: ErrCannotDelay.Error(),
+	})
+
+	mng.Add(ErrUnknownCondition, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "unknown_condition",
+		Message: ErrUnknownCondition.Error(),
+	})
+
+	mng.Add(ErrEncounterEnded, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "encounter_ended",
+		Message: ErrEncounterEnded.Error(),
+	})
+
+	mng.Add(ErrEncounterNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "encounter_not_found",
+		Message: ErrEncounterNotFound.Error(),
+	})
+
+	mng.Add(ErrCharacterNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "character_not_found",
+		Message: ErrCharacterNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "campaign_not_found",
+		Message: ErrCampaignNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignHasAnotherMaster, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "campaign_has_another_master",
+		Message: ErrCampaignHasAnotherMaster.Error(),
+	})
+
+	mng.Add(ErrPlayerNotInCampaign, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "player_not_in_campaign",
+		Message: ErrPlayerNotInCampaign.Error(),
+	})
+
+	return mng
+}
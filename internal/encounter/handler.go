@@ -0,0 +1,235 @@
+package encounter
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/httperr"
+	"beldur/pkg/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpHandler struct {
+	encounterUC *UseCase
+	errManager  *httperr.Manager
+}
+
+func NewHttpHandler(encounterUC *UseCase) *HttpHandler {
+	return &HttpHandler{
+		encounterUC: encounterUC,
+		errManager:  NewEncounterApiErrorManager(),
+	}
+}
+
+func (h *HttpHandler) HandleCreateEncounter(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(CreateEncounterRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.encounterUC.Create(c.Context(), req, id.CampaignId(campaignId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetEncounters(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.encounterUC.List(c.Context(), id.CampaignId(campaignId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetEncounter(c *fiber.Ctx) error {
+	encounterId, err := encounterIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.encounterUC.Get(c.Context(), encounterId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetActions(c *fiber.Ctx) error {
+	encounterId, err := encounterIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.encounterUC.Actions(c.Context(), encounterId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleNextTurn(c *fiber.Ctx) error {
+	encounterId, err := encounterIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.encounterUC.NextTurn(c.Context(), encounterId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleDelay(c *fiber.Ctx) error {
+	encounterId, err := encounterIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(DelayRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.encounterUC.Delay(c.Context(), req, encounterId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleEnd(c *fiber.Ctx) error {
+	encounterId, err := encounterIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.encounterUC.End(c.Context(), encounterId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleUpdateCombatant(c *fiber.Ctx) error {
+	encounterId, characterId, err := combatantParams(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(UpdateCombatantRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.encounterUC.UpdateCombatant(c.Context(), req, encounterId, characterId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleRemoveCombatant(c *fiber.Ctx) error {
+	encounterId, characterId, err := combatantParams(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.encounterUC.RemoveCombatant(c.Context(), encounterId, characterId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func encounterIdParam(c *fiber.Ctx) (id.EncounterId, error) {
+	encounterInstr := c.Params("encounterId")
+	if encounterInstr == "" {
+		panic("wrong parameter naming")
+	}
+	encounterId, err := strconv.Atoi(encounterInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.EncounterId(encounterId), nil
+}
+
+func combatantParams(c *fiber.Ctx) (id.EncounterId, id.CharacterId, error) {
+	encounterId, err := encounterIdParam(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	charInstr := c.Params("characterId")
+	if charInstr == "" {
+		panic("wrong parameter naming")
+	}
+	charId, err := strconv.Atoi(charInstr)
+	if err != nil {
+		return 0, 0, err
+	}
+	return encounterId, id.CharacterId(charId), nil
+}
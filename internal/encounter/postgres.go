@@ -0,0 +1,355 @@
+package encounter
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresRepository struct {
+	q postgres.QuerierProvider
+}
+
+func NewPostgresRepository(q postgres.QuerierProvider) *PostgresRepository {
+	return &PostgresRepository{q: q}
+}
+
+const selectEncounter = `
+	SELECT
+	    encounter_id,
+	    campaign_id,
+	    name,
+	    status,
+	    round,
+	    turn,
+	    created_at,
+	    ended_at
+	FROM encounters
+`
+
+// Save inserts the encounter with its combatants and the actions recorded so far.
+// It should be called inside a transaction.
+func (p *PostgresRepository) Save(ctx context.Context, e *Encounter) error {
+	const query = `
+		INSERT INTO encounters (campaign_id, name, status, round, turn, created_at, ended_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING encounter_id
+	`
+
+	var encounterID int
+	if err := p.q(ctx).QueryRow(ctx, query,
+		int(e.campaignId),
+		e.name,
+		string(e.status),
+		e.round,
+		e.turn,
+		e.createdAt,
+		e.endedAt,
+	).Scan(&encounterID); err != nil {
+		return err
+	}
+	e.id = id.EncounterId(encounterID)
+
+	if err := p.saveCombatants(ctx, e); err != nil {
+		return err
+	}
+	return p.saveActions(ctx, e)
+}
+
+// Update persists the state of the encounter and appends the actions recorded since it was loaded.
+// It should be called inside a transaction.
+func (p *PostgresRepository) Update(ctx context.Context, e *Encounter) error {
+	const query = `
+		UPDATE encounters
+		SET status = $1,
+		    round = $2,
+		    turn = $3,
+		    ended_at = $4
+		WHERE encounter_id = $5
+	`
+
+	cmd, err := p.q(ctx).Exec(ctx, query, string(e.status), e.round, e.turn, e.endedAt, int(e.id))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+
+	// combatants are replaced as a whole, the order may have changed
+	if _, err := p.q(ctx).Exec(ctx, `DELETE FROM encounter_combatants WHERE encounter_id = $1`, int(e.id)); err != nil {
+		return err
+	}
+	if err := p.saveCombatants(ctx, e); err != nil {
+		return err
+	}
+	return p.saveActions(ctx, e)
+}
+
+func (p *PostgresRepository) saveCombatants(ctx context.Context, e *Encounter) error {
+	const query = `
+		INSERT INTO encounter_combatants
+		    (encounter_id, character_id, position, name, is_npc, initiative, dex_modifier,
+		     hit_points, max_hit_points, conditions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	for i, c := range e.combatants {
+		conditions := make([]string, len(c.conditions))
+		for j, cond := range c.conditions {
+			conditions[j] = string(cond)
+		}
+		if _, err := p.q(ctx).Exec(ctx, query,
+			int(e.id),
+			int(c.characterId),
+			i,
+			c.name,
+			c.npc,
+			c.initiative,
+			c.dexModifier,
+			c.hitPoints,
+			c.maxHitPoints,
+			conditions,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveActions inserts the recorded actions and clears them from the encounter
+func (p *PostgresRepository) saveActions(ctx context.Context, e *Encounter) error {
+	const query = `
+		INSERT INTO encounter_actions (encounter_id, round, kind, character_id, detail, author_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	for _, a := range e.actions {
+		detailJSON, err := json.Marshal(a.detail)
+		if err != nil {
+			return err
+		}
+		var characterID *int
+		if a.characterId != nil {
+			cid := int(*a.characterId)
+			characterID = &cid
+		}
+		if _, err := p.q(ctx).Exec(ctx, query,
+			int(e.id),
+			a.round,
+			string(a.kind),
+			characterID,
+			detailJSON,
+			int(a.authorId),
+			a.createdAt,
+		); err != nil {
+			return err
+		}
+	}
+	e.actions = nil
+	return nil
+}
+
+func (p *PostgresRepository) FindById(ctx context.Context, encounterId id.EncounterId) (*Encounter, error) {
+	const query = selectEncounter + `WHERE encounter_id = $1`
+
+	e, err := p.scanEncounter(p.q(ctx).QueryRow(ctx, query, int(encounterId)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+
+	combatants, positions, err := p.findCombatants(ctx, encounterId)
+	if err != nil {
+		return nil, err
+	}
+	e.combatants = combatants
+	// a deleted NPC takes its combatant with it
+	e.resumeTurn(positions)
+	return e, nil
+}
+
+// FindByCampaign gives back the encounters of the campaign, the most recent first.
+// Combatants are not loaded.
+func (p *PostgresRepository) FindByCampaign(ctx context.Context, campaignId id.CampaignId) ([]*Encounter, error) {
+	const query = selectEncounter + `
+		WHERE campaign_id = $1
+		ORDER BY created_at DESC, encounter_id DESC
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(campaignId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	encounters := make([]*Encounter, 0)
+	for rows.Next() {
+		e, err := p.scanEncounter(rows)
+		if err != nil {
+			return nil, err
+		}
+		encounters = append(encounters, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return encounters, nil
+}
+
+func (p *PostgresRepository) scanEncounter(row pgx.Row) (*Encounter, error) {
+	var (
+		encounterID int
+		campaignID  int
+		name        string
+		status      Status
+		round       int
+		turn        int
+		createdAt   time.Time
+		endedAt     *time.Time
+	)
+
+	if err := row.Scan(&encounterID, &campaignID, &name, &status, &round, &turn, &createdAt, &endedAt); err != nil {
+		return nil, err
+	}
+
+	return &Encounter{
+		id:         id.EncounterId(encounterID),
+		campaignId: id.CampaignId(campaignID),
+		name:       name,
+		status:     status,
+		round:      round,
+		turn:       turn,
+		combatants: make([]*Combatant, 0),
+		createdAt:  createdAt,
+		endedAt:    endedAt,
+	}, nil
+}
+
+// findCombatants gives back the combatants in order of turn, along with their saved positions
+func (p *PostgresRepository) findCombatants(ctx context.Context, encounterId id.EncounterId) ([]*Combatant, []int, error) {
+	const query = `
+		SELECT character_id, position, name, is_npc, initiative, dex_modifier, hit_points, max_hit_points, conditions
+		FROM encounter_combatants
+		WHERE encounter_id = $1
+		ORDER BY position
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(encounterId))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	combatants := make([]*Combatant, 0)
+	positions := make([]int, 0)
+	for rows.Next() {
+		var (
+			characterID int
+			position    int
+			conditions  []string
+		)
+		c := &Combatant{}
+		if err := rows.Scan(
+			&characterID,
+			&position,
+			&c.name,
+			&c.npc,
+			&c.initiative,
+			&c.dexModifier,
+			&c.hitPoints,
+			&c.maxHitPoints,
+			&conditions,
+		); err != nil {
+			return nil, nil, err
+		}
+		c.characterId = id.CharacterId(characterID)
+		c.conditions = make([]Condition, len(conditions))
+		for i, cond := range conditions {
+			c.conditions[i] = Condition(cond)
+		}
+		combatants = append(combatants, c)
+		positions = append(positions, position)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return combatants, positions, nil
+}
+
+// FindActions gives back the history of the encounter, oldest first
+func (p *PostgresRepository) FindActions(ctx context.Context, encounterId id.EncounterId) ([]Action, error) {
+	const query = `
+		SELECT action_id, round, kind, character_id, detail, author_id, created_at
+		FROM encounter_actions
+		WHERE encounter_id = $1
+		ORDER BY action_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(encounterId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := make([]Action, 0)
+	for rows.Next() {
+		var (
+			a           Action
+			characterID *int
+			detailJSON  []byte
+			authorID    int
+		)
+		if err := rows.Scan(&a.id, &a.round, &a.kind, &characterID, &detailJSON, &authorID, &a.createdAt); err != nil {
+			return nil, err
+		}
+		if characterID != nil {
+			cid := id.CharacterId(*characterID)
+			a.characterId = &cid
+		}
+		if err := json.Unmarshal(detailJSON, &a.detail); err != nil {
+			return nil, err
+		}
+		a.authorId = id.PlayerId(authorID)
+		actions = append(actions, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return actions, nil
+}
+
+func (p *PostgresRepository) FindExportActions(ctx context.Context, playerId id.PlayerId) ([]ExportedAction, error) {
+	const query = `
+		SELECT ea.action_id, ea.encounter_id, e.campaign_id, ea.round, ea.kind, ea.character_id, ea.detail, ea.created_at
+		FROM encounter_actions ea
+		JOIN encounters e ON e.encounter_id = ea.encounter_id
+		WHERE ea.author_id = $1
+		ORDER BY ea.action_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := make([]ExportedAction, 0)
+	for rows.Next() {
+		var ac ExportedAction
+		err := rows.Scan(&ac.ActionId, &ac.EncounterId, &ac.CampaignId, &ac.Round, &ac.Kind, &ac.CharacterId,
+			&ac.Detail, &ac.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, ac)
+	}
+	return actions, rows.Err()
+}
@@ -0,0 +1,32 @@
+package encounter
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"context"
+)
+
+type Saver interface {
+	Save(ctx context.Context, encounter *Encounter) error
+	Update(ctx context.Context, encounter *Encounter) error
+}
+
+type Finder interface {
+	FindById(ctx context.Context, encounterId id.EncounterId) (*Encounter, error)
+	FindByCampaign(ctx context.Context, campaignId id.CampaignId) ([]*Encounter, error)
+	FindActions(ctx context.Context, encounterId id.EncounterId) ([]Action, error)
+}
+
+type CampaignFinder interface {
+	FindById(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error)
+}
+
+type CharacterFinder interface {
+	FindById(ctx context.Context, characterId id.CharacterId) (*character.Character, error)
+}
+
+// Exporter reads the encounter actions of a player for the export of its account
+type Exporter interface {
+	FindExportActions(ctx context.Context, playerId id.PlayerId) ([]ExportedAction, error)
+}
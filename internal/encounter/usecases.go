@@ -0,0 +1,324 @@
+package encounter
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dice"
+	"beldur/pkg/dto"
+	"beldur/pkg/logger"
+	"context"
+	"errors"
+)
+
+type UseCase struct {
+	encSaver        Saver
+	encFinder       Finder
+	campaignFinder  CampaignFinder
+	characterFinder CharacterFinder
+	roller          dice.Roller
+	tx              tx.Transactor
+}
+
+func NewUseCase(
+	encounterSaver Saver,
+	encounterFinder Finder,
+	campaignFinder CampaignFinder,
+	characterFinder CharacterFinder,
+	roller dice.Roller,
+	tx tx.Transactor,
+) *UseCase {
+	return &UseCase{
+		encSaver:        encounterSaver,
+		encFinder:       encounterFinder,
+		campaignFinder:  campaignFinder,
+		characterFinder: characterFinder,
+		roller:          roller,
+		tx:              tx,
+	}
+}
+
+// Create starts an encounter with the characters of the campaign and rolls their initiative.
+// Only the master of the campaign can do it.
+func (uc *UseCase) Create(ctx context.Context, req CreateEncounterRequest, campaignId id.CampaignId, masterId id.PlayerId) (EncounterResponse, error) {
+	var resp EncounterResponse
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		camp, err := uc.findCampaign(ctx, campaignId)
+		if err != nil {
+			return err
+		}
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+
+		participants := make([]*character.Character, len(req.CharacterIds))
+		for i, charId := range req.CharacterIds {
+			ch, err := uc.characterFinder.FindById(ctx, id.CharacterId(charId))
+			if err != nil {
+				if errors.Is(err, postgres.ErrNoRowFound) {
+					return ErrCharacterNotFound
+				}
+				return err
+			}
+			participants[i] = ch
+		}
+
+		e, err := New(req.Name, campaignId, participants, uc.roller, masterId)
+		if err != nil {
+			return err
+		}
+		if err := uc.encSaver.Save(ctx, e); err != nil {
+			logger.Debug("failed to save encounter", "campaign_id", campaignId, "error", err)
+			return err
+		}
+		resp = toEncounterResponse(e, true)
+		return nil
+	})
+	if err != nil {
+		return EncounterResponse{}, err
+	}
+	return resp, nil
+}
+
+// List gives back the encounters of the campaign. Every player of the campaign can read them.
+func (uc *UseCase) List(ctx context.Context, campaignId id.CampaignId, playerId id.PlayerId) (dto.ListResponse[EncounterSummaryResponse], error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return dto.ListResponse[EncounterSummaryResponse]{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return dto.ListResponse[EncounterSummaryResponse]{}, ErrPlayerNotInCampaign
+	}
+
+	encounters, err := uc.encFinder.FindByCampaign(ctx, campaignId)
+	if err != nil {
+		logger.Debug("failed to find encounters", "campaign_id", campaignId, "error", err)
+		return dto.ListResponse[EncounterSummaryResponse]{}, err
+	}
+
+	list := make([]EncounterSummaryResponse, len(encounters))
+	for i, e := range encounters {
+		list[i] = EncounterSummaryResponse{
+			Id:        int(e.id),
+			Name:      e.name,
+			Status:    e.status,
+			Round:     e.round,
+			CreatedAt: e.createdAt,
+			EndedAt:   e.endedAt,
+		}
+	}
+	return dto.ListResponse[EncounterSummaryResponse]{Data: list}, nil
+}
+
+// Get gives back the state of the encounter. Every player of the campaign can read it,
+// but only the master sees the hit points of the NPCs.
+func (uc *UseCase) Get(ctx context.Context, encounterId id.EncounterId, playerId id.PlayerId) (EncounterResponse, error) {
+	e, camp, err := uc.findEncounter(ctx, encounterId)
+	if err != nil {
+		return EncounterResponse{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return EncounterResponse{}, ErrPlayerNotInCampaign
+	}
+	return toEncounterResponse(e, camp.IsMaster(playerId)), nil
+}
+
+// Actions gives back the history of the encounter, oldest first. Every player of the campaign
+// can read it, but only the master sees the hit points of the NPCs.
+func (uc *UseCase) Actions(ctx context.Context, encounterId id.EncounterId, playerId id.PlayerId) (dto.ListResponse[ActionResponse], error) {
+	_, camp, err := uc.findEncounter(ctx, encounterId)
+	if err != nil {
+		return dto.ListResponse[ActionResponse]{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return dto.ListResponse[ActionResponse]{}, ErrPlayerNotInCampaign
+	}
+	isMaster := camp.IsMaster(playerId)
+
+	actions, err := uc.encFinder.FindActions(ctx, encounterId)
+	if err != nil {
+		logger.Debug("failed to find encounter actions", "encounter_id", encounterId, "error", err)
+		return dto.ListResponse[ActionResponse]{}, err
+	}
+
+	list := make([]ActionResponse, len(actions))
+	for i, a := range actions {
+		detail := a.detail
+		if npc, _ := detail["npc"].(bool); npc && !isMaster && a.kind == ActionHitPoints {
+			detail = map[string]any{"name": detail["name"], "npc": true}
+		}
+
+		var characterID *int
+		if a.characterId != nil {
+			cid := int(*a.characterId)
+			characterID = &cid
+		}
+		list[i] = ActionResponse{
+			Id:          a.id,
+			Round:       a.round,
+			Kind:        a.kind,
+			CharacterId: characterID,
+			Detail:      detail,
+			AuthorId:    int(a.authorId),
+			CreatedAt:   a.createdAt,
+		}
+	}
+	return dto.ListResponse[ActionResponse]{Data: list}, nil
+}
+
+// NextTurn passes the turn to the next combatant. Only the master of the campaign can do it.
+func (uc *UseCase) NextTurn(ctx context.Context, encounterId id.EncounterId, masterId id.PlayerId) (EncounterResponse, error) {
+	return uc.modify(ctx, encounterId, masterId, func(e *Encounter) error {
+		return e.NextTurn(masterId)
+	})
+}
+
+// Delay moves the active combatant later in the order. Only the master of the campaign can do it.
+func (uc *UseCase) Delay(ctx context.Context, req DelayRequest, encounterId id.EncounterId, masterId id.PlayerId) (EncounterResponse, error) {
+	return uc.modify(ctx, encounterId, masterId, func(e *Encounter) error {
+		var after *id.CharacterId
+		if req.AfterCharacterId != nil {
+			cid := id.CharacterId(*req.AfterCharacterId)
+			after = &cid
+		}
+		return e.Delay(after, masterId)
+	})
+}
+
+// RemoveCombatant takes a combatant out of the encounter. Only the master of the campaign can do it.
+func (uc *UseCase) RemoveCombatant(ctx context.Context, encounterId id.EncounterId, characterId id.CharacterId, masterId id.PlayerId) (EncounterResponse, error) {
+	return uc.modify(ctx, encounterId, masterId, func(e *Encounter) error {
+		return e.Remove(characterId, masterId)
+	})
+}
+
+// UpdateCombatant changes hit points and conditions of a combatant. Only the master of the campaign can do it.
+func (uc *UseCase) UpdateCombatant(
+	ctx context.Context,
+	req UpdateCombatantRequest,
+	encounterId id.EncounterId,
+	characterId id.CharacterId,
+	masterId id.PlayerId,
+) (EncounterResponse, error) {
+	return uc.modify(ctx, encounterId, masterId, func(e *Encounter) error {
+		if req.HitPointsDelta != nil {
+			if err := e.ChangeHitPoints(characterId, *req.HitPointsDelta, masterId); err != nil {
+				return err
+			}
+		}
+		for _, cond := range req.AddConditions {
+			if err := e.AddCondition(characterId, cond, masterId); err != nil {
+				return err
+			}
+		}
+		for _, cond := range req.RemoveConditions {
+			if err := e.RemoveCondition(characterId, cond, masterId); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// End closes the encounter. Only the master of the campaign can do it.
+func (uc *UseCase) End(ctx context.Context, encounterId id.EncounterId, masterId id.PlayerId) (EncounterResponse, error) {
+	return uc.modify(ctx, encounterId, masterId, func(e *Encounter) error {
+		return e.End(masterId)
+	})
+}
+
+// modify loads the encounter, checks that the player is the master of its campaign,
+// applies the change and persists it.
+func (uc *UseCase) modify(ctx context.Context, encounterId id.EncounterId, masterId id.PlayerId, change func(*Encounter) error) (EncounterResponse, error) {
+	var resp EncounterResponse
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		e, camp, err := uc.findEncounter(ctx, encounterId)
+		if err != nil {
+			return err
+		}
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+
+		if err := change(e); err != nil {
+			return err
+		}
+		if err := uc.encSaver.Update(ctx, e); err != nil {
+			logger.Debug("failed to update encounter", "encounter_id", encounterId, "error", err)
+			return err
+		}
+		resp = toEncounterResponse(e, true)
+		return nil
+	})
+	if err != nil {
+		return EncounterResponse{}, err
+	}
+	return resp, nil
+}
+
+func (uc *UseCase) findEncounter(ctx context.Context, encounterId id.EncounterId) (*Encounter, *campaign.Campaign, error) {
+	e, err := uc.encFinder.FindById(ctx, encounterId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, nil, ErrEncounterNotFound
+		}
+		logger.Debug("failed to find encounter", "encounter_id", encounterId, "error", err)
+		return nil, nil, err
+	}
+
+	camp, err := uc.findCampaign(ctx, e.campaignId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, camp, nil
+}
+
+func (uc *UseCase) findCampaign(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		logger.Debug("no campaign found", "campaign_id", campaignId)
+		return nil, errors.Join(ErrCampaignNotFound, err)
+	}
+	return camp, nil
+}
+
+func toEncounterResponse(e *Encounter, isMaster bool) EncounterResponse {
+	combatants := make([]CombatantResponse, len(e.combatants))
+	for i, c := range e.combatants {
+		resp := CombatantResponse{
+			CharacterId: int(c.characterId),
+			Name:        c.name,
+			NPC:         c.npc,
+			Initiative:  c.initiative,
+			Conditions:  c.conditions,
+		}
+		if isMaster || !c.npc {
+			hp, maxHp := c.hitPoints, c.maxHitPoints
+			resp.HitPoints = &hp
+			resp.MaxHitPoints = &maxHp
+		}
+		combatants[i] = resp
+	}
+
+	var activeID *int
+	if active := e.Active(); active != nil && e.status == StatusRunning {
+		aid := int(active.characterId)
+		activeID = &aid
+	}
+
+	return EncounterResponse{
+		Id:                int(e.id),
+		CampaignId:        int(e.campaignId),
+		Name:              e.name,
+		Status:            e.status,
+		Round:             e.round,
+		ActiveCharacterId: activeID,
+		Combatants:        combatants,
+		CreatedAt:         e.createdAt,
+		EndedAt:           e.endedAt,
+	}
+}
@@ -0,0 +1,23 @@
+package encounter
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dice"
+)
+
+type Deps struct {
+	QProvider  postgres.QuerierProvider
+	Transactor tx.Transactor
+}
+
+func NewHandlerFromDeps(deps Deps) *HttpHandler {
+	encounterRepo := NewPostgresRepository(deps.QProvider)
+	campaignRepo := campaign.NewPostgresRepository(deps.QProvider)
+	characterRepo := character.NewPostgresRepository(deps.QProvider)
+
+	encounterUC := NewUseCase(encounterRepo, encounterRepo, campaignRepo, characterRepo, dice.NewRoller(), deps.Transactor)
+	return NewHttpHandler(encounterUC)
+}
@@ -0,0 +1,62 @@
+package handout
+
+import (
+	"io"
+	"time"
+)
+
+// CreateHandoutRequest is sent as multipart form, along with the file in the "file" field
+type CreateHandoutRequest struct {
+	Title       string `json:"title" form:"title" validate:"required,max=100"`
+	Description string `json:"description" form:"description" validate:"max=2000"`
+}
+
+// RevealRequest reveals the handout to all the players, or to the listed ones only
+type RevealRequest struct {
+	Everyone  bool  `json:"everyone"`
+	PlayerIds []int `json:"player_ids" validate:"required_without=Everyone,excluded_with=Everyone,max=50"`
+}
+
+// Upload is a file received from the client
+type Upload struct {
+	Name string
+	Data []byte
+}
+
+type HandoutResponse struct {
+	Id          int        `json:"id"`
+	CampaignId  int        `json:"campaign_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	FileName    string     `json:"file_name"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	Visibility  Visibility `json:"visibility"`
+	// only the master sees the recipients
+	RecipientIds []int      `json:"recipient_ids,omitempty"`
+	DownloadUrl  string     `json:"download_url"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RevealedAt   *time.Time `json:"revealed_at"`
+}
+
+// Download is the content of a handout file, to be closed by the caller
+type Download struct {
+	Name        string
+	ContentType string
+	Size        int64
+	Content     io.ReadCloser
+}
+
+// ExportedHandout describes a handout uploaded to a campaign mastered by the player, without its file
+type ExportedHandout struct {
+	HandoutId   int        `json:"handout_id"`
+	CampaignId  int        `json:"campaign_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	FileName    string     `json:"file_name"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	Visibility  string     `json:"visibility"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RevealedAt  *time.Time `json:"revealed_at"`
+}
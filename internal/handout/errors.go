@@ -0,0 +1,103 @@
+package handout
+
+import (
+	"beldur/pkg/httperr"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidTitle        = errors.New("handout title must be between 1 and 100 characters")
+	ErrInvalidDescription  = errors.New("handout description must be at most 2000 characters")
+	ErrEmptyFile           = errors.New("uploaded file is empty")
+	ErrFileTooLarge        = errors.New("uploaded file must be at most 10 MiB")
+	ErrUnsupportedFileType = errors.New("uploaded file must be a PNG, JPEG, GIF or WebP image, a PDF or a text file")
+	ErrNoRecipients        = errors.New("a handout must be revealed to at least one player")
+)
+
+var (
+	ErrHandoutNotFound          = errors.New("handout not found")
+	ErrFileNotFound             = errors.New("handout file not found")
+	ErrCampaignNotFound         = errors.New("campaign not found")
+	ErrCampaignHasAnotherMaster = errors.New("campaign has another master")
+	ErrPlayerNotInCampaign      = errors.New("player is not in the campaign")
+	ErrRecipientNotInCampaign   = errors.New("handout recipient is not in the campaign")
+)
+
+func NewHandoutApiErrorManager() *httperr.Manager {
+	mng := httperr.NewManager()
+
+	mng.Add(ErrInvalidTitle, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_title",
+		Message: ErrInvalidTitle.Error(),
+	})
+
+	mng.Add(ErrInvalidDescription, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_description",
+		Message: ErrInvalidDescription.Error(),
+	})
+
+	mng.Add(ErrEmptyFile, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "empty_file",
+		Message: ErrEmptyFile.Error(),
+	})
+
+	mng.Add(ErrFileTooLarge, httperr.Mapped{
+		Status:  http.StatusRequestEntityTooLarge,
+		Code:    "file_too_large",
+		Message: ErrFileTooLarge.Error(),
+	})
+
+	mng.Add(ErrUnsupportedFileType, httperr.Mapped{
+		Status:  http.StatusUnsupportedMediaType,
+		Code:    "unsupported_file_type",
+		Message: ErrUnsupportedFileType.Error(),
+	})
+
+	mng.Add(ErrNoRecipients, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "no_recipients",
+		Message: ErrNoRecipients.Error(),
+	})
+
+	mng.Add(ErrHandoutNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "handout_not_found",
+		Message: ErrHandoutNotFound.Error(),
+	})
+
+	mng.Add(ErrFileNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "file_not_found",
+		Message: ErrFileNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "campaign_not_found",
+		Message: ErrCampaignNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignHasAnotherMaster, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "campaign_has_another_master",
+		Message: ErrCampaignHasAnotherMaster.Error(),
+	})
+
+	mng.Add(ErrPlayerNotInCampaign, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "player_not_in_campaign",
+		Message: ErrPlayerNotInCampaign.Error(),
+	})
+
+	mng.Add(ErrRecipientNotInCampaign, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "recipient_not_in_campaign",
+		Message: ErrRecipientNotInCampaign.Error(),
+	})
+
+	return mng
+}
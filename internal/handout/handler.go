@@ -0,0 +1,207 @@
+package handout
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/httperr"
+	"beldur/pkg/middleware"
+	"io"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpHandler struct {
+	handoutUC  *UseCase
+	errManager *httperr.Manager
+}
+
+func NewHttpHandler(handoutUC *UseCase) *HttpHandler {
+	return &HttpHandler{
+		handoutUC:  handoutUC,
+		errManager: NewHandoutApiErrorManager(),
+	}
+}
+
+// HandleUpload reads the multipart form with the title, the description and the file
+func (h *HttpHandler) HandleUpload(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(CreateHandoutRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	if fh.Size > MaxFileSize {
+		status, body := h.errManager.Map(ErrFileTooLarge)
+		return c.Status(status).JSON(body)
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, MaxFileSize+1))
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	upload := Upload{Name: fh.Filename, Data: data}
+	resp, err := h.handoutUC.Upload(c.Context(), req, upload, id.CampaignId(campaignId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetHandouts(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.handoutUC.List(c.Context(), id.CampaignId(campaignId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetHandout(c *fiber.Ctx) error {
+	handoutId, err := handoutIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.handoutUC.Get(c.Context(), handoutId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleReveal(c *fiber.Ctx) error {
+	handoutId, err := handoutIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(RevealRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.handoutUC.Reveal(c.Context(), req, handoutId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleHide(c *fiber.Ctx) error {
+	handoutId, err := handoutIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.handoutUC.Hide(c.Context(), handoutId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleDeleteHandout(c *fiber.Ctx) error {
+	handoutId, err := handoutIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.handoutUC.Delete(c.Context(), handoutId, p.PlayerID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleDownload sends the file as an attachment, with the sniffed content type
+func (h *HttpHandler) HandleDownload(c *fiber.Ctx) error {
+	handoutId, err := handoutIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	d, err := h.handoutUC.Download(c.Context(), handoutId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+
+	// Attachment guesses the type from the extension, the sniffed one replaces it
+	c.Attachment(d.Name)
+	c.Set(fiber.HeaderContentType, d.ContentType)
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	// the stream is closed once sent
+	return c.SendStream(d.Content, int(d.Size))
+}
+
+func handoutIdParam(c *fiber.Ctx) (id.HandoutId, error) {
+	handoutInstr := c.Params("handoutId")
+	if handoutInstr == "" {
+		panic("wrong parameter naming")
+	}
+	handoutId, err := strconv.Atoi(handoutInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.HandoutId(handoutId), nil
+}
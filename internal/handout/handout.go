@@ -0,0 +1,176 @@
+package handout
+
+import (
+	"beldur/internal/id"
+	"crypto/rand"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const (
+	MaxTitleCharacters       = 100
+	MaxDescriptionCharacters = 2000
+	MaxFileNameCharacters    = 200
+	// MaxFileSize is the size limit of an uploaded file, in bytes
+	MaxFileSize = 10 << 20
+)
+
+// allowedContentTypes are matched against the sniffed content of the file, never against
+// the type declared by the client
+var allowedContentTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"text/plain",
+}
+
+type Visibility string
+
+const (
+	// VisibilityHidden handout is known only by the master, until it is revealed
+	VisibilityHidden   Visibility = "HIDDEN"
+	VisibilityEveryone Visibility = "EVERYONE"
+	// VisibilitySelected handout is visible to the recipients chosen by the master
+	VisibilitySelected Visibility = "SELECTED"
+)
+
+// File is an uploaded file, its content lives in the blob store under the key
+type File struct {
+	key         string
+	name        string
+	contentType string
+	size        int64
+}
+
+// NewFile checks the size and the content of an uploaded file and picks its blob key
+func NewFile(campaignId id.CampaignId, name string, data []byte) (File, error) {
+	if len(data) == 0 {
+		return File{}, ErrEmptyFile
+	}
+	if len(data) > MaxFileSize {
+		return File{}, ErrFileTooLarge
+	}
+
+	mt := mimetype.Detect(data)
+	if !slices.ContainsFunc(allowedContentTypes, mt.Is) {
+		return File{}, ErrUnsupportedFileType
+	}
+
+	return File{
+		key:         fmt.Sprintf("campaigns/%d/handouts/%s%s", campaignId, rand.Text(), mt.Extension()),
+		name:        cleanFileName(name, mt.Extension()),
+		contentType: mt.String(),
+		size:        int64(len(data)),
+	}, nil
+}
+
+func (f File) Key() string { return f.key }
+
+// cleanFileName keeps the base name without control characters, so it can be sent back in a header
+func cleanFileName(name, extension string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '"' || r == '\\' {
+			return -1
+		}
+		return r
+	}, path.Base(strings.ReplaceAll(name, "\\", "/")))
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == "/" {
+		name = "handout" + extension
+	}
+	if utf8.RuneCountInString(name) > MaxFileNameCharacters {
+		name = string([]rune(name)[:MaxFileNameCharacters])
+	}
+	return name
+}
+
+// Handout is a file given by the master to the players of the campaign, such as a map,
+// a letter or an image. It stays hidden until the master reveals it.
+type Handout struct {
+	id          id.HandoutId
+	campaignId  id.CampaignId
+	title       string
+	description string
+	file        File
+	visibility  Visibility
+	// players who can see a handout with VisibilitySelected
+	recipients []id.PlayerId
+	createdAt  time.Time
+	revealedAt *time.Time
+}
+
+func New(campaignId id.CampaignId, title, description string, file File) (*Handout, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || utf8.RuneCountInString(title) > MaxTitleCharacters {
+		return nil, ErrInvalidTitle
+	}
+	if utf8.RuneCountInString(description) > MaxDescriptionCharacters {
+		return nil, ErrInvalidDescription
+	}
+
+	return &Handout{
+		campaignId:  campaignId,
+		title:       title,
+		description: description,
+		file:        file,
+		visibility:  VisibilityHidden,
+		recipients:  make([]id.PlayerId, 0),
+		createdAt:   time.Now().UTC(),
+	}, nil
+}
+
+// RevealToEveryone makes the handout visible to all the players of the campaign
+func (h *Handout) RevealToEveryone() {
+	h.visibility = VisibilityEveryone
+	h.recipients = make([]id.PlayerId, 0)
+	h.revealed()
+}
+
+// RevealTo makes the handout visible to the given players only, replacing the previous recipients
+func (h *Handout) RevealTo(players []id.PlayerId) error {
+	if len(players) == 0 {
+		return ErrNoRecipients
+	}
+	recipients := slices.Clone(players)
+	slices.Sort(recipients)
+
+	h.visibility = VisibilitySelected
+	h.recipients = slices.Compact(recipients)
+	h.revealed()
+	return nil
+}
+
+// Hide takes the handout back from the players
+func (h *Handout) Hide() {
+	h.visibility = VisibilityHidden
+	h.recipients = make([]id.PlayerId, 0)
+	h.revealedAt = nil
+}
+
+func (h *Handout) IsVisibleTo(playerId id.PlayerId, isMaster bool) bool {
+	switch {
+	case isMaster:
+		return true
+	case h.visibility == VisibilityEveryone:
+		return true
+	case h.visibility == VisibilitySelected:
+		return slices.Contains(h.recipients, playerId)
+	default:
+		return false
+	}
+}
+
+func (h *Handout) revealed() {
+	now := time.Now().UTC()
+	h.revealedAt = &now
+}
@@ -0,0 +1,77 @@
+package handout
+
+import (
+	"beldur/internal/id"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+
+func TestNewFile(t *testing.T) {
+	tests := []struct {
+		name            string
+		fileName        string
+		data            []byte
+		wantContentType string
+		wantErr         error
+	}{
+		{"png", "map.png", pngHeader, "image/png", nil},
+		{"pdf", "letter.pdf", []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"), "application/pdf", nil},
+		{"text", "letter.txt", []byte("To the heroes of Waterdeep"), "text/plain; charset=utf-8", nil},
+		{"declared type is ignored", "map.png", []byte("<html><script>alert(1)</script></html>"), "", ErrUnsupportedFileType},
+		{"empty", "map.png", nil, "", ErrEmptyFile},
+		{"too large", "map.png", append(pngHeader, bytes.Repeat([]byte{0}, MaxFileSize)...), "", ErrFileTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFile(1, tt.fileName, tt.data)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContentType, f.contentType)
+			assert.Equal(t, int64(len(tt.data)), f.size)
+			assert.True(t, strings.HasPrefix(f.key, "campaigns/1/handouts/"))
+		})
+	}
+}
+
+func TestCleanFileName(t *testing.T) {
+	assert.Equal(t, "map.png", cleanFileName("../../etc/map.png", ".png"))
+	assert.Equal(t, "map.png", cleanFileName(`C:\Users\dm\map.png`, ".png"))
+	assert.Equal(t, "evil.png", cleanFileName("ev\"il\r\n.png", ".png"))
+	assert.Equal(t, "handout.pdf", cleanFileName("", ".pdf"))
+}
+
+func TestReveal(t *testing.T) {
+	f, err := NewFile(1, "map.png", pngHeader)
+	require.NoError(t, err)
+	h, err := New(1, "Map of the keep", "", f)
+	require.NoError(t, err)
+
+	assert.True(t, h.IsVisibleTo(1, true))
+	assert.False(t, h.IsVisibleTo(2, false))
+
+	assert.ErrorIs(t, h.RevealTo(nil), ErrNoRecipients)
+	require.NoError(t, h.RevealTo([]id.PlayerId{3, 2, 3}))
+	assert.Equal(t, VisibilitySelected, h.visibility)
+	assert.Equal(t, []id.PlayerId{2, 3}, h.recipients)
+	assert.NotNil(t, h.revealedAt)
+	assert.True(t, h.IsVisibleTo(2, false))
+	assert.False(t, h.IsVisibleTo(4, false))
+
+	h.RevealToEveryone()
+	assert.True(t, h.IsVisibleTo(4, false))
+	assert.Empty(t, h.recipients)
+
+	h.Hide()
+	assert.False(t, h.IsVisibleTo(2, false))
+	assert.Nil(t, h.revealedAt)
+}
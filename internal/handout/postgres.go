@@ -0,0 +1,217 @@
+package handout
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresRepository struct {
+	q postgres.QuerierProvider
+}
+
+func NewPostgresRepository(q postgres.QuerierProvider) *PostgresRepository {
+	return &PostgresRepository{q: q}
+}
+
+const selectHandout = `
+	SELECT
+	    h.handout_id,
+	    h.campaign_id,
+	    h.title,
+	    h.description,
+	    h.file_key,
+	    h.file_name,
+	    h.content_type,
+	    h.size,
+	    h.visibility,
+	    COALESCE(
+	        (SELECT array_agg(r.player_id ORDER BY r.player_id) FROM handout_recipients r WHERE r.handout_id = h.handout_id),
+	        '{}'
+	    ),
+	    h.created_at,
+	    h.revealed_at
+	FROM handouts h
+`
+
+func (p *PostgresRepository) Save(ctx context.Context, h *Handout) error {
+	const query = `
+		INSERT INTO handouts (campaign_id, title, description, file_key, file_name, content_type, size, visibility, created_at, revealed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING handout_id
+	`
+
+	var handoutID int
+	if err := p.q(ctx).QueryRow(ctx, query,
+		int(h.campaignId),
+		h.title,
+		h.description,
+		h.file.key,
+		h.file.name,
+		h.file.contentType,
+		h.file.size,
+		string(h.visibility),
+		h.createdAt,
+		h.revealedAt,
+	).Scan(&handoutID); err != nil {
+		return err
+	}
+	h.id = id.HandoutId(handoutID)
+	return p.saveRecipients(ctx, h)
+}
+
+func (p *PostgresRepository) Update(ctx context.Context, h *Handout) error {
+	const query = `
+		UPDATE handouts
+		SET visibility = $1,
+		    revealed_at = $2
+		WHERE handout_id = $3
+	`
+
+	cmd, err := p.q(ctx).Exec(ctx, query, string(h.visibility), h.revealedAt, int(h.id))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return p.saveRecipients(ctx, h)
+}
+
+func (p *PostgresRepository) Delete(ctx context.Context, handoutId id.HandoutId) error {
+	const query = `DELETE FROM handouts WHERE handout_id = $1`
+
+	cmd, err := p.q(ctx).Exec(ctx, query, int(handoutId))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (p *PostgresRepository) FindById(ctx context.Context, handoutId id.HandoutId) (*Handout, error) {
+	const query = selectHandout + `WHERE h.handout_id = $1`
+
+	h, err := scanHandout(p.q(ctx).QueryRow(ctx, query, int(handoutId)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+	return h, nil
+}
+
+func (p *PostgresRepository) FindByCampaign(ctx context.Context, campaignId id.CampaignId) ([]*Handout, error) {
+	const query = selectHandout + `
+		WHERE h.campaign_id = $1
+		ORDER BY h.created_at DESC, h.handout_id DESC
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(campaignId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	handouts := make([]*Handout, 0)
+	for rows.Next() {
+		h, err := scanHandout(rows)
+		if err != nil {
+			return nil, err
+		}
+		handouts = append(handouts, h)
+	}
+	return handouts, rows.Err()
+}
+
+func (p *PostgresRepository) saveRecipients(ctx context.Context, h *Handout) error {
+	const sqlDelete = `DELETE FROM handout_recipients WHERE handout_id = $1`
+	const sqlInsert = `
+		INSERT INTO handout_recipients (handout_id, player_id)
+		SELECT $1, unnest($2::int[])
+	`
+
+	if _, err := p.q(ctx).Exec(ctx, sqlDelete, int(h.id)); err != nil {
+		return err
+	}
+	if len(h.recipients) == 0 {
+		return nil
+	}
+
+	recipients := make([]int, len(h.recipients))
+	for i, r := range h.recipients {
+		recipients[i] = int(r)
+	}
+	_, err := p.q(ctx).Exec(ctx, sqlInsert, int(h.id), recipients)
+	return err
+}
+
+func scanHandout(row pgx.Row) (*Handout, error) {
+	var (
+		h          Handout
+		handoutID  int
+		campaignID int
+		visibility string
+		recipients []int
+	)
+	if err := row.Scan(
+		&handoutID,
+		&campaignID,
+		&h.title,
+		&h.description,
+		&h.file.key,
+		&h.file.name,
+		&h.file.contentType,
+		&h.file.size,
+		&visibility,
+		&recipients,
+		&h.createdAt,
+		&h.revealedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	h.id = id.HandoutId(handoutID)
+	h.campaignId = id.CampaignId(campaignID)
+	h.visibility = Visibility(visibility)
+	h.recipients = make([]id.PlayerId, len(recipients))
+	for i, r := range recipients {
+		h.recipients[i] = id.PlayerId(r)
+	}
+	return &h, nil
+}
+
+func (p *PostgresRepository) FindExportHandouts(ctx context.Context, playerId id.PlayerId) ([]ExportedHandout, error) {
+	const query = `
+		SELECT h.handout_id, h.campaign_id, h.title, h.description, h.file_name, h.content_type, h.size,
+		       h.visibility, h.created_at, h.revealed_at
+		FROM handouts h
+		JOIN campaigns c ON c.campaign_id = h.campaign_id
+		WHERE c.master_id = $1
+		ORDER BY h.handout_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	handouts := make([]ExportedHandout, 0)
+	for rows.Next() {
+		var h ExportedHandout
+		err := rows.Scan(&h.HandoutId, &h.CampaignId, &h.Title, &h.Description, &h.FileName, &h.ContentType, &h.Size,
+			&h.Visibility, &h.CreatedAt, &h.RevealedAt)
+		if err != nil {
+			return nil, err
+		}
+		handouts = append(handouts, h)
+	}
+	return handouts, rows.Err()
+}
@@ -0,0 +1,40 @@
+package handout
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"context"
+	"io"
+)
+
+type Saver interface {
+	Save(ctx context.Context, h *Handout) error
+	// Update saves the visibility and the recipients of the handout
+	Update(ctx context.Context, h *Handout) error
+	Delete(ctx context.Context, handoutId id.HandoutId) error
+}
+
+type Finder interface {
+	FindById(ctx context.Context, handoutId id.HandoutId) (*Handout, error)
+	// FindByCampaign gives back all the handouts of the campaign, newest first
+	FindByCampaign(ctx context.Context, campaignId id.CampaignId) ([]*Handout, error)
+}
+
+// BlobStore keeps the content of the uploaded files. Open gives back blob.ErrNotFound
+// for a missing key.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type CampaignFinder interface {
+	FindById(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error)
+}
+
+// Exporter reads the handouts of a player for the export of its account
+type Exporter interface {
+	// FindExportHandouts gives back the handouts of the campaigns mastered by the player. Only
+	// the master uploads handouts, the uploader itself is not kept.
+	FindExportHandouts(ctx context.Context, playerId id.PlayerId) ([]ExportedHandout, error)
+}
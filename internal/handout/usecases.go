@@ -0,0 +1,303 @@
+package handout
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"beldur/pkg/blob"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dto"
+	"beldur/pkg/logger"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+)
+
+type UseCase struct {
+	handoutSaver   Saver
+	handoutFinder  Finder
+	blobStore      BlobStore
+	campaignFinder CampaignFinder
+	tx             tx.Transactor
+}
+
+func NewUseCase(
+	handoutSaver Saver,
+	handoutFinder Finder,
+	blobStore BlobStore,
+	campaignFinder CampaignFinder,
+	tx tx.Transactor,
+) *UseCase {
+	return &UseCase{
+		handoutSaver:   handoutSaver,
+		handoutFinder:  handoutFinder,
+		blobStore:      blobStore,
+		campaignFinder: campaignFinder,
+		tx:             tx,
+	}
+}
+
+// Upload stores the file and creates a hidden handout for it. Only the master of the campaign can do it.
+func (uc *UseCase) Upload(
+	ctx context.Context,
+	req CreateHandoutRequest,
+	upload Upload,
+	campaignId id.CampaignId,
+	masterId id.PlayerId,
+) (HandoutResponse, error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return HandoutResponse{}, err
+	}
+	if !camp.IsMaster(masterId) {
+		return HandoutResponse{}, ErrCampaignHasAnotherMaster
+	}
+
+	file, err := NewFile(campaignId, upload.Name, upload.Data)
+	if err != nil {
+		return HandoutResponse{}, err
+	}
+	h, err := New(campaignId, req.Title, req.Description, file)
+	if err != nil {
+		return HandoutResponse{}, err
+	}
+
+	if err := uc.blobStore.Put(ctx, file.key, bytes.NewReader(upload.Data)); err != nil {
+		logger.Debug("failed to store handout file", "campaign_id", campaignId, "error", err)
+		return HandoutResponse{}, err
+	}
+
+	err = uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		return uc.handoutSaver.Save(ctx, h)
+	})
+	if err != nil {
+		logger.Debug("failed to save handout", "campaign_id", campaignId, "error", err)
+		uc.deleteFile(ctx, file.key)
+		return HandoutResponse{}, err
+	}
+	return toHandoutResponse(h, true), nil
+}
+
+// List gives back the handouts of the campaign visible to the player
+func (uc *UseCase) List(ctx context.Context, campaignId id.CampaignId, playerId id.PlayerId) (dto.ListResponse[HandoutResponse], error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return dto.ListResponse[HandoutResponse]{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return dto.ListResponse[HandoutResponse]{}, ErrPlayerNotInCampaign
+	}
+
+	handouts, err := uc.handoutFinder.FindByCampaign(ctx, campaignId)
+	if err != nil {
+		logger.Debug("failed to find handouts", "campaign_id", campaignId, "error", err)
+		return dto.ListResponse[HandoutResponse]{}, err
+	}
+
+	isMaster := camp.IsMaster(playerId)
+	list := make([]HandoutResponse, 0, len(handouts))
+	for _, h := range handouts {
+		if h.IsVisibleTo(playerId, isMaster) {
+			list = append(list, toHandoutResponse(h, isMaster))
+		}
+	}
+	return dto.ListResponse[HandoutResponse]{Data: list}, nil
+}
+
+func (uc *UseCase) Get(ctx context.Context, handoutId id.HandoutId, playerId id.PlayerId) (HandoutResponse, error) {
+	h, isMaster, err := uc.findVisibleHandout(ctx, handoutId, playerId)
+	if err != nil {
+		return HandoutResponse{}, err
+	}
+	return toHandoutResponse(h, isMaster), nil
+}
+
+// Reveal shows the handout to all the players of the campaign or to the selected ones.
+// Only the master of the campaign can do it.
+func (uc *UseCase) Reveal(ctx context.Context, req RevealRequest, handoutId id.HandoutId, masterId id.PlayerId) (HandoutResponse, error) {
+	return uc.modify(ctx, handoutId, masterId, func(h *Handout, camp *campaign.Campaign) error {
+		if req.Everyone {
+			h.RevealToEveryone()
+			return nil
+		}
+
+		players := make([]id.PlayerId, len(req.PlayerIds))
+		for i, playerId := range req.PlayerIds {
+			players[i] = id.PlayerId(playerId)
+			if !camp.HasPlayer(players[i]) {
+				return ErrRecipientNotInCampaign
+			}
+		}
+		return h.RevealTo(players)
+	})
+}
+
+// Hide takes the handout back from the players. Only the master of the campaign can do it.
+func (uc *UseCase) Hide(ctx context.Context, handoutId id.HandoutId, masterId id.PlayerId) (HandoutResponse, error) {
+	return uc.modify(ctx, handoutId, masterId, func(h *Handout, _ *campaign.Campaign) error {
+		h.Hide()
+		return nil
+	})
+}
+
+// Delete removes the handout and its file. Only the master of the campaign can do it.
+func (uc *UseCase) Delete(ctx context.Context, handoutId id.HandoutId, masterId id.PlayerId) error {
+	var h *Handout
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var (
+			camp *campaign.Campaign
+			err  error
+		)
+		h, camp, err = uc.findHandout(ctx, handoutId)
+		if err != nil {
+			return err
+		}
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		if err := uc.handoutSaver.Delete(ctx, handoutId); err != nil {
+			logger.Debug("failed to delete handout", "handout_id", handoutId, "error", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the file goes only once the handout is gone, a leftover file is harmless
+	uc.deleteFile(ctx, h.file.key)
+	return nil
+}
+
+// Download opens the file of the handout, if the player can see the handout
+func (uc *UseCase) Download(ctx context.Context, handoutId id.HandoutId, playerId id.PlayerId) (Download, error) {
+	h, _, err := uc.findVisibleHandout(ctx, handoutId, playerId)
+	if err != nil {
+		return Download{}, err
+	}
+
+	content, err := uc.blobStore.Open(ctx, h.file.key)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return Download{}, ErrFileNotFound
+		}
+		logger.Debug("failed to open handout file", "handout_id", handoutId, "error", err)
+		return Download{}, err
+	}
+
+	return Download{
+		Name:        h.file.name,
+		ContentType: h.file.contentType,
+		Size:        h.file.size,
+		Content:     content,
+	}, nil
+}
+
+func (uc *UseCase) modify(
+	ctx context.Context,
+	handoutId id.HandoutId,
+	masterId id.PlayerId,
+	fn func(h *Handout, camp *campaign.Campaign) error,
+) (HandoutResponse, error) {
+	var h *Handout
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var (
+			camp *campaign.Campaign
+			err  error
+		)
+		h, camp, err = uc.findHandout(ctx, handoutId)
+		if err != nil {
+			return err
+		}
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		if err := fn(h, camp); err != nil {
+			return err
+		}
+		if err := uc.handoutSaver.Update(ctx, h); err != nil {
+			logger.Debug("failed to update handout", "handout_id", handoutId, "error", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return HandoutResponse{}, err
+	}
+	return toHandoutResponse(h, true), nil
+}
+
+// findVisibleHandout hides the handouts not revealed to the player behind ErrHandoutNotFound
+func (uc *UseCase) findVisibleHandout(ctx context.Context, handoutId id.HandoutId, playerId id.PlayerId) (*Handout, bool, error) {
+	h, camp, err := uc.findHandout(ctx, handoutId)
+	if err != nil {
+		return nil, false, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return nil, false, ErrPlayerNotInCampaign
+	}
+	isMaster := camp.IsMaster(playerId)
+	if !h.IsVisibleTo(playerId, isMaster) {
+		return nil, false, ErrHandoutNotFound
+	}
+	return h, isMaster, nil
+}
+
+func (uc *UseCase) findHandout(ctx context.Context, handoutId id.HandoutId) (*Handout, *campaign.Campaign, error) {
+	h, err := uc.handoutFinder.FindById(ctx, handoutId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, nil, ErrHandoutNotFound
+		}
+		logger.Debug("failed to find handout", "handout_id", handoutId, "error", err)
+		return nil, nil, err
+	}
+
+	camp, err := uc.findCampaign(ctx, h.campaignId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h, camp, nil
+}
+
+func (uc *UseCase) findCampaign(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		logger.Debug("no campaign found", "campaign_id", campaignId)
+		return nil, errors.Join(ErrCampaignNotFound, err)
+	}
+	return camp, nil
+}
+
+func (uc *UseCase) deleteFile(ctx context.Context, key string) {
+	if err := uc.blobStore.Delete(ctx, key); err != nil {
+		logger.Debug("failed to delete handout file", "key", key, "error", err)
+	}
+}
+
+func toHandoutResponse(h *Handout, isMaster bool) HandoutResponse {
+	resp := HandoutResponse{
+		Id:          int(h.id),
+		CampaignId:  int(h.campaignId),
+		Title:       h.title,
+		Description: h.description,
+		FileName:    h.file.name,
+		ContentType: h.file.contentType,
+		Size:        h.file.size,
+		Visibility:  h.visibility,
+		DownloadUrl: fmt.Sprintf("/handouts/%d/file", h.id),
+		CreatedAt:   h.createdAt,
+		RevealedAt:  h.revealedAt,
+	}
+	if isMaster {
+		resp.RecipientIds = make([]int, len(h.recipients))
+		for i, r := range h.recipients {
+			resp.RecipientIds[i] = int(r)
+		}
+	}
+	return resp
+}
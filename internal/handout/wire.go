@@ -0,0 +1,21 @@
+package handout
+
+import (
+	"beldur/internal/campaign"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+)
+
+type Deps struct {
+	QProvider  postgres.QuerierProvider
+	Transactor tx.Transactor
+	BlobStore  BlobStore
+}
+
+func NewHandlerFromDeps(deps Deps) *HttpHandler {
+	handoutRepo := NewPostgresRepository(deps.QProvider)
+	campaignRepo := campaign.NewPostgresRepository(deps.QProvider)
+
+	handoutUC := NewUseCase(handoutRepo, handoutRepo, deps.BlobStore, campaignRepo, deps.Transactor)
+	return NewHttpHandler(handoutUC)
+}
@@ -5,3 +5,19 @@ type AccountId int
 type CampaignId int
 type CharacterId int
 type ItemId int
+type NpcTemplateId int
+type TransferId int
+type EncounterId int
+type SessionId int
+type SlotId int
+type JournalEntryId int
+type MessageId int
+type QuestId int
+type ObjectiveId int
+type WikiEntryId int
+type WikiRevisionId int
+type HandoutId int
+type MapId int
+type TokenId int
+type AccessTokenId int
+type AccountSessionId int
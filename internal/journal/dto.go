@@ -0,0 +1,46 @@
+package journal
+
+import "time"
+
+type CreateEntryRequest struct {
+	Kind       Kind       `json:"kind" validate:"required,oneof=RECAP LOG"`
+	SessionId  *int       `json:"session_id"`
+	Title      string     `json:"title" validate:"max=100"`
+	Body       string     `json:"body" validate:"required,max=20000"`
+	Visibility Visibility `json:"visibility" validate:"required,oneof=EVERYONE MASTER"`
+	// when the logged event happened, now if nil
+	OccurredAt *time.Time `json:"occurred_at"`
+}
+
+type UpdateEntryRequest struct {
+	Title      string     `json:"title" validate:"max=100"`
+	Body       string     `json:"body" validate:"required,max=20000"`
+	Visibility Visibility `json:"visibility" validate:"required,oneof=EVERYONE MASTER"`
+}
+
+type EntryResponse struct {
+	Id         int        `json:"entry_id"`
+	CampaignId int        `json:"campaign_id"`
+	SessionId  *int       `json:"session_id"`
+	Kind       Kind       `json:"kind"`
+	AuthorId   int        `json:"author_id"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body"`
+	Visibility Visibility `json:"visibility"`
+	OccurredAt time.Time  `json:"occurred_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+// ExportedEntry is a journal entry written by the player, for the export of its account
+type ExportedEntry struct {
+	EntryId    int        `json:"entry_id"`
+	CampaignId int        `json:"campaign_id"`
+	Kind       string     `json:"kind"`
+	Title      string     `json:"title"`
+	Body       string     `json:"body"`
+	Visibility string     `json:"visibility"`
+	OccurredAt time.Time  `json:"occurred_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
@@ -0,0 +1,146 @@
+package journal
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/markdown"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxTitleCharacters = 100
+	MaxBodyCharacters  = 20000
+)
+
+type Kind string
+
+const (
+	// KindRecap summary of a game session
+	KindRecap Kind = "RECAP"
+	// KindLog entry of the campaign log, something that happened at a given time
+	KindLog Kind = "LOG"
+)
+
+type Visibility string
+
+const (
+	VisibilityEveryone Visibility = "EVERYONE"
+	VisibilityMaster   Visibility = "MASTER"
+)
+
+type Option func(*Entry) error
+
+// ForSession links the entry to a game session, mandatory for recaps
+func ForSession(sessionId id.SessionId) Option {
+	return func(e *Entry) error {
+		e.sessionId = &sessionId
+		return nil
+	}
+}
+
+// OccurredAt dates the entry, by default it is the time of the creation
+func OccurredAt(t time.Time) Option {
+	return func(e *Entry) error {
+		e.occurredAt = t
+		return nil
+	}
+}
+
+// Entry is a page of the journal of a campaign. The body is markdown,
+// sanitized when it is written.
+type Entry struct {
+	id         id.JournalEntryId
+	campaignId id.CampaignId
+	// nil if not linked to a session
+	sessionId  *id.SessionId
+	kind       Kind
+	authorId   id.PlayerId
+	title      string
+	body       string
+	visibility Visibility
+	occurredAt time.Time
+	createdAt  time.Time
+	// nil if never edited
+	updatedAt *time.Time
+}
+
+func New(
+	campaignId id.CampaignId,
+	kind Kind,
+	authorId id.PlayerId,
+	title string,
+	body string,
+	visibility Visibility,
+	opt ...Option,
+) (*Entry, error) {
+	if kind != KindRecap && kind != KindLog {
+		return nil, ErrInvalidKind
+	}
+
+	now := time.Now()
+	e := &Entry{
+		campaignId: campaignId,
+		kind:       kind,
+		authorId:   authorId,
+		occurredAt: now,
+		createdAt:  now,
+	}
+	if err := e.set(title, body, visibility); err != nil {
+		return nil, err
+	}
+	for _, o := range opt {
+		if err := o(e); err != nil {
+			return nil, err
+		}
+	}
+
+	if kind == KindRecap && e.sessionId == nil {
+		return nil, ErrRecapWithoutSession
+	}
+	return e, nil
+}
+
+// Edit replaces title, body and visibility of the entry
+func (e *Entry) Edit(title string, body string, visibility Visibility) error {
+	if err := e.set(title, body, visibility); err != nil {
+		return err
+	}
+	now := time.Now()
+	e.updatedAt = &now
+	return nil
+}
+
+// CanBeEditedBy tells if the player can edit or delete the entry: its author or the master
+func (e *Entry) CanBeEditedBy(playerId id.PlayerId, isMaster bool) bool {
+	return isMaster || e.authorId == playerId
+}
+
+// IsVisibleTo tells if a player of the campaign can read the entry
+func (e *Entry) IsVisibleTo(isMaster bool) bool {
+	return isMaster || e.visibility == VisibilityEveryone
+}
+
+func (e *Entry) Id() id.JournalEntryId { return e.id }
+
+func (e *Entry) CampaignId() id.CampaignId { return e.campaignId }
+
+func (e *Entry) Visibility() Visibility { return e.visibility }
+
+func (e *Entry) set(title string, body string, visibility Visibility) error {
+	if utf8.RuneCountInString(title) > MaxTitleCharacters {
+		return ErrInvalidTitle
+	}
+	if visibility != VisibilityEveryone && visibility != VisibilityMaster {
+		return ErrInvalidVisibility
+	}
+
+	body = markdown.Sanitize(body)
+	if body == "" || utf8.RuneCountInString(body) > MaxBodyCharacters {
+		return ErrInvalidBody
+	}
+
+	e.title = title
+	e.body = body
+	e.visibility = visibility
+	return nil
+}
@@ -0,0 +1,86 @@
+package journal
+
+import (
+	"beldur/internal/id"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		kind       Kind
+		title      string
+		body       string
+		visibility Visibility
+		opts       []Option
+		wantErr    error
+	}{
+		{"log", KindLog, "", "The party reaches Neverwinter", VisibilityEveryone, nil, nil},
+		{"recap", KindRecap, "Session 1", "We met in a tavern", VisibilityEveryone, []Option{ForSession(1)}, nil},
+		{"master only", KindLog, "", "The innkeeper is a spy", VisibilityMaster, nil, nil},
+		{"recap without session", KindRecap, "Session 1", "We met in a tavern", VisibilityEveryone, nil, ErrRecapWithoutSession},
+		{"invalid kind", "DIARY", "", "text", VisibilityEveryone, nil, ErrInvalidKind},
+		{"invalid visibility", KindLog, "", "text", "FRIENDS", nil, ErrInvalidVisibility},
+		{"title too long", KindLog, strings.Repeat("a", MaxTitleCharacters+1), "text", VisibilityEveryone, nil, ErrInvalidTitle},
+		{"empty body", KindLog, "", "", VisibilityEveryone, nil, ErrInvalidBody},
+		{"body only html", KindLog, "", "<script></script>", VisibilityEveryone, nil, ErrInvalidBody},
+		{"body too long", KindLog, "", strings.Repeat("a", MaxBodyCharacters+1), VisibilityEveryone, nil, ErrInvalidBody},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(1, tt.kind, 2, tt.title, tt.body, tt.visibility, tt.opts...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.visibility, e.Visibility())
+		})
+	}
+}
+
+func TestNew_SanitizesBody(t *testing.T) {
+	e, err := New(1, KindLog, 2, "", "**Boom**<script>alert(1)</script>", VisibilityEveryone)
+	require.NoError(t, err)
+	assert.Equal(t, "**Boom**alert(1)", e.body)
+}
+
+func TestNew_OccurredAt(t *testing.T) {
+	at := time.Date(2030, 3, 1, 20, 0, 0, 0, time.UTC)
+	e, err := New(1, KindLog, 2, "", "Dragon attack", VisibilityEveryone, OccurredAt(at))
+	require.NoError(t, err)
+	assert.Equal(t, at, e.occurredAt)
+}
+
+func TestEdit(t *testing.T) {
+	e, err := New(1, KindLog, 2, "", "Dragon attack", VisibilityEveryone)
+	require.NoError(t, err)
+	require.Nil(t, e.updatedAt)
+
+	require.NoError(t, e.Edit("Attack", "A red dragon attacks", VisibilityMaster))
+	assert.Equal(t, "Attack", e.title)
+	assert.Equal(t, VisibilityMaster, e.visibility)
+	assert.NotNil(t, e.updatedAt)
+
+	assert.ErrorIs(t, e.Edit("", "", VisibilityEveryone), ErrInvalidBody)
+	assert.Equal(t, "A red dragon attacks", e.body)
+}
+
+func TestPermissions(t *testing.T) {
+	const author, other = id.PlayerId(2), id.PlayerId(3)
+
+	e, err := New(1, KindLog, author, "", "Dragon attack", VisibilityMaster)
+	require.NoError(t, err)
+
+	assert.True(t, e.CanBeEditedBy(author, false))
+	assert.True(t, e.CanBeEditedBy(other, true))
+	assert.False(t, e.CanBeEditedBy(other, false))
+
+	assert.True(t, e.IsVisibleTo(true))
+	assert.False(t, e.IsVisibleTo(false))
+}
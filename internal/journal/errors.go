@@ -0,0 +1,96 @@
+package journal
+
+import (
+	"beldur/pkg/httperr"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidKind         = errors.New("invalid journal entry kind")
+	ErrInvalidTitle        = errors.New("invalid journal entry title")
+	ErrInvalidBody         = errors.New("journal entry body must be between 1 and 20000 characters")
+	ErrInvalidVisibility   = errors.New("invalid journal entry visibility")
+	ErrRecapWithoutSession = errors.New("a recap must be linked to a session")
+)
+
+var (
+	ErrEntryNotFound        = errors.New("journal entry not found")
+	ErrSessionNotFound      = errors.New("session not found")
+	ErrCampaignNotFound     = errors.New("campaign not found")
+	ErrPlayerNotInCampaign  = errors.New("player is not in the campaign")
+	ErrMasterOnlyVisibility = errors.New("only the master can write entries visible to the master only")
+	ErrEntryOfAnotherAuthor = errors.New("journal entry can be edited only by its author or the master")
+)
+
+func NewJournalApiErrorManager() *httperr.Manager {
+	mng := httperr.NewManager()
+
+	mng.Add(ErrInvalidKind, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_kind",
+		Message: ErrInvalidKind.Error(),
+	})
+
+	mng.Add(ErrInvalidTitle, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_title",
+		Message: ErrInvalidTitle.Error(),
+	})
+
+	mng.Add(ErrInvalidBody, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_body",
+		Message: ErrInvalidBody.Error(),
+	})
+
+	mng.Add(ErrInvalidVisibility, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_visibility",
+		Message: ErrInvalidVisibility.Error(),
+	})
+
+	mng.Add(ErrRecapWithoutSession, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "recap_without_session",
+		Message: ErrRecapWithoutSession.Error(),
+	})
+
+	mng.Add(ErrEntryNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "journal_entry_not_found",
+		Message: ErrEntryNotFound.Error(),
+	})
+
+	mng.Add(ErrSessionNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "session_not_found",
+		Message: ErrSessionNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "campaign_not_found",
+		Message: ErrCampaignNotFound.Error(),
+	})
+
+	mng.Add(ErrPlayerNotInCampaign, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "player_not_in_campaign",
+		Message: ErrPlayerNotInCampaign.Error(),
+	})
+
+	mng.Add(ErrMasterOnlyVisibility, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "master_only_visibility",
+		Message: ErrMasterOnlyVisibility.Error(),
+	})
+
+	mng.Add(ErrEntryOfAnotherAuthor, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "journal_entry_of_another_author",
+		Message: ErrEntryOfAnotherAuthor.Error(),
+	})
+
+	return mng
+}
@@ -0,0 +1,159 @@
+package journal
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/dto"
+	"beldur/pkg/httperr"
+	"beldur/pkg/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpHandler struct {
+	journalUC  *UseCase
+	errManager *httperr.Manager
+}
+
+func NewHttpHandler(journalUC *UseCase) *HttpHandler {
+	return &HttpHandler{
+		journalUC:  journalUC,
+		errManager: NewJournalApiErrorManager(),
+	}
+}
+
+func (h *HttpHandler) HandleCreateEntry(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(CreateEntryRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.journalUC.Create(c.Context(), req, id.CampaignId(campaignId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// HandleGetJournal accepts the query parameters kind, session_id, limit and offset
+func (h *HttpHandler) HandleGetJournal(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	var filter Filter
+	if kind := Kind(c.Query("kind")); kind != "" {
+		if kind != KindRecap && kind != KindLog {
+			return c.SendStatus(fiber.StatusBadRequest)
+		}
+		filter.Kind = &kind
+	}
+	if sessionInstr := c.Query("session_id"); sessionInstr != "" {
+		sessionId, err := strconv.Atoi(sessionInstr)
+		if err != nil {
+			return c.SendStatus(fiber.StatusBadRequest)
+		}
+		sid := id.SessionId(sessionId)
+		filter.SessionId = &sid
+	}
+	page := dto.NewPage(c.QueryInt("limit"), c.QueryInt("offset"))
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.journalUC.List(c.Context(), id.CampaignId(campaignId), filter, page, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetEntry(c *fiber.Ctx) error {
+	entryId, err := entryIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.journalUC.Get(c.Context(), entryId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleUpdateEntry(c *fiber.Ctx) error {
+	entryId, err := entryIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(UpdateEntryRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.journalUC.Update(c.Context(), req, entryId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleDeleteEntry(c *fiber.Ctx) error {
+	entryId, err := entryIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.journalUC.Delete(c.Context(), entryId, p.PlayerID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func entryIdParam(c *fiber.Ctx) (id.JournalEntryId, error) {
+	entryInstr := c.Params("entryId")
+	if entryInstr == "" {
+		panic("wrong parameter naming")
+	}
+	entryId, err := strconv.Atoi(entryInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.JournalEntryId(entryId), nil
+}
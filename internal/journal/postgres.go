@@ -0,0 +1,265 @@
+package journal
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/dto"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresRepository struct {
+	q postgres.QuerierProvider
+}
+
+func NewPostgresRepository(q postgres.QuerierProvider) *PostgresRepository {
+	return &PostgresRepository{q: q}
+}
+
+const selectEntry = `
+	SELECT
+	    entry_id,
+	    campaign_id,
+	    session_id,
+	    kind,
+	    author_id,
+	    title,
+	    body,
+	    visibility,
+	    occurred_at,
+	    created_at,
+	    updated_at
+	FROM journal_entries
+`
+
+func (p *PostgresRepository) Save(ctx context.Context, e *Entry) error {
+	const query = `
+		INSERT INTO journal_entries (campaign_id, session_id, kind, author_id, title, body, visibility, occurred_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING entry_id
+	`
+
+	var entryID int
+	if err := p.q(ctx).QueryRow(ctx, query,
+		int(e.campaignId),
+		sessionIdArg(e.sessionId),
+		string(e.kind),
+		int(e.authorId),
+		e.title,
+		e.body,
+		string(e.visibility),
+		e.occurredAt,
+		e.createdAt,
+	).Scan(&entryID); err != nil {
+		return err
+	}
+	e.id = id.JournalEntryId(entryID)
+	return nil
+}
+
+func (p *PostgresRepository) Update(ctx context.Context, e *Entry) error {
+	const query = `
+		UPDATE journal_entries
+		SET title = $1,
+		    body = $2,
+		    visibility = $3,
+		    updated_at = $4
+		WHERE entry_id = $5
+	`
+
+	cmd, err := p.q(ctx).Exec(ctx, query, e.title, e.body, string(e.visibility), e.updatedAt, int(e.id))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (p *PostgresRepository) Delete(ctx context.Context, entryId id.JournalEntryId) error {
+	const query = `DELETE FROM journal_entries WHERE entry_id = $1`
+
+	cmd, err := p.q(ctx).Exec(ctx, query, int(entryId))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (p *PostgresRepository) FindById(ctx context.Context, entryId id.JournalEntryId) (*Entry, error) {
+	const query = selectEntry + `WHERE entry_id = $1`
+
+	e, err := p.scanEntry(p.q(ctx).QueryRow(ctx, query, int(entryId)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+func (p *PostgresRepository) FindByCampaign(
+	ctx context.Context,
+	campaignId id.CampaignId,
+	filter Filter,
+	page dto.Page,
+) ([]*Entry, int, error) {
+	const filterEntries = `
+		FROM journal_entries
+		WHERE campaign_id = $1
+		  AND ($2::VARCHAR IS NULL OR kind = $2)
+		  AND ($3::INT IS NULL OR session_id = $3)
+		  AND ($4 OR visibility = 'EVERYONE')
+	`
+	const query = `
+		SELECT
+		    entry_id,
+		    campaign_id,
+		    session_id,
+		    kind,
+		    author_id,
+		    title,
+		    body,
+		    visibility,
+		    occurred_at,
+		    created_at,
+		    updated_at,
+		    COUNT(*) OVER ()
+	` + filterEntries + `
+		ORDER BY occurred_at DESC, entry_id DESC
+		LIMIT $5 OFFSET $6
+	`
+	const sqlCount = `SELECT COUNT(*)` + filterEntries
+
+	var kind *string
+	if filter.Kind != nil {
+		k := string(*filter.Kind)
+		kind = &k
+	}
+
+	args := []any{int(campaignId), kind, sessionIdArg(filter.SessionId), filter.IncludeMasterOnly}
+	rows, err := p.q(ctx).Query(ctx, query, append(args, page.Limit, page.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := make([]*Entry, 0)
+	total := 0
+	for rows.Next() {
+		e, err := p.scanEntry(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// past the last page there is no row to carry the total
+	if len(entries) == 0 && page.Offset > 0 {
+		if err := p.q(ctx).QueryRow(ctx, sqlCount, args...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+	return entries, total, nil
+}
+
+// scanEntry translates DB row -> domain model. Extra columns after the ones of
+// selectEntry are scanned into extra.
+func (p *PostgresRepository) scanEntry(row pgx.Row, extra ...any) (*Entry, error) {
+	var (
+		entryID    int
+		campaignID int
+		sessionID  *int
+		kind       Kind
+		authorID   int
+		title      string
+		body       string
+		visibility Visibility
+		occurredAt time.Time
+		createdAt  time.Time
+		updatedAt  *time.Time
+	)
+
+	dest := []any{
+		&entryID,
+		&campaignID,
+		&sessionID,
+		&kind,
+		&authorID,
+		&title,
+		&body,
+		&visibility,
+		&occurredAt,
+		&createdAt,
+		&updatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	var sessionId *id.SessionId
+	if sessionID != nil {
+		sid := id.SessionId(*sessionID)
+		sessionId = &sid
+	}
+
+	return &Entry{
+		id:         id.JournalEntryId(entryID),
+		campaignId: id.CampaignId(campaignID),
+		sessionId:  sessionId,
+		kind:       kind,
+		authorId:   id.PlayerId(authorID),
+		title:      title,
+		body:       body,
+		visibility: visibility,
+		occurredAt: occurredAt,
+		createdAt:  createdAt,
+		updatedAt:  updatedAt,
+	}, nil
+}
+
+func sessionIdArg(sessionId *id.SessionId) *int {
+	if sessionId == nil {
+		return nil
+	}
+	sid := int(*sessionId)
+	return &sid
+}
+
+func (p *PostgresRepository) FindExportEntries(ctx context.Context, playerId id.PlayerId) ([]ExportedEntry, error) {
+	const query = `
+		SELECT entry_id, campaign_id, kind, title, body, visibility, occurred_at, created_at, updated_at
+		FROM journal_entries
+		WHERE author_id = $1
+		ORDER BY entry_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]ExportedEntry, 0)
+	for rows.Next() {
+		var e ExportedEntry
+		err := rows.Scan(&e.EntryId, &e.CampaignId, &e.Kind, &e.Title, &e.Body, &e.Visibility,
+			&e.OccurredAt, &e.CreatedAt, &e.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,44 @@
+package journal
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"beldur/internal/schedule"
+	"beldur/pkg/dto"
+	"context"
+)
+
+// Filter narrows the entries of a campaign
+type Filter struct {
+	// nil for every kind
+	Kind *Kind
+	// nil for every session
+	SessionId *id.SessionId
+	// entries visible to the master only are included
+	IncludeMasterOnly bool
+}
+
+type Saver interface {
+	Save(ctx context.Context, e *Entry) error
+	Update(ctx context.Context, e *Entry) error
+	Delete(ctx context.Context, entryId id.JournalEntryId) error
+}
+
+type Finder interface {
+	FindById(ctx context.Context, entryId id.JournalEntryId) (*Entry, error)
+	// FindByCampaign gives back a page of entries, newest first, and the number of all the matching entries
+	FindByCampaign(ctx context.Context, campaignId id.CampaignId, filter Filter, page dto.Page) ([]*Entry, int, error)
+}
+
+type CampaignFinder interface {
+	FindById(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error)
+}
+
+type SessionFinder interface {
+	FindById(ctx context.Context, sessionId id.SessionId) (*schedule.Session, error)
+}
+
+// Exporter reads the journal entries written by a player for the export of its account
+type Exporter interface {
+	FindExportEntries(ctx context.Context, playerId id.PlayerId) ([]ExportedEntry, error)
+}
@@ -0,0 +1,252 @@
+package journal
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dto"
+	"beldur/pkg/logger"
+	"context"
+	"errors"
+)
+
+type UseCase struct {
+	entrySaver     Saver
+	entryFinder    Finder
+	campaignFinder CampaignFinder
+	sessionFinder  SessionFinder
+	tx             tx.Transactor
+}
+
+func NewUseCase(
+	entrySaver Saver,
+	entryFinder Finder,
+	campaignFinder CampaignFinder,
+	sessionFinder SessionFinder,
+	tx tx.Transactor,
+) *UseCase {
+	return &UseCase{
+		entrySaver:     entrySaver,
+		entryFinder:    entryFinder,
+		campaignFinder: campaignFinder,
+		sessionFinder:  sessionFinder,
+		tx:             tx,
+	}
+}
+
+// Create writes an entry in the journal of the campaign. Every player of the campaign can do it,
+// but only the master can hide an entry from the players.
+func (uc *UseCase) Create(ctx context.Context, req CreateEntryRequest, campaignId id.CampaignId, playerId id.PlayerId) (EntryResponse, error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return EntryResponse{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return EntryResponse{}, ErrPlayerNotInCampaign
+	}
+	if req.Visibility == VisibilityMaster && !camp.IsMaster(playerId) {
+		return EntryResponse{}, ErrMasterOnlyVisibility
+	}
+
+	var opts []Option
+	if req.SessionId != nil {
+		sessionId := id.SessionId(*req.SessionId)
+		if err := uc.checkSession(ctx, sessionId, campaignId); err != nil {
+			return EntryResponse{}, err
+		}
+		opts = append(opts, ForSession(sessionId))
+	}
+	if req.OccurredAt != nil {
+		opts = append(opts, OccurredAt(*req.OccurredAt))
+	}
+
+	e, err := New(campaignId, req.Kind, playerId, req.Title, req.Body, req.Visibility, opts...)
+	if err != nil {
+		return EntryResponse{}, err
+	}
+
+	err = uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		return uc.entrySaver.Save(ctx, e)
+	})
+	if err != nil {
+		logger.Debug("failed to save journal entry", "campaign_id", campaignId, "error", err)
+		return EntryResponse{}, err
+	}
+	return toEntryResponse(e), nil
+}
+
+// List gives back a page of the journal of the campaign, newest first.
+// Every player of the campaign can read it, the entries visible to the master only are skipped for the players.
+func (uc *UseCase) List(
+	ctx context.Context,
+	campaignId id.CampaignId,
+	filter Filter,
+	page dto.Page,
+	playerId id.PlayerId,
+) (dto.PageResponse[EntryResponse], error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return dto.PageResponse[EntryResponse]{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return dto.PageResponse[EntryResponse]{}, ErrPlayerNotInCampaign
+	}
+	filter.IncludeMasterOnly = camp.IsMaster(playerId)
+
+	entries, total, err := uc.entryFinder.FindByCampaign(ctx, campaignId, filter, page)
+	if err != nil {
+		logger.Debug("failed to find journal entries", "campaign_id", campaignId, "error", err)
+		return dto.PageResponse[EntryResponse]{}, err
+	}
+
+	list := make([]EntryResponse, len(entries))
+	for i, e := range entries {
+		list[i] = toEntryResponse(e)
+	}
+	return dto.PageResponse[EntryResponse]{
+		Data:   list,
+		Total:  total,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}, nil
+}
+
+// Get gives back the entry, if it is visible to the player
+func (uc *UseCase) Get(ctx context.Context, entryId id.JournalEntryId, playerId id.PlayerId) (EntryResponse, error) {
+	e, camp, err := uc.findEntry(ctx, entryId)
+	if err != nil {
+		return EntryResponse{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return EntryResponse{}, ErrPlayerNotInCampaign
+	}
+	// hidden entries do not exist for the players
+	if !e.IsVisibleTo(camp.IsMaster(playerId)) {
+		return EntryResponse{}, ErrEntryNotFound
+	}
+	return toEntryResponse(e), nil
+}
+
+// Update edits the entry. Only its author or the master can do it.
+func (uc *UseCase) Update(ctx context.Context, req UpdateEntryRequest, entryId id.JournalEntryId, playerId id.PlayerId) (EntryResponse, error) {
+	var resp EntryResponse
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		e, camp, err := uc.findEditableEntry(ctx, entryId, playerId)
+		if err != nil {
+			return err
+		}
+		if req.Visibility == VisibilityMaster && !camp.IsMaster(playerId) {
+			return ErrMasterOnlyVisibility
+		}
+		if err := e.Edit(req.Title, req.Body, req.Visibility); err != nil {
+			return err
+		}
+		if err := uc.entrySaver.Update(ctx, e); err != nil {
+			logger.Debug("failed to update journal entry", "entry_id", entryId, "error", err)
+			return err
+		}
+		resp = toEntryResponse(e)
+		return nil
+	})
+	if err != nil {
+		return EntryResponse{}, err
+	}
+	return resp, nil
+}
+
+// Delete removes the entry. Only its author or the master can do it.
+func (uc *UseCase) Delete(ctx context.Context, entryId id.JournalEntryId, playerId id.PlayerId) error {
+	return uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, _, err := uc.findEditableEntry(ctx, entryId, playerId); err != nil {
+			return err
+		}
+		if err := uc.entrySaver.Delete(ctx, entryId); err != nil {
+			logger.Debug("failed to delete journal entry", "entry_id", entryId, "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+func (uc *UseCase) findEditableEntry(ctx context.Context, entryId id.JournalEntryId, playerId id.PlayerId) (*Entry, *campaign.Campaign, error) {
+	e, camp, err := uc.findEntry(ctx, entryId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return nil, nil, ErrPlayerNotInCampaign
+	}
+	isMaster := camp.IsMaster(playerId)
+	if !e.IsVisibleTo(isMaster) {
+		return nil, nil, ErrEntryNotFound
+	}
+	if !e.CanBeEditedBy(playerId, isMaster) {
+		return nil, nil, ErrEntryOfAnotherAuthor
+	}
+	return e, camp, nil
+}
+
+func (uc *UseCase) findEntry(ctx context.Context, entryId id.JournalEntryId) (*Entry, *campaign.Campaign, error) {
+	e, err := uc.entryFinder.FindById(ctx, entryId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, nil, ErrEntryNotFound
+		}
+		logger.Debug("failed to find journal entry", "entry_id", entryId, "error", err)
+		return nil, nil, err
+	}
+
+	camp, err := uc.findCampaign(ctx, e.campaignId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, camp, nil
+}
+
+func (uc *UseCase) findCampaign(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		logger.Debug("no campaign found", "campaign_id", campaignId)
+		return nil, errors.Join(ErrCampaignNotFound, err)
+	}
+	return camp, nil
+}
+
+// checkSession verifies the session exists and belongs to the campaign
+func (uc *UseCase) checkSession(ctx context.Context, sessionId id.SessionId, campaignId id.CampaignId) error {
+	s, err := uc.sessionFinder.FindById(ctx, sessionId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return ErrSessionNotFound
+		}
+		return err
+	}
+	if s.CampaignId() != campaignId {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+func toEntryResponse(e *Entry) EntryResponse {
+	var sessionId *int
+	if e.sessionId != nil {
+		sid := int(*e.sessionId)
+		sessionId = &sid
+	}
+
+	return EntryResponse{
+		Id:         int(e.id),
+		CampaignId: int(e.campaignId),
+		SessionId:  sessionId,
+		Kind:       e.kind,
+		AuthorId:   int(e.authorId),
+		Title:      e.title,
+		Body:       e.body,
+		Visibility: e.visibility,
+		OccurredAt: e.occurredAt,
+		CreatedAt:  e.createdAt,
+		UpdatedAt:  e.updatedAt,
+	}
+}
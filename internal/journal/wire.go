@@ -0,0 +1,22 @@
+package journal
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/schedule"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+)
+
+type Deps struct {
+	QProvider  postgres.QuerierProvider
+	Transactor tx.Transactor
+}
+
+func NewHandlerFromDeps(deps Deps) *HttpHandler {
+	entryRepo := NewPostgresRepository(deps.QProvider)
+	campaignRepo := campaign.NewPostgresRepository(deps.QProvider)
+	sessionRepo := schedule.NewPostgresRepository(deps.QProvider)
+
+	journalUC := NewUseCase(entryRepo, entryRepo, campaignRepo, sessionRepo, deps.Transactor)
+	return NewHttpHandler(journalUC)
+}
@@ -1,9 +1,15 @@
 package player
 
-import "beldur/internal/id"
+import (
+	"beldur/internal/id"
+	"strings"
+)
 
 const (
 	UsernameMaxCharacters = 20
+	// DeletedNamePrefix starts the names given to the players of the deleted accounts,
+	// no one can choose such a name
+	DeletedNamePrefix = "deleted#"
 )
 
 type Player struct {
@@ -34,7 +40,7 @@ func (p *Player) String() string {
 }
 
 func validateUsername(value string) error {
-	if len(value) > UsernameMaxCharacters {
+	if len(value) > UsernameMaxCharacters || strings.HasPrefix(value, DeletedNamePrefix) {
 		return ErrInvalidPlayerName
 	}
 	return nil
@@ -0,0 +1,64 @@
+package quest
+
+import "time"
+
+type CreateQuestRequest struct {
+	Title       string `json:"title" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=5000"`
+	// NPC who gave the quest
+	GiverId    *int       `json:"giver_id"`
+	Objectives []string   `json:"objectives" validate:"max=50,dive,required,max=200"`
+	Rewards    RewardsDto `json:"rewards"`
+	// the quest is visible to the players from its creation
+	Revealed bool `json:"revealed"`
+}
+
+type UpdateQuestRequest struct {
+	Title       string     `json:"title" validate:"required,max=100"`
+	Description string     `json:"description" validate:"max=5000"`
+	GiverId     *int       `json:"giver_id"`
+	Rewards     RewardsDto `json:"rewards"`
+}
+
+type AddObjectiveRequest struct {
+	Description string `json:"description" validate:"required,max=200"`
+}
+
+type UpdateObjectiveRequest struct {
+	Description string `json:"description" validate:"required,max=200"`
+	Done        bool   `json:"done"`
+}
+
+type RewardsDto struct {
+	XP    int             `json:"xp" validate:"min=0"`
+	Items []RewardItemDto `json:"items" validate:"max=20,dive"`
+}
+
+type RewardItemDto struct {
+	Name     string `json:"name" validate:"required,max=100"`
+	Quantity int    `json:"quantity" validate:"required,min=1"`
+}
+
+type QuestResponse struct {
+	Id          int                 `json:"quest_id"`
+	CampaignId  int                 `json:"campaign_id"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Giver       *GiverResponse      `json:"giver"`
+	Status      Status              `json:"status"`
+	Objectives  []ObjectiveResponse `json:"objectives"`
+	Rewards     RewardsDto          `json:"rewards"`
+	CreatedAt   time.Time           `json:"created_at"`
+	ClosedAt    *time.Time          `json:"closed_at"`
+}
+
+type GiverResponse struct {
+	CharacterId int    `json:"character_id"`
+	Name        string `json:"name"`
+}
+
+type ObjectiveResponse struct {
+	Id          int    `json:"objective_id"`
+	Description string `json:"description"`
+	Done        bool   `json:"done"`
+}
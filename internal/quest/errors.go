@@ -0,0 +1,117 @@
+package quest
+
+import (
+	"beldur/pkg/httperr"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidTitle         = errors.New("invalid quest title")
+	ErrInvalidDescription   = errors.New("invalid quest description")
+	ErrInvalidRewards       = errors.New("invalid quest rewards")
+	ErrInvalidGiver         = errors.New("quest giver must be an NPC of the campaign")
+	ErrInvalidObjective     = errors.New("invalid quest objective")
+	ErrTooManyObjectives    = errors.New("quest has too many objectives")
+	ErrQuestAlreadyRevealed = errors.New("quest is already revealed")
+	ErrQuestNotActive       = errors.New("quest is not active")
+)
+
+var (
+	ErrQuestNotFound            = errors.New("quest not found")
+	ErrObjectiveNotFound        = errors.New("objective not found")
+	ErrCharacterNotFound        = errors.New("character not found")
+	ErrCampaignNotFound         = errors.New("campaign not found")
+	ErrCampaignHasAnotherMaster = errors.New("campaign has another master")
+	ErrPlayerNotInCampaign      = errors.New("player is not in the campaign")
+)
+
+func NewQuestApiErrorManager() *httperr.Manager {
+	mng := httperr.NewManager()
+
+	mng.Add(ErrInvalidTitle, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_quest_title",
+		Message: ErrInvalidTitle.Error(),
+	})
+
+	mng.Add(ErrInvalidDescription, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_quest_description",
+		Message: ErrInvalidDescription.Error(),
+	})
+
+	mng.Add(ErrInvalidRewards, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_rewards",
+		Message: ErrInvalidRewards.Error(),
+	})
+
+	mng.Add(ErrInvalidGiver, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_giver",
+		Message: ErrInvalidGiver.Error(),
+	})
+
+	mng.Add(ErrInvalidObjective, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_objective",
+		Message: ErrInvalidObjective.Error(),
+	})
+
+	mng.Add(ErrTooManyObjectives, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "too_many_objectives",
+		Message: ErrTooManyObjectives.Error(),
+	})
+
+	mng.Add(ErrQuestAlreadyRevealed, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "quest_already_revealed",
+		Message: ErrQuestAlreadyRevealed.Error(),
+	})
+
+	mng.Add(ErrQuestNotActive, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "quest_not_active",
+		Message: ErrQuestNotActive.Error(),
+	})
+
+	mng.Add(ErrQuestNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "quest_not_found",
+		Message: ErrQuestNotFound.Error(),
+	})
+
+	mng.Add(ErrObjectiveNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "objective_not_found",
+		Message: ErrObjectiveNotFound.Error(),
+	})
+
+	mng.Add(ErrCharacterNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "character_not_found",
+		Message: ErrCharacterNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "campaign_not_found",
+		Message: ErrCampaignNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignHasAnotherMaster, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "campaign_has_another_master",
+		Message: ErrCampaignHasAnotherMaster.Error(),
+	})
+
+	mng.Add(ErrPlayerNotInCampaign, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "player_not_in_campaign",
+		Message: ErrPlayerNotInCampaign.Error(),
+	})
+
+	return mng
+}
@@ -0,0 +1,194 @@
+package quest
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/httperr"
+	"beldur/pkg/middleware"
+	"context"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpHandler struct {
+	questUC    *UseCase
+	errManager *httperr.Manager
+}
+
+func NewHttpHandler(questUC *UseCase) *HttpHandler {
+	return &HttpHandler{
+		questUC:    questUC,
+		errManager: NewQuestApiErrorManager(),
+	}
+}
+
+func (h *HttpHandler) HandleCreateQuest(c *fiber.Ctx) error {
+	campaignId, err := campaignIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(CreateQuestRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.questUC.Create(c.Context(), req, campaignId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetQuests(c *fiber.Ctx) error {
+	campaignId, err := campaignIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.questUC.List(c.Context(), campaignId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetQuest(c *fiber.Ctx) error {
+	return h.handleQuest(c, h.questUC.Get)
+}
+
+func (h *HttpHandler) HandleUpdateQuest(c *fiber.Ctx) error {
+	req := c.Locals("body").(UpdateQuestRequest)
+	return h.handleQuest(c, func(ctx context.Context, campaignId id.CampaignId, questId id.QuestId, playerId id.PlayerId) (QuestResponse, error) {
+		return h.questUC.Update(ctx, req, campaignId, questId, playerId)
+	})
+}
+
+func (h *HttpHandler) HandleReveal(c *fiber.Ctx) error {
+	return h.handleQuest(c, h.questUC.Reveal)
+}
+
+func (h *HttpHandler) HandleComplete(c *fiber.Ctx) error {
+	return h.handleQuest(c, h.questUC.Complete)
+}
+
+func (h *HttpHandler) HandleFail(c *fiber.Ctx) error {
+	return h.handleQuest(c, h.questUC.Fail)
+}
+
+func (h *HttpHandler) HandleAddObjective(c *fiber.Ctx) error {
+	req := c.Locals("body").(AddObjectiveRequest)
+	return h.handleQuest(c, func(ctx context.Context, campaignId id.CampaignId, questId id.QuestId, playerId id.PlayerId) (QuestResponse, error) {
+		return h.questUC.AddObjective(ctx, req, campaignId, questId, playerId)
+	})
+}
+
+func (h *HttpHandler) HandleUpdateObjective(c *fiber.Ctx) error {
+	objectiveId, err := objectiveIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	req := c.Locals("body").(UpdateObjectiveRequest)
+	return h.handleQuest(c, func(ctx context.Context, campaignId id.CampaignId, questId id.QuestId, playerId id.PlayerId) (QuestResponse, error) {
+		return h.questUC.UpdateObjective(ctx, req, campaignId, questId, objectiveId, playerId)
+	})
+}
+
+func (h *HttpHandler) HandleRemoveObjective(c *fiber.Ctx) error {
+	objectiveId, err := objectiveIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	return h.handleQuest(c, func(ctx context.Context, campaignId id.CampaignId, questId id.QuestId, playerId id.PlayerId) (QuestResponse, error) {
+		return h.questUC.RemoveObjective(ctx, campaignId, questId, objectiveId, playerId)
+	})
+}
+
+func (h *HttpHandler) HandleDeleteQuest(c *fiber.Ctx) error {
+	campaignId, questId, err := questParams(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.questUC.Delete(c.Context(), campaignId, questId, p.PlayerID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *HttpHandler) handleQuest(
+	c *fiber.Ctx,
+	action func(context.Context, id.CampaignId, id.QuestId, id.PlayerId) (QuestResponse, error),
+) error {
+	campaignId, questId, err := questParams(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := action(c.Context(), campaignId, questId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func campaignIdParam(c *fiber.Ctx) (id.CampaignId, error) {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.CampaignId(campaignId), nil
+}
+
+func questParams(c *fiber.Ctx) (id.CampaignId, id.QuestId, error) {
+	campaignId, err := campaignIdParam(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	questInstr := c.Params("questId")
+	if questInstr == "" {
+		panic("wrong parameter naming")
+	}
+	questId, err := strconv.Atoi(questInstr)
+	if err != nil {
+		return 0, 0, err
+	}
+	return campaignId, id.QuestId(questId), nil
+}
+
+func objectiveIdParam(c *fiber.Ctx) (id.ObjectiveId, error) {
+	objectiveInstr := c.Params("objectiveId")
+	if objectiveInstr == "" {
+		panic("wrong parameter naming")
+	}
+	objectiveId, err := strconv.Atoi(objectiveInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.ObjectiveId(objectiveId), nil
+}
@@ -0,0 +1,323 @@
+package quest
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresRepository struct {
+	q postgres.QuerierProvider
+}
+
+func NewPostgresRepository(q postgres.QuerierProvider) *PostgresRepository {
+	return &PostgresRepository{q: q}
+}
+
+const selectQuest = `
+	SELECT
+	    q.quest_id,
+	    q.campaign_id,
+	    q.title,
+	    q.description,
+	    q.giver_id,
+	    c.name,
+	    q.status,
+	    q.reward_xp,
+	    q.reward_items,
+	    q.created_at,
+	    q.closed_at
+	FROM quests q
+	LEFT JOIN characters c ON c.character_id = q.giver_id
+`
+
+// rewardItem is the JSON form of a RewardItem in the DB
+type rewardItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+// Save inserts the quest with its objectives. It should be called inside a transaction.
+func (p *PostgresRepository) Save(ctx context.Context, q *Quest) error {
+	const query = `
+		INSERT INTO quests (campaign_id, title, description, giver_id, status, reward_xp, reward_items, created_at, closed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING quest_id
+	`
+
+	items, err := marshalRewardItems(q.rewards.Items)
+	if err != nil {
+		return err
+	}
+
+	var questID int
+	if err := p.q(ctx).QueryRow(ctx, query,
+		int(q.campaignId),
+		q.title,
+		q.description,
+		giverIdArg(q.giver),
+		string(q.status),
+		q.rewards.XP,
+		items,
+		q.createdAt,
+		q.closedAt,
+	).Scan(&questID); err != nil {
+		return err
+	}
+	q.id = id.QuestId(questID)
+
+	return p.saveObjectives(ctx, q)
+}
+
+// Update should be called inside a transaction
+func (p *PostgresRepository) Update(ctx context.Context, q *Quest) error {
+	const query = `
+		UPDATE quests
+		SET title = $1,
+		    description = $2,
+		    giver_id = $3,
+		    status = $4,
+		    reward_xp = $5,
+		    reward_items = $6,
+		    closed_at = $7
+		WHERE quest_id = $8
+	`
+
+	items, err := marshalRewardItems(q.rewards.Items)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := p.q(ctx).Exec(ctx, query,
+		q.title,
+		q.description,
+		giverIdArg(q.giver),
+		string(q.status),
+		q.rewards.XP,
+		items,
+		q.closedAt,
+		int(q.id),
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+
+	return p.saveObjectives(ctx, q)
+}
+
+func (p *PostgresRepository) Delete(ctx context.Context, questId id.QuestId) error {
+	const query = `DELETE FROM quests WHERE quest_id = $1`
+
+	cmd, err := p.q(ctx).Exec(ctx, query, int(questId))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (p *PostgresRepository) FindById(ctx context.Context, questId id.QuestId) (*Quest, error) {
+	const query = selectQuest + `WHERE q.quest_id = $1`
+
+	q, err := p.scanQuest(p.q(ctx).QueryRow(ctx, query, int(questId)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+	if err := p.loadObjectives(ctx, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func (p *PostgresRepository) FindByCampaign(ctx context.Context, campaignId id.CampaignId, includeHidden bool) ([]*Quest, error) {
+	const query = selectQuest + `
+		WHERE q.campaign_id = $1 AND ($2 OR q.status <> 'HIDDEN')
+		ORDER BY q.quest_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(campaignId), includeHidden)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quests := make([]*Quest, 0)
+	for rows.Next() {
+		q, err := p.scanQuest(rows)
+		if err != nil {
+			return nil, err
+		}
+		quests = append(quests, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, q := range quests {
+		if err := p.loadObjectives(ctx, q); err != nil {
+			return nil, err
+		}
+	}
+	return quests, nil
+}
+
+// saveObjectives deletes the objectives removed from the quest, updates the existing ones
+// and inserts the new ones, keeping the order of the checklist
+func (p *PostgresRepository) saveObjectives(ctx context.Context, q *Quest) error {
+	const sqlDeleteRemoved = `
+		DELETE FROM quest_objectives
+		WHERE quest_id = $1 AND NOT (objective_id = ANY($2))
+	`
+	const sqlInsert = `
+		INSERT INTO quest_objectives (quest_id, description, done, position)
+		VALUES ($1, $2, $3, $4)
+		RETURNING objective_id
+	`
+	const sqlUpdate = `
+		UPDATE quest_objectives
+		SET description = $1,
+		    done = $2,
+		    position = $3
+		WHERE objective_id = $4 AND quest_id = $5
+	`
+
+	kept := make([]int, 0, len(q.objectives))
+	for _, o := range q.objectives {
+		if o.id != 0 {
+			kept = append(kept, int(o.id))
+		}
+	}
+	if _, err := p.q(ctx).Exec(ctx, sqlDeleteRemoved, int(q.id), kept); err != nil {
+		return err
+	}
+
+	for i, o := range q.objectives {
+		if o.id != 0 {
+			if _, err := p.q(ctx).Exec(ctx, sqlUpdate, o.description, o.done, i, int(o.id), int(q.id)); err != nil {
+				return err
+			}
+			continue
+		}
+		var objectiveID int
+		if err := p.q(ctx).QueryRow(ctx, sqlInsert, int(q.id), o.description, o.done, i).Scan(&objectiveID); err != nil {
+			return err
+		}
+		o.id = id.ObjectiveId(objectiveID)
+	}
+	return nil
+}
+
+func (p *PostgresRepository) loadObjectives(ctx context.Context, q *Quest) error {
+	const query = `
+		SELECT objective_id, description, done
+		FROM quest_objectives
+		WHERE quest_id = $1
+		ORDER BY position, objective_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(q.id))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			o           Objective
+			objectiveID int
+		)
+		if err := rows.Scan(&objectiveID, &o.description, &o.done); err != nil {
+			return err
+		}
+		o.id = id.ObjectiveId(objectiveID)
+		q.objectives = append(q.objectives, &o)
+	}
+	return rows.Err()
+}
+
+// scanQuest translates DB row -> domain model, objectives excluded
+func (p *PostgresRepository) scanQuest(row pgx.Row) (*Quest, error) {
+	var (
+		questID     int
+		campaignID  int
+		title       string
+		description string
+		giverID     *int
+		giverName   *string
+		status      Status
+		rewardXP    int
+		itemsJSON   []byte
+		createdAt   time.Time
+		closedAt    *time.Time
+	)
+
+	if err := row.Scan(
+		&questID,
+		&campaignID,
+		&title,
+		&description,
+		&giverID,
+		&giverName,
+		&status,
+		&rewardXP,
+		&itemsJSON,
+		&createdAt,
+		&closedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	var items []rewardItem
+	if err := json.Unmarshal(itemsJSON, &items); err != nil {
+		return nil, err
+	}
+	rewards := Rewards{XP: rewardXP, Items: make([]RewardItem, len(items))}
+	for i, item := range items {
+		rewards.Items[i] = RewardItem{Name: item.Name, Quantity: item.Quantity}
+	}
+
+	q := &Quest{
+		id:          id.QuestId(questID),
+		campaignId:  id.CampaignId(campaignID),
+		title:       title,
+		description: description,
+		status:      status,
+		objectives:  make([]*Objective, 0),
+		rewards:     rewards,
+		createdAt:   createdAt,
+		closedAt:    closedAt,
+	}
+	if giverID != nil && giverName != nil {
+		q.giver = &Giver{CharacterId: id.CharacterId(*giverID), Name: *giverName}
+	}
+	return q, nil
+}
+
+func marshalRewardItems(items []RewardItem) ([]byte, error) {
+	dbItems := make([]rewardItem, len(items))
+	for i, item := range items {
+		dbItems[i] = rewardItem{Name: item.Name, Quantity: item.Quantity}
+	}
+	return json.Marshal(dbItems)
+}
+
+func giverIdArg(giver *Giver) *int {
+	if giver == nil {
+		return nil
+	}
+	gid := int(giver.CharacterId)
+	return &gid
+}
@@ -0,0 +1,243 @@
+package quest
+
+import (
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxTitleCharacters       = 100
+	MaxDescriptionCharacters = 5000
+	MaxObjectiveCharacters   = 200
+	MaxObjectives            = 50
+	MaxRewardItems           = 20
+)
+
+type Status string
+
+const (
+	// StatusHidden quest is known only by the master, until it is revealed
+	StatusHidden    Status = "HIDDEN"
+	StatusActive    Status = "ACTIVE"
+	StatusCompleted Status = "COMPLETED"
+	StatusFailed    Status = "FAILED"
+)
+
+// Objective is a step of the quest
+type Objective struct {
+	id          id.ObjectiveId
+	description string
+	done        bool
+}
+
+// RewardItem is given to the party when the quest is completed
+type RewardItem struct {
+	Name     string
+	Quantity int
+}
+
+// Rewards of the quest, experience points and items
+type Rewards struct {
+	XP    int
+	Items []RewardItem
+}
+
+func (r Rewards) validate() error {
+	if r.XP < 0 || len(r.Items) > MaxRewardItems {
+		return ErrInvalidRewards
+	}
+	for _, item := range r.Items {
+		if item.Name == "" || utf8.RuneCountInString(item.Name) > MaxTitleCharacters || item.Quantity < 1 {
+			return ErrInvalidRewards
+		}
+	}
+	return nil
+}
+
+// Giver is the NPC who gave the quest
+type Giver struct {
+	CharacterId id.CharacterId
+	Name        string
+}
+
+type Option func(*Quest) error
+
+// GivenBy links the quest to the NPC who gave it, the NPC must be a character of the campaign
+func GivenBy(npc *character.Character) Option {
+	return func(q *Quest) error {
+		return q.setGiver(npc)
+	}
+}
+
+// Revealed makes the quest visible to the players from its creation
+func Revealed() Option {
+	return func(q *Quest) error {
+		q.status = StatusActive
+		return nil
+	}
+}
+
+// Quest of a campaign, tracked by the master
+type Quest struct {
+	id          id.QuestId
+	campaignId  id.CampaignId
+	title       string
+	description string
+	// nil if there is no giver
+	giver      *Giver
+	status     Status
+	objectives []*Objective
+	rewards    Rewards
+	createdAt  time.Time
+	// nil if not completed nor failed
+	closedAt *time.Time
+}
+
+// New creates a quest of the campaign, hidden to the players unless Revealed is given
+func New(campaignId id.CampaignId, title string, description string, rewards Rewards, opt ...Option) (*Quest, error) {
+	q := &Quest{
+		campaignId: campaignId,
+		status:     StatusHidden,
+		objectives: make([]*Objective, 0),
+		createdAt:  time.Now(),
+	}
+	if err := q.Edit(title, description, rewards); err != nil {
+		return nil, err
+	}
+	for _, o := range opt {
+		if err := o(q); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// Edit replaces title, description and rewards of the quest
+func (q *Quest) Edit(title string, description string, rewards Rewards) error {
+	if title == "" || utf8.RuneCountInString(title) > MaxTitleCharacters {
+		return ErrInvalidTitle
+	}
+	if utf8.RuneCountInString(description) > MaxDescriptionCharacters {
+		return ErrInvalidDescription
+	}
+	if err := rewards.validate(); err != nil {
+		return err
+	}
+	if rewards.Items == nil {
+		rewards.Items = make([]RewardItem, 0)
+	}
+
+	q.title = title
+	q.description = description
+	q.rewards = rewards
+	return nil
+}
+
+// ChangeGiver links the quest to another NPC, or to no one if npc is nil
+func (q *Quest) ChangeGiver(npc *character.Character) error {
+	if npc == nil {
+		q.giver = nil
+		return nil
+	}
+	return q.setGiver(npc)
+}
+
+// Reveal shows the hidden quest to the players
+func (q *Quest) Reveal() error {
+	if q.status != StatusHidden {
+		return ErrQuestAlreadyRevealed
+	}
+	q.status = StatusActive
+	return nil
+}
+
+func (q *Quest) Complete() error {
+	return q.close(StatusCompleted)
+}
+
+func (q *Quest) Fail() error {
+	return q.close(StatusFailed)
+}
+
+// AddObjective appends an objective to the checklist of the quest
+func (q *Quest) AddObjective(description string) (*Objective, error) {
+	if err := validateObjective(description); err != nil {
+		return nil, err
+	}
+	if len(q.objectives) >= MaxObjectives {
+		return nil, ErrTooManyObjectives
+	}
+	o := &Objective{description: description}
+	q.objectives = append(q.objectives, o)
+	return o, nil
+}
+
+// UpdateObjective changes the description of the objective and checks it or unchecks it
+func (q *Quest) UpdateObjective(objectiveId id.ObjectiveId, description string, done bool) error {
+	o := q.objective(objectiveId)
+	if o == nil {
+		return ErrObjectiveNotFound
+	}
+	if err := validateObjective(description); err != nil {
+		return err
+	}
+	o.description = description
+	o.done = done
+	return nil
+}
+
+func (q *Quest) RemoveObjective(objectiveId id.ObjectiveId) error {
+	idx := slices.IndexFunc(q.objectives, func(o *Objective) bool { return o.id == objectiveId })
+	if idx < 0 {
+		return ErrObjectiveNotFound
+	}
+	q.objectives = slices.Delete(q.objectives, idx, idx+1)
+	return nil
+}
+
+// IsVisibleTo tells if a player of the campaign can read the quest
+func (q *Quest) IsVisibleTo(isMaster bool) bool {
+	return isMaster || q.status != StatusHidden
+}
+
+func (q *Quest) Id() id.QuestId { return q.id }
+
+func (q *Quest) CampaignId() id.CampaignId { return q.campaignId }
+
+func (q *Quest) Status() Status { return q.status }
+
+func (q *Quest) close(status Status) error {
+	if q.status != StatusActive {
+		return ErrQuestNotActive
+	}
+	now := time.Now()
+	q.status = status
+	q.closedAt = &now
+	return nil
+}
+
+func (q *Quest) setGiver(npc *character.Character) error {
+	if !npc.IsNPC() || npc.CampaignId() != q.campaignId {
+		return ErrInvalidGiver
+	}
+	q.giver = &Giver{CharacterId: npc.Id(), Name: npc.Name()}
+	return nil
+}
+
+func (q *Quest) objective(objectiveId id.ObjectiveId) *Objective {
+	idx := slices.IndexFunc(q.objectives, func(o *Objective) bool { return o.id == objectiveId })
+	if idx < 0 {
+		return nil
+	}
+	return q.objectives[idx]
+}
+
+func validateObjective(description string) error {
+	if description == "" || utf8.RuneCountInString(description) > MaxObjectiveCharacters {
+		return ErrInvalidObjective
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package quest
+
+import (
+	"beldur/internal/character"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newQuest(t *testing.T, opt ...Option) *Quest {
+	t.Helper()
+	q, err := New(1, "Rescue the blacksmith", "He was taken by goblins", Rewards{XP: 100}, opt...)
+	require.NoError(t, err)
+	return q
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		rewards Rewards
+		wantErr error
+	}{
+		{"no rewards", "Quest", Rewards{}, nil},
+		{"rewards", "Quest", Rewards{XP: 50, Items: []RewardItem{{Name: "Potion", Quantity: 2}}}, nil},
+		{"empty title", "", Rewards{}, ErrInvalidTitle},
+		{"title too long", strings.Repeat("a", MaxTitleCharacters+1), Rewards{}, ErrInvalidTitle},
+		{"negative xp", "Quest", Rewards{XP: -1}, ErrInvalidRewards},
+		{"item without name", "Quest", Rewards{Items: []RewardItem{{Quantity: 1}}}, ErrInvalidRewards},
+		{"item without quantity", "Quest", Rewards{Items: []RewardItem{{Name: "Potion"}}}, ErrInvalidRewards},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := New(1, tt.title, "", tt.rewards)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, StatusHidden, q.Status())
+			assert.NotNil(t, q.rewards.Items)
+		})
+	}
+}
+
+func TestNew_Giver(t *testing.T) {
+	// a character not persisted as NPC of the campaign
+	pc := character.New("Frodo", "")
+	_, err := New(1, "Quest", "", Rewards{}, GivenBy(pc))
+	assert.ErrorIs(t, err, ErrInvalidGiver)
+
+	q := newQuest(t)
+	assert.ErrorIs(t, q.ChangeGiver(pc), ErrInvalidGiver)
+	require.NoError(t, q.ChangeGiver(nil))
+	assert.Nil(t, q.giver)
+}
+
+func TestLifecycle(t *testing.T) {
+	q := newQuest(t)
+	assert.False(t, q.IsVisibleTo(false))
+	assert.True(t, q.IsVisibleTo(true))
+
+	assert.ErrorIs(t, q.Complete(), ErrQuestNotActive)
+
+	require.NoError(t, q.Reveal())
+	assert.Equal(t, StatusActive, q.Status())
+	assert.True(t, q.IsVisibleTo(false))
+	assert.ErrorIs(t, q.Reveal(), ErrQuestAlreadyRevealed)
+
+	require.NoError(t, q.Complete())
+	assert.Equal(t, StatusCompleted, q.Status())
+	assert.NotNil(t, q.closedAt)
+	assert.ErrorIs(t, q.Fail(), ErrQuestNotActive)
+
+	failed := newQuest(t, Revealed())
+	require.NoError(t, failed.Fail())
+	assert.Equal(t, StatusFailed, failed.Status())
+}
+
+func TestObjectives(t *testing.T) {
+	q := newQuest(t)
+
+	first, err := q.AddObjective("Find the goblin camp")
+	require.NoError(t, err)
+	first.id = 1
+	second, err := q.AddObjective("Free the blacksmith")
+	require.NoError(t, err)
+	second.id = 2
+
+	_, err = q.AddObjective("")
+	assert.ErrorIs(t, err, ErrInvalidObjective)
+
+	require.NoError(t, q.UpdateObjective(1, "Find the goblin cave", true))
+	assert.True(t, first.done)
+	assert.Equal(t, "Find the goblin cave", first.description)
+	assert.ErrorIs(t, q.UpdateObjective(3, "Missing", true), ErrObjectiveNotFound)
+
+	require.NoError(t, q.RemoveObjective(1))
+	require.Len(t, q.objectives, 1)
+	assert.Equal(t, second, q.objectives[0])
+	assert.ErrorIs(t, q.RemoveObjective(1), ErrObjectiveNotFound)
+
+	for len(q.objectives) < MaxObjectives {
+		_, err := q.AddObjective("Step")
+		require.NoError(t, err)
+	}
+	_, err = q.AddObjective("One too many")
+	assert.ErrorIs(t, err, ErrTooManyObjectives)
+}
@@ -0,0 +1,29 @@
+package quest
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"context"
+)
+
+type Saver interface {
+	Save(ctx context.Context, q *Quest) error
+	// Update persists the quest along with its objectives and rewards
+	Update(ctx context.Context, q *Quest) error
+	Delete(ctx context.Context, questId id.QuestId) error
+}
+
+type Finder interface {
+	FindById(ctx context.Context, questId id.QuestId) (*Quest, error)
+	// FindByCampaign gives back the quests of the campaign, the hidden ones only if includeHidden
+	FindByCampaign(ctx context.Context, campaignId id.CampaignId, includeHidden bool) ([]*Quest, error)
+}
+
+type CampaignFinder interface {
+	FindById(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error)
+}
+
+type CharacterFinder interface {
+	FindById(ctx context.Context, characterId id.CharacterId) (*character.Character, error)
+}
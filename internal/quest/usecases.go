@@ -0,0 +1,337 @@
+package quest
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dto"
+	"beldur/pkg/logger"
+	"context"
+	"errors"
+)
+
+type UseCase struct {
+	questSaver      Saver
+	questFinder     Finder
+	campaignFinder  CampaignFinder
+	characterFinder CharacterFinder
+	tx              tx.Transactor
+}
+
+func NewUseCase(
+	questSaver Saver,
+	questFinder Finder,
+	campaignFinder CampaignFinder,
+	characterFinder CharacterFinder,
+	tx tx.Transactor,
+) *UseCase {
+	return &UseCase{
+		questSaver:      questSaver,
+		questFinder:     questFinder,
+		campaignFinder:  campaignFinder,
+		characterFinder: characterFinder,
+		tx:              tx,
+	}
+}
+
+// Create adds a quest to the campaign, hidden to the players unless revealed.
+// Only the master of the campaign can do it.
+func (uc *UseCase) Create(ctx context.Context, req CreateQuestRequest, campaignId id.CampaignId, masterId id.PlayerId) (QuestResponse, error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return QuestResponse{}, err
+	}
+	if !camp.IsMaster(masterId) {
+		return QuestResponse{}, ErrCampaignHasAnotherMaster
+	}
+
+	var opts []Option
+	if req.GiverId != nil {
+		npc, err := uc.findCharacter(ctx, id.CharacterId(*req.GiverId))
+		if err != nil {
+			return QuestResponse{}, err
+		}
+		opts = append(opts, GivenBy(npc))
+	}
+	if req.Revealed {
+		opts = append(opts, Revealed())
+	}
+
+	q, err := New(campaignId, req.Title, req.Description, toRewards(req.Rewards), opts...)
+	if err != nil {
+		return QuestResponse{}, err
+	}
+	for _, description := range req.Objectives {
+		if _, err := q.AddObjective(description); err != nil {
+			return QuestResponse{}, err
+		}
+	}
+
+	err = uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		return uc.questSaver.Save(ctx, q)
+	})
+	if err != nil {
+		logger.Debug("failed to save quest", "campaign_id", campaignId, "error", err)
+		return QuestResponse{}, err
+	}
+	return toQuestResponse(q), nil
+}
+
+// List gives back the quests of the campaign. Every player of the campaign can read them,
+// the hidden ones are for the master only.
+func (uc *UseCase) List(ctx context.Context, campaignId id.CampaignId, playerId id.PlayerId) (dto.ListResponse[QuestResponse], error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return dto.ListResponse[QuestResponse]{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return dto.ListResponse[QuestResponse]{}, ErrPlayerNotInCampaign
+	}
+
+	quests, err := uc.questFinder.FindByCampaign(ctx, campaignId, camp.IsMaster(playerId))
+	if err != nil {
+		logger.Debug("failed to find quests", "campaign_id", campaignId, "error", err)
+		return dto.ListResponse[QuestResponse]{}, err
+	}
+
+	list := make([]QuestResponse, len(quests))
+	for i, q := range quests {
+		list[i] = toQuestResponse(q)
+	}
+	return dto.ListResponse[QuestResponse]{Data: list}, nil
+}
+
+// Get gives back the quest, if it is visible to the player
+func (uc *UseCase) Get(ctx context.Context, campaignId id.CampaignId, questId id.QuestId, playerId id.PlayerId) (QuestResponse, error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return QuestResponse{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return QuestResponse{}, ErrPlayerNotInCampaign
+	}
+
+	q, err := uc.findQuest(ctx, campaignId, questId)
+	if err != nil {
+		return QuestResponse{}, err
+	}
+	// hidden quests do not exist for the players
+	if !q.IsVisibleTo(camp.IsMaster(playerId)) {
+		return QuestResponse{}, ErrQuestNotFound
+	}
+	return toQuestResponse(q), nil
+}
+
+// Update edits the quest. Only the master of the campaign can do it.
+func (uc *UseCase) Update(ctx context.Context, req UpdateQuestRequest, campaignId id.CampaignId, questId id.QuestId, masterId id.PlayerId) (QuestResponse, error) {
+	return uc.modify(ctx, campaignId, questId, masterId, func(ctx context.Context, q *Quest) error {
+		var giver *character.Character
+		if req.GiverId != nil {
+			npc, err := uc.findCharacter(ctx, id.CharacterId(*req.GiverId))
+			if err != nil {
+				return err
+			}
+			giver = npc
+		}
+		if err := q.ChangeGiver(giver); err != nil {
+			return err
+		}
+		return q.Edit(req.Title, req.Description, toRewards(req.Rewards))
+	})
+}
+
+// Reveal shows the hidden quest to the players. Only the master of the campaign can do it.
+func (uc *UseCase) Reveal(ctx context.Context, campaignId id.CampaignId, questId id.QuestId, masterId id.PlayerId) (QuestResponse, error) {
+	return uc.modify(ctx, campaignId, questId, masterId, func(_ context.Context, q *Quest) error {
+		return q.Reveal()
+	})
+}
+
+// Complete closes the quest as completed. Only the master of the campaign can do it.
+func (uc *UseCase) Complete(ctx context.Context, campaignId id.CampaignId, questId id.QuestId, masterId id.PlayerId) (QuestResponse, error) {
+	return uc.modify(ctx, campaignId, questId, masterId, func(_ context.Context, q *Quest) error {
+		return q.Complete()
+	})
+}
+
+// Fail closes the quest as failed. Only the master of the campaign can do it.
+func (uc *UseCase) Fail(ctx context.Context, campaignId id.CampaignId, questId id.QuestId, masterId id.PlayerId) (QuestResponse, error) {
+	return uc.modify(ctx, campaignId, questId, masterId, func(_ context.Context, q *Quest) error {
+		return q.Fail()
+	})
+}
+
+// AddObjective appends an objective to the quest. Only the master of the campaign can do it.
+func (uc *UseCase) AddObjective(ctx context.Context, req AddObjectiveRequest, campaignId id.CampaignId, questId id.QuestId, masterId id.PlayerId) (QuestResponse, error) {
+	return uc.modify(ctx, campaignId, questId, masterId, func(_ context.Context, q *Quest) error {
+		_, err := q.AddObjective(req.Description)
+		return err
+	})
+}
+
+// UpdateObjective edits the objective and checks it or unchecks it. Only the master of the campaign can do it.
+func (uc *UseCase) UpdateObjective(
+	ctx context.Context,
+	req UpdateObjectiveRequest,
+	campaignId id.CampaignId,
+	questId id.QuestId,
+	objectiveId id.ObjectiveId,
+	masterId id.PlayerId,
+) (QuestResponse, error) {
+	return uc.modify(ctx, campaignId, questId, masterId, func(_ context.Context, q *Quest) error {
+		return q.UpdateObjective(objectiveId, req.Description, req.Done)
+	})
+}
+
+// RemoveObjective deletes the objective from the quest. Only the master of the campaign can do it.
+func (uc *UseCase) RemoveObjective(
+	ctx context.Context,
+	campaignId id.CampaignId,
+	questId id.QuestId,
+	objectiveId id.ObjectiveId,
+	masterId id.PlayerId,
+) (QuestResponse, error) {
+	return uc.modify(ctx, campaignId, questId, masterId, func(_ context.Context, q *Quest) error {
+		return q.RemoveObjective(objectiveId)
+	})
+}
+
+// Delete removes the quest. Only the master of the campaign can do it.
+func (uc *UseCase) Delete(ctx context.Context, campaignId id.CampaignId, questId id.QuestId, masterId id.PlayerId) error {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return err
+	}
+	if !camp.IsMaster(masterId) {
+		return ErrCampaignHasAnotherMaster
+	}
+
+	return uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		if _, err := uc.findQuest(ctx, campaignId, questId); err != nil {
+			return err
+		}
+		if err := uc.questSaver.Delete(ctx, questId); err != nil {
+			logger.Debug("failed to delete quest", "quest_id", questId, "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// modify checks the player is the master, loads the quest, applies the change and persists it
+func (uc *UseCase) modify(
+	ctx context.Context,
+	campaignId id.CampaignId,
+	questId id.QuestId,
+	masterId id.PlayerId,
+	change func(context.Context, *Quest) error,
+) (QuestResponse, error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return QuestResponse{}, err
+	}
+	if !camp.IsMaster(masterId) {
+		return QuestResponse{}, ErrCampaignHasAnotherMaster
+	}
+
+	var resp QuestResponse
+	err = uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		q, err := uc.findQuest(ctx, campaignId, questId)
+		if err != nil {
+			return err
+		}
+		if err := change(ctx, q); err != nil {
+			return err
+		}
+		if err := uc.questSaver.Update(ctx, q); err != nil {
+			logger.Debug("failed to update quest", "quest_id", questId, "error", err)
+			return err
+		}
+		resp = toQuestResponse(q)
+		return nil
+	})
+	if err != nil {
+		return QuestResponse{}, err
+	}
+	return resp, nil
+}
+
+// findQuest gives back the quest, if it belongs to the campaign
+func (uc *UseCase) findQuest(ctx context.Context, campaignId id.CampaignId, questId id.QuestId) (*Quest, error) {
+	q, err := uc.questFinder.FindById(ctx, questId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, ErrQuestNotFound
+		}
+		logger.Debug("failed to find quest", "quest_id", questId, "error", err)
+		return nil, err
+	}
+	if q.campaignId != campaignId {
+		return nil, ErrQuestNotFound
+	}
+	return q, nil
+}
+
+func (uc *UseCase) findCampaign(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		logger.Debug("no campaign found", "campaign_id", campaignId)
+		return nil, errors.Join(ErrCampaignNotFound, err)
+	}
+	return camp, nil
+}
+
+func (uc *UseCase) findCharacter(ctx context.Context, characterId id.CharacterId) (*character.Character, error) {
+	ch, err := uc.characterFinder.FindById(ctx, characterId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, ErrCharacterNotFound
+		}
+		return nil, err
+	}
+	return ch, nil
+}
+
+func toRewards(r RewardsDto) Rewards {
+	items := make([]RewardItem, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = RewardItem{Name: item.Name, Quantity: item.Quantity}
+	}
+	return Rewards{XP: r.XP, Items: items}
+}
+
+func toQuestResponse(q *Quest) QuestResponse {
+	objectives := make([]ObjectiveResponse, len(q.objectives))
+	for i, o := range q.objectives {
+		objectives[i] = ObjectiveResponse{
+			Id:          int(o.id),
+			Description: o.description,
+			Done:        o.done,
+		}
+	}
+
+	items := make([]RewardItemDto, len(q.rewards.Items))
+	for i, item := range q.rewards.Items {
+		items[i] = RewardItemDto{Name: item.Name, Quantity: item.Quantity}
+	}
+
+	var giver *GiverResponse
+	if q.giver != nil {
+		giver = &GiverResponse{CharacterId: int(q.giver.CharacterId), Name: q.giver.Name}
+	}
+
+	return QuestResponse{
+		Id:          int(q.id),
+		CampaignId:  int(q.campaignId),
+		Title:       q.title,
+		Description: q.description,
+		Giver:       giver,
+		Status:      q.status,
+		Objectives:  objectives,
+		Rewards:     RewardsDto{XP: q.rewards.XP, Items: items},
+		CreatedAt:   q.createdAt,
+		ClosedAt:    q.closedAt,
+	}
+}
@@ -0,0 +1,22 @@
+package quest
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/character"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+)
+
+type Deps struct {
+	QProvider  postgres.QuerierProvider
+	Transactor tx.Transactor
+}
+
+func NewHandlerFromDeps(deps Deps) *HttpHandler {
+	questRepo := NewPostgresRepository(deps.QProvider)
+	campaignRepo := campaign.NewPostgresRepository(deps.QProvider)
+	characterRepo := character.NewPostgresRepository(deps.QProvider)
+
+	questUC := NewUseCase(questRepo, questRepo, campaignRepo, characterRepo, deps.Transactor)
+	return NewHttpHandler(questUC)
+}
@@ -0,0 +1,81 @@
+package schedule
+
+import "time"
+
+// CreateSessionRequest needs either PlannedStart, to schedule the session directly,
+// or Slots, to open a poll among the members of the campaign.
+type CreateSessionRequest struct {
+	Title           string      `json:"title" validate:"required,max=100"`
+	DurationMinutes int         `json:"duration_minutes" validate:"required,min=15,max=1440"`
+	Location        string      `json:"location" validate:"max=500"`
+	PlannedStart    *time.Time  `json:"planned_start"`
+	Slots           []time.Time `json:"slots" validate:"max=20"`
+}
+
+type VoteRequest struct {
+	Vote Vote `json:"vote" validate:"required,oneof=AVAILABLE MAYBE NO"`
+}
+
+// ScheduleRequest closes the poll on the slot, or on the best voted slot if SlotId is nil
+type ScheduleRequest struct {
+	SlotId *int `json:"slot_id"`
+}
+
+type AttendanceRequest struct {
+	Attendance []AttendanceDto `json:"attendance" validate:"required,min=1,dive"`
+}
+
+type AttendanceDto struct {
+	PlayerId   int        `json:"player_id" validate:"required"`
+	Attendance Attendance `json:"attendance" validate:"required,oneof=PRESENT ABSENT EXCUSED"`
+}
+
+type SessionResponse struct {
+	Id              int             `json:"session_id"`
+	CampaignId      int             `json:"campaign_id"`
+	Title           string          `json:"title"`
+	PlannedStart    *time.Time      `json:"planned_start"`
+	DurationMinutes int             `json:"duration_minutes"`
+	Location        string          `json:"location"`
+	Status          Status          `json:"status"`
+	Slots           []SlotResponse  `json:"slots"`
+	Attendance      []AttendanceDto `json:"attendance"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+type SlotResponse struct {
+	Id        int       `json:"slot_id"`
+	StartsAt  time.Time `json:"starts_at"`
+	Available int       `json:"available"`
+	Maybe     int       `json:"maybe"`
+	No        int       `json:"no"`
+	Votes     []VoteDto `json:"votes"`
+	Best      bool      `json:"best"`
+}
+
+type VoteDto struct {
+	PlayerId int  `json:"player_id"`
+	Vote     Vote `json:"vote"`
+}
+
+// CalendarTokenResponse holds the secret feed token, it is shown only once
+type CalendarTokenResponse struct {
+	Token string `json:"token"`
+	Path  string `json:"path"`
+}
+
+// ExportedVote is a vote of the player on a slot of a session poll, for the export of its account
+type ExportedVote struct {
+	SessionId  int       `json:"session_id"`
+	CampaignId int       `json:"campaign_id"`
+	Title      string    `json:"title"`
+	SlotStart  time.Time `json:"slot_start"`
+	Vote       string    `json:"vote"`
+}
+
+type ExportedAttendance struct {
+	SessionId  int    `json:"session_id"`
+	CampaignId int    `json:"campaign_id"`
+	Title      string `json:"title"`
+	Attendance string `json:"attendance"`
+}
@@ -0,0 +1,131 @@
+package schedule
+
+import (
+	"beldur/pkg/httperr"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidTitle        = errors.New("invalid session title")
+	ErrInvalidDuration     = errors.New("session duration must be between 15 minutes and 24 hours")
+	ErrInvalidLocation     = errors.New("invalid session location")
+	ErrInvalidSlots        = errors.New("invalid proposed slots")
+	ErrNoDate              = errors.New("session needs either a planned start or proposed slots")
+	ErrInvalidVote         = errors.New("invalid vote")
+	ErrInvalidAttendance   = errors.New("invalid attendance")
+	ErrPollClosed          = errors.New("poll of the session is closed")
+	ErrSessionNotScheduled = errors.New("session is not scheduled")
+	ErrSessionClosed       = errors.New("session is completed or cancelled")
+)
+
+var (
+	ErrSlotNotFound             = errors.New("slot not found")
+	ErrSessionNotFound          = errors.New("session not found")
+	ErrCampaignNotFound         = errors.New("campaign not found")
+	ErrCampaignHasAnotherMaster = errors.New("campaign has another master")
+	ErrPlayerNotInCampaign      = errors.New("player is not in the campaign")
+	ErrCalendarTokenNotFound    = errors.New("calendar token not found")
+)
+
+func NewScheduleApiErrorManager() *httperr.Manager {
+	mng := httperr.NewManager()
+
+	mng.Add(ErrInvalidTitle, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_session_title",
+		Message: ErrInvalidTitle.Error(),
+	})
+
+	mng.Add(ErrInvalidDuration, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_session_duration",
+		Message: ErrInvalidDuration.Error(),
+	})
+
+	mng.Add(ErrInvalidLocation, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_session_location",
+		Message: ErrInvalidLocation.Error(),
+	})
+
+	mng.Add(ErrInvalidSlots, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_slots",
+		Message: ErrInvalidSlots.Error(),
+	})
+
+	mng.Add(ErrNoDate, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "no_date",
+		Message: ErrNoDate.Error(),
+	})
+
+	mng.Add(ErrInvalidVote, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_vote",
+		Message: ErrInvalidVote.Error(),
+	})
+
+	mng.Add(ErrInvalidAttendance, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_attendance",
+		Message: ErrInvalidAttendance.Error(),
+	})
+
+	mng.Add(ErrPollClosed, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "poll_closed",
+		Message: ErrPollClosed.Error(),
+	})
+
+	mng.Add(ErrSessionNotScheduled, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "session_not_scheduled",
+		Message: ErrSessionNotScheduled.Error(),
+	})
+
+	mng.Add(ErrSessionClosed, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "session_closed",
+		Message: ErrSessionClosed.Error(),
+	})
+
+	mng.Add(ErrSlotNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "slot_not_found",
+		Message: ErrSlotNotFound.Error(),
+	})
+
+	mng.Add(ErrSessionNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "session_not_found",
+		Message: ErrSessionNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "campaign_not_found",
+		Message: ErrCampaignNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignHasAnotherMaster, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "campaign_has_another_master",
+		Message: ErrCampaignHasAnotherMaster.Error(),
+	})
+
+	mng.Add(ErrPlayerNotInCampaign, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "player_not_in_campaign",
+		Message: ErrPlayerNotInCampaign.Error(),
+	})
+
+	mng.Add(ErrCalendarTokenNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "calendar_token_not_found",
+		Message: ErrCalendarTokenNotFound.Error(),
+	})
+
+	return mng
+}
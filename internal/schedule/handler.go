@@ -0,0 +1,258 @@
+package schedule
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/httperr"
+	"beldur/pkg/middleware"
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+type HttpHandler struct {
+	scheduleUC *UseCase
+	calendarUC *CalendarUseCase
+	errManager *httperr.Manager
+}
+
+func NewHttpHandler(scheduleUC *UseCase, calendarUC *CalendarUseCase) *HttpHandler {
+	return &HttpHandler{
+		scheduleUC: scheduleUC,
+		calendarUC: calendarUC,
+		errManager: NewScheduleApiErrorManager(),
+	}
+}
+
+func (h *HttpHandler) HandleCreateSession(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(CreateSessionRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.scheduleUC.Create(c.Context(), req, id.CampaignId(campaignId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetSessions(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.scheduleUC.List(c.Context(), id.CampaignId(campaignId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetSession(c *fiber.Ctx) error {
+	return h.handleSession(c, h.scheduleUC.Get)
+}
+
+func (h *HttpHandler) HandleVote(c *fiber.Ctx) error {
+	sessionId, err := sessionIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+	slotInstr := c.Params("slotId")
+	if slotInstr == "" {
+		panic("wrong parameter naming")
+	}
+	slotId, err := strconv.Atoi(slotInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(VoteRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.scheduleUC.Vote(c.Context(), req, sessionId, id.SlotId(slotId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleSchedule(c *fiber.Ctx) error {
+	sessionId, err := sessionIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(ScheduleRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.scheduleUC.Schedule(c.Context(), req, sessionId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleComplete(c *fiber.Ctx) error {
+	return h.handleSession(c, h.scheduleUC.Complete)
+}
+
+func (h *HttpHandler) HandleCancel(c *fiber.Ctx) error {
+	return h.handleSession(c, h.scheduleUC.Cancel)
+}
+
+func (h *HttpHandler) HandleAttendance(c *fiber.Ctx) error {
+	sessionId, err := sessionIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(AttendanceRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.scheduleUC.MarkAttendance(c.Context(), req, sessionId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleCreateCalendarToken(c *fiber.Ctx) error {
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.calendarUC.CreateFeedToken(c.Context(), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *HttpHandler) HandleRevokeCalendarToken(c *fiber.Ctx) error {
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.calendarUC.RevokeFeedTokens(c.Context(), p.PlayerID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleCalendarFeed serves the feed without authentication, the secret token identifies the player
+func (h *HttpHandler) HandleCalendarFeed(c *fiber.Ctx) error {
+	token := c.Params("token")
+	if token == "" {
+		panic("wrong parameter naming")
+	}
+
+	cal, err := h.calendarUC.Feed(c.Context(), token)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	c.Set(fiber.HeaderContentType, calendarContentType)
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	return c.Status(fiber.StatusOK).Send(cal)
+}
+
+func (h *HttpHandler) HandleSessionCalendar(c *fiber.Ctx) error {
+	sessionId, err := sessionIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	cal, err := h.calendarUC.SessionCalendar(c.Context(), sessionId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	c.Set(fiber.HeaderContentType, calendarContentType)
+	c.Attachment(fmt.Sprintf("session-%d.ics", sessionId))
+	return c.Status(fiber.StatusOK).Send(cal)
+}
+
+func (h *HttpHandler) handleSession(
+	c *fiber.Ctx,
+	action func(context.Context, id.SessionId, id.PlayerId) (SessionResponse, error),
+) error {
+	sessionId, err := sessionIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := action(c.Context(), sessionId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func sessionIdParam(c *fiber.Ctx) (id.SessionId, error) {
+	sessionInstr := c.Params("sessionId")
+	if sessionInstr == "" {
+		panic("wrong parameter naming")
+	}
+	sessionId, err := strconv.Atoi(sessionInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.SessionId(sessionId), nil
+}
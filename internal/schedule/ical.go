@@ -0,0 +1,121 @@
+package schedule
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	icalProdId = "-//Beldur//Campaign sessions//EN"
+	icalDomain = "beldur.app"
+	// RFC 5545 limits lines to 75 octets, line break excluded
+	icalMaxLineOctets = 75
+	icalTimeLayout    = "20060102T150405Z"
+)
+
+// CalendarEvent is a session along with the name of its campaign
+type CalendarEvent struct {
+	Session      *Session
+	CampaignName string
+}
+
+// WriteCalendar writes the events as an iCalendar (RFC 5545) document.
+// Sessions without a planned start are skipped. Dates are written in UTC,
+// so every calendar client shows them in the time zone of its user.
+func WriteCalendar(w io.Writer, name string, events []CalendarEvent, now time.Time) error {
+	var buf bytes.Buffer
+	line := func(content string) {
+		writeFolded(&buf, content)
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:" + icalProdId)
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeText(name))
+
+	for _, e := range events {
+		s := e.Session
+		if s.plannedStart == nil {
+			continue
+		}
+		start := s.plannedStart.UTC()
+
+		line("BEGIN:VEVENT")
+		line(fmt.Sprintf("UID:session-%d@%s", s.id, icalDomain))
+		line("DTSTAMP:" + now.UTC().Format(icalTimeLayout))
+		line("DTSTART:" + start.Format(icalTimeLayout))
+		line("DTEND:" + start.Add(s.duration).Format(icalTimeLayout))
+		line("SUMMARY:" + escapeText(fmt.Sprintf("%s: %s", e.CampaignName, s.title)))
+		if s.location != "" {
+			line("LOCATION:" + escapeText(s.location))
+			if u, ok := locationURL(s.location); ok {
+				line("URL:" + u)
+			}
+		}
+		if s.status == StatusCancelled {
+			line("STATUS:CANCELLED")
+		} else {
+			line("STATUS:CONFIRMED")
+		}
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// locationURL gives back the location when it is a plain http or https URL. The URL value is
+// not escaped, a location with spaces or control characters is left out rather than breaking
+// the lines of the calendar.
+func locationURL(location string) (string, bool) {
+	if strings.ContainsFunc(location, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) }) {
+		return "", false
+	}
+	u, err := url.Parse(location)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", false
+	}
+	return location, true
+}
+
+// escapeText escapes a TEXT value (RFC 5545, 3.3.11)
+func escapeText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// writeFolded writes the content line ended by CRLF, folding it in lines of at most
+// 75 octets. Continuation lines start with a space and never split a UTF-8 character.
+func writeFolded(buf *bytes.Buffer, content string) {
+	limit := icalMaxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		buf.WriteString(content[:cut])
+		buf.WriteString("\r\n ")
+		content = content[cut:]
+		// the leading space counts in the limit
+		limit = icalMaxLineOctets - 1
+	}
+	buf.WriteString(content)
+	buf.WriteString("\r\n")
+}
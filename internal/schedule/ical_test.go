@@ -0,0 +1,103 @@
+package schedule
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCalendar(t *testing.T, events ...CalendarEvent) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, WriteCalendar(&buf, "Sessions", events, friday))
+	return buf.String()
+}
+
+func TestWriteCalendar(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	require.NoError(t, err)
+
+	s, err := New(1, "The mines; part 2", 3*time.Hour, "https://meet.example.com/abc",
+		StartingAt(time.Date(2030, 7, 5, 21, 30, 0, 0, rome)))
+	require.NoError(t, err)
+	s.id = 7
+
+	cal := writeCalendar(t, CalendarEvent{Session: s, CampaignName: "Lost, found"})
+
+	assert.True(t, strings.HasPrefix(cal, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(cal, "END:VCALENDAR\r\n"))
+	assert.Contains(t, cal, "UID:session-7@beldur.app\r\n")
+	// CEST is UTC+2
+	assert.Contains(t, cal, "DTSTART:20300705T193000Z\r\n")
+	assert.Contains(t, cal, "DTEND:20300705T223000Z\r\n")
+	assert.Contains(t, cal, `SUMMARY:Lost\, found: The mines\; part 2`+"\r\n")
+	assert.Contains(t, cal, "URL:https://meet.example.com/abc\r\n")
+	assert.Contains(t, cal, "STATUS:CONFIRMED\r\n")
+}
+
+func TestWriteCalendar_Skip(t *testing.T) {
+	poll := newPoll(t, friday, saturday)
+
+	cancelled, err := New(1, "Session 2", 3*time.Hour, "Tavern", StartingAt(sunday))
+	require.NoError(t, err)
+	require.NoError(t, cancelled.Cancel())
+
+	cal := writeCalendar(t,
+		CalendarEvent{Session: poll, CampaignName: "A"},
+		CalendarEvent{Session: cancelled, CampaignName: "B"},
+	)
+
+	assert.Equal(t, 1, strings.Count(cal, "BEGIN:VEVENT"))
+	assert.Contains(t, cal, "STATUS:CANCELLED\r\n")
+	assert.Contains(t, cal, "LOCATION:Tavern\r\n")
+	assert.NotContains(t, cal, "URL:")
+}
+
+func TestWriteCalendar_LocationURL(t *testing.T) {
+	locations := map[string]bool{
+		"https://meet.example.com/abc":                 true,
+		"https://x\r\nBEGIN:VALARM":                    false,
+		"http://x\rATTENDEE:mailto:a@example.com":      false,
+		"https://meet.example.com/a b":                 false,
+		"javascript:alert(1)":                          false,
+		"https:///no-host":                             false,
+		"Tavern, behind the https://example.com stall": false,
+	}
+	for location, wantURL := range locations {
+		s, err := New(1, "Session", 3*time.Hour, location, StartingAt(sunday))
+		require.NoError(t, err)
+
+		cal := writeCalendar(t, CalendarEvent{Session: s, CampaignName: "A"})
+		assert.Equal(t, wantURL, strings.Contains(cal, "URL:"), location)
+		assert.NotContains(t, cal, "\r\nBEGIN:VALARM", location)
+		assert.NotContains(t, cal, "\r\nATTENDEE", location)
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a\\b\;c\,d\ne\nf`, escapeText("a\\b;c,d\r\ne\nf"))
+}
+
+func TestWriteFolded(t *testing.T) {
+	var buf bytes.Buffer
+	content := "SUMMARY:" + strings.Repeat("è", 80)
+	writeFolded(&buf, content)
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n")
+	require.Greater(t, len(lines), 1)
+
+	var unfolded strings.Builder
+	for i, l := range lines {
+		assert.LessOrEqual(t, len(l), icalMaxLineOctets)
+		if i > 0 {
+			require.True(t, strings.HasPrefix(l, " "))
+			l = l[1:]
+		}
+		unfolded.WriteString(l)
+	}
+	assert.Equal(t, content, unfolded.String())
+}
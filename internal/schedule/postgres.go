@@ -0,0 +1,409 @@
+package schedule
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresRepository struct {
+	q postgres.QuerierProvider
+}
+
+func NewPostgresRepository(q postgres.QuerierProvider) *PostgresRepository {
+	return &PostgresRepository{q: q}
+}
+
+const selectSession = `
+	SELECT
+	    session_id,
+	    campaign_id,
+	    title,
+	    planned_start,
+	    duration_minutes,
+	    location,
+	    status,
+	    created_at
+	FROM game_sessions
+`
+
+// Save inserts the session with its proposed slots. It should be called inside a transaction.
+func (p *PostgresRepository) Save(ctx context.Context, s *Session) error {
+	const query = `
+		INSERT INTO game_sessions (campaign_id, title, planned_start, duration_minutes, location, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING session_id
+	`
+	const sqlInsertSlot = `
+		INSERT INTO game_session_slots (session_id, starts_at)
+		VALUES ($1, $2)
+		RETURNING slot_id
+	`
+
+	var sessionID int
+	if err := p.q(ctx).QueryRow(ctx, query,
+		int(s.campaignId),
+		s.title,
+		s.plannedStart,
+		int(s.duration.Minutes()),
+		s.location,
+		string(s.status),
+		s.createdAt,
+	).Scan(&sessionID); err != nil {
+		return err
+	}
+	s.id = id.SessionId(sessionID)
+
+	for _, slot := range s.slots {
+		var slotID int
+		if err := p.q(ctx).QueryRow(ctx, sqlInsertSlot, sessionID, slot.start).Scan(&slotID); err != nil {
+			return err
+		}
+		slot.id = id.SlotId(slotID)
+	}
+	return nil
+}
+
+// Update persists the status of the session, its votes and its attendance.
+// Slots never change after the creation. It should be called inside a transaction.
+func (p *PostgresRepository) Update(ctx context.Context, s *Session) error {
+	const query = `
+		UPDATE game_sessions
+		SET planned_start = $1,
+		    status = $2
+		WHERE session_id = $3
+	`
+	const sqlUpsertVote = `
+		INSERT INTO game_session_votes (slot_id, campaign_id, player_id, vote)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (slot_id, player_id) DO UPDATE SET vote = EXCLUDED.vote
+	`
+	const sqlUpsertAttendance = `
+		INSERT INTO game_session_attendance (session_id, campaign_id, player_id, attendance)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (session_id, player_id) DO UPDATE SET attendance = EXCLUDED.attendance
+	`
+
+	cmd, err := p.q(ctx).Exec(ctx, query, s.plannedStart, string(s.status), int(s.id))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+
+	for _, slot := range s.slots {
+		for playerID, vote := range slot.votes {
+			if _, err := p.q(ctx).Exec(ctx, sqlUpsertVote, int(slot.id), int(s.campaignId), int(playerID), string(vote)); err != nil {
+				return err
+			}
+		}
+	}
+	for playerID, attendance := range s.attendance {
+		if _, err := p.q(ctx).Exec(ctx, sqlUpsertAttendance, int(s.id), int(s.campaignId), int(playerID), string(attendance)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PostgresRepository) FindById(ctx context.Context, sessionId id.SessionId) (*Session, error) {
+	const query = selectSession + `WHERE session_id = $1`
+
+	s, err := p.scanSession(p.q(ctx).QueryRow(ctx, query, int(sessionId)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+	if err := p.loadDetails(ctx, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// FindByCampaign gives back the sessions of the campaign, the ones still polling first,
+// then by planned start.
+func (p *PostgresRepository) FindByCampaign(ctx context.Context, campaignId id.CampaignId) ([]*Session, error) {
+	const query = selectSession + `
+		WHERE campaign_id = $1
+		ORDER BY planned_start NULLS FIRST, session_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(campaignId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]*Session, 0)
+	for rows.Next() {
+		s, err := p.scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, s := range sessions {
+		if err := p.loadDetails(ctx, s); err != nil {
+			return nil, err
+		}
+	}
+	return sessions, nil
+}
+
+// scanSession translates DB row -> domain model. Extra columns after the ones of
+// selectSession are scanned into extra.
+func (p *PostgresRepository) scanSession(row pgx.Row, extra ...any) (*Session, error) {
+	var (
+		sessionID       int
+		campaignID      int
+		title           string
+		plannedStart    *time.Time
+		durationMinutes int
+		location        string
+		status          Status
+		createdAt       time.Time
+	)
+
+	dest := []any{
+		&sessionID,
+		&campaignID,
+		&title,
+		&plannedStart,
+		&durationMinutes,
+		&location,
+		&status,
+		&createdAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		id:           id.SessionId(sessionID),
+		campaignId:   id.CampaignId(campaignID),
+		title:        title,
+		plannedStart: plannedStart,
+		duration:     time.Duration(durationMinutes) * time.Minute,
+		location:     location,
+		status:       status,
+		createdAt:    createdAt,
+		slots:        make([]*Slot, 0),
+		attendance:   make(map[id.PlayerId]Attendance),
+	}, nil
+}
+
+// loadDetails loads slots with their votes and the attendance of the session
+func (p *PostgresRepository) loadDetails(ctx context.Context, s *Session) error {
+	const sqlSlots = `
+		SELECT sl.slot_id, sl.starts_at, v.player_id, v.vote
+		FROM game_session_slots sl
+		LEFT JOIN game_session_votes v ON v.slot_id = sl.slot_id
+		WHERE sl.session_id = $1
+		ORDER BY sl.starts_at, sl.slot_id
+	`
+	const sqlAttendance = `
+		SELECT player_id, attendance
+		FROM game_session_attendance
+		WHERE session_id = $1
+	`
+
+	rows, err := p.q(ctx).Query(ctx, sqlSlots, int(s.id))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			slotID   int
+			startsAt time.Time
+			playerID *int
+			vote     *string
+		)
+		if err := rows.Scan(&slotID, &startsAt, &playerID, &vote); err != nil {
+			return err
+		}
+
+		// rows of the same slot are adjacent
+		if n := len(s.slots); n == 0 || s.slots[n-1].id != id.SlotId(slotID) {
+			s.slots = append(s.slots, &Slot{
+				id:    id.SlotId(slotID),
+				start: startsAt,
+				votes: make(map[id.PlayerId]Vote),
+			})
+		}
+		if playerID != nil && vote != nil {
+			s.slots[len(s.slots)-1].votes[id.PlayerId(*playerID)] = Vote(*vote)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	attRows, err := p.q(ctx).Query(ctx, sqlAttendance, int(s.id))
+	if err != nil {
+		return err
+	}
+	defer attRows.Close()
+
+	for attRows.Next() {
+		var (
+			playerID   int
+			attendance Attendance
+		)
+		if err := attRows.Scan(&playerID, &attendance); err != nil {
+			return err
+		}
+		s.attendance[id.PlayerId(playerID)] = attendance
+	}
+	return attRows.Err()
+}
+
+// FindCalendarEvents gives back the scheduled sessions of all the campaigns of the player
+func (p *PostgresRepository) FindCalendarEvents(ctx context.Context, playerId id.PlayerId) ([]CalendarEvent, error) {
+	const query = `
+		SELECT
+		    s.session_id,
+		    s.campaign_id,
+		    s.title,
+		    s.planned_start,
+		    s.duration_minutes,
+		    s.location,
+		    s.status,
+		    s.created_at,
+		    c.name
+		FROM game_sessions s
+		JOIN campaigns c ON c.campaign_id = s.campaign_id
+		JOIN campaigns_players cp ON cp.campaign_id = s.campaign_id
+		WHERE cp.player_id = $1 AND s.planned_start IS NOT NULL
+		ORDER BY s.planned_start, s.session_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]CalendarEvent, 0)
+	for rows.Next() {
+		var campaignName string
+		s, err := p.scanSession(rows, &campaignName)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, CalendarEvent{Session: s, CampaignName: campaignName})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// SaveCalendarToken stores the hash of a new feed token of the player, revoking the previous ones.
+// It should be called inside a transaction.
+func (p *PostgresRepository) SaveCalendarToken(ctx context.Context, playerId id.PlayerId, tokenHash string) error {
+	const query = `
+		INSERT INTO calendar_tokens (player_id, token_hash)
+		VALUES ($1, $2)
+	`
+
+	if err := p.RevokeCalendarTokens(ctx, playerId); err != nil {
+		return err
+	}
+	_, err := p.q(ctx).Exec(ctx, query, int(playerId), tokenHash)
+	return err
+}
+
+func (p *PostgresRepository) RevokeCalendarTokens(ctx context.Context, playerId id.PlayerId) error {
+	const query = `
+		UPDATE calendar_tokens
+		SET revoked_at = NOW()
+		WHERE player_id = $1 AND revoked_at IS NULL
+	`
+
+	_, err := p.q(ctx).Exec(ctx, query, int(playerId))
+	return err
+}
+
+// FindPlayerByCalendarToken gives back the owner of the token, if the token is not revoked
+func (p *PostgresRepository) FindPlayerByCalendarToken(ctx context.Context, tokenHash string) (id.PlayerId, error) {
+	const query = `
+		SELECT player_id
+		FROM calendar_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL
+	`
+
+	var playerID int
+	if err := p.q(ctx).QueryRow(ctx, query, tokenHash).Scan(&playerID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, postgres.ErrNoRowFound
+		}
+		return 0, err
+	}
+	return id.PlayerId(playerID), nil
+}
+
+func (p *PostgresRepository) FindExportVotes(ctx context.Context, playerId id.PlayerId) ([]ExportedVote, error) {
+	const query = `
+		SELECT s.session_id, s.campaign_id, s.title, sl.starts_at, v.vote
+		FROM game_session_votes v
+		JOIN game_session_slots sl ON sl.slot_id = v.slot_id
+		JOIN game_sessions s ON s.session_id = sl.session_id
+		WHERE v.player_id = $1
+		ORDER BY s.session_id, sl.starts_at
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	votes := make([]ExportedVote, 0)
+	for rows.Next() {
+		var v ExportedVote
+		if err := rows.Scan(&v.SessionId, &v.CampaignId, &v.Title, &v.SlotStart, &v.Vote); err != nil {
+			return nil, err
+		}
+		votes = append(votes, v)
+	}
+	return votes, rows.Err()
+}
+
+func (p *PostgresRepository) FindExportAttendance(ctx context.Context, playerId id.PlayerId) ([]ExportedAttendance, error) {
+	const query = `
+		SELECT s.session_id, s.campaign_id, s.title, at.attendance
+		FROM game_session_attendance at
+		JOIN game_sessions s ON s.session_id = at.session_id
+		WHERE at.player_id = $1
+		ORDER BY s.session_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attendance := make([]ExportedAttendance, 0)
+	for rows.Next() {
+		var at ExportedAttendance
+		if err := rows.Scan(&at.SessionId, &at.CampaignId, &at.Title, &at.Attendance); err != nil {
+			return nil, err
+		}
+		attendance = append(attendance, at)
+	}
+	return attendance, rows.Err()
+}
@@ -0,0 +1,37 @@
+package schedule
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"context"
+)
+
+type Saver interface {
+	Save(ctx context.Context, session *Session) error
+	Update(ctx context.Context, session *Session) error
+}
+
+type Finder interface {
+	FindById(ctx context.Context, sessionId id.SessionId) (*Session, error)
+	FindByCampaign(ctx context.Context, campaignId id.CampaignId) ([]*Session, error)
+}
+
+type CampaignFinder interface {
+	FindById(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error)
+}
+
+type CalendarFinder interface {
+	FindCalendarEvents(ctx context.Context, playerId id.PlayerId) ([]CalendarEvent, error)
+	FindPlayerByCalendarToken(ctx context.Context, tokenHash string) (id.PlayerId, error)
+}
+
+type CalendarTokenSaver interface {
+	SaveCalendarToken(ctx context.Context, playerId id.PlayerId, tokenHash string) error
+	RevokeCalendarTokens(ctx context.Context, playerId id.PlayerId) error
+}
+
+// Exporter reads the votes and the attendance of a player for the export of its account
+type Exporter interface {
+	FindExportVotes(ctx context.Context, playerId id.PlayerId) ([]ExportedVote, error)
+	FindExportAttendance(ctx context.Context, playerId id.PlayerId) ([]ExportedAttendance, error)
+}
@@ -0,0 +1,255 @@
+package schedule
+
+import (
+	"beldur/internal/id"
+	"cmp"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxTitleCharacters    = 100
+	MaxLocationCharacters = 500
+	MaxSlots              = 20
+
+	MinDuration = 15 * time.Minute
+	MaxDuration = 24 * time.Hour
+)
+
+type Status string
+
+const (
+	// StatusPolling session has no date yet, members vote the proposed slots
+	StatusPolling   Status = "POLLING"
+	StatusScheduled Status = "SCHEDULED"
+	StatusCompleted Status = "COMPLETED"
+	StatusCancelled Status = "CANCELLED"
+)
+
+type Vote string
+
+const (
+	VoteAvailable Vote = "AVAILABLE"
+	VoteMaybe     Vote = "MAYBE"
+	VoteNo        Vote = "NO"
+)
+
+type Attendance string
+
+const (
+	AttendancePresent Attendance = "PRESENT"
+	AttendanceAbsent  Attendance = "ABSENT"
+	AttendanceExcused Attendance = "EXCUSED"
+)
+
+// Slot is a date proposed for the session
+type Slot struct {
+	id    id.SlotId
+	start time.Time
+	votes map[id.PlayerId]Vote
+}
+
+func (s *Slot) count(vote Vote) int {
+	n := 0
+	for _, v := range s.votes {
+		if v == vote {
+			n++
+		}
+	}
+	return n
+}
+
+// score weights an available player twice a maybe
+func (s *Slot) score() int {
+	return 2*s.count(VoteAvailable) + s.count(VoteMaybe)
+}
+
+type Option func(*Session) error
+
+// StartingAt schedules the session directly, without a poll
+func StartingAt(start time.Time) Option {
+	return func(s *Session) error {
+		s.plannedStart = &start
+		s.status = StatusScheduled
+		return nil
+	}
+}
+
+// WithSlots opens a poll on the proposed dates
+func WithSlots(starts []time.Time) Option {
+	return func(s *Session) error {
+		if len(starts) == 0 || len(starts) > MaxSlots {
+			return ErrInvalidSlots
+		}
+		slots := make([]*Slot, 0, len(starts))
+		for _, start := range starts {
+			if slices.ContainsFunc(slots, func(sl *Slot) bool { return sl.start.Equal(start) }) {
+				return ErrInvalidSlots
+			}
+			slots = append(slots, &Slot{start: start, votes: make(map[id.PlayerId]Vote)})
+		}
+		slices.SortFunc(slots, func(a, b *Slot) int { return a.start.Compare(b.start) })
+
+		s.slots = slots
+		s.status = StatusPolling
+		return nil
+	}
+}
+
+// Session is a game session of a campaign
+type Session struct {
+	id         id.SessionId
+	campaignId id.CampaignId
+	title      string
+	// nil while polling
+	plannedStart *time.Time
+	duration     time.Duration
+	// address or link of the call
+	location   string
+	status     Status
+	createdAt  time.Time
+	slots      []*Slot
+	attendance map[id.PlayerId]Attendance
+}
+
+// New creates a session of the campaign. Either StartingAt or WithSlots must be given.
+func New(campaignId id.CampaignId, title string, duration time.Duration, location string, opt ...Option) (*Session, error) {
+	if title == "" || len(title) > MaxTitleCharacters {
+		return nil, ErrInvalidTitle
+	}
+	if duration < MinDuration || duration > MaxDuration {
+		return nil, ErrInvalidDuration
+	}
+	if utf8.RuneCountInString(location) > MaxLocationCharacters {
+		return nil, ErrInvalidLocation
+	}
+
+	s := &Session{
+		campaignId: campaignId,
+		title:      title,
+		duration:   duration,
+		location:   location,
+		createdAt:  time.Now(),
+		slots:      make([]*Slot, 0),
+		attendance: make(map[id.PlayerId]Attendance),
+	}
+	for _, o := range opt {
+		if err := o(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.status == "" {
+		return nil, ErrNoDate
+	}
+	if s.plannedStart != nil && len(s.slots) > 0 {
+		return nil, ErrNoDate
+	}
+	return s, nil
+}
+
+// Vote records the availability of the player for the slot, replacing the previous vote
+func (s *Session) Vote(playerId id.PlayerId, slotId id.SlotId, vote Vote) error {
+	if s.status != StatusPolling {
+		return ErrPollClosed
+	}
+	if vote != VoteAvailable && vote != VoteMaybe && vote != VoteNo {
+		return ErrInvalidVote
+	}
+	slot := s.slot(slotId)
+	if slot == nil {
+		return ErrSlotNotFound
+	}
+	slot.votes[playerId] = vote
+	return nil
+}
+
+// BestSlot gives back the slot with the highest score. Ties go to the slot
+// with fewer players unavailable, then to the earliest one.
+func (s *Session) BestSlot() *Slot {
+	if len(s.slots) == 0 {
+		return nil
+	}
+	return slices.MinFunc(s.slots, func(a, b *Slot) int {
+		if c := cmp.Compare(b.score(), a.score()); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.count(VoteNo), b.count(VoteNo)); c != 0 {
+			return c
+		}
+		return a.start.Compare(b.start)
+	})
+}
+
+// Schedule closes the poll on the slot, or on the best slot if slotId is nil
+func (s *Session) Schedule(slotId *id.SlotId) error {
+	if s.status != StatusPolling {
+		return ErrPollClosed
+	}
+
+	slot := s.BestSlot()
+	if slotId != nil {
+		slot = s.slot(*slotId)
+	}
+	if slot == nil {
+		return ErrSlotNotFound
+	}
+
+	start := slot.start
+	s.plannedStart = &start
+	s.status = StatusScheduled
+	return nil
+}
+
+func (s *Session) Complete() error {
+	if s.status != StatusScheduled {
+		return ErrSessionNotScheduled
+	}
+	s.status = StatusCompleted
+	return nil
+}
+
+func (s *Session) Cancel() error {
+	if s.status == StatusCompleted || s.status == StatusCancelled {
+		return ErrSessionClosed
+	}
+	s.status = StatusCancelled
+	return nil
+}
+
+// MarkAttendance records if the player took part in the session.
+// The player must be a member of the campaign, it is checked by the caller.
+func (s *Session) MarkAttendance(playerId id.PlayerId, attendance Attendance) error {
+	if s.status != StatusScheduled && s.status != StatusCompleted {
+		return ErrSessionNotScheduled
+	}
+	if attendance != AttendancePresent && attendance != AttendanceAbsent && attendance != AttendanceExcused {
+		return ErrInvalidAttendance
+	}
+	s.attendance[playerId] = attendance
+	return nil
+}
+
+func (s *Session) Id() id.SessionId { return s.id }
+
+func (s *Session) CampaignId() id.CampaignId { return s.campaignId }
+
+func (s *Session) Title() string { return s.title }
+
+func (s *Session) Status() Status { return s.status }
+
+// PlannedStart is nil while polling
+func (s *Session) PlannedStart() *time.Time { return s.plannedStart }
+
+func (s *Session) Duration() time.Duration { return s.duration }
+
+func (s *Session) Location() string { return s.location }
+
+func (s *Session) slot(slotId id.SlotId) *Slot {
+	idx := slices.IndexFunc(s.slots, func(sl *Slot) bool { return sl.id == slotId })
+	if idx < 0 {
+		return nil
+	}
+	return s.slots[idx]
+}
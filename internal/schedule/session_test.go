@@ -0,0 +1,130 @@
+package schedule
+
+import (
+	"beldur/internal/id"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	friday   = time.Date(2030, 3, 1, 20, 0, 0, 0, time.UTC)
+	saturday = friday.AddDate(0, 0, 1)
+	sunday   = friday.AddDate(0, 0, 2)
+)
+
+// newPoll creates a poll with the slots already persisted, with ids from 1
+func newPoll(t *testing.T, starts ...time.Time) *Session {
+	t.Helper()
+	s, err := New(1, "Session zero", 3*time.Hour, "https://meet.example.com/abc", WithSlots(starts))
+	require.NoError(t, err)
+	for i, sl := range s.slots {
+		sl.id = id.SlotId(i + 1)
+	}
+	return s
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		title      string
+		duration   time.Duration
+		opts       []Option
+		wantStatus Status
+		wantErr    error
+	}{
+		{"scheduled", "Session 1", 4 * time.Hour, []Option{StartingAt(friday)}, StatusScheduled, nil},
+		{"poll", "Session 1", 4 * time.Hour, []Option{WithSlots([]time.Time{friday, saturday})}, StatusPolling, nil},
+		{"no date", "Session 1", 4 * time.Hour, nil, "", ErrNoDate},
+		{"both", "Session 1", 4 * time.Hour, []Option{StartingAt(friday), WithSlots([]time.Time{saturday})}, "", ErrNoDate},
+		{"duplicated slots", "Session 1", 4 * time.Hour, []Option{WithSlots([]time.Time{friday, friday})}, "", ErrInvalidSlots},
+		{"empty title", "", 4 * time.Hour, []Option{StartingAt(friday)}, "", ErrInvalidTitle},
+		{"too short", "Session 1", 5 * time.Minute, []Option{StartingAt(friday)}, "", ErrInvalidDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(1, tt.title, tt.duration, "", tt.opts...)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, s.status)
+		})
+	}
+}
+
+func TestNewLocation(t *testing.T) {
+	// the limit is in characters, not bytes
+	_, err := New(1, "Session 1", 4*time.Hour, strings.Repeat("é", MaxLocationCharacters), StartingAt(friday))
+	require.NoError(t, err)
+	_, err = New(1, "Session 1", 4*time.Hour, strings.Repeat("é", MaxLocationCharacters+1), StartingAt(friday))
+	assert.ErrorIs(t, err, ErrInvalidLocation)
+}
+
+func TestBestSlot(t *testing.T) {
+	t.Run("highest score", func(t *testing.T) {
+		s := newPoll(t, friday, saturday, sunday)
+		require.NoError(t, s.Vote(1, 1, VoteMaybe))
+		require.NoError(t, s.Vote(2, 1, VoteMaybe))
+		require.NoError(t, s.Vote(1, 2, VoteAvailable))
+		require.NoError(t, s.Vote(2, 2, VoteAvailable))
+		require.NoError(t, s.Vote(1, 3, VoteAvailable))
+		require.NoError(t, s.Vote(2, 3, VoteNo))
+
+		assert.Equal(t, id.SlotId(2), s.BestSlot().id)
+	})
+
+	t.Run("tie goes to fewer no, then earliest", func(t *testing.T) {
+		s := newPoll(t, friday, saturday, sunday)
+		require.NoError(t, s.Vote(1, 1, VoteAvailable))
+		require.NoError(t, s.Vote(2, 1, VoteNo))
+		require.NoError(t, s.Vote(1, 2, VoteAvailable))
+		require.NoError(t, s.Vote(1, 3, VoteAvailable))
+
+		assert.Equal(t, id.SlotId(2), s.BestSlot().id)
+	})
+
+	t.Run("a vote replaces the previous one", func(t *testing.T) {
+		s := newPoll(t, friday, saturday)
+		require.NoError(t, s.Vote(1, 2, VoteAvailable))
+		require.NoError(t, s.Vote(1, 2, VoteNo))
+		assert.Equal(t, id.SlotId(1), s.BestSlot().id)
+	})
+}
+
+func TestSchedule(t *testing.T) {
+	s := newPoll(t, friday, saturday)
+	require.NoError(t, s.Vote(1, 2, VoteAvailable))
+	assert.ErrorIs(t, s.MarkAttendance(1, AttendancePresent), ErrSessionNotScheduled)
+
+	require.NoError(t, s.Schedule(nil))
+	assert.Equal(t, StatusScheduled, s.status)
+	require.NotNil(t, s.plannedStart)
+	assert.Equal(t, saturday, *s.plannedStart)
+
+	assert.ErrorIs(t, s.Vote(1, 1, VoteAvailable), ErrPollClosed)
+	assert.ErrorIs(t, s.Schedule(nil), ErrPollClosed)
+
+	require.NoError(t, s.MarkAttendance(1, AttendancePresent))
+	require.NoError(t, s.Complete())
+	require.NoError(t, s.MarkAttendance(2, AttendanceExcused))
+	assert.ErrorIs(t, s.Cancel(), ErrSessionClosed)
+	assert.Len(t, s.attendance, 2)
+}
+
+func TestScheduleChosenSlot(t *testing.T) {
+	s := newPoll(t, friday, saturday)
+	require.NoError(t, s.Vote(1, 2, VoteAvailable))
+
+	slot := id.SlotId(1)
+	require.NoError(t, s.Schedule(&slot))
+	assert.Equal(t, friday, *s.plannedStart)
+
+	unknown := id.SlotId(9)
+	assert.ErrorIs(t, newPoll(t, friday).Schedule(&unknown), ErrSlotNotFound)
+}
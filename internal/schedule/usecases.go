@@ -0,0 +1,367 @@
+package schedule
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"beldur/pkg/auth"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dto"
+	"beldur/pkg/logger"
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+type UseCase struct {
+	sessionSaver   Saver
+	sessionFinder  Finder
+	campaignFinder CampaignFinder
+	tx             tx.Transactor
+}
+
+func NewUseCase(sessionSaver Saver, sessionFinder Finder, campaignFinder CampaignFinder, tx tx.Transactor) *UseCase {
+	return &UseCase{
+		sessionSaver:   sessionSaver,
+		sessionFinder:  sessionFinder,
+		campaignFinder: campaignFinder,
+		tx:             tx,
+	}
+}
+
+// Create plans a session of the campaign, either at a fixed date or with a poll on the proposed slots.
+// Only the master of the campaign can do it.
+func (uc *UseCase) Create(ctx context.Context, req CreateSessionRequest, campaignId id.CampaignId, masterId id.PlayerId) (SessionResponse, error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return SessionResponse{}, err
+	}
+	if !camp.IsMaster(masterId) {
+		return SessionResponse{}, ErrCampaignHasAnotherMaster
+	}
+
+	var opts []Option
+	if req.PlannedStart != nil {
+		opts = append(opts, StartingAt(*req.PlannedStart))
+	}
+	if len(req.Slots) > 0 {
+		opts = append(opts, WithSlots(req.Slots))
+	}
+
+	s, err := New(campaignId, req.Title, time.Duration(req.DurationMinutes)*time.Minute, req.Location, opts...)
+	if err != nil {
+		return SessionResponse{}, err
+	}
+
+	err = uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		return uc.sessionSaver.Save(ctx, s)
+	})
+	if err != nil {
+		logger.Debug("failed to save session", "campaign_id", campaignId, "error", err)
+		return SessionResponse{}, err
+	}
+	return toSessionResponse(s), nil
+}
+
+// List gives back the sessions of the campaign. Every player of the campaign can read them.
+func (uc *UseCase) List(ctx context.Context, campaignId id.CampaignId, playerId id.PlayerId) (dto.ListResponse[SessionResponse], error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return dto.ListResponse[SessionResponse]{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return dto.ListResponse[SessionResponse]{}, ErrPlayerNotInCampaign
+	}
+
+	sessions, err := uc.sessionFinder.FindByCampaign(ctx, campaignId)
+	if err != nil {
+		logger.Debug("failed to find sessions", "campaign_id", campaignId, "error", err)
+		return dto.ListResponse[SessionResponse]{}, err
+	}
+
+	list := make([]SessionResponse, len(sessions))
+	for i, s := range sessions {
+		list[i] = toSessionResponse(s)
+	}
+	return dto.ListResponse[SessionResponse]{Data: list}, nil
+}
+
+// Get gives back the session. Every player of the campaign can read it.
+func (uc *UseCase) Get(ctx context.Context, sessionId id.SessionId, playerId id.PlayerId) (SessionResponse, error) {
+	s, camp, err := uc.findSession(ctx, sessionId)
+	if err != nil {
+		return SessionResponse{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return SessionResponse{}, ErrPlayerNotInCampaign
+	}
+	return toSessionResponse(s), nil
+}
+
+// Vote records the availability of a player of the campaign for a proposed slot
+func (uc *UseCase) Vote(ctx context.Context, req VoteRequest, sessionId id.SessionId, slotId id.SlotId, playerId id.PlayerId) (SessionResponse, error) {
+	return uc.modify(ctx, sessionId, func(s *Session, camp *campaign.Campaign) error {
+		if !camp.HasPlayer(playerId) {
+			return ErrPlayerNotInCampaign
+		}
+		return s.Vote(playerId, slotId, req.Vote)
+	})
+}
+
+// Schedule closes the poll on the chosen slot, or on the best voted one.
+// Only the master of the campaign can do it.
+func (uc *UseCase) Schedule(ctx context.Context, req ScheduleRequest, sessionId id.SessionId, masterId id.PlayerId) (SessionResponse, error) {
+	return uc.modify(ctx, sessionId, func(s *Session, camp *campaign.Campaign) error {
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		var slotId *id.SlotId
+		if req.SlotId != nil {
+			sid := id.SlotId(*req.SlotId)
+			slotId = &sid
+		}
+		return s.Schedule(slotId)
+	})
+}
+
+// Complete marks the session as played. Only the master of the campaign can do it.
+func (uc *UseCase) Complete(ctx context.Context, sessionId id.SessionId, masterId id.PlayerId) (SessionResponse, error) {
+	return uc.modify(ctx, sessionId, func(s *Session, camp *campaign.Campaign) error {
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		return s.Complete()
+	})
+}
+
+// Cancel calls the session off. Only the master of the campaign can do it.
+func (uc *UseCase) Cancel(ctx context.Context, sessionId id.SessionId, masterId id.PlayerId) (SessionResponse, error) {
+	return uc.modify(ctx, sessionId, func(s *Session, camp *campaign.Campaign) error {
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		return s.Cancel()
+	})
+}
+
+// MarkAttendance records who took part in the session. Every player must be a member
+// of the campaign. Only the master of the campaign can do it.
+func (uc *UseCase) MarkAttendance(ctx context.Context, req AttendanceRequest, sessionId id.SessionId, masterId id.PlayerId) (SessionResponse, error) {
+	return uc.modify(ctx, sessionId, func(s *Session, camp *campaign.Campaign) error {
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		for _, a := range req.Attendance {
+			playerId := id.PlayerId(a.PlayerId)
+			if !camp.HasPlayer(playerId) {
+				return ErrPlayerNotInCampaign
+			}
+			if err := s.MarkAttendance(playerId, a.Attendance); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// modify loads the session with its campaign, applies the change and persists it
+func (uc *UseCase) modify(ctx context.Context, sessionId id.SessionId, change func(*Session, *campaign.Campaign) error) (SessionResponse, error) {
+	var resp SessionResponse
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		s, camp, err := uc.findSession(ctx, sessionId)
+		if err != nil {
+			return err
+		}
+		if err := change(s, camp); err != nil {
+			return err
+		}
+		if err := uc.sessionSaver.Update(ctx, s); err != nil {
+			logger.Debug("failed to update session", "session_id", sessionId, "error", err)
+			return err
+		}
+		resp = toSessionResponse(s)
+		return nil
+	})
+	if err != nil {
+		return SessionResponse{}, err
+	}
+	return resp, nil
+}
+
+func (uc *UseCase) findSession(ctx context.Context, sessionId id.SessionId) (*Session, *campaign.Campaign, error) {
+	s, err := uc.sessionFinder.FindById(ctx, sessionId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, nil, ErrSessionNotFound
+		}
+		logger.Debug("failed to find session", "session_id", sessionId, "error", err)
+		return nil, nil, err
+	}
+
+	camp, err := uc.findCampaign(ctx, s.campaignId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, camp, nil
+}
+
+func (uc *UseCase) findCampaign(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		logger.Debug("no campaign found", "campaign_id", campaignId)
+		return nil, errors.Join(ErrCampaignNotFound, err)
+	}
+	return camp, nil
+}
+
+func toSessionResponse(s *Session) SessionResponse {
+	best := s.BestSlot()
+
+	slots := make([]SlotResponse, len(s.slots))
+	for i, sl := range s.slots {
+		votes := make([]VoteDto, 0, len(sl.votes))
+		for playerID, vote := range sl.votes {
+			votes = append(votes, VoteDto{PlayerId: int(playerID), Vote: vote})
+		}
+		slices.SortFunc(votes, func(a, b VoteDto) int { return cmp.Compare(a.PlayerId, b.PlayerId) })
+
+		slots[i] = SlotResponse{
+			Id:        int(sl.id),
+			StartsAt:  sl.start,
+			Available: sl.count(VoteAvailable),
+			Maybe:     sl.count(VoteMaybe),
+			No:        sl.count(VoteNo),
+			Votes:     votes,
+			Best:      sl == best,
+		}
+	}
+
+	attendance := make([]AttendanceDto, 0, len(s.attendance))
+	for playerID, a := range s.attendance {
+		attendance = append(attendance, AttendanceDto{PlayerId: int(playerID), Attendance: a})
+	}
+	slices.SortFunc(attendance, func(a, b AttendanceDto) int { return cmp.Compare(a.PlayerId, b.PlayerId) })
+
+	return SessionResponse{
+		Id:              int(s.id),
+		CampaignId:      int(s.campaignId),
+		Title:           s.title,
+		PlannedStart:    s.plannedStart,
+		DurationMinutes: int(s.duration.Minutes()),
+		Location:        s.location,
+		Status:          s.status,
+		Slots:           slots,
+		Attendance:      attendance,
+		CreatedAt:       s.createdAt,
+	}
+}
+
+// CalendarUseCase exports the sessions in the iCalendar format, either as a feed
+// of all the campaigns of the player, reachable with a secret token, or one session at a time.
+type CalendarUseCase struct {
+	sessionFinder  Finder
+	campaignFinder CampaignFinder
+	calendarFinder CalendarFinder
+	tokenSaver     CalendarTokenSaver
+	tx             tx.Transactor
+}
+
+func NewCalendarUseCase(
+	sessionFinder Finder,
+	campaignFinder CampaignFinder,
+	calendarFinder CalendarFinder,
+	tokenSaver CalendarTokenSaver,
+	tx tx.Transactor,
+) *CalendarUseCase {
+	return &CalendarUseCase{
+		sessionFinder:  sessionFinder,
+		campaignFinder: campaignFinder,
+		calendarFinder: calendarFinder,
+		tokenSaver:     tokenSaver,
+		tx:             tx,
+	}
+}
+
+// CreateFeedToken gives a new secret token for the feed of the player.
+// The previous tokens stop working.
+func (uc *CalendarUseCase) CreateFeedToken(ctx context.Context, playerId id.PlayerId) (CalendarTokenResponse, error) {
+	plain, hash := auth.NewSecretToken()
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		return uc.tokenSaver.SaveCalendarToken(ctx, playerId, hash)
+	})
+	if err != nil {
+		logger.Debug("failed to save calendar token", "player_id", playerId, "error", err)
+		return CalendarTokenResponse{}, err
+	}
+	return CalendarTokenResponse{
+		Token: plain,
+		Path:  fmt.Sprintf("/calendar/%s.ics", plain),
+	}, nil
+}
+
+// RevokeFeedTokens disables the feed of the player until a new token is created
+func (uc *CalendarUseCase) RevokeFeedTokens(ctx context.Context, playerId id.PlayerId) error {
+	if err := uc.tokenSaver.RevokeCalendarTokens(ctx, playerId); err != nil {
+		logger.Debug("failed to revoke calendar tokens", "player_id", playerId, "error", err)
+		return err
+	}
+	return nil
+}
+
+// Feed gives back the calendar with the sessions of all the campaigns of the owner of the token
+func (uc *CalendarUseCase) Feed(ctx context.Context, token string) ([]byte, error) {
+	playerId, err := uc.calendarFinder.FindPlayerByCalendarToken(ctx, auth.HashSecretToken(token))
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, ErrCalendarTokenNotFound
+		}
+		return nil, err
+	}
+
+	events, err := uc.calendarFinder.FindCalendarEvents(ctx, playerId)
+	if err != nil {
+		logger.Debug("failed to find calendar events", "player_id", playerId, "error", err)
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCalendar(&buf, "Beldur sessions", events, time.Now()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SessionCalendar gives back the calendar with the single session.
+// Every player of the campaign can download it, once the session is scheduled.
+func (uc *CalendarUseCase) SessionCalendar(ctx context.Context, sessionId id.SessionId, playerId id.PlayerId) ([]byte, error) {
+	s, err := uc.sessionFinder.FindById(ctx, sessionId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	camp, err := uc.campaignFinder.FindById(ctx, s.campaignId)
+	if err != nil {
+		return nil, errors.Join(ErrCampaignNotFound, err)
+	}
+	if !camp.HasPlayer(playerId) {
+		return nil, ErrPlayerNotInCampaign
+	}
+	if s.plannedStart == nil {
+		return nil, ErrSessionNotScheduled
+	}
+
+	var buf bytes.Buffer
+	events := []CalendarEvent{{Session: s, CampaignName: camp.Name()}}
+	if err := WriteCalendar(&buf, camp.Name(), events, time.Now()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,21 @@
+package schedule
+
+import (
+	"beldur/internal/campaign"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+)
+
+type Deps struct {
+	QProvider  postgres.QuerierProvider
+	Transactor tx.Transactor
+}
+
+func NewHandlerFromDeps(deps Deps) *HttpHandler {
+	sessionRepo := NewPostgresRepository(deps.QProvider)
+	campaignRepo := campaign.NewPostgresRepository(deps.QProvider)
+
+	scheduleUC := NewUseCase(sessionRepo, sessionRepo, campaignRepo, deps.Transactor)
+	calendarUC := NewCalendarUseCase(sessionRepo, campaignRepo, sessionRepo, sessionRepo, deps.Transactor)
+	return NewHttpHandler(scheduleUC, calendarUC)
+}
@@ -0,0 +1,82 @@
+package wiki
+
+import "time"
+
+type CreateEntryRequest struct {
+	Title    string   `json:"title" validate:"required,max=100"`
+	Category Category `json:"category" validate:"required"`
+	Body     string   `json:"body" validate:"max=50000"`
+}
+
+type UpdateEntryRequest struct {
+	Title    string   `json:"title" validate:"required,max=100"`
+	Category Category `json:"category" validate:"required"`
+	Body     string   `json:"body" validate:"max=50000"`
+}
+
+type LinkKind string
+
+const (
+	LinkEntry     LinkKind = "ENTRY"
+	LinkCharacter LinkKind = "CHARACTER"
+	// LinkMissing the target does not exist yet
+	LinkMissing LinkKind = "MISSING"
+)
+
+type EntryResponse struct {
+	Id         int            `json:"entry_id"`
+	CampaignId int            `json:"campaign_id"`
+	Title      string         `json:"title"`
+	Category   Category       `json:"category"`
+	Body       string         `json:"body"`
+	Links      []LinkResponse `json:"links"`
+	Backlinks  []EntrySummary `json:"backlinks"`
+	AuthorId   int            `json:"author_id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// LinkResponse is a [[link]] of the body resolved to an entry or a character of the campaign
+type LinkResponse struct {
+	Label       string   `json:"label"`
+	Target      string   `json:"target"`
+	Kind        LinkKind `json:"kind"`
+	EntryId     *int     `json:"entry_id,omitempty"`
+	CharacterId *int     `json:"character_id,omitempty"`
+}
+
+type EntrySummary struct {
+	Id        int       `json:"entry_id"`
+	Title     string    `json:"title"`
+	Category  Category  `json:"category"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type RevisionResponse struct {
+	Id        int       `json:"revision_id"`
+	Title     string    `json:"title"`
+	Category  Category  `json:"category"`
+	Body      string    `json:"body"`
+	AuthorId  int       `json:"author_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportedEntry is a wiki entry written by the player, for the export of its account
+type ExportedEntry struct {
+	EntryId    int       `json:"entry_id"`
+	CampaignId int       `json:"campaign_id"`
+	Title      string    `json:"title"`
+	Category   string    `json:"category"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ExportedRevision struct {
+	RevisionId int       `json:"revision_id"`
+	EntryId    int       `json:"entry_id"`
+	Title      string    `json:"title"`
+	Category   string    `json:"category"`
+	Body       string    `json:"body"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,127 @@
+package wiki
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/markdown"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxTitleCharacters = 100
+	MaxBodyCharacters  = 50000
+)
+
+type Category string
+
+const (
+	CategoryPlace   Category = "PLACE"
+	CategoryFaction Category = "FACTION"
+	CategoryDeity   Category = "DEITY"
+	CategoryPerson  Category = "PERSON"
+	CategoryItem    Category = "ITEM"
+	CategoryEvent   Category = "EVENT"
+	CategoryOther   Category = "OTHER"
+)
+
+var AllCategories = []Category{
+	CategoryPlace,
+	CategoryFaction,
+	CategoryDeity,
+	CategoryPerson,
+	CategoryItem,
+	CategoryEvent,
+	CategoryOther,
+}
+
+func (c Category) Validate() error {
+	for _, valid := range AllCategories {
+		if c == valid {
+			return nil
+		}
+	}
+	return ErrInvalidCategory
+}
+
+// Entry is a page of the wiki of a campaign. The body is sanitized markdown, with [[link]]
+// cross-references and secret sections read only by the master.
+type Entry struct {
+	id         id.WikiEntryId
+	campaignId id.CampaignId
+	title      string
+	category   Category
+	body       string
+	// body without the secret sections, the one read by the players
+	publicBody string
+	links      []Link
+	authorId   id.PlayerId
+	createdAt  time.Time
+	updatedAt  time.Time
+}
+
+func New(campaignId id.CampaignId, title string, category Category, body string, authorId id.PlayerId) (*Entry, error) {
+	now := time.Now()
+	e := &Entry{
+		campaignId: campaignId,
+		createdAt:  now,
+	}
+	if err := e.set(title, category, body, authorId, now); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Edit replaces the content of the entry, the repository keeps the previous one as a revision
+func (e *Entry) Edit(title string, category Category, body string, authorId id.PlayerId) error {
+	return e.set(title, category, body, authorId, time.Now())
+}
+
+// BodyFor gives back the body readable by the player
+func (e *Entry) BodyFor(isMaster bool) string {
+	if isMaster {
+		return e.body
+	}
+	return e.publicBody
+}
+
+// LinksFor gives back the cross-references readable by the player
+func (e *Entry) LinksFor(isMaster bool) []Link {
+	links := make([]Link, 0, len(e.links))
+	for _, l := range e.links {
+		if isMaster || !l.Secret {
+			links = append(links, l)
+		}
+	}
+	return links
+}
+
+func (e *Entry) Id() id.WikiEntryId { return e.id }
+
+func (e *Entry) CampaignId() id.CampaignId { return e.campaignId }
+
+func (e *Entry) Title() string { return e.title }
+
+func (e *Entry) set(title string, category Category, body string, authorId id.PlayerId, at time.Time) error {
+	// links match titles case-insensitively with collapsed whitespace, see NormalizeTarget
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" || utf8.RuneCountInString(title) > MaxTitleCharacters || strings.ContainsAny(title, "[]|") {
+		return ErrInvalidTitle
+	}
+	if err := category.Validate(); err != nil {
+		return err
+	}
+	body = markdown.Sanitize(body)
+	if utf8.RuneCountInString(body) > MaxBodyCharacters {
+		return ErrInvalidBody
+	}
+
+	e.title = title
+	e.category = category
+	e.body = body
+	e.publicBody = publicBody(body)
+	e.links = parseLinks(body)
+	e.authorId = authorId
+	e.updatedAt = at
+	return nil
+}
@@ -0,0 +1,93 @@
+package wiki
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lore = `The [[Sunken City|old capital]] lies under the lake.
+:::secret
+The [[Cult of Dagon]] still meets in the [[sunken  city]].
+:::
+Pilgrims pray to [[Dagon]] and to [[DAGON|the deep one]].
+`
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		category Category
+		body     string
+		wantErr  error
+	}{
+		{"valid", "Sunken City", CategoryPlace, lore, nil},
+		{"empty title", "  ", CategoryPlace, "", ErrInvalidTitle},
+		{"title with link syntax", "[[Sunken City]]", CategoryPlace, "", ErrInvalidTitle},
+		{"title too long", strings.Repeat("a", MaxTitleCharacters+1), CategoryPlace, "", ErrInvalidTitle},
+		{"unknown category", "Sunken City", "CITY", "", ErrInvalidCategory},
+		{"body too long", "Sunken City", CategoryPlace, strings.Repeat("a", MaxBodyCharacters+1), ErrInvalidBody},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(1, tt.title, tt.category, tt.body, 1)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTitleWhitespaceIsCollapsed(t *testing.T) {
+	e, err := New(1, "  Sunken   City ", CategoryPlace, "", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Sunken City", e.Title())
+}
+
+func TestBodyFor(t *testing.T) {
+	e, err := New(1, "Sunken City", CategoryPlace, lore, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, lore, e.BodyFor(true))
+	player := e.BodyFor(false)
+	assert.NotContains(t, player, "Cult of Dagon")
+	assert.NotContains(t, player, ":::")
+	assert.Contains(t, player, "Pilgrims pray")
+}
+
+func TestPublicBodyUnclosedSection(t *testing.T) {
+	body := "visible\n:::secret\nhidden\nstill hidden\n"
+	assert.Equal(t, "visible\n", publicBody(body))
+}
+
+func TestLinksFor(t *testing.T) {
+	e, err := New(1, "Sunken City", CategoryPlace, lore, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Link{
+		{Target: "sunken city", Label: "old capital"},
+		{Target: "cult of dagon", Label: "Cult of Dagon", Secret: true},
+		{Target: "dagon", Label: "Dagon"},
+	}, e.LinksFor(true))
+
+	assert.Equal(t, []Link{
+		{Target: "sunken city", Label: "old capital"},
+		{Target: "dagon", Label: "Dagon"},
+	}, e.LinksFor(false))
+}
+
+func TestLinkInBothSectionsIsPublic(t *testing.T) {
+	links := parseLinks(":::secret\n[[Dagon]]\n:::\n[[dagon]]\n")
+	require.Len(t, links, 1)
+	assert.False(t, links[0].Secret)
+
+	links = parseLinks(":::secret\n[[Castle|the traitor's hideout]]\n:::\n[[Castle]]\n")
+	require.Len(t, links, 1)
+	assert.False(t, links[0].Secret)
+	assert.Equal(t, "Castle", links[0].Label)
+}
@@ -0,0 +1,75 @@
+package wiki
+
+import (
+	"beldur/pkg/httperr"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrInvalidTitle    = errors.New("wiki entry title must be between 1 and 100 characters, without [ ] and |")
+	ErrInvalidCategory = errors.New("invalid wiki category")
+	ErrInvalidBody     = errors.New("wiki entry body is too long")
+)
+
+var (
+	ErrEntryNotFound            = errors.New("wiki entry not found")
+	ErrDuplicateTitle           = errors.New("campaign already has a wiki entry with this title")
+	ErrCampaignNotFound         = errors.New("campaign not found")
+	ErrCampaignHasAnotherMaster = errors.New("campaign has another master")
+	ErrPlayerNotInCampaign      = errors.New("player is not in the campaign")
+)
+
+func NewWikiApiErrorManager() *httperr.Manager {
+	mng := httperr.NewManager()
+
+	mng.Add(ErrInvalidTitle, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_wiki_title",
+		Message: ErrInvalidTitle.Error(),
+	})
+
+	mng.Add(ErrInvalidCategory, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_wiki_category",
+		Message: ErrInvalidCategory.Error(),
+	})
+
+	mng.Add(ErrInvalidBody, httperr.Mapped{
+		Status:  http.StatusBadRequest,
+		Code:    "invalid_wiki_body",
+		Message: ErrInvalidBody.Error(),
+	})
+
+	mng.Add(ErrEntryNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "wiki_entry_not_found",
+		Message: ErrEntryNotFound.Error(),
+	})
+
+	mng.Add(ErrDuplicateTitle, httperr.Mapped{
+		Status:  http.StatusConflict,
+		Code:    "duplicate_wiki_title",
+		Message: ErrDuplicateTitle.Error(),
+	})
+
+	mng.Add(ErrCampaignNotFound, httperr.Mapped{
+		Status:  http.StatusNotFound,
+		Code:    "campaign_not_found",
+		Message: ErrCampaignNotFound.Error(),
+	})
+
+	mng.Add(ErrCampaignHasAnotherMaster, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "campaign_has_another_master",
+		Message: ErrCampaignHasAnotherMaster.Error(),
+	})
+
+	mng.Add(ErrPlayerNotInCampaign, httperr.Mapped{
+		Status:  http.StatusForbidden,
+		Code:    "player_not_in_campaign",
+		Message: ErrPlayerNotInCampaign.Error(),
+	})
+
+	return mng
+}
@@ -0,0 +1,170 @@
+package wiki
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/dto"
+	"beldur/pkg/httperr"
+	"beldur/pkg/middleware"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HttpHandler struct {
+	wikiUC     *UseCase
+	errManager *httperr.Manager
+}
+
+func NewHttpHandler(wikiUC *UseCase) *HttpHandler {
+	return &HttpHandler{
+		wikiUC:     wikiUC,
+		errManager: NewWikiApiErrorManager(),
+	}
+}
+
+func (h *HttpHandler) HandleCreateEntry(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(CreateEntryRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.wikiUC.Create(c.Context(), req, id.CampaignId(campaignId), p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// HandleSearch accepts the query parameters q, category, limit and offset
+func (h *HttpHandler) HandleSearch(c *fiber.Ctx) error {
+	campaignInstr := c.Params("campaignId")
+	if campaignInstr == "" {
+		panic("wrong parameter naming")
+	}
+	campaignId, err := strconv.Atoi(campaignInstr)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	filter := SearchFilter{Query: c.Query("q")}
+	if category := Category(c.Query("category")); category != "" {
+		if category.Validate() != nil {
+			return c.SendStatus(fiber.StatusBadRequest)
+		}
+		filter.Category = &category
+	}
+	page := dto.NewPage(c.QueryInt("limit"), c.QueryInt("offset"))
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.wikiUC.Search(c.Context(), id.CampaignId(campaignId), filter, page, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleGetEntry(c *fiber.Ctx) error {
+	entryId, err := entryIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.wikiUC.Get(c.Context(), entryId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleUpdateEntry(c *fiber.Ctx) error {
+	entryId, err := entryIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	req := c.Locals("body").(UpdateEntryRequest)
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.wikiUC.Update(c.Context(), req, entryId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func (h *HttpHandler) HandleDeleteEntry(c *fiber.Ctx) error {
+	entryId, err := entryIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	if err := h.wikiUC.Delete(c.Context(), entryId, p.PlayerID); err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *HttpHandler) HandleRevisions(c *fiber.Ctx) error {
+	entryId, err := entryIdParam(c)
+	if err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	p, ok := middleware.PrincipalFromCtx(c)
+	if !ok {
+		return c.SendStatus(fiber.StatusUnauthorized)
+	}
+
+	resp, err := h.wikiUC.Revisions(c.Context(), entryId, p.PlayerID)
+	if err != nil {
+		status, body := h.errManager.Map(err)
+		return c.Status(status).JSON(body)
+	}
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+func entryIdParam(c *fiber.Ctx) (id.WikiEntryId, error) {
+	entryInstr := c.Params("entryId")
+	if entryInstr == "" {
+		panic("wrong parameter naming")
+	}
+	entryId, err := strconv.Atoi(entryInstr)
+	if err != nil {
+		return 0, err
+	}
+	return id.WikiEntryId(entryId), nil
+}
@@ -0,0 +1,104 @@
+package wiki
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// a secret section starts with a line ":::secret" and ends with a line ":::"
+	secretOpen  = ":::secret"
+	secretClose = ":::"
+)
+
+// linkPattern matches [[Target]] and [[Target|label]]
+var linkPattern = regexp.MustCompile(`\[\[([^\[\]|]+)(?:\|([^\[\]]+))?\]\]`)
+
+// Link is a [[link]] cross-reference found in a body
+type Link struct {
+	// normalized title of the linked entry or name of the linked character
+	Target string
+	// text shown in place of the link
+	Label string
+	// the link is inside a secret section
+	Secret bool
+}
+
+// NormalizeTarget makes link targets and titles comparable, ignoring case and surrounding spaces
+func NormalizeTarget(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// publicBody removes the secret sections, with their markers. A section never closed
+// is secret up to the end of the body.
+func publicBody(body string) string {
+	var (
+		out    strings.Builder
+		secret bool
+	)
+	for line := range strings.Lines(body) {
+		marker := strings.TrimSpace(line)
+		switch {
+		case !secret && marker == secretOpen:
+			secret = true
+		case secret && marker == secretClose:
+			secret = false
+		case !secret:
+			out.WriteString(line)
+		}
+	}
+	return out.String()
+}
+
+// parseLinks gives back the links of the body, once per target. A target linked
+// both in public and in secret sections counts as public, with the label of its
+// first public link.
+func parseLinks(body string) []Link {
+	links := make([]Link, 0)
+	seen := make(map[string]int)
+
+	add := func(text string, secret bool) {
+		for _, m := range linkPattern.FindAllStringSubmatch(text, -1) {
+			target := NormalizeTarget(m[1])
+			if target == "" {
+				continue
+			}
+			label := strings.TrimSpace(m[2])
+			if label == "" {
+				label = strings.TrimSpace(m[1])
+			}
+			if i, ok := seen[target]; ok {
+				// the secret label must not be shown to the players
+				if links[i].Secret && !secret {
+					links[i].Secret = false
+					links[i].Label = label
+				}
+				continue
+			}
+			seen[target] = len(links)
+			links = append(links, Link{Target: target, Label: label, Secret: secret})
+		}
+	}
+
+	var (
+		section strings.Builder
+		secret  bool
+	)
+	for line := range strings.Lines(body) {
+		marker := strings.TrimSpace(line)
+		switch {
+		case !secret && marker == secretOpen:
+			add(section.String(), false)
+			section.Reset()
+			secret = true
+		case secret && marker == secretClose:
+			add(section.String(), true)
+			section.Reset()
+			secret = false
+		default:
+			section.WriteString(line)
+		}
+	}
+	add(section.String(), secret)
+	return links
+}
@@ -0,0 +1,423 @@
+package wiki
+
+import (
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/dto"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type PostgresRepository struct {
+	q postgres.QuerierProvider
+}
+
+func NewPostgresRepository(q postgres.QuerierProvider) *PostgresRepository {
+	return &PostgresRepository{q: q}
+}
+
+const entryColumns = `
+	entry_id,
+	campaign_id,
+	title,
+	category,
+	body,
+	public_body,
+	author_id,
+	created_at,
+	updated_at
+`
+
+const selectEntry = `SELECT ` + entryColumns + ` FROM wiki_entries `
+
+// Save should be called inside a transaction
+func (p *PostgresRepository) Save(ctx context.Context, e *Entry) error {
+	const query = `
+		INSERT INTO wiki_entries (campaign_id, title, category, body, public_body, author_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING entry_id
+	`
+
+	var entryID int
+	if err := p.q(ctx).QueryRow(ctx, query,
+		int(e.campaignId),
+		e.title,
+		string(e.category),
+		e.body,
+		e.publicBody,
+		int(e.authorId),
+		e.createdAt,
+		e.updatedAt,
+	).Scan(&entryID); err != nil {
+		return uniqueViolation(err)
+	}
+	e.id = id.WikiEntryId(entryID)
+
+	if err := p.saveLinks(ctx, e); err != nil {
+		return err
+	}
+	return p.saveRevision(ctx, e)
+}
+
+// Update should be called inside a transaction
+func (p *PostgresRepository) Update(ctx context.Context, e *Entry) error {
+	const query = `
+		UPDATE wiki_entries
+		SET title = $1,
+		    category = $2,
+		    body = $3,
+		    public_body = $4,
+		    author_id = $5,
+		    updated_at = $6
+		WHERE entry_id = $7
+	`
+
+	cmd, err := p.q(ctx).Exec(ctx, query,
+		e.title,
+		string(e.category),
+		e.body,
+		e.publicBody,
+		int(e.authorId),
+		e.updatedAt,
+		int(e.id),
+	)
+	if err != nil {
+		return uniqueViolation(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+
+	if err := p.saveLinks(ctx, e); err != nil {
+		return err
+	}
+	return p.saveRevision(ctx, e)
+}
+
+func (p *PostgresRepository) Delete(ctx context.Context, entryId id.WikiEntryId) error {
+	const query = `DELETE FROM wiki_entries WHERE entry_id = $1`
+
+	cmd, err := p.q(ctx).Exec(ctx, query, int(entryId))
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return postgres.ErrNoRowUpdated
+	}
+	return nil
+}
+
+func (p *PostgresRepository) FindById(ctx context.Context, entryId id.WikiEntryId) (*Entry, error) {
+	const query = selectEntry + `WHERE entry_id = $1`
+
+	e, err := p.scanEntry(p.q(ctx).QueryRow(ctx, query, int(entryId)))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, postgres.ErrNoRowFound
+		}
+		return nil, err
+	}
+	return e, nil
+}
+
+func (p *PostgresRepository) Search(ctx context.Context, campaignId id.CampaignId, filter SearchFilter, page dto.Page) ([]*Entry, int, error) {
+	// the players search only the public part of the entries
+	const filterPublic = ` FROM wiki_entries
+		WHERE campaign_id = $1
+		  AND ($2::VARCHAR IS NULL OR category = $2)
+		  AND ($3 = '' OR search_public @@ websearch_to_tsquery('simple', $3))
+	`
+	const filterAll = ` FROM wiki_entries
+		WHERE campaign_id = $1
+		  AND ($2::VARCHAR IS NULL OR category = $2)
+		  AND ($3 = '' OR search_all @@ websearch_to_tsquery('simple', $3))
+	`
+	const sqlSearchPublic = `SELECT ` + entryColumns + `, COUNT(*) OVER ()` + filterPublic + `
+		ORDER BY ts_rank(search_public, websearch_to_tsquery('simple', $3)) DESC, lower(title)
+		LIMIT $4 OFFSET $5
+	`
+	const sqlSearchAll = `SELECT ` + entryColumns + `, COUNT(*) OVER ()` + filterAll + `
+		ORDER BY ts_rank(search_all, websearch_to_tsquery('simple', $3)) DESC, lower(title)
+		LIMIT $4 OFFSET $5
+	`
+
+	query, sqlCount := sqlSearchPublic, `SELECT COUNT(*)`+filterPublic
+	if filter.IncludeSecret {
+		query, sqlCount = sqlSearchAll, `SELECT COUNT(*)`+filterAll
+	}
+	var category *string
+	if filter.Category != nil {
+		c := string(*filter.Category)
+		category = &c
+	}
+
+	args := []any{int(campaignId), category, filter.Query}
+	rows, err := p.q(ctx).Query(ctx, query, append(args, page.Limit, page.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := make([]*Entry, 0)
+	total := 0
+	for rows.Next() {
+		e, err := p.scanEntry(rows, &total)
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	// past the last page there is no row to carry the total
+	if len(entries) == 0 && page.Offset > 0 {
+		if err := p.q(ctx).QueryRow(ctx, sqlCount, args...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+	return entries, total, nil
+}
+
+func (p *PostgresRepository) FindBacklinks(ctx context.Context, campaignId id.CampaignId, title string, includeSecret bool) ([]*Entry, error) {
+	const query = selectEntry + `
+		WHERE campaign_id = $1
+		  AND entry_id IN (
+		      SELECT entry_id
+		      FROM wiki_links
+		      WHERE target = $2 AND ($3 OR NOT secret)
+		  )
+		ORDER BY lower(title)
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(campaignId), NormalizeTarget(title), includeSecret)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*Entry, 0)
+	for rows.Next() {
+		e, err := p.scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (p *PostgresRepository) FindRevisions(ctx context.Context, entryId id.WikiEntryId) ([]*Revision, error) {
+	const query = `
+		SELECT revision_id, entry_id, title, category, body, author_id, created_at
+		FROM wiki_revisions
+		WHERE entry_id = $1
+		ORDER BY revision_id DESC
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(entryId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]*Revision, 0)
+	for rows.Next() {
+		var (
+			r          Revision
+			revisionID int
+			entryID    int
+			authorID   int
+		)
+		if err := rows.Scan(&revisionID, &entryID, &r.title, &r.category, &r.body, &authorID, &r.createdAt); err != nil {
+			return nil, err
+		}
+		r.id = id.WikiRevisionId(revisionID)
+		r.entryId = id.WikiEntryId(entryID)
+		r.authorId = id.PlayerId(authorID)
+		revisions = append(revisions, &r)
+	}
+	return revisions, rows.Err()
+}
+
+// ResolveLinks prefers the entries to the characters with the same name
+func (p *PostgresRepository) ResolveLinks(ctx context.Context, campaignId id.CampaignId, targets []string) (map[string]LinkTarget, error) {
+	const query = `
+		SELECT lower(title), 'ENTRY', entry_id
+		FROM wiki_entries
+		WHERE campaign_id = $1 AND lower(title) = ANY($2)
+		UNION ALL
+		SELECT lower(name), 'CHARACTER', character_id
+		FROM characters
+		WHERE campaign_id = $1 AND lower(name) = ANY($2)
+	`
+
+	resolved := make(map[string]LinkTarget)
+	if len(targets) == 0 {
+		return resolved, nil
+	}
+
+	rows, err := p.q(ctx).Query(ctx, query, int(campaignId), targets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			target string
+			kind   LinkKind
+			refID  int
+		)
+		if err := rows.Scan(&target, &kind, &refID); err != nil {
+			return nil, err
+		}
+		if current, ok := resolved[target]; ok && current.Kind == LinkEntry {
+			continue
+		}
+		t := LinkTarget{Kind: kind}
+		if kind == LinkEntry {
+			t.EntryId = id.WikiEntryId(refID)
+		} else {
+			t.CharacterId = id.CharacterId(refID)
+		}
+		resolved[target] = t
+	}
+	return resolved, rows.Err()
+}
+
+func (p *PostgresRepository) saveLinks(ctx context.Context, e *Entry) error {
+	const sqlDelete = `DELETE FROM wiki_links WHERE entry_id = $1`
+	const sqlInsert = `
+		INSERT INTO wiki_links (entry_id, target, secret)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := p.q(ctx).Exec(ctx, sqlDelete, int(e.id)); err != nil {
+		return err
+	}
+	for _, l := range e.links {
+		if _, err := p.q(ctx).Exec(ctx, sqlInsert, int(e.id), l.Target, l.Secret); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PostgresRepository) saveRevision(ctx context.Context, e *Entry) error {
+	const query = `
+		INSERT INTO wiki_revisions (entry_id, title, category, body, author_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := p.q(ctx).Exec(ctx, query, int(e.id), e.title, string(e.category), e.body, int(e.authorId), e.updatedAt)
+	return err
+}
+
+// scanEntry translates DB row -> domain model. Extra columns after the ones of
+// selectEntry are scanned into extra.
+func (p *PostgresRepository) scanEntry(row pgx.Row, extra ...any) (*Entry, error) {
+	var (
+		entryID    int
+		campaignID int
+		title      string
+		category   Category
+		body       string
+		publicBody string
+		authorID   int
+		createdAt  time.Time
+		updatedAt  time.Time
+	)
+
+	dest := []any{
+		&entryID,
+		&campaignID,
+		&title,
+		&category,
+		&body,
+		&publicBody,
+		&authorID,
+		&createdAt,
+		&updatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	return &Entry{
+		id:         id.WikiEntryId(entryID),
+		campaignId: id.CampaignId(campaignID),
+		title:      title,
+		category:   category,
+		body:       body,
+		publicBody: publicBody,
+		links:      parseLinks(body),
+		authorId:   id.PlayerId(authorID),
+		createdAt:  createdAt,
+		updatedAt:  updatedAt,
+	}, nil
+}
+
+func uniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return postgres.ErrUniqueValueViolation
+	}
+	return err
+}
+
+func (p *PostgresRepository) FindExportEntries(ctx context.Context, playerId id.PlayerId) ([]ExportedEntry, error) {
+	const query = `
+		SELECT entry_id, campaign_id, title, category, body, created_at, updated_at
+		FROM wiki_entries
+		WHERE author_id = $1
+		ORDER BY entry_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]ExportedEntry, 0)
+	for rows.Next() {
+		var e ExportedEntry
+		err := rows.Scan(&e.EntryId, &e.CampaignId, &e.Title, &e.Category, &e.Body, &e.CreatedAt, &e.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (p *PostgresRepository) FindExportRevisions(ctx context.Context, playerId id.PlayerId) ([]ExportedRevision, error) {
+	const query = `
+		SELECT revision_id, entry_id, title, category, body, created_at
+		FROM wiki_revisions
+		WHERE author_id = $1
+		ORDER BY revision_id
+	`
+
+	rows, err := p.q(ctx).Query(ctx, query, int(playerId))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]ExportedRevision, 0)
+	for rows.Next() {
+		var r ExportedRevision
+		if err := rows.Scan(&r.RevisionId, &r.EntryId, &r.Title, &r.Category, &r.Body, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
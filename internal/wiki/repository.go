@@ -0,0 +1,57 @@
+package wiki
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"beldur/pkg/dto"
+	"context"
+)
+
+// SearchFilter narrows the entries of a campaign
+type SearchFilter struct {
+	// full text query, every entry if empty
+	Query string
+	// nil for every category
+	Category *Category
+	// the secret sections are searched too
+	IncludeSecret bool
+}
+
+// LinkTarget is what a [[link]] points to, an entry or a character of the campaign
+type LinkTarget struct {
+	Kind        LinkKind
+	EntryId     id.WikiEntryId
+	CharacterId id.CharacterId
+}
+
+type Saver interface {
+	// Save inserts the entry with its links and its first revision
+	Save(ctx context.Context, e *Entry) error
+	// Update persists the entry with its links and a new revision
+	Update(ctx context.Context, e *Entry) error
+	Delete(ctx context.Context, entryId id.WikiEntryId) error
+}
+
+type Finder interface {
+	FindById(ctx context.Context, entryId id.WikiEntryId) (*Entry, error)
+	// Search gives back a page of entries, the best matches first, and the number of all the matching entries
+	Search(ctx context.Context, campaignId id.CampaignId, filter SearchFilter, page dto.Page) ([]*Entry, int, error)
+	// FindBacklinks gives back the entries linking to the title
+	FindBacklinks(ctx context.Context, campaignId id.CampaignId, title string, includeSecret bool) ([]*Entry, error)
+	FindRevisions(ctx context.Context, entryId id.WikiEntryId) ([]*Revision, error)
+}
+
+type LinkResolver interface {
+	// ResolveLinks maps the normalized targets to the entries, or else to the characters, of the campaign
+	ResolveLinks(ctx context.Context, campaignId id.CampaignId, targets []string) (map[string]LinkTarget, error)
+}
+
+type CampaignFinder interface {
+	FindById(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error)
+}
+
+// Exporter reads the wiki entries and revisions written by a player for the export of its account
+type Exporter interface {
+	FindExportEntries(ctx context.Context, playerId id.PlayerId) ([]ExportedEntry, error)
+	FindExportRevisions(ctx context.Context, playerId id.PlayerId) ([]ExportedRevision, error)
+}
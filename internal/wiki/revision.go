@@ -0,0 +1,17 @@
+package wiki
+
+import (
+	"beldur/internal/id"
+	"time"
+)
+
+// Revision is a past content of an entry, saved at every change
+type Revision struct {
+	id        id.WikiRevisionId
+	entryId   id.WikiEntryId
+	title     string
+	category  Category
+	body      string
+	authorId  id.PlayerId
+	createdAt time.Time
+}
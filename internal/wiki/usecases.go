@@ -0,0 +1,282 @@
+package wiki
+
+import (
+	"beldur/internal/campaign"
+	"beldur/internal/id"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+	"beldur/pkg/dto"
+	"beldur/pkg/logger"
+	"context"
+	"errors"
+)
+
+type UseCase struct {
+	entrySaver     Saver
+	entryFinder    Finder
+	linkResolver   LinkResolver
+	campaignFinder CampaignFinder
+	tx             tx.Transactor
+}
+
+func NewUseCase(
+	entrySaver Saver,
+	entryFinder Finder,
+	linkResolver LinkResolver,
+	campaignFinder CampaignFinder,
+	tx tx.Transactor,
+) *UseCase {
+	return &UseCase{
+		entrySaver:     entrySaver,
+		entryFinder:    entryFinder,
+		linkResolver:   linkResolver,
+		campaignFinder: campaignFinder,
+		tx:             tx,
+	}
+}
+
+// Create writes an entry in the wiki of the campaign. Only the master of the campaign can do it.
+func (uc *UseCase) Create(ctx context.Context, req CreateEntryRequest, campaignId id.CampaignId, masterId id.PlayerId) (EntryResponse, error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return EntryResponse{}, err
+	}
+	if !camp.IsMaster(masterId) {
+		return EntryResponse{}, ErrCampaignHasAnotherMaster
+	}
+
+	e, err := New(campaignId, req.Title, req.Category, req.Body, masterId)
+	if err != nil {
+		return EntryResponse{}, err
+	}
+
+	err = uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		return uc.entrySaver.Save(ctx, e)
+	})
+	if err != nil {
+		if errors.Is(err, postgres.ErrUniqueValueViolation) {
+			return EntryResponse{}, ErrDuplicateTitle
+		}
+		logger.Debug("failed to save wiki entry", "campaign_id", campaignId, "error", err)
+		return EntryResponse{}, err
+	}
+	return uc.toEntryResponse(ctx, e, true)
+}
+
+// Search gives back a page of the entries of the campaign matching the filter.
+// Every player of the campaign can do it, the secret sections are searched only for the master.
+func (uc *UseCase) Search(
+	ctx context.Context,
+	campaignId id.CampaignId,
+	filter SearchFilter,
+	page dto.Page,
+	playerId id.PlayerId,
+) (dto.PageResponse[EntrySummary], error) {
+	camp, err := uc.findCampaign(ctx, campaignId)
+	if err != nil {
+		return dto.PageResponse[EntrySummary]{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return dto.PageResponse[EntrySummary]{}, ErrPlayerNotInCampaign
+	}
+	filter.IncludeSecret = camp.IsMaster(playerId)
+
+	entries, total, err := uc.entryFinder.Search(ctx, campaignId, filter, page)
+	if err != nil {
+		logger.Debug("failed to search wiki entries", "campaign_id", campaignId, "error", err)
+		return dto.PageResponse[EntrySummary]{}, err
+	}
+
+	return dto.PageResponse[EntrySummary]{
+		Data:   toEntrySummaries(entries),
+		Total:  total,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}, nil
+}
+
+// Get gives back the entry with its links resolved and its backlinks.
+// Every player of the campaign can read it, the secret sections are for the master only.
+func (uc *UseCase) Get(ctx context.Context, entryId id.WikiEntryId, playerId id.PlayerId) (EntryResponse, error) {
+	e, camp, err := uc.findEntry(ctx, entryId)
+	if err != nil {
+		return EntryResponse{}, err
+	}
+	if !camp.HasPlayer(playerId) {
+		return EntryResponse{}, ErrPlayerNotInCampaign
+	}
+	return uc.toEntryResponse(ctx, e, camp.IsMaster(playerId))
+}
+
+// Update edits the entry, keeping the previous content in the history.
+// Only the master of the campaign can do it.
+func (uc *UseCase) Update(ctx context.Context, req UpdateEntryRequest, entryId id.WikiEntryId, masterId id.PlayerId) (EntryResponse, error) {
+	var e *Entry
+
+	err := uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		var (
+			camp *campaign.Campaign
+			err  error
+		)
+		e, camp, err = uc.findEntry(ctx, entryId)
+		if err != nil {
+			return err
+		}
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		if err := e.Edit(req.Title, req.Category, req.Body, masterId); err != nil {
+			return err
+		}
+		if err := uc.entrySaver.Update(ctx, e); err != nil {
+			if errors.Is(err, postgres.ErrUniqueValueViolation) {
+				return ErrDuplicateTitle
+			}
+			logger.Debug("failed to update wiki entry", "entry_id", entryId, "error", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return EntryResponse{}, err
+	}
+	return uc.toEntryResponse(ctx, e, true)
+}
+
+// Delete removes the entry with its history. Only the master of the campaign can do it.
+func (uc *UseCase) Delete(ctx context.Context, entryId id.WikiEntryId, masterId id.PlayerId) error {
+	return uc.tx.WithTransaction(ctx, func(ctx context.Context) error {
+		_, camp, err := uc.findEntry(ctx, entryId)
+		if err != nil {
+			return err
+		}
+		if !camp.IsMaster(masterId) {
+			return ErrCampaignHasAnotherMaster
+		}
+		if err := uc.entrySaver.Delete(ctx, entryId); err != nil {
+			logger.Debug("failed to delete wiki entry", "entry_id", entryId, "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// Revisions gives back the history of the entry, newest first.
+// Only the master of the campaign can read it, since revisions hold the secret sections.
+func (uc *UseCase) Revisions(ctx context.Context, entryId id.WikiEntryId, masterId id.PlayerId) (dto.ListResponse[RevisionResponse], error) {
+	_, camp, err := uc.findEntry(ctx, entryId)
+	if err != nil {
+		return dto.ListResponse[RevisionResponse]{}, err
+	}
+	if !camp.IsMaster(masterId) {
+		return dto.ListResponse[RevisionResponse]{}, ErrCampaignHasAnotherMaster
+	}
+
+	revisions, err := uc.entryFinder.FindRevisions(ctx, entryId)
+	if err != nil {
+		logger.Debug("failed to find wiki revisions", "entry_id", entryId, "error", err)
+		return dto.ListResponse[RevisionResponse]{}, err
+	}
+
+	list := make([]RevisionResponse, len(revisions))
+	for i, r := range revisions {
+		list[i] = RevisionResponse{
+			Id:        int(r.id),
+			Title:     r.title,
+			Category:  r.category,
+			Body:      r.body,
+			AuthorId:  int(r.authorId),
+			CreatedAt: r.createdAt,
+		}
+	}
+	return dto.ListResponse[RevisionResponse]{Data: list}, nil
+}
+
+func (uc *UseCase) findEntry(ctx context.Context, entryId id.WikiEntryId) (*Entry, *campaign.Campaign, error) {
+	e, err := uc.entryFinder.FindById(ctx, entryId)
+	if err != nil {
+		if errors.Is(err, postgres.ErrNoRowFound) {
+			return nil, nil, ErrEntryNotFound
+		}
+		logger.Debug("failed to find wiki entry", "entry_id", entryId, "error", err)
+		return nil, nil, err
+	}
+
+	camp, err := uc.findCampaign(ctx, e.campaignId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return e, camp, nil
+}
+
+func (uc *UseCase) findCampaign(ctx context.Context, campaignId id.CampaignId) (*campaign.Campaign, error) {
+	camp, err := uc.campaignFinder.FindById(ctx, campaignId)
+	if err != nil {
+		logger.Debug("no campaign found", "campaign_id", campaignId)
+		return nil, errors.Join(ErrCampaignNotFound, err)
+	}
+	return camp, nil
+}
+
+// toEntryResponse resolves the links readable by the player and finds the backlinks
+func (uc *UseCase) toEntryResponse(ctx context.Context, e *Entry, isMaster bool) (EntryResponse, error) {
+	links := e.LinksFor(isMaster)
+	targets := make([]string, len(links))
+	for i, l := range links {
+		targets[i] = l.Target
+	}
+
+	resolved, err := uc.linkResolver.ResolveLinks(ctx, e.campaignId, targets)
+	if err != nil {
+		logger.Debug("failed to resolve wiki links", "entry_id", e.id, "error", err)
+		return EntryResponse{}, err
+	}
+	backlinks, err := uc.entryFinder.FindBacklinks(ctx, e.campaignId, e.title, isMaster)
+	if err != nil {
+		logger.Debug("failed to find wiki backlinks", "entry_id", e.id, "error", err)
+		return EntryResponse{}, err
+	}
+
+	linkResponses := make([]LinkResponse, len(links))
+	for i, l := range links {
+		resp := LinkResponse{Label: l.Label, Target: l.Target, Kind: LinkMissing}
+		if t, ok := resolved[l.Target]; ok {
+			resp.Kind = t.Kind
+			switch t.Kind {
+			case LinkEntry:
+				entryId := int(t.EntryId)
+				resp.EntryId = &entryId
+			case LinkCharacter:
+				characterId := int(t.CharacterId)
+				resp.CharacterId = &characterId
+			}
+		}
+		linkResponses[i] = resp
+	}
+
+	return EntryResponse{
+		Id:         int(e.id),
+		CampaignId: int(e.campaignId),
+		Title:      e.title,
+		Category:   e.category,
+		Body:       e.BodyFor(isMaster),
+		Links:      linkResponses,
+		Backlinks:  toEntrySummaries(backlinks),
+		AuthorId:   int(e.authorId),
+		CreatedAt:  e.createdAt,
+		UpdatedAt:  e.updatedAt,
+	}, nil
+}
+
+func toEntrySummaries(entries []*Entry) []EntrySummary {
+	summaries := make([]EntrySummary, len(entries))
+	for i, e := range entries {
+		summaries[i] = EntrySummary{
+			Id:        int(e.id),
+			Title:     e.title,
+			Category:  e.category,
+			UpdatedAt: e.updatedAt,
+		}
+	}
+	return summaries
+}
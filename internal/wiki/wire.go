@@ -0,0 +1,20 @@
+package wiki
+
+import (
+	"beldur/internal/campaign"
+	"beldur/pkg/db/postgres"
+	"beldur/pkg/db/tx"
+)
+
+type Deps struct {
+	QProvider  postgres.QuerierProvider
+	Transactor tx.Transactor
+}
+
+func NewHandlerFromDeps(deps Deps) *HttpHandler {
+	entryRepo := NewPostgresRepository(deps.QProvider)
+	campaignRepo := campaign.NewPostgresRepository(deps.QProvider)
+
+	wikiUC := NewUseCase(entryRepo, entryRepo, entryRepo, campaignRepo, deps.Transactor)
+	return NewHttpHandler(wikiUC)
+}
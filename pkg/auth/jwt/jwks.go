@@ -0,0 +1,70 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// JWKS is the JSON Web Key Set of the public keys verifying the tokens, RFC 7517
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS gives back the public keys, the signing key first. The shared secret is never published,
+// the tokens it signs can be verified by Beldur only.
+func (s *Service) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, k := range s.keys {
+		jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.method.Alg()}
+		switch pub := k.public().(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	slices.SortFunc(jwks.Keys, func(a, b JWK) int {
+		switch {
+		case s.signing != nil && a.Kid == s.signing.ID:
+			return -1
+		case s.signing != nil && b.Kid == s.signing.ID:
+			return 1
+		}
+		return strings.Compare(a.Kid, b.Kid)
+	})
+	return jwks
+}
+
+// JWKSHandler serves the JWKS of the service, usually at /.well-known/jwks.json
+func JWKSHandler(s *Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// the verifiers fetch the keys again on an unknown kid, after a rotation
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(s.JWKS())
+	}
+}
@@ -20,28 +20,94 @@ var (
 	ErrExpiredToken = errors.New("token expired")
 )
 
+// Service issues and verifies the session tokens. They are signed with an RSA or Ed25519 key,
+// or with a shared secret and HS256.
 type Service struct {
-	secret     []byte
+	secret  []byte
+	signing *signingKey
+	// keys verify the tokens, by kid
+	keys       map[string]signingKey
 	expiration time.Duration
 	issuer     string
 }
 
+type signingKey struct {
+	Key
+	method jwtlib.SigningMethod
+}
+
+// Config of a Service. The tokens are signed by SigningKey, or by Secret when there is no
+// SigningKey. To rotate the keys, the previous SigningKey goes to VerificationKeys until the
+// tokens it signed have expired, so that nobody is logged out. Secret can be kept the same
+// way when moving from HS256 to a key.
+type Config struct {
+	Secret           []byte
+	SigningKey       *Key
+	VerificationKeys []Key
+	Expiration       time.Duration
+	Issuer           string
+}
+
+// NewService gives a Service signing with the shared secret and HS256
 func NewService(secret []byte, expiration time.Duration, issuer string) *Service {
 	if secret == nil {
 		panic("secret is nil")
 	}
-	if expiration == 0 {
-		panic("expiration is zero")
+	s, err := New(Config{Secret: secret, Expiration: expiration, Issuer: issuer})
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func New(cfg Config) (*Service, error) {
+	if cfg.Expiration == 0 {
+		return nil, errors.New("expiration is zero")
+	}
+	if cfg.Issuer == "" {
+		return nil, errors.New("issuer is empty")
 	}
-	if issuer == "" {
-		panic("issuer is empty")
+	if cfg.SigningKey == nil && len(cfg.Secret) == 0 {
+		return nil, errors.New("no signing key nor secret")
 	}
 
-	return &Service{
-		secret:     secret,
-		expiration: expiration,
-		issuer:     issuer,
+	s := &Service{
+		secret:     cfg.Secret,
+		keys:       make(map[string]signingKey),
+		expiration: cfg.Expiration,
+		issuer:     cfg.Issuer,
 	}
+
+	keys := cfg.VerificationKeys
+	if cfg.SigningKey != nil {
+		if cfg.SigningKey.Private == nil {
+			return nil, fmt.Errorf("%w: signing key %q has no private key", ErrInvalidKey, cfg.SigningKey.ID)
+		}
+		keys = append([]Key{*cfg.SigningKey}, keys...)
+	}
+	for _, k := range keys {
+		if k.ID == "" {
+			return nil, fmt.Errorf("%w: key without id", ErrInvalidKey)
+		}
+		if _, found := s.keys[k.ID]; found {
+			return nil, fmt.Errorf("%w: duplicate key id %q", ErrInvalidKey, k.ID)
+		}
+		method, err := k.method()
+		if err != nil {
+			return nil, err
+		}
+		s.keys[k.ID] = signingKey{Key: k, method: method}
+	}
+	if cfg.SigningKey != nil {
+		signing := s.keys[cfg.SigningKey.ID]
+		s.signing = &signing
+	}
+	return s, nil
+}
+
+// Expiration is how long the issued tokens are valid
+func (s *Service) Expiration() time.Duration {
+	return s.expiration
 }
 
 func (s *Service) Issue(ctx context.Context, c auth.Claims) (string, error) {
@@ -51,12 +117,25 @@ func (s *Service) Issue(ctx context.Context, c auth.Claims) (string, error) {
 		"iss": s.issuer,
 		"sub": strconv.Itoa(int(c.Subject)),
 		"aid": int(c.PlayerID),
+		"sv":  c.SessionVersion,
 		"iat": jwtlib.NewNumericDate(now),
 		"exp": jwtlib.NewNumericDate(now.Add(s.expiration)),
 	}
+	if c.SessionID != "" {
+		claims["jti"] = c.SessionID
+	}
 
-	tok := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims)
-	signed, err := tok.SignedString(s.secret)
+	var (
+		signed string
+		err    error
+	)
+	if s.signing != nil {
+		tok := jwtlib.NewWithClaims(s.signing.method, claims)
+		tok.Header["kid"] = s.signing.ID
+		signed, err = tok.SignedString(s.signing.Private)
+	} else {
+		signed, err = jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, claims).SignedString(s.secret)
+	}
 	if err != nil {
 		return "", errors.Join(ErrIssueToken, err)
 	}
@@ -69,14 +148,13 @@ func (s *Service) Verify(ctx context.Context, tokenStr string) (auth.Verified, e
 	token, err := jwtlib.ParseWithClaims(
 		tokenStr,
 		claims,
-		func(t *jwtlib.Token) (any, error) {
-			if t.Method != jwtlib.SigningMethodHS256 {
-				return nil, fmt.Errorf("%w: unexpected signing method %v", ErrInvalidToken, t.Header["alg"])
-			}
-			return s.secret, nil
-		},
+		s.verificationKey,
 		jwtlib.WithIssuer(s.issuer),
-		jwtlib.WithValidMethods([]string{jwtlib.SigningMethodHS256.Alg()}),
+		jwtlib.WithValidMethods([]string{
+			jwtlib.SigningMethodHS256.Alg(),
+			jwtlib.SigningMethodRS256.Alg(),
+			jwtlib.SigningMethodEdDSA.Alg(),
+		}),
 	)
 	if err != nil {
 		if errors.Is(err, jwtlib.ErrTokenExpired) {
@@ -98,25 +176,54 @@ func (s *Service) Verify(ctx context.Context, tokenStr string) (auth.Verified, e
 		return auth.Verified{}, errors.Join(ErrInvalidToken, err)
 	}
 
-	var playerId id.PlayerId
-	if v, ok := claims["aid"]; ok {
-		switch n := v.(type) {
-		case float64:
-			playerId = id.PlayerId(int(n))
-		case int:
-			playerId = id.PlayerId(n)
-		case int64:
-			playerId = id.PlayerId(int(n))
-		case json.Number:
-			i, err := n.Int64()
-			if err == nil {
-				playerId = id.PlayerId(int(i))
-			}
+	return auth.Verified{
+		Subject:        id.AccountId(subInt),
+		PlayerId:       id.PlayerId(intClaim(claims, "aid")),
+		SessionVersion: intClaim(claims, "sv"),
+		SessionID:      stringClaim(claims, "jti"),
+	}, nil
+}
+
+// verificationKey finds the key of the kid header. The algorithm must be the one of the key,
+// so that a public key is never used as an HS256 secret.
+func (s *Service) verificationKey(t *jwtlib.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		if t.Method != jwtlib.SigningMethodHS256 || len(s.secret) == 0 {
+			return nil, fmt.Errorf("%w: unexpected signing method %v", ErrInvalidToken, t.Header["alg"])
 		}
+		return s.secret, nil
 	}
 
-	return auth.Verified{
-		Subject:  id.AccountId(subInt),
-		PlayerId: playerId,
-	}, nil
+	k, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+	if t.Method != k.method {
+		return nil, fmt.Errorf("%w: unexpected signing method %v for key %q", ErrInvalidToken, t.Header["alg"], kid)
+	}
+	return k.public(), nil
+}
+
+// stringClaim reads a string claim, empty when missing
+func stringClaim(claims jwtlib.MapClaims, name string) string {
+	v, _ := claims[name].(string)
+	return v
+}
+
+// intClaim reads a numeric claim, 0 when missing
+func intClaim(claims jwtlib.MapClaims, name string) int {
+	switch n := claims[name].(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return int(i)
+		}
+	}
+	return 0
 }
@@ -0,0 +1,199 @@
+package jwt
+
+import (
+	"beldur/pkg/auth"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var claims = auth.Claims{Subject: 1, PlayerID: 7, SessionVersion: 2, SessionID: "jti-1"}
+
+func newRSAKey(t *testing.T, kid string) Key {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return Key{ID: kid, Private: private}
+}
+
+func newEd25519Key(t *testing.T, kid string) Key {
+	t.Helper()
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return Key{ID: kid, Private: private}
+}
+
+func newService(t *testing.T, cfg Config) *Service {
+	t.Helper()
+	cfg.Expiration = time.Hour
+	cfg.Issuer = "beldur-test"
+	s, err := New(cfg)
+	require.NoError(t, err)
+	return s
+}
+
+func TestService_SignsWithKey(t *testing.T) {
+	ctx := context.Background()
+
+	for _, key := range []Key{newRSAKey(t, "rsa-1"), newEd25519Key(t, "ed-1")} {
+		t.Run(key.ID, func(t *testing.T) {
+			s := newService(t, Config{SigningKey: &key})
+
+			token, err := s.Issue(ctx, claims)
+			require.NoError(t, err)
+
+			parsed, _, err := jwtlib.NewParser().ParseUnverified(token, jwtlib.MapClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+
+			verified, err := s.Verify(ctx, token)
+			require.NoError(t, err)
+			assert.Equal(t, auth.Verified{Subject: 1, PlayerId: 7, SessionVersion: 2, SessionID: "jti-1"}, verified)
+		})
+	}
+}
+
+func TestService_Rotation(t *testing.T) {
+	ctx := context.Background()
+	previous, current := newRSAKey(t, "2026-01"), newEd25519Key(t, "2026-07")
+
+	before := newService(t, Config{Secret: []byte("secret"), SigningKey: &previous})
+	oldToken, err := before.Issue(ctx, claims)
+	require.NoError(t, err)
+	secretToken, err := NewService([]byte("secret"), time.Hour, "beldur-test").Issue(ctx, claims)
+	require.NoError(t, err)
+
+	// the previous key public part only is enough to verify
+	retired := Key{ID: previous.ID, Public: previous.Private.Public()}
+	after := newService(t, Config{Secret: []byte("secret"), SigningKey: &current, VerificationKeys: []Key{retired}})
+
+	_, err = after.Verify(ctx, oldToken)
+	assert.NoError(t, err)
+	_, err = after.Verify(ctx, secretToken)
+	assert.NoError(t, err)
+
+	// once the previous key and the secret are dropped
+	later := newService(t, Config{SigningKey: &current})
+	_, err = later.Verify(ctx, oldToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = later.Verify(ctx, secretToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestService_RejectsAlgorithmConfusion(t *testing.T) {
+	ctx := context.Background()
+	key := newRSAKey(t, "rsa-1")
+	s := newService(t, Config{SigningKey: &key})
+
+	// the public key, known by everyone, used as an HS256 secret
+	publicDER, err := x509.MarshalPKIXPublicKey(key.Private.Public())
+	require.NoError(t, err)
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	for _, secret := range [][]byte{publicDER, publicPEM} {
+		tok := jwtlib.NewWithClaims(jwtlib.SigningMethodHS256, jwtlib.MapClaims{
+			"iss": "beldur-test",
+			"sub": "1",
+			"exp": jwtlib.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+		tok.Header["kid"] = "rsa-1"
+		forged, err := tok.SignedString(secret)
+		require.NoError(t, err)
+
+		_, err = s.Verify(ctx, forged)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	}
+}
+
+func TestService_UnknownKey(t *testing.T) {
+	ctx := context.Background()
+	key, other := newEd25519Key(t, "ed-1"), newEd25519Key(t, "ed-1")
+
+	token, err := newService(t, Config{SigningKey: &other}).Issue(ctx, claims)
+	require.NoError(t, err)
+
+	// same kid, another key
+	_, err = newService(t, Config{SigningKey: &key}).Verify(ctx, token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	key := newEd25519Key(t, "ed-1")
+
+	_, err := New(Config{Expiration: time.Hour, Issuer: "beldur"})
+	assert.Error(t, err)
+
+	_, err = New(Config{SigningKey: &key, VerificationKeys: []Key{key}, Expiration: time.Hour, Issuer: "beldur"})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	retired := Key{ID: "ed-1", Public: key.Private.Public()}
+	_, err = New(Config{SigningKey: &retired, Expiration: time.Hour, Issuer: "beldur"})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestService_JWKS(t *testing.T) {
+	ctx := context.Background()
+	signing, previous := newEd25519Key(t, "ed-2"), newRSAKey(t, "rsa-1")
+	s := newService(t, Config{Secret: []byte("secret"), SigningKey: &signing, VerificationKeys: []Key{previous}})
+
+	jwks := s.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, JWK{
+		Kty: "OKP",
+		Kid: "ed-2",
+		Use: "sig",
+		Alg: "EdDSA",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(signing.Private.Public().(ed25519.PublicKey)),
+	}, jwks.Keys[0])
+
+	// another service verifies the tokens with the published key only
+	rsaJWK := jwks.Keys[1]
+	assert.Equal(t, "RS256", rsaJWK.Alg)
+	n, err := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	require.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(rsaJWK.E)
+	require.NoError(t, err)
+	published := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	token, err := newService(t, Config{SigningKey: &previous}).Issue(ctx, claims)
+	require.NoError(t, err)
+	_, err = jwtlib.Parse(token, func(*jwtlib.Token) (any, error) { return published, nil })
+	assert.NoError(t, err)
+}
+
+func TestParseKeyPEM(t *testing.T) {
+	key := newEd25519Key(t, "ed-1")
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key.Private)
+	require.NoError(t, err)
+	parsed, err := ParsePrivateKeyPEM("ed-1", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	require.NoError(t, err)
+	assert.Equal(t, key.Private, parsed.Private)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(key.Private.Public())
+	require.NoError(t, err)
+	parsed, err = ParsePublicKeyPEM("ed-1", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	require.NoError(t, err)
+	assert.Nil(t, parsed.Private)
+	assert.Equal(t, key.Private.Public(), parsed.Public)
+
+	_, err = ParsePrivateKeyPEM("ed-1", []byte("not a key"))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = ParsePrivateKeyPEM("rsa-1", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(small)}))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
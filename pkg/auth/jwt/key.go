@@ -0,0 +1,99 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+)
+
+var ErrInvalidKey = errors.New("invalid key")
+
+// Key is an RSA or Ed25519 key, named by the kid header of the tokens it signs
+type Key struct {
+	ID string
+	// Private signs the tokens, a *rsa.PrivateKey or an ed25519.PrivateKey. It is nil for a key
+	// kept only to verify the tokens signed before a rotation.
+	Private crypto.Signer
+	// Public verifies the tokens, derived from Private when nil
+	Public crypto.PublicKey
+}
+
+// ParsePrivateKeyPEM reads a PKCS #8 RSA or Ed25519 private key, or a PKCS #1 RSA private key
+func ParsePrivateKeyPEM(kid string, data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("%w: no PEM block", ErrInvalidKey)
+	}
+
+	var (
+		parsed any
+		err    error
+	)
+	if block.Type == "RSA PRIVATE KEY" {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return Key{}, errors.Join(ErrInvalidKey, err)
+	}
+
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return Key{}, fmt.Errorf("%w: unsupported private key %T", ErrInvalidKey, parsed)
+	}
+	key := Key{ID: kid, Private: signer}
+	if _, err := key.method(); err != nil {
+		return Key{}, err
+	}
+	return key, nil
+}
+
+// ParsePublicKeyPEM reads a PKIX RSA or Ed25519 public key, for a key that only verifies
+func ParsePublicKeyPEM(kid string, data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("%w: no PEM block", ErrInvalidKey)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return Key{}, errors.Join(ErrInvalidKey, err)
+	}
+
+	key := Key{ID: kid, Public: parsed}
+	if _, err := key.method(); err != nil {
+		return Key{}, err
+	}
+	return key, nil
+}
+
+func (k Key) public() crypto.PublicKey {
+	if k.Public == nil && k.Private != nil {
+		return k.Private.Public()
+	}
+	return k.Public
+}
+
+// method gives back RS256 for an RSA key and EdDSA for an Ed25519 key
+func (k Key) method() (jwtlib.SigningMethod, error) {
+	switch pub := k.public().(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return nil, fmt.Errorf("%w: RSA key %q is shorter than 2048 bits", ErrInvalidKey, k.ID)
+		}
+		if _, ok := k.Private.(*rsa.PrivateKey); k.Private != nil && !ok {
+			return nil, fmt.Errorf("%w: RSA key %q must be a *rsa.PrivateKey", ErrInvalidKey, k.ID)
+		}
+		return jwtlib.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwtlib.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key %q of type %T", ErrInvalidKey, k.ID, pub)
+	}
+}
@@ -0,0 +1,43 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider_KeysRefetch(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+		w.Write([]byte(`{"keys":[{"kty":"EC","kid":"k1","crv":"P-256",` +
+			`"x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU","y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0"}]}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	p := NewProvider(Config{Issuer: server.URL}, nil)
+	d := &discovery{JwksURI: server.URL}
+
+	_, err := p.key(ctx, d, "k1")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, fetches.Load())
+
+	// unknown kids are checked against the cache until the interval is over
+	for range 10 {
+		_, err := p.key(ctx, d, "forged")
+		assert.Error(t, err)
+	}
+	assert.EqualValues(t, 1, fetches.Load())
+
+	p.keysFetchedAt = time.Now().Add(-keysRefetchInterval)
+	_, err = p.key(ctx, d, "forged")
+	assert.Error(t, err)
+	assert.EqualValues(t, 2, fetches.Load())
+}
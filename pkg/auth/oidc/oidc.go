@@ -0,0 +1,335 @@
+// Package oidc is a client of the OpenID Connect providers, for the authorization code flow
+// with PKCE. The provider is configured by its issuer, the endpoints are discovered.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwtlib "github.com/golang-jwt/jwt/v5"
+)
+
+// keysRefetchInterval is the minimum time between two fetches of the keys. An unknown kid
+// in between is checked against the keys in cache only, so that tokens with random kids
+// can't make the provider fetch its keys over and over.
+const keysRefetchInterval = time.Minute
+
+var (
+	ErrDiscovery      = errors.New("oidc discovery failed")
+	ErrExchange       = errors.New("oidc code exchange failed")
+	ErrInvalidIDToken = errors.New("invalid oidc id token")
+)
+
+// Config of a client registered at the provider
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL receives the code and the state once the user has signed in
+	RedirectURL string
+	// Scopes requested along with openid, email and profile if empty
+	Scopes []string
+}
+
+// Claims of the ID token identifying the user
+type Claims struct {
+	// Subject identifies the user at the provider, it never changes
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Nonce             string
+}
+
+// Provider is an OpenID Connect provider. Its configuration and keys are fetched on first use.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      *keySet
+	// keysFetchedAt is the start of the last fetch of the keys, keysFetching is closed
+	// once the running one is over
+	keysFetchedAt time.Time
+	keysFetching  chan struct{}
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"email", "profile"}
+	}
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	return &Provider{cfg: cfg, client: client}
+}
+
+// NewCodeVerifier generates the secret of a PKCE flow, kept by the client until the exchange
+func NewCodeVerifier() string {
+	return randomString(32)
+}
+
+// CodeChallenge is the S256 challenge of the verifier, sent with the authorization request
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// NewNonce generates the value bound to the ID token, against replays
+func NewNonce() string {
+	return randomString(16)
+}
+
+// AuthCodeURL is the page of the provider where the user signs in
+func (p *Provider) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{}
+	q.Set("response_type", "code")
+	q.Set("client_id", p.cfg.ClientID)
+	q.Set("redirect_uri", p.cfg.RedirectURL)
+	q.Set("scope", strings.Join(append([]string{"openid"}, p.cfg.Scopes...), " "))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange trades the code for the tokens of the user, and gives back the claims of the
+// verified ID token. The caller checks the nonce.
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (Claims, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, errors.Join(ErrExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := p.doJSON(req, &tokens); err != nil {
+		return Claims{}, errors.Join(ErrExchange, err)
+	}
+	if tokens.IDToken == "" {
+		return Claims{}, fmt.Errorf("%w: no id_token in the response", ErrExchange)
+	}
+	return p.verify(ctx, d, tokens.IDToken)
+}
+
+func (p *Provider) verify(ctx context.Context, d *discovery, rawIDToken string) (Claims, error) {
+	var claims struct {
+		jwtlib.RegisteredClaims
+		Email             string `json:"email"`
+		EmailVerified     any    `json:"email_verified"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+		Nonce             string `json:"nonce"`
+	}
+
+	_, err := jwtlib.ParseWithClaims(
+		rawIDToken,
+		&claims,
+		func(t *jwtlib.Token) (any, error) {
+			kid, _ := t.Header["kid"].(string)
+			return p.key(ctx, d, kid)
+		},
+		jwtlib.WithIssuer(d.Issuer),
+		jwtlib.WithAudience(p.cfg.ClientID),
+		jwtlib.WithExpirationRequired(),
+		jwtlib.WithValidMethods([]string{"RS256", "ES256"}),
+	)
+	if err != nil {
+		return Claims{}, errors.Join(ErrInvalidIDToken, err)
+	}
+	if claims.Subject == "" {
+		return Claims{}, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+
+	return Claims{
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     isTrue(claims.EmailVerified),
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+		Nonce:             claims.Nonce,
+	}, nil
+}
+
+// key gives back the public key of kid. The keys are fetched again when the provider has
+// rotated them, at most once every keysRefetchInterval, and the HTTP request is made without
+// holding the lock.
+func (p *Provider) key(ctx context.Context, d *discovery, kid string) (any, error) {
+	p.mu.Lock()
+	if p.keys != nil {
+		if k, ok := p.keys.find(kid); ok {
+			p.mu.Unlock()
+			return k, nil
+		}
+	}
+	if fetching := p.keysFetching; fetching != nil {
+		p.mu.Unlock()
+		select {
+		case <-fetching:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return p.cachedKey(kid)
+	}
+	if time.Since(p.keysFetchedAt) < keysRefetchInterval {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	fetching := make(chan struct{})
+	p.keysFetching = fetching
+	p.keysFetchedAt = time.Now()
+	p.mu.Unlock()
+
+	keys, err := p.fetchKeys(ctx, d)
+
+	p.mu.Lock()
+	if err == nil {
+		p.keys = keys
+	}
+	p.keysFetching = nil
+	close(fetching)
+	p.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return p.cachedKey(kid)
+}
+
+func (p *Provider) cachedKey(kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys != nil {
+		if k, ok := p.keys.find(kid); ok {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+func (p *Provider) fetchKeys(ctx context.Context, d *discovery) (*keySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.JwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var jwks jsonWebKeySet
+	if err := p.doJSON(req, &jwks); err != nil {
+		return nil, err
+	}
+	return jwks.keySet(), nil
+}
+
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	cached := p.discovery
+	p.mu.Unlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, errors.Join(ErrDiscovery, err)
+	}
+	var d discovery
+	if err := p.doJSON(req, &d); err != nil {
+		return nil, errors.Join(ErrDiscovery, err)
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != p.cfg.Issuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", ErrDiscovery, d.Issuer, p.cfg.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JwksURI == "" {
+		return nil, fmt.Errorf("%w: missing endpoints", ErrDiscovery)
+	}
+
+	// the lock is not held during the request, a concurrent discovery may have won
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery == nil {
+		p.discovery = &d
+	}
+	return p.discovery, nil
+}
+
+func (p *Provider) doJSON(req *http.Request, out any) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: status %d: %s", req.Method, req.URL, resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// isTrue reads email_verified, sent as a string by some providers
+func isTrue(v any) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		return b == "true"
+	}
+	return false
+}
+
+func randomString(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
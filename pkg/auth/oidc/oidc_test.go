@@ -0,0 +1,87 @@
+package oidc_test
+
+import (
+	"beldur/pkg/auth/oidc"
+	"beldur/pkg/auth/oidc/oidctest"
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProvider(issuer string) *oidc.Provider {
+	return oidc.NewProvider(oidc.Config{
+		Issuer:       issuer,
+		ClientID:     oidctest.ClientID,
+		ClientSecret: oidctest.ClientSecret,
+		RedirectURL:  "http://localhost:5173/oidc/callback",
+	}, nil)
+}
+
+func TestProvider_CodeFlowWithPKCE(t *testing.T) {
+	ctx := context.Background()
+	server := oidctest.NewServer()
+	defer server.Close()
+	server.SignIn(oidctest.User{Subject: "user-1", Email: "dm@example.com", EmailVerified: true, Name: "Dungeon Master"})
+
+	provider := newProvider(server.Issuer())
+	verifier := oidc.NewCodeVerifier()
+	authURL, err := provider.AuthCodeURL(ctx, "the-state", "the-nonce", oidc.CodeChallenge(verifier))
+	require.NoError(t, err)
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "openid email profile", u.Query().Get("scope"))
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+
+	code, state, err := server.Authorize(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "the-state", state)
+
+	claims, err := provider.Exchange(ctx, code, verifier)
+	require.NoError(t, err)
+	assert.Equal(t, oidc.Claims{
+		Subject:       "user-1",
+		Email:         "dm@example.com",
+		EmailVerified: true,
+		Name:          "Dungeon Master",
+		Nonce:         "the-nonce",
+	}, claims)
+
+	// a code is used once
+	_, err = provider.Exchange(ctx, code, verifier)
+	assert.ErrorIs(t, err, oidc.ErrExchange)
+}
+
+func TestProvider_WrongVerifier(t *testing.T) {
+	ctx := context.Background()
+	server := oidctest.NewServer()
+	defer server.Close()
+	server.SignIn(oidctest.User{Subject: "user-1"})
+
+	provider := newProvider(server.Issuer())
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", oidc.CodeChallenge(oidc.NewCodeVerifier()))
+	require.NoError(t, err)
+	code, _, err := server.Authorize(authURL)
+	require.NoError(t, err)
+
+	_, err = provider.Exchange(ctx, code, oidc.NewCodeVerifier())
+	assert.ErrorIs(t, err, oidc.ErrExchange)
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	server := oidctest.NewServer()
+	defer server.Close()
+
+	provider := newProvider(server.Issuer() + "/other")
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	assert.ErrorIs(t, err, oidc.ErrDiscovery)
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// base64url(sha256(verifier)) without padding
+	assert.Equal(t, "TvSQ7lolnOxguxxKZ__MvzP3I-l6il5FK8GLpZaaekI", oidc.CodeChallenge("dBjftJeZ4CVP-mB92K-uhy7RCHxt3Fj_GtRkSBXiljVh"))
+	assert.Len(t, oidc.NewCodeVerifier(), 43)
+}
@@ -0,0 +1,27 @@
+package auth
+
+import "slices"
+
+// Scopes given to the personal access tokens. A session of the user is not limited by scopes.
+const (
+	ScopeCampaignRead    = "campaign:read"
+	ScopeCampaignWrite   = "campaign:write"
+	ScopeCharactersRead  = "characters:read"
+	ScopeCharactersWrite = "characters:write"
+	// ScopeRollsWrite allows the chat messages carrying a dice roll
+	ScopeRollsWrite = "rolls:write"
+)
+
+// Scopes lists every scope a personal access token can be given
+var Scopes = []string{
+	ScopeCampaignRead,
+	ScopeCampaignWrite,
+	ScopeCharactersRead,
+	ScopeCharactersWrite,
+	ScopeRollsWrite,
+}
+
+// IsScope tells whether the scope is one of Scopes
+func IsScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const secretTokenBytes = 32
+
+// NewSecretToken generates a random URL safe token. Only its hash should be stored,
+// the plain token is given once to the user.
+func NewSecretToken() (plain string, hash string) {
+	b := make([]byte, secretTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	plain = base64.RawURLEncoding.EncodeToString(b)
+	return plain, HashSecretToken(plain)
+}
+
+// HashSecretToken gives back the hex SHA-256 of the token. Tokens have enough entropy
+// to not need a slow hash function.
+func HashSecretToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
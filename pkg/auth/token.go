@@ -3,21 +3,47 @@ package auth
 import (
 	"beldur/internal/id"
 	"context"
+	"slices"
 )
 
 type Claims struct {
 	Subject  id.AccountId
 	PlayerID id.PlayerId
+	// SessionVersion of the account when the token is issued, see Verified
+	SessionVersion int
+	// SessionID identifies the session opened by the token, given as its jti
+	SessionID string
 }
 
 type Principal struct {
 	AccountID id.AccountId
 	PlayerID  id.PlayerId
+	// Scopes limit what a personal access token can do, nil for a session of the user
+	Scopes []string
+	// SessionID of the session of the user, empty for a personal access token
+	SessionID string
+}
+
+// IsSession tells whether the principal is the user logged in, not a personal access token
+func (p Principal) IsSession() bool {
+	return p.Scopes == nil
+}
+
+// HasScope tells whether the principal is allowed the scope, a session has all the scopes
+func (p Principal) HasScope(scope string) bool {
+	return p.IsSession() || slices.Contains(p.Scopes, scope)
 }
 
 type Verified struct {
 	Subject  id.AccountId
 	PlayerId id.PlayerId
+	// SessionVersion must match the current one of the account, the account bumps it
+	// to revoke all the tokens issued before
+	SessionVersion int
+	// Scopes of a personal access token, nil for a session token
+	Scopes []string
+	// SessionID of a session token, empty for the tokens issued before the sessions were recorded
+	SessionID string
 }
 
 type TokenIssuer interface {
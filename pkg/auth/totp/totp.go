@@ -0,0 +1,105 @@
+// Package totp implements the time-based one-time passwords of RFC 6238, as read by the
+// authenticator apps: HMAC-SHA1, 6 digits, 30 seconds steps.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	Digits = 6
+	Period = 30 * time.Second
+
+	// 160 bits, the size of the HMAC-SHA1 key recommended by RFC 4226
+	secretBytes = 20
+)
+
+var ErrInvalidSecret = errors.New("invalid totp secret")
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret generates a random secret, base32 encoded as the authenticator apps expect it
+func NewSecret() string {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return encoding.EncodeToString(b)
+}
+
+// Step is the counter of the time step containing at
+func Step(at time.Time) int64 {
+	return at.Unix() / int64(Period/time.Second)
+}
+
+// Code gives back the code of the secret for the time step containing at
+func Code(secret string, at time.Time) (string, error) {
+	key, err := decode(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, Step(at)), nil
+}
+
+// Validate checks the code against the steps around at, skew steps before and after to allow
+// for clock drift. It gives back the matching step, so that the caller can refuse a code
+// already used.
+func Validate(secret string, candidate string, at time.Time, skew int) (int64, bool) {
+	key, err := decode(secret)
+	if err != nil || len(candidate) != Digits {
+		return 0, false
+	}
+	current := Step(at)
+	for i := -int64(skew); i <= int64(skew); i++ {
+		if subtle.ConstantTimeCompare([]byte(code(key, current+i)), []byte(candidate)) == 1 {
+			return current + i, true
+		}
+	}
+	return 0, false
+}
+
+// URI gives back the otpauth:// URI of the secret, usually shown as a QR code
+func URI(issuer string, accountName string, secret string) string {
+	label := url.PathEscape(issuer + ":" + accountName)
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(int(Period.Seconds())))
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+func decode(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.TrimRight(strings.ReplaceAll(secret, " ", ""), "="))
+	key, err := encoding.DecodeString(secret)
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
+
+// code is the HOTP of RFC 4226 for the counter
+func code(key []byte, counter int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	// 10^Digits
+	return fmt.Sprintf("%0*d", Digits, value%1_000_000)
+}
@@ -0,0 +1,42 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, s.Put(ctx, "campaign/1/map.png", strings.NewReader("data")))
+
+	r, err := s.Open(ctx, "campaign/1/map.png")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "data", string(data))
+
+	require.NoError(t, s.Delete(ctx, "campaign/1/map.png"))
+	require.NoError(t, s.Delete(ctx, "campaign/1/map.png"))
+	_, err = s.Open(ctx, "campaign/1/map.png")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocalStoreInvalidKey(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "campaign/../../secret", "campaign//map", `campaign\map`} {
+		t.Run(key, func(t *testing.T) {
+			assert.ErrorIs(t, s.Put(context.Background(), key, strings.NewReader("data")), ErrInvalidKey)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package dice
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+)
+
+const (
+	MaxDice  = 100
+	MaxSides = 1000
+)
+
+var ErrInvalidExpression = errors.New("invalid dice expression")
+
+// Roller is the source of randomness of the rolls.
+// The default one is safe for concurrent use, the seeded one is meant for tests.
+type Roller interface {
+	IntN(n int) int
+}
+
+type globalRoller struct{}
+
+func (globalRoller) IntN(n int) int { return rand.IntN(n) }
+
+// NewRoller returns a Roller backed by the global random generator
+func NewRoller() Roller {
+	return globalRoller{}
+}
+
+// NewSeededRoller returns a deterministic Roller. It is not safe for concurrent use.
+func NewSeededRoller(seed uint64) Roller {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+// Between returns a random number in [min, max]
+func Between(r Roller, min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + r.IntN(max-min+1)
+}
+
+// D rolls a single die with the given number of sides
+func D(r Roller, sides int) int {
+	return Between(r, 1, sides)
+}
+
+// Expr is a dice expression in the standard notation, like 2d6+3
+type Expr struct {
+	Count    int
+	Sides    int
+	Modifier int
+}
+
+// Parse parses expressions like "d20", "2d6", "1d8+2" and "3d4-1"
+func Parse(s string) (Expr, error) {
+	s = strings.ToLower(strings.ReplaceAll(s, " ", ""))
+
+	countStr, rest, ok := strings.Cut(s, "d")
+	if !ok {
+		return Expr{}, fmt.Errorf("%w: %q", ErrInvalidExpression, s)
+	}
+
+	count := 1
+	if countStr != "" {
+		n, err := strconv.Atoi(countStr)
+		if err != nil {
+			return Expr{}, fmt.Errorf("%w: %q", ErrInvalidExpression, s)
+		}
+		count = n
+	}
+
+	sidesStr, modStr, sign, hasMod := rest, "", 1, false
+	if i := strings.IndexAny(rest, "+-"); i >= 0 {
+		hasMod = true
+		sidesStr, modStr = rest[:i], rest[i+1:]
+		if rest[i] == '-' {
+			sign = -1
+		}
+	}
+
+	sides, err := strconv.Atoi(sidesStr)
+	if err != nil {
+		return Expr{}, fmt.Errorf("%w: %q", ErrInvalidExpression, s)
+	}
+
+	mod := 0
+	if hasMod {
+		mod, err = strconv.Atoi(modStr)
+		if err != nil || mod < 0 {
+			return Expr{}, fmt.Errorf("%w: %q", ErrInvalidExpression, s)
+		}
+	}
+
+	if count < 1 || count > MaxDice || sides < 2 || sides > MaxSides {
+		return Expr{}, fmt.Errorf("%w: %q", ErrInvalidExpression, s)
+	}
+	return Expr{Count: count, Sides: sides, Modifier: sign * mod}, nil
+}
+
+// Result of a rolled expression
+type Result struct {
+	Expr  Expr
+	Rolls []int
+	Total int
+}
+
+func (e Expr) Roll(r Roller) Result {
+	rolls := make([]int, e.Count)
+	total := e.Modifier
+	for i := range rolls {
+		rolls[i] = D(r, e.Sides)
+		total += rolls[i]
+	}
+	return Result{Expr: e, Rolls: rolls, Total: total}
+}
+
+func (e Expr) String() string {
+	switch {
+	case e.Modifier > 0:
+		return fmt.Sprintf("%dd%d+%d", e.Count, e.Sides, e.Modifier)
+	case e.Modifier < 0:
+		return fmt.Sprintf("%dd%d%d", e.Count, e.Sides, e.Modifier)
+	default:
+		return fmt.Sprintf("%dd%d", e.Count, e.Sides)
+	}
+}
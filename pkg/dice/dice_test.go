@@ -0,0 +1,55 @@
+package dice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]Expr{
+		"d20":     {Count: 1, Sides: 20},
+		"2d6":     {Count: 2, Sides: 6},
+		"1d8+2":   {Count: 1, Sides: 8, Modifier: 2},
+		"3D4-1":   {Count: 3, Sides: 4, Modifier: -1},
+		" 1d6 ":   {Count: 1, Sides: 6},
+		"10d10+0": {Count: 10, Sides: 10},
+	}
+	for in, want := range tests {
+		got, err := Parse(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "d", "2x6", "0d6", "1d1", "1d6+", "1d6+a", "1000d6"} {
+		_, err := Parse(in)
+		assert.ErrorIs(t, err, ErrInvalidExpression, in)
+	}
+}
+
+func TestExpr_Roll(t *testing.T) {
+	e, err := Parse("4d6-2")
+	require.NoError(t, err)
+
+	r := NewSeededRoller(42)
+	for i := 0; i < 100; i++ {
+		res := e.Roll(r)
+		assert.Len(t, res.Rolls, 4)
+		assert.GreaterOrEqual(t, res.Total, 2)
+		assert.LessOrEqual(t, res.Total, 22)
+	}
+
+	// same seed, same rolls
+	assert.Equal(t, e.Roll(NewSeededRoller(7)), e.Roll(NewSeededRoller(7)))
+}
+
+func TestBetween(t *testing.T) {
+	r := NewSeededRoller(1)
+	for i := 0; i < 100; i++ {
+		v := Between(r, 3, 5)
+		assert.GreaterOrEqual(t, v, 3)
+		assert.LessOrEqual(t, v, 5)
+	}
+	assert.Equal(t, 7, Between(r, 7, 7))
+}
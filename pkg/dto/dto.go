@@ -4,3 +4,31 @@ package dto
 type ListResponse[T any] struct {
 	Data []T `json:"data"`
 }
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Page selects a slice of a long list
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// NewPage gives back a valid page: the limit is clamped to 1..MaxPageLimit,
+// DefaultPageLimit when not given, and the offset is never negative.
+func NewPage(limit, offset int) Page {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	return Page{Limit: min(limit, MaxPageLimit), Offset: max(offset, 0)}
+}
+
+// PageResponse is used as a wrapper for http JSON responses of paginated lists
+type PageResponse[T any] struct {
+	Data   []T `json:"data"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
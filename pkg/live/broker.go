@@ -0,0 +1,76 @@
+package live
+
+import (
+	"beldur/internal/id"
+	"sync"
+)
+
+// subscriptionBuffer is the number of events a subscriber can lag behind
+// before losing the new ones
+const subscriptionBuffer = 32
+
+// Event is delivered to the subscribers of a topic
+type Event struct {
+	Type string
+	Data any
+}
+
+type subscription struct {
+	playerId id.PlayerId
+	events   chan Event
+}
+
+// Broker delivers the events published on a topic to the players connected to it.
+// It keeps everything in memory, so it works with a single instance of the server.
+type Broker struct {
+	mu     sync.RWMutex
+	topics map[string]map[*subscription]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{topics: make(map[string]map[*subscription]struct{})}
+}
+
+// Subscribe connects the player to the topic. The returned function must be called
+// to disconnect, it closes the channel of the events.
+func (b *Broker) Subscribe(topic string, playerId id.PlayerId) (<-chan Event, func()) {
+	sub := &subscription{playerId: playerId, events: make(chan Event, subscriptionBuffer)}
+
+	b.mu.Lock()
+	if b.topics[topic] == nil {
+		b.topics[topic] = make(map[*subscription]struct{})
+	}
+	b.topics[topic][sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.topics[topic], sub)
+			if len(b.topics[topic]) == 0 {
+				delete(b.topics, topic)
+			}
+			close(sub.events)
+			b.mu.Unlock()
+		})
+	}
+	return sub.events, unsubscribe
+}
+
+// Publish sends the event to the players of the topic accepted by to, every player if to is nil.
+// It never blocks: subscribers too slow to keep up lose the event.
+func (b *Broker) Publish(topic string, event Event, to func(playerId id.PlayerId) bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.topics[topic] {
+		if to != nil && !to(sub.playerId) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
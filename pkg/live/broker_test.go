@@ -0,0 +1,48 @@
+package live
+
+import (
+	"beldur/internal/id"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+
+	first, unsubscribeFirst := b.Subscribe("campaign-1", 1)
+	second, unsubscribeSecond := b.Subscribe("campaign-1", 2)
+	other, unsubscribeOther := b.Subscribe("campaign-2", 1)
+	defer unsubscribeSecond()
+	defer unsubscribeOther()
+
+	b.Publish("campaign-1", Event{Type: "message", Data: "hello"}, nil)
+	b.Publish("campaign-1", Event{Type: "message", Data: "whisper"}, func(p id.PlayerId) bool { return p == 2 })
+
+	assert.Equal(t, "hello", (<-first).Data)
+	assert.Equal(t, "hello", (<-second).Data)
+	assert.Equal(t, "whisper", (<-second).Data)
+	assert.Empty(t, first)
+	assert.Empty(t, other)
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+	_, ok := <-first
+	require.False(t, ok)
+
+	// no panic publishing after a subscriber left
+	b.Publish("campaign-1", Event{Type: "message", Data: "bye"}, nil)
+	assert.Equal(t, "bye", (<-second).Data)
+}
+
+func TestBroker_SlowSubscriber(t *testing.T) {
+	b := NewBroker()
+	events, unsubscribe := b.Subscribe("campaign-1", 1)
+	defer unsubscribe()
+
+	for range subscriptionBuffer + 5 {
+		b.Publish("campaign-1", Event{Type: "message"}, nil)
+	}
+	assert.Len(t, events, subscriptionBuffer)
+}
@@ -0,0 +1,65 @@
+package live
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// heartbeat keeps the connection open through proxies closing idle ones
+const heartbeat = 25 * time.Second
+
+// Stream sends the events to the client as Server-Sent Events, until the client disconnects
+// or the channel is closed. Then it calls unsubscribe.
+func Stream(c *fiber.Ctx, events <-chan Event, unsubscribe func()) error {
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		// tells the client the stream is open
+		if !writeComment(w, "connected") {
+			return
+		}
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(e.Data)
+				if err != nil {
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if !writeComment(w, "ping") {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// writeComment writes a line ignored by the clients, false if the client is gone
+func writeComment(w *bufio.Writer, comment string) bool {
+	if _, err := fmt.Fprintf(w, ": %s\n\n", comment); err != nil {
+		return false
+	}
+	return w.Flush() == nil
+}
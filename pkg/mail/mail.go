@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"beldur/pkg/logger"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers the messages to the users
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Outbox is a Mailer that never leaves the machine: every message is logged and, when a directory
+// is given, written there as an .eml file. It lets the flows relying on emails work without SMTP.
+type Outbox struct {
+	dir string
+}
+
+// NewOutbox writes the messages under dir, or only logs them if dir is empty
+func NewOutbox(dir string) (*Outbox, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, err
+		}
+	}
+	return &Outbox{dir: dir}, nil
+}
+
+func (o *Outbox) Send(_ context.Context, msg Message) error {
+	logger.Info("mail sent to outbox", "to", msg.To, "subject", msg.Subject)
+	if o.dir == "" {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405"), rand.Text()[:8])
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	b.WriteString(msg.Body)
+
+	return os.WriteFile(filepath.Join(o.dir, name), []byte(b.String()), 0o640)
+}
@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"beldur/pkg/logger"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutbox(t *testing.T) {
+	logger.Init()
+	dir := t.TempDir()
+	outbox, err := NewOutbox(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, outbox.Send(context.Background(), Message{
+		To:      "dm@example.com",
+		Subject: "Reset your password",
+		Body:    "Your code is 1234",
+	}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "To: dm@example.com\r\n")
+	assert.Contains(t, string(data), "Subject: Reset your password\r\n")
+	assert.Contains(t, string(data), "\r\n\r\nYour code is 1234")
+}
@@ -0,0 +1,156 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// HTML tags and comments. Autolinks like <https://example.com> are kept,
+	// their "tag name" is followed by a colon.
+	htmlComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlTag     = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9-]*(\s[^>]*)?/?>`)
+	// what is left able to open HTML once the tags are removed, along with the autolinks to keep
+	htmlOpening = regexp.MustCompile("<[a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^<>\\s]*>|<[a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9.-]+>|<[a-zA-Z/!?]")
+
+	// link destinations, checked by safeDestination
+	inlineLink = regexp.MustCompile(`\]\(\s*(<[^<>\n]*>|[^\s<()]*(?:\([^\s()]*\)[^\s()]*)*)`)
+	autolink   = regexp.MustCompile(`<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^<>\s]*)>`)
+	reference  = regexp.MustCompile(`(?m)^(\s{0,3}\[[^\]]+\]:\s*)(<[^<>\n]*>|\S+)`)
+
+	urlScheme       = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*):`)
+	backslashEscape = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
+)
+
+// Sanitize makes a markdown text safe to be rendered by the clients: raw HTML is removed, what
+// could still open a tag is escaped, and links other than http, https, mailto or relative ones
+// point to "#". Fenced code blocks are left untouched, renderers show them as text. Line endings
+// are normalized to \n.
+func Sanitize(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, src)
+
+	var (
+		out   strings.Builder
+		chunk strings.Builder
+		fence string
+	)
+	flush := func() {
+		out.WriteString(sanitizeText(chunk.String()))
+		chunk.Reset()
+	}
+
+	for line := range strings.Lines(src) {
+		switch {
+		case fence != "":
+			out.WriteString(line)
+			if closesFence(line, fence) {
+				fence = ""
+			}
+		case openingFence(line) != "":
+			flush()
+			fence = openingFence(line)
+			out.WriteString(line)
+		default:
+			chunk.WriteString(line)
+		}
+	}
+	flush()
+	return out.String()
+}
+
+// openingFence gives back the fence opening a fenced code block on the line, following the
+// CommonMark rules: at most 3 spaces of indent, 3 backticks or tildes at least, and no
+// backtick in the info string of a backtick fence
+func openingFence(line string) string {
+	rest := strings.TrimLeft(line, " ")
+	if len(line)-len(rest) > 3 || rest == "" || (rest[0] != '`' && rest[0] != '~') {
+		return ""
+	}
+	n := len(rest) - len(strings.TrimLeft(rest, rest[:1]))
+	if n < 3 {
+		return ""
+	}
+	if rest[0] == '`' && strings.Contains(rest[n:], "`") {
+		return ""
+	}
+	return rest[:n]
+}
+
+// closesFence tells if the line closes the block opened by fence: at most 3 spaces of indent,
+// at least as many of the same character, and nothing after but spaces
+func closesFence(line string, fence string) bool {
+	rest := strings.TrimLeft(line, " ")
+	if len(line)-len(rest) > 3 {
+		return false
+	}
+	n := len(rest) - len(strings.TrimLeft(rest, fence[:1]))
+	return n >= len(fence) && strings.TrimSpace(rest[n:]) == ""
+}
+
+func sanitizeText(s string) string {
+	// until nothing is left, removing a tag can join the pieces of another one
+	for {
+		stripped := htmlTag.ReplaceAllString(htmlComment.ReplaceAllString(s, ""), "")
+		if stripped == s {
+			break
+		}
+		s = stripped
+	}
+	s = inlineLink.ReplaceAllStringFunc(s, func(m string) string {
+		if safeDestination(inlineLink.FindStringSubmatch(m)[1]) {
+			return m
+		}
+		return "](#"
+	})
+	s = autolink.ReplaceAllStringFunc(s, func(m string) string {
+		if safeDestination(m[1 : len(m)-1]) {
+			return m
+		}
+		return ""
+	})
+	s = reference.ReplaceAllStringFunc(s, func(m string) string {
+		sub := reference.FindStringSubmatch(m)
+		if safeDestination(sub[2]) {
+			return m
+		}
+		return sub[1] + "#"
+	})
+	return htmlOpening.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasSuffix(m, ">") {
+			return m
+		}
+		return "&lt;" + m[1:]
+	})
+}
+
+// safeDestination tells if a link destination is relative, or has the http, https or mailto
+// scheme, once read the way the renderers do: backslash escapes and entities are decoded, and
+// the browsers ignore the whitespace and control characters.
+func safeDestination(dest string) bool {
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+	dest = html.UnescapeString(backslashEscape.ReplaceAllString(dest, "$1"))
+	dest = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, dest)
+
+	m := urlScheme.FindStringSubmatch(dest)
+	if m == nil {
+		return true
+	}
+	switch strings.ToLower(m[1]) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
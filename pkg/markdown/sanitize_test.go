@@ -0,0 +1,48 @@
+package markdown
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"plain", "# Session 1\n\nThe party **wins**.", "# Session 1\n\nThe party **wins**."},
+		{"script", "Hi<script>alert(1)</script>!", "Hialert(1)!"},
+		{"tag with attributes", `<img src=x onerror="alert(1)">text`, "text"},
+		{"comment", "a<!-- secret\nnote -->b", "ab"},
+		{"autolink kept", "see <https://example.com>", "see <https://example.com>"},
+		{"comparison kept", "AC < 15 and 3 > 2", "AC < 15 and 3 > 2"},
+		{"javascript link", "[click](javascript:alert(1))", "[click](#)"},
+		{"data link", "![x]( DATA:text/html;base64,xx)", "![x](#)"},
+		{"http link kept", "[map](https://example.com/map.png)", "[map](https://example.com/map.png)"},
+		{"javascript autolink", "<javascript:alert(1)>", ""},
+		{"javascript reference", "[a]\n\n[a]: javascript:alert(1)", "[a]\n\n[a]: #"},
+		{"entity in the scheme", "[x](&#106;avascript:alert(1))", "[x](#)"},
+		{"entity colon", "[x](javascript&#58;alert(1))", "[x](#)"},
+		{"entity in a reference", "[x]\n\n[x]: &#106;avascript:alert(1)", "[x]\n\n[x]: #"},
+		{"escaped colon", "[x](javascript\\:alert(1))", "[x](#)"},
+		{"encoded tab in the scheme", "[x](java&#9;script:alert(1))", "[x](#)"},
+		{"unknown scheme", "[x](vbscript:msgbox)", "[x](#)"},
+		{"mailto kept", "[me](mailto:gm@example.com)", "[me](mailto:gm@example.com)"},
+		{"relative kept", "[notes](/campaign/1/wiki?q=a:b)", "[notes](/campaign/1/wiki?q=a:b)"},
+		{"title kept", `[x](javascript:alert(1) "t")`, `[x](# "t")`},
+		{"crlf and control chars", "a\r\nb\x00c", "a\nbc"},
+		{"code block untouched", "```\n<b>bold</b>\n```\n<b>x</b>", "```\n<b>bold</b>\n```\nx"},
+		{"nested tags", "<scr<script>ipt>alert(1)</script>", "alert(1)"},
+		{"unclosed tag escaped", "<img src=x onerror=alert(1)", "&lt;img src=x onerror=alert(1)"},
+		{"fence indented by 4 spaces", "    ```\n<img src=x onerror=alert(1)>\n    ```", "    ```\n\n    ```"},
+		{"backtick in the info string", "```a`\n<img src=x onerror=alert(1)>", "```a`\n"},
+		{"longer closing fence", "````\n```\n<b>x</b>\n`````\n<b>y</b>", "````\n```\n<b>x</b>\n`````\ny"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Sanitize(tt.src))
+		})
+	}
+}
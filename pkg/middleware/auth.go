@@ -2,17 +2,27 @@ package middleware
 
 import (
 	"beldur/pkg/auth"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 const principalKey = "principal"
 
-func Auth(verifier auth.TokenVerifier) fiber.Handler {
+// Auth authenticates the request with the token of the Authorization header, or else with the
+// session cookie. The requests authenticated by the cookie which change something must carry
+// the CSRF token, see SessionCookie.
+func Auth(verifier auth.TokenVerifier, cookie SessionCookie) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		token := c.Cookies("jwt")
+		token := bearerToken(c)
 		if token == "" {
-			return c.SendStatus(fiber.StatusUnauthorized)
+			token = cookie.token(c)
+			if token == "" {
+				return c.SendStatus(fiber.StatusUnauthorized)
+			}
+			if !cookie.checkCSRF(c, token) {
+				return c.SendStatus(fiber.StatusForbidden)
+			}
 		}
 
 		verified, err := verifier.Verify(c.Context(), token)
@@ -23,6 +33,8 @@ func Auth(verifier auth.TokenVerifier) fiber.Handler {
 		c.Locals(principalKey, auth.Principal{
 			AccountID: verified.Subject,
 			PlayerID:  verified.PlayerId,
+			Scopes:    verified.Scopes,
+			SessionID: verified.SessionID,
 		})
 
 		return c.Next()
@@ -34,3 +46,40 @@ func PrincipalFromCtx(c *fiber.Ctx) (auth.Principal, bool) {
 	p, ok := v.(auth.Principal)
 	return p, ok
 }
+
+// RequireScope rejects the personal access tokens not given the scope, it goes after Auth
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		p, ok := PrincipalFromCtx(c)
+		if !ok {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+		if !p.HasScope(scope) {
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+		return c.Next()
+	}
+}
+
+// SessionOnly rejects the personal access tokens, for the routes managing the account itself.
+// It goes after Auth.
+func SessionOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		p, ok := PrincipalFromCtx(c)
+		if !ok {
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+		if !p.IsSession() {
+			return c.SendStatus(fiber.StatusForbidden)
+		}
+		return c.Next()
+	}
+}
+
+func bearerToken(c *fiber.Ctx) string {
+	scheme, token, found := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
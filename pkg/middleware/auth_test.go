@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"beldur/pkg/auth"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubVerifier map[string]auth.Verified
+
+func (v stubVerifier) Verify(_ context.Context, token string) (auth.Verified, error) {
+	verified, ok := v[token]
+	if !ok {
+		return auth.Verified{}, errors.New("invalid token")
+	}
+	return verified, nil
+}
+
+func newAuthApp(cookie SessionCookie) *fiber.App {
+	verifier := stubVerifier{
+		"session": {Subject: 1, PlayerId: 7},
+		"pat":     {Subject: 1, PlayerId: 7, Scopes: []string{auth.ScopeCampaignRead}},
+	}
+	app := fiber.New()
+	app.Post("/login", func(c *fiber.Ctx) error {
+		cookie.Set(c, "session")
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/campaign", Auth(verifier, cookie), RequireScope(auth.ScopeCampaignRead), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/campaign", Auth(verifier, cookie), RequireScope(auth.ScopeCampaignWrite), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusCreated)
+	})
+	app.Post("/account", Auth(verifier, cookie), SessionOnly(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func TestAuth(t *testing.T) {
+	app := newAuthApp(SessionCookie{})
+
+	do := func(method, path string, header http.Header) int {
+		req := httptest.NewRequest(method, path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+	withCookie := func(csrf string) http.Header {
+		h := http.Header{"Cookie": {"jwt=session"}}
+		if csrf != "" {
+			h.Set(CSRFHeader, csrf)
+		}
+		return h
+	}
+	bearer := func(token string) http.Header {
+		return http.Header{"Authorization": {"Bearer " + token}}
+	}
+
+	assert.Equal(t, fiber.StatusUnauthorized, do("GET", "/campaign", nil))
+	assert.Equal(t, fiber.StatusUnauthorized, do("GET", "/campaign", bearer("unknown")))
+
+	// the cookie needs the CSRF token to change something
+	assert.Equal(t, fiber.StatusOK, do("GET", "/campaign", withCookie("")))
+	assert.Equal(t, fiber.StatusForbidden, do("POST", "/campaign", withCookie("")))
+	assert.Equal(t, fiber.StatusForbidden, do("POST", "/campaign", withCookie(CSRFToken("other"))))
+	assert.Equal(t, fiber.StatusCreated, do("POST", "/campaign", withCookie(CSRFToken("session"))))
+
+	// a bearer token doesn't, but it is limited by its scopes
+	assert.Equal(t, fiber.StatusCreated, do("POST", "/campaign", bearer("session")))
+	assert.Equal(t, fiber.StatusOK, do("GET", "/campaign", bearer("pat")))
+	assert.Equal(t, fiber.StatusForbidden, do("POST", "/campaign", bearer("pat")))
+	assert.Equal(t, fiber.StatusForbidden, do("POST", "/account", bearer("pat")))
+	assert.Equal(t, fiber.StatusOK, do("POST", "/account", bearer("session")))
+}
+
+func TestSessionCookie_Set(t *testing.T) {
+	app := newAuthApp(SessionCookie{Domain: "beldur.example", SameSite: "None", MaxAge: time.Hour})
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/login", nil))
+	require.NoError(t, err)
+
+	cookies := map[string]*http.Cookie{}
+	for _, c := range resp.Cookies() {
+		cookies[c.Name] = c
+	}
+	require.Contains(t, cookies, "jwt")
+	require.Contains(t, cookies, "csrf_token")
+
+	session := cookies["jwt"]
+	assert.Equal(t, "session", session.Value)
+	assert.True(t, session.HttpOnly)
+	assert.True(t, session.Secure, "SameSite=None needs Secure")
+	assert.Equal(t, http.SameSiteNoneMode, session.SameSite)
+	assert.Equal(t, "beldur.example", session.Domain)
+	assert.Equal(t, 3600, session.MaxAge)
+
+	csrf := cookies["csrf_token"]
+	assert.Equal(t, CSRFToken("session"), csrf.Value)
+	assert.False(t, csrf.HttpOnly)
+}
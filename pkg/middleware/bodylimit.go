@@ -0,0 +1,16 @@
+package middleware
+
+import "github.com/gofiber/fiber/v2"
+
+// BodyLimit refuses with 413 the requests whose body is over limit, unless skip tells otherwise.
+// The server reads the body before the routing, so its own limit (fiber.Config.BodyLimit) must
+// stay above the largest body accepted by the skipped routes.
+func BodyLimit(limit int, skip func(c *fiber.Ctx) bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// the raw body, c.Body() would decompress it
+		if len(c.Request().Body()) > limit && (skip == nil || !skip(c)) {
+			return c.SendStatus(fiber.StatusRequestEntityTooLarge)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimit(t *testing.T) {
+	app := fiber.New()
+	app.Use(BodyLimit(10, func(c *fiber.Ctx) bool { return c.Path() == "/upload" }))
+	for _, path := range []string{"/upload", "/json"} {
+		app.Post(path, func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+	}
+
+	do := func(path string, body string) int {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(body)))
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, fiber.StatusOK, do("/json", "0123456789"))
+	assert.Equal(t, fiber.StatusRequestEntityTooLarge, do("/json", "0123456789a"))
+	assert.Equal(t, fiber.StatusOK, do("/upload", "0123456789a"))
+}
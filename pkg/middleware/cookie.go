@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"beldur/pkg/auth"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// CSRFHeader carries the value of the CSRF cookie in the requests authenticated by the
+	// session cookie which change something
+	CSRFHeader = "X-CSRF-Token"
+
+	defaultSessionCookieName = "jwt"
+	defaultCSRFCookieName    = "csrf_token"
+)
+
+// SessionCookie sets the attributes of the cookie holding the session token, and of the cookie
+// the client reads to send back the CSRF token. The zero value is usable for development.
+type SessionCookie struct {
+	// Name of the session cookie, jwt if empty
+	Name string
+	// CSRFName is the name of the cookie readable by the client, csrf_token if empty
+	CSRFName string
+	Domain   string
+	Secure   bool
+	// SameSite is Lax, Strict or None, Lax if empty. None makes the cookies Secure.
+	SameSite string
+	// MaxAge should match the expiration of the session tokens, the cookies last as long as
+	// the browser session if zero
+	MaxAge time.Duration
+}
+
+// Set gives the session token in an HTTP only cookie, along with the CSRF token derived from it
+func (s SessionCookie) Set(c *fiber.Ctx, token string) {
+	var expires time.Time
+	maxAge := int(s.MaxAge.Seconds())
+	if maxAge > 0 {
+		expires = time.Now().Add(s.MaxAge)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     s.name(),
+		Value:    token,
+		Path:     "/",
+		Domain:   s.Domain,
+		MaxAge:   maxAge,
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   s.secure(),
+		SameSite: s.sameSite(),
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     s.csrfName(),
+		Value:    CSRFToken(token),
+		Path:     "/",
+		Domain:   s.Domain,
+		MaxAge:   maxAge,
+		Expires:  expires,
+		HTTPOnly: false,
+		Secure:   s.secure(),
+		SameSite: s.sameSite(),
+	})
+}
+
+func (s SessionCookie) token(c *fiber.Ctx) string {
+	return c.Cookies(s.name())
+}
+
+// checkCSRF tells whether the request carries the CSRF token of the session token. The safe
+// methods don't need it.
+func (s SessionCookie) checkCSRF(c *fiber.Ctx, token string) bool {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return true
+	}
+	given := c.Get(CSRFHeader)
+	return given != "" && subtle.ConstantTimeCompare([]byte(given), []byte(CSRFToken(token))) == 1
+}
+
+func (s SessionCookie) name() string {
+	if s.Name == "" {
+		return defaultSessionCookieName
+	}
+	return s.Name
+}
+
+func (s SessionCookie) csrfName() string {
+	if s.CSRFName == "" {
+		return defaultCSRFCookieName
+	}
+	return s.CSRFName
+}
+
+func (s SessionCookie) sameSite() string {
+	switch strings.ToLower(s.SameSite) {
+	case "strict":
+		return fiber.CookieSameSiteStrictMode
+	case "none":
+		return fiber.CookieSameSiteNoneMode
+	default:
+		return fiber.CookieSameSiteLaxMode
+	}
+}
+
+func (s SessionCookie) secure() bool {
+	return s.Secure || s.sameSite() == fiber.CookieSameSiteNoneMode
+}
+
+// CSRFToken derives the CSRF token from the session token. Another site can't read the HTTP
+// only session cookie, so it can't send the matching header.
+func CSRFToken(sessionToken string) string {
+	return auth.HashSecretToken(sessionToken)
+}
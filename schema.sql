@@ -1,4 +1,40 @@
 -- Clean DB (drop in dependency order)
+DROP TABLE IF EXISTS account_deletion_handovers;
+DROP TABLE IF EXISTS account_deletions;
+DROP TABLE IF EXISTS account_sessions;
+DROP TABLE IF EXISTS personal_access_tokens;
+DROP TABLE IF EXISTS oidc_login_states;
+DROP TABLE IF EXISTS account_identities;
+DROP TABLE IF EXISTS login_challenges;
+DROP TABLE IF EXISTS account_recovery_codes;
+DROP TABLE IF EXISTS email_verification_tokens;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS battle_map_tokens;
+DROP TABLE IF EXISTS battle_maps;
+DROP TABLE IF EXISTS handout_recipients;
+DROP TABLE IF EXISTS handouts;
+DROP TABLE IF EXISTS wiki_revisions;
+DROP TABLE IF EXISTS wiki_links;
+DROP TABLE IF EXISTS wiki_entries;
+DROP TABLE IF EXISTS quest_objectives;
+DROP TABLE IF EXISTS quests;
+DROP TABLE IF EXISTS chat_message_audit;
+DROP TABLE IF EXISTS chat_messages;
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS calendar_tokens;
+DROP TABLE IF EXISTS game_session_attendance;
+DROP TABLE IF EXISTS game_session_votes;
+DROP TABLE IF EXISTS game_session_slots;
+DROP TABLE IF EXISTS game_sessions;
+DROP TABLE IF EXISTS encounter_actions;
+DROP TABLE IF EXISTS encounter_combatants;
+DROP TABLE IF EXISTS encounters;
+DROP TABLE IF EXISTS npc_templates;
+DROP TABLE IF EXISTS character_transfers;
+DROP TABLE IF EXISTS character_versions;
+DROP TABLE IF EXISTS character_attacks;
+DROP TABLE IF EXISTS character_items;
+DROP TABLE IF EXISTS characters;
 DROP TABLE IF EXISTS campaigns_players;
 DROP TABLE IF EXISTS campaigns;
 DROP TABLE IF EXISTS players;
@@ -10,8 +46,21 @@ CREATE TABLE accounts (
     username     VARCHAR(20) UNIQUE NOT NULL,
     password     VARCHAR(255) NOT NULL,
     email        VARCHAR(255) UNIQUE,
+    -- NULL until the owner uses the link sent to the email
+    email_verified_at TIMESTAMP,
     created_at   TIMESTAMP NOT NULL DEFAULT NOW(),
-    last_access  TIMESTAMP NOT NULL DEFAULT NOW()
+    last_access  TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- bumped to revoke all the tokens issued for the account
+    session_version INT NOT NULL DEFAULT 0,
+    -- TOTP second factor, required at login once enabled
+    totp_secret VARCHAR(64),
+    two_factor_enabled_at TIMESTAMP,
+    -- time step of the last code accepted, a code cannot be used twice
+    totp_last_step BIGINT NOT NULL DEFAULT 0,
+    -- codes given at login since the last right one, across the login challenges
+    two_factor_failures INT NOT NULL DEFAULT 0,
+    -- no code is accepted at login before, set after too many wrong codes
+    two_factor_locked_until TIMESTAMPTZ
 );
 
 -- Players (1:1 with accounts)
@@ -47,6 +96,7 @@ CREATE TABLE characters (
     character_id SERIAL PRIMARY KEY,
     name VARCHAR(50) NOT NULL,
     description VARCHAR(500),
+    notes TEXT NOT NULL DEFAULT '',
     is_npc BOOLEAN,
     base_strength INTEGER NOT NULL,
     base_dexterity INTEGER NOT NULL,
@@ -54,18 +104,117 @@ CREATE TABLE characters (
     base_intelligence INTEGER NOT NULL,
     base_wisdom INTEGER NOT NULL,
     base_charisma INTEGER NOT NULL,
+    hit_points INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE',
+    died_at TIMESTAMP,
+    retired_at TIMESTAMP,
     player_id INTEGER NOT NULL,
     campaign_id INTEGER NOT NULL,
 
     CONSTRAINT fk_characters_campaign
         FOREIGN KEY (campaign_id)
-        REFERENCES campaigns(campaign_id),
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE,
 
     CONSTRAINT fk_characters_player
         FOREIGN KEY (player_id)
         REFERENCES players(player_id)
 );
 
+CREATE TABLE character_items (
+    item_id SERIAL PRIMARY KEY,
+    character_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    quantity INTEGER NOT NULL DEFAULT 1,
+
+    CONSTRAINT fk_character_items_character
+        FOREIGN KEY (character_id)
+        REFERENCES characters(character_id)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE character_attacks (
+    character_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    bonus INTEGER NOT NULL DEFAULT 0,
+    damage VARCHAR(20) NOT NULL,
+
+    CONSTRAINT pk_character_attacks
+        PRIMARY KEY (character_id, position),
+
+    CONSTRAINT fk_character_attacks_character
+        FOREIGN KEY (character_id)
+        REFERENCES characters(character_id)
+        ON DELETE CASCADE
+);
+
+-- Every change of a character sheet, snapshot is the sheet in the export format
+CREATE TABLE character_versions (
+    character_id INTEGER NOT NULL,
+    version INTEGER NOT NULL,
+    author_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    snapshot JSONB NOT NULL,
+    changes JSONB NOT NULL DEFAULT '[]',
+    restored_from INTEGER,
+
+    CONSTRAINT pk_character_versions
+        PRIMARY KEY (character_id, version),
+
+    CONSTRAINT fk_character_versions_character
+        FOREIGN KEY (character_id)
+        REFERENCES characters(character_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_character_versions_author
+        FOREIGN KEY (author_id)
+        REFERENCES players(player_id)
+);
+
+CREATE TABLE character_transfers (
+    transfer_id SERIAL PRIMARY KEY,
+    character_id INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    source_campaign_id INTEGER NOT NULL,
+    target_campaign_id INTEGER NOT NULL,
+    mode VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    decided_at TIMESTAMP,
+    result_character_id INTEGER,
+
+    CONSTRAINT fk_character_transfers_character
+        FOREIGN KEY (character_id)
+        REFERENCES characters(character_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_character_transfers_player
+        FOREIGN KEY (player_id)
+        REFERENCES players(player_id),
+
+    CONSTRAINT fk_character_transfers_source
+        FOREIGN KEY (source_campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_character_transfers_target
+        FOREIGN KEY (target_campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_character_transfers_result
+        FOREIGN KEY (result_character_id)
+        REFERENCES characters(character_id)
+        ON DELETE SET NULL
+);
+
+-- a character can have only one pending transfer to the same campaign
+CREATE UNIQUE INDEX uq_character_transfers_pending
+    ON character_transfers (character_id, target_campaign_id)
+    WHERE status = 'PENDING';
+
 CREATE TABLE campaigns_players (
     campaign_id  INTEGER NOT NULL,
     player_id    INTEGER NOT NULL,
@@ -84,4 +233,603 @@ CREATE TABLE campaigns_players (
         FOREIGN KEY (player_id)
         REFERENCES players(player_id)
         ON DELETE CASCADE
-);
\ No newline at end of file
+);
+
+-- NPC templates (bestiary). Templates without a campaign are in the shared library
+CREATE TABLE npc_templates (
+    template_id SERIAL PRIMARY KEY,
+    campaign_id INTEGER,
+    author_id INTEGER NOT NULL,
+    name VARCHAR(40) NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    strength_min INTEGER NOT NULL,
+    strength_max INTEGER NOT NULL,
+    dexterity_min INTEGER NOT NULL,
+    dexterity_max INTEGER NOT NULL,
+    constitution_min INTEGER NOT NULL,
+    constitution_max INTEGER NOT NULL,
+    intelligence_min INTEGER NOT NULL,
+    intelligence_max INTEGER NOT NULL,
+    wisdom_min INTEGER NOT NULL,
+    wisdom_max INTEGER NOT NULL,
+    charisma_min INTEGER NOT NULL,
+    charisma_max INTEGER NOT NULL,
+    hit_points_min INTEGER NOT NULL,
+    hit_points_max INTEGER NOT NULL,
+    attacks JSONB NOT NULL DEFAULT '[]',
+    loot JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_npc_templates_campaign
+        FOREIGN KEY (campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_npc_templates_author
+        FOREIGN KEY (author_id)
+        REFERENCES players(player_id)
+);
+
+CREATE TABLE encounters (
+    encounter_id SERIAL PRIMARY KEY,
+    campaign_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    status VARCHAR(10) NOT NULL,
+    round INTEGER NOT NULL DEFAULT 1,
+    turn INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ended_at TIMESTAMP,
+
+    CONSTRAINT fk_encounters_campaign
+        FOREIGN KEY (campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE encounter_combatants (
+    encounter_id INTEGER NOT NULL,
+    character_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    is_npc BOOLEAN NOT NULL,
+    initiative INTEGER NOT NULL,
+    dex_modifier INTEGER NOT NULL,
+    hit_points INTEGER NOT NULL,
+    max_hit_points INTEGER NOT NULL,
+    conditions TEXT[] NOT NULL DEFAULT '{}',
+
+    CONSTRAINT pk_encounter_combatants
+        PRIMARY KEY (encounter_id, character_id),
+
+    CONSTRAINT fk_encounter_combatants_encounter
+        FOREIGN KEY (encounter_id)
+        REFERENCES encounters(encounter_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_encounter_combatants_character
+        FOREIGN KEY (character_id)
+        REFERENCES characters(character_id)
+        ON DELETE CASCADE
+);
+
+-- character_id is not a foreign key, the history outlives deleted NPCs
+CREATE TABLE encounter_actions (
+    action_id SERIAL PRIMARY KEY,
+    encounter_id INTEGER NOT NULL,
+    round INTEGER NOT NULL,
+    kind VARCHAR(30) NOT NULL,
+    character_id INTEGER,
+    detail JSONB NOT NULL DEFAULT '{}',
+    author_id INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_encounter_actions_encounter
+        FOREIGN KEY (encounter_id)
+        REFERENCES encounters(encounter_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_encounter_actions_author
+        FOREIGN KEY (author_id)
+        REFERENCES players(player_id)
+);
+
+-- dates of the sessions keep the time zone, members may live in different ones
+CREATE TABLE game_sessions (
+    session_id SERIAL PRIMARY KEY,
+    campaign_id INTEGER NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    planned_start TIMESTAMPTZ,
+    duration_minutes INTEGER NOT NULL,
+    location VARCHAR(500) NOT NULL DEFAULT '',
+    status VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_game_sessions_campaign
+        FOREIGN KEY (campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE game_session_slots (
+    slot_id SERIAL PRIMARY KEY,
+    session_id INTEGER NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+
+    CONSTRAINT fk_game_session_slots_session
+        FOREIGN KEY (session_id)
+        REFERENCES game_sessions(session_id)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE game_session_votes (
+    slot_id INTEGER NOT NULL,
+    campaign_id INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    vote VARCHAR(10) NOT NULL,
+
+    CONSTRAINT pk_game_session_votes
+        PRIMARY KEY (slot_id, player_id),
+
+    CONSTRAINT fk_game_session_votes_slot
+        FOREIGN KEY (slot_id)
+        REFERENCES game_session_slots(slot_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_game_session_votes_member
+        FOREIGN KEY (campaign_id, player_id)
+        REFERENCES campaigns_players(campaign_id, player_id)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE game_session_attendance (
+    session_id INTEGER NOT NULL,
+    campaign_id INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    attendance VARCHAR(10) NOT NULL,
+
+    CONSTRAINT pk_game_session_attendance
+        PRIMARY KEY (session_id, player_id),
+
+    CONSTRAINT fk_game_session_attendance_session
+        FOREIGN KEY (session_id)
+        REFERENCES game_sessions(session_id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_game_session_attendance_member
+        FOREIGN KEY (campaign_id, player_id)
+        REFERENCES campaigns_players(campaign_id, player_id)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE calendar_tokens (
+    token_id SERIAL PRIMARY KEY,
+    player_id INT NOT NULL,
+    -- SHA-256 of the secret token, the token itself is never stored
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP,
+    CONSTRAINT fk_calendar_tokens_player
+        FOREIGN KEY (player_id)
+        REFERENCES players(player_id)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE journal_entries (
+    entry_id SERIAL PRIMARY KEY,
+    campaign_id INT NOT NULL,
+    -- mandatory for recaps
+    session_id INT,
+    kind VARCHAR(10) NOT NULL,
+    author_id INT NOT NULL,
+    title VARCHAR(100) NOT NULL DEFAULT '',
+    -- sanitized markdown
+    body TEXT NOT NULL,
+    visibility VARCHAR(10) NOT NULL DEFAULT 'EVERYONE',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP,
+    CONSTRAINT fk_journal_entries_campaign
+        FOREIGN KEY (campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_journal_entries_session
+        FOREIGN KEY (session_id)
+        REFERENCES game_sessions(session_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_journal_entries_author
+        FOREIGN KEY (author_id)
+        REFERENCES players(player_id)
+);
+
+-- pages of the journal, newest first
+CREATE INDEX idx_journal_entries_campaign
+    ON journal_entries (campaign_id, occurred_at DESC, entry_id DESC);
+
+CREATE TABLE chat_messages (
+    message_id SERIAL PRIMARY KEY,
+    campaign_id INT NOT NULL,
+    author_id INT NOT NULL,
+    -- character the author speaks as
+    character_id INT,
+    character_name VARCHAR(255) NOT NULL DEFAULT '',
+    audience VARCHAR(10) NOT NULL DEFAULT 'EVERYONE',
+    -- recipient of a whisper to a player
+    whisper_to INT,
+    body TEXT NOT NULL,
+    roll_expression VARCHAR(20),
+    roll_results INT[],
+    roll_total INT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    edited_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    CONSTRAINT fk_chat_messages_campaign
+        FOREIGN KEY (campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_chat_messages_author
+        FOREIGN KEY (author_id)
+        REFERENCES players(player_id),
+    CONSTRAINT fk_chat_messages_character
+        FOREIGN KEY (character_id)
+        REFERENCES characters(character_id)
+        ON DELETE SET NULL,
+    CONSTRAINT fk_chat_messages_whisper_to
+        FOREIGN KEY (whisper_to)
+        REFERENCES players(player_id)
+);
+
+-- history of a campaign, read by cursor on the id
+CREATE INDEX idx_chat_messages_campaign
+    ON chat_messages (campaign_id, message_id);
+
+CREATE TABLE chat_message_audit (
+    audit_id SERIAL PRIMARY KEY,
+    message_id INT NOT NULL,
+    action VARCHAR(10) NOT NULL,
+    previous_body TEXT NOT NULL,
+    actor_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_chat_message_audit_message
+        FOREIGN KEY (message_id)
+        REFERENCES chat_messages(message_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_chat_message_audit_actor
+        FOREIGN KEY (actor_id)
+        REFERENCES players(player_id)
+);
+
+CREATE TABLE quests (
+    quest_id SERIAL PRIMARY KEY,
+    campaign_id INT NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    -- NPC who gave the quest
+    giver_id INT,
+    status VARCHAR(10) NOT NULL DEFAULT 'HIDDEN',
+    reward_xp INT NOT NULL DEFAULT 0,
+    -- [{"name": ..., "quantity": ...}]
+    reward_items JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    closed_at TIMESTAMP,
+    CONSTRAINT fk_quests_campaign
+        FOREIGN KEY (campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_quests_giver
+        FOREIGN KEY (giver_id)
+        REFERENCES characters(character_id)
+        ON DELETE SET NULL
+);
+
+CREATE TABLE quest_objectives (
+    objective_id SERIAL PRIMARY KEY,
+    quest_id INT NOT NULL,
+    description VARCHAR(200) NOT NULL,
+    done BOOLEAN NOT NULL DEFAULT FALSE,
+    position INT NOT NULL,
+    CONSTRAINT fk_quest_objectives_quest
+        FOREIGN KEY (quest_id)
+        REFERENCES quests(quest_id)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE wiki_entries (
+    entry_id SERIAL PRIMARY KEY,
+    campaign_id INT NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    category VARCHAR(10) NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    -- body without the sections secret to the master
+    public_body TEXT NOT NULL DEFAULT '',
+    author_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    search_public TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', title), 'A') ||
+        setweight(to_tsvector('simple', public_body), 'B')
+    ) STORED,
+    search_all TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', title), 'A') ||
+        setweight(to_tsvector('simple', body), 'B')
+    ) STORED,
+    CONSTRAINT fk_wiki_entries_campaign
+        FOREIGN KEY (campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_wiki_entries_author
+        FOREIGN KEY (author_id)
+        REFERENCES players(player_id)
+);
+
+-- links are resolved by title, case-insensitively
+CREATE UNIQUE INDEX idx_wiki_entries_title
+    ON wiki_entries (campaign_id, lower(title));
+
+-- full text search for the players and for the master
+CREATE INDEX idx_wiki_entries_search_public
+    ON wiki_entries USING GIN (search_public);
+CREATE INDEX idx_wiki_entries_search_all
+    ON wiki_entries USING GIN (search_all);
+
+CREATE TABLE wiki_links (
+    entry_id INT NOT NULL,
+    -- normalized title of the linked entry or character
+    target VARCHAR(100) NOT NULL,
+    secret BOOLEAN NOT NULL DEFAULT FALSE,
+    CONSTRAINT fk_wiki_links_entry
+        FOREIGN KEY (entry_id)
+        REFERENCES wiki_entries(entry_id)
+        ON DELETE CASCADE
+);
+
+-- backlinks lookup
+CREATE INDEX idx_wiki_links_target
+    ON wiki_links (target);
+
+CREATE TABLE wiki_revisions (
+    revision_id SERIAL PRIMARY KEY,
+    entry_id INT NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    category VARCHAR(10) NOT NULL,
+    body TEXT NOT NULL,
+    author_id INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_wiki_revisions_entry
+        FOREIGN KEY (entry_id)
+        REFERENCES wiki_entries(entry_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_wiki_revisions_author
+        FOREIGN KEY (author_id)
+        REFERENCES players(player_id)
+);
+
+CREATE TABLE handouts (
+    handout_id SERIAL PRIMARY KEY,
+    campaign_id INT NOT NULL,
+    title VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    -- key of the file content in the blob store
+    file_key VARCHAR(255) NOT NULL UNIQUE,
+    file_name VARCHAR(200) NOT NULL,
+    -- sniffed from the content at upload
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    visibility VARCHAR(10) NOT NULL DEFAULT 'HIDDEN',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revealed_at TIMESTAMP,
+    CONSTRAINT fk_handouts_campaign
+        FOREIGN KEY (campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE
+);
+
+-- players who can see a handout revealed to SELECTED players
+CREATE TABLE handout_recipients (
+    handout_id INT NOT NULL,
+    player_id INT NOT NULL,
+    PRIMARY KEY (handout_id, player_id),
+    CONSTRAINT fk_handout_recipients_handout
+        FOREIGN KEY (handout_id)
+        REFERENCES handouts(handout_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_handout_recipients_player
+        FOREIGN KEY (player_id)
+        REFERENCES players(player_id)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE battle_maps (
+    map_id SERIAL PRIMARY KEY,
+    campaign_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    -- key of the image in the blob store
+    image_key VARCHAR(255) NOT NULL UNIQUE,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    columns INT NOT NULL,
+    rows INT NOT NULL,
+    cell_size INT NOT NULL,
+    -- a bit per cell, row by row, set when the cell is revealed to the players
+    fog BYTEA NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_battle_maps_campaign
+        FOREIGN KEY (campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE battle_map_tokens (
+    token_id SERIAL PRIMARY KEY,
+    map_id INT NOT NULL,
+    character_id INT NOT NULL,
+    -- player controlling the character, NULL for an NPC
+    owner_id INT,
+    x INT NOT NULL,
+    y INT NOT NULL,
+    UNIQUE (map_id, character_id),
+    CONSTRAINT fk_battle_map_tokens_map
+        FOREIGN KEY (map_id)
+        REFERENCES battle_maps(map_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_battle_map_tokens_character
+        FOREIGN KEY (character_id)
+        REFERENCES characters(character_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_battle_map_tokens_owner
+        FOREIGN KEY (owner_id)
+        REFERENCES players(player_id)
+        ON DELETE SET NULL
+);
+
+-- Password reset links, only the SHA-256 of the token is stored
+CREATE TABLE password_reset_tokens (
+    token_id SERIAL PRIMARY KEY,
+    account_id INT NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    -- written from the application, the time zone keeps it comparable with NOW()
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_password_reset_tokens_account
+        FOREIGN KEY (account_id)
+        REFERENCES accounts(account_id)
+        ON DELETE CASCADE
+);
+
+-- Email verification tokens, bound to the email they were sent to
+CREATE TABLE email_verification_tokens (
+    token_id SERIAL PRIMARY KEY,
+    account_id INT NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    -- zoned, the expiry and the resend throttle compare with NOW()
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_email_verification_tokens_account
+        FOREIGN KEY (account_id)
+        REFERENCES accounts(account_id)
+        ON DELETE CASCADE
+);
+
+-- Two-factor recovery codes, only the SHA-256 of the code is stored
+CREATE TABLE account_recovery_codes (
+    code_id SERIAL PRIMARY KEY,
+    account_id INT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_account_recovery_codes_account
+        FOREIGN KEY (account_id)
+        REFERENCES accounts(account_id)
+        ON DELETE CASCADE,
+    UNIQUE (account_id, code_hash)
+);
+
+-- Logins waiting for the second factor, only the SHA-256 of the token is stored
+CREATE TABLE login_challenges (
+    challenge_id SERIAL PRIMARY KEY,
+    account_id INT NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_login_challenges_account
+        FOREIGN KEY (account_id)
+        REFERENCES accounts(account_id)
+        ON DELETE CASCADE
+);
+
+-- Users of OpenID Connect providers linked to an account
+CREATE TABLE account_identities (
+    identity_id SERIAL PRIMARY KEY,
+    account_id INT NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_account_identities_account
+        FOREIGN KEY (account_id)
+        REFERENCES accounts(account_id)
+        ON DELETE CASCADE,
+    UNIQUE (provider, subject)
+);
+
+-- OpenID Connect logins waiting for the callback, only the SHA-256 of the state is stored
+CREATE TABLE oidc_login_states (
+    state_id SERIAL PRIMARY KEY,
+    state_hash CHAR(64) NOT NULL UNIQUE,
+    provider VARCHAR(50) NOT NULL,
+    nonce VARCHAR(255) NOT NULL,
+    code_verifier VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Personal access tokens of the scripts and bots, only the SHA-256 of the token is stored
+CREATE TABLE personal_access_tokens (
+    token_id SERIAL PRIMARY KEY,
+    account_id INT NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_personal_access_tokens_account
+        FOREIGN KEY (account_id)
+        REFERENCES accounts(account_id)
+        ON DELETE CASCADE
+);
+
+-- Sessions opened at login, identified in the token by its jti
+CREATE TABLE account_sessions (
+    session_id SERIAL PRIMARY KEY,
+    account_id INT NOT NULL,
+    token_id VARCHAR(64) NOT NULL UNIQUE,
+    session_version INT NOT NULL,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- compared with NOW() by the session checks and the purge
+    expires_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT fk_account_sessions_account
+        FOREIGN KEY (account_id)
+        REFERENCES accounts(account_id)
+        ON DELETE CASCADE
+);
+
+-- Deletions of accounts requested by their owner, purged at the end of the grace period
+CREATE TABLE account_deletions (
+    account_id INT PRIMARY KEY,
+    requested_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    purge_after TIMESTAMP NOT NULL,
+    -- the account and its player are kept anonymized once purged
+    purged_at TIMESTAMP,
+    CONSTRAINT fk_account_deletions_account
+        FOREIGN KEY (account_id)
+        REFERENCES accounts(account_id)
+        ON DELETE CASCADE
+);
+
+-- Campaigns mastered by an account being deleted, given to another of their players at the purge
+CREATE TABLE account_deletion_handovers (
+    account_id INT NOT NULL,
+    campaign_id INT NOT NULL,
+    new_master_id INT NOT NULL,
+    PRIMARY KEY (account_id, campaign_id),
+    CONSTRAINT fk_account_deletion_handovers_deletion
+        FOREIGN KEY (account_id)
+        REFERENCES account_deletions(account_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_account_deletion_handovers_campaign
+        FOREIGN KEY (campaign_id)
+        REFERENCES campaigns(campaign_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_account_deletion_handovers_player
+        FOREIGN KEY (new_master_id)
+        REFERENCES players(player_id)
+        ON DELETE CASCADE
+);